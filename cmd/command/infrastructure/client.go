@@ -0,0 +1,145 @@
+package infrastructure
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"coredns_api/internal/model"
+	"coredns_api/pkg/interface/controllers"
+)
+
+// commandRequest is the API request made from a command.
+// Path has parameters like "{domain_uuid}", which are replaced with params.
+type commandRequest struct {
+	method string
+	path   string
+	params map[string]string
+	query  map[string]string
+	body   interface{}
+}
+
+// requestExecutor sends the command request and returns the status code and the response body.
+type requestExecutor interface {
+	Do(tenant string, request *commandRequest) (int, []byte, error)
+}
+
+// httpExecutor sends the request to the API server.
+type httpExecutor struct {
+	server string
+	client *http.Client
+}
+
+func newHTTPExecutor(server string) *httpExecutor {
+	return &httpExecutor{
+		server: strings.TrimSuffix(server, "/"),
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (e *httpExecutor) Do(tenant string, request *commandRequest) (int, []byte, error) {
+	path := request.path
+	for key, value := range request.params {
+		path = strings.Replace(path, "{"+key+"}", url.PathEscape(value), 1)
+	}
+
+	query := url.Values{}
+	for key, value := range request.query {
+		query.Set(key, value)
+	}
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var body io.Reader
+	if request.body != nil {
+		data, err := json.Marshal(request.body)
+		if err != nil {
+			return 0, nil, err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(request.method, e.server+path, body)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if request.body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if tenant != "" {
+		req.Header.Set("Tenant", tenant)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+	return resp.StatusCode, data, nil
+}
+
+// offlineExecutor calls the controllers directly,
+// which work on HOSTS_DIR and CONF_PATH through the same interactors as the API server.
+type offlineExecutor struct {
+	handlers map[string]func(c controllers.Context)
+}
+
+func newOfflineExecutor() *offlineExecutor {
+	// The hosts files are loaded once before the controllers use them.
+	InitializeFilesystemRepository().Initialize()
+
+	dcntr := InitializeDomainController()
+	hcntr := InitializeHostController()
+	tcntr := InitializeTenantController()
+	scntr := InitializeSearchController()
+
+	return &offlineExecutor{handlers: map[string]func(c controllers.Context){
+		"POST /v1/domains":                                       dcntr.Add,
+		"GET /v1/domains":                                        dcntr.List,
+		"GET /v1/domains/{domain_uuid}":                          dcntr.Get,
+		"PATCH /v1/domains/{domain_uuid}":                        dcntr.Update,
+		"DELETE /v1/domains/{domain_uuid}":                       dcntr.Delete,
+		"POST /v1/domains/{domain_uuid}/hosts":                   hcntr.Add,
+		"GET /v1/domains/{domain_uuid}/hosts":                    hcntr.List,
+		"GET /v1/domains/{domain_uuid}/hosts/{host_uuid}":        hcntr.Get,
+		"PATCH /v1/domains/{domain_uuid}/hosts/{host_uuid}":      hcntr.Update,
+		"DELETE /v1/domains/{domain_uuid}/hosts/{host_uuid}":     hcntr.Delete,
+		"POST /v1/domains/{domain_uuid}/hosts/{host_uuid}:renew": hcntr.Renew,
+		"GET /v1/tenants":                                        tcntr.List,
+		"GET /v1/search":                                         scntr.Search,
+	}}
+}
+
+func (e *offlineExecutor) Do(tenant string, request *commandRequest) (int, []byte, error) {
+	handler, ok := e.handlers[request.method+" "+request.path]
+	if !ok {
+		return 0, nil, newUsageError("command is not available in offline mode")
+	}
+
+	headers := map[string]string{"Tenant": tenant, controllers.RequestIdHeader: model.NewRequestId("")}
+	c := controllers.NewRecordingContext(headers, request.params, request.query, request.body)
+	handler(c)
+	if c.StatusCode() == 0 {
+		return 0, nil, fmt.Errorf("controller did not respond")
+	}
+
+	// The response is printed same as the one got from the API server.
+	body, err := c.ResponseBody()
+	if err != nil {
+		return 0, nil, err
+	}
+	return c.StatusCode(), body, nil
+}
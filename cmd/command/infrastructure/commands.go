@@ -0,0 +1,252 @@
+package infrastructure
+
+import (
+	"flag"
+	"net/http"
+	"strings"
+
+	"coredns_api/pkg/interface/controllers"
+)
+
+// command is a subcommand like "domains list".
+// Positional args are also used as the path parameters of the request.
+type command struct {
+	resource    string
+	verb        string
+	args        []string
+	description string
+	dryRun      bool
+	// newRequest registers the flags of the command,
+	// and returns the function to make the request after the flags are parsed.
+	newRequest func(fs *flag.FlagSet) func(args map[string]string) *commandRequest
+	print      printer
+}
+
+func (c *command) usage() string {
+	var args []string
+	for _, a := range c.args {
+		args = append(args, "<"+a+">")
+	}
+	return strings.TrimSpace(c.resource + " " + c.verb + " " + strings.Join(args, " "))
+}
+
+func simpleRequest(method, path string) func(fs *flag.FlagSet) func(args map[string]string) *commandRequest {
+	return func(fs *flag.FlagSet) func(args map[string]string) *commandRequest {
+		return func(args map[string]string) *commandRequest {
+			return &commandRequest{method: method, path: path}
+		}
+	}
+}
+
+// listRequest registers the filter and page flags of list commands.
+// tenantFilter is set for the commands which can filter by tenant.
+func listRequest(path string, tenantFilter bool) func(fs *flag.FlagSet) func(args map[string]string) *commandRequest {
+	return func(fs *flag.FlagSet) func(args map[string]string) *commandRequest {
+		filters := map[string]*string{
+			"name":    fs.String("name", "", "name prefix, or glob pattern with any of *?["),
+			"address": fs.String("address", "", "address or CIDR of the hosts"),
+			"limit":   fs.String("limit", "", "max number of the items in the page"),
+			"cursor":  fs.String("cursor", "", "cursor of the page, shown with the previous page"),
+		}
+		if tenantFilter {
+			filters["tenant"] = fs.String("filter-tenant", "", "tenant UUID which can access the domain. only for admin tenants")
+		}
+
+		return func(args map[string]string) *commandRequest {
+			query := map[string]string{}
+			for key, value := range filters {
+				if *value != "" {
+					query[key] = *value
+				}
+			}
+			return &commandRequest{method: http.MethodGet, path: path, query: query}
+		}
+	}
+}
+
+func splitFlagList(value string) []string {
+	var list []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+var commands = []*command{
+	{
+		resource:    "domains",
+		verb:        "list",
+		description: "List domains of the tenant",
+		newRequest:  listRequest("/v1/domains", true),
+		print:       printDomainList,
+	},
+	{
+		resource:    "domains",
+		verb:        "get",
+		args:        []string{"domain_uuid"},
+		description: "Get domain and its hosts",
+		newRequest:  simpleRequest(http.MethodGet, "/v1/domains/{domain_uuid}"),
+		print:       printDomainInfo,
+	},
+	{
+		resource:    "domains",
+		verb:        "create",
+		args:        []string{"domain"},
+		description: "Create domain",
+		dryRun:      true,
+		newRequest: func(fs *flag.FlagSet) func(args map[string]string) *commandRequest {
+			tenants := fs.String("tenants", "", "comma separated tenant UUIDs which can access the domain")
+			return func(args map[string]string) *commandRequest {
+				body := controllers.DomainRequest{Name: args["domain"], Tenants: splitFlagList(*tenants)}
+				return &commandRequest{method: http.MethodPost, path: "/v1/domains", body: body}
+			}
+		},
+		print: printDomainInfo,
+	},
+	{
+		resource:    "domains",
+		verb:        "update",
+		args:        []string{"domain_uuid"},
+		description: "Update tenants of domain",
+		dryRun:      true,
+		newRequest: func(fs *flag.FlagSet) func(args map[string]string) *commandRequest {
+			tenants := fs.String("tenants", "", "comma separated tenant UUIDs which can access the domain")
+			return func(args map[string]string) *commandRequest {
+				body := controllers.DomainUpdateRequest{Tenants: splitFlagList(*tenants)}
+				return &commandRequest{method: http.MethodPatch, path: "/v1/domains/{domain_uuid}", body: body}
+			}
+		},
+		print: printDomainInfo,
+	},
+	{
+		resource:    "domains",
+		verb:        "delete",
+		args:        []string{"domain_uuid"},
+		description: "Delete domain",
+		dryRun:      true,
+		newRequest:  simpleRequest(http.MethodDelete, "/v1/domains/{domain_uuid}"),
+		print:       printNothing,
+	},
+	{
+		resource:    "hosts",
+		verb:        "list",
+		args:        []string{"domain_uuid"},
+		description: "List hosts of domain",
+		newRequest:  listRequest("/v1/domains/{domain_uuid}/hosts", false),
+		print:       printHostList,
+	},
+	{
+		resource:    "hosts",
+		verb:        "get",
+		args:        []string{"domain_uuid", "host_uuid"},
+		description: "Get host",
+		newRequest:  simpleRequest(http.MethodGet, "/v1/domains/{domain_uuid}/hosts/{host_uuid}"),
+		print:       printDomainInfo,
+	},
+	{
+		resource:    "hosts",
+		verb:        "add",
+		args:        []string{"domain_uuid", "hostname"},
+		description: "Add host with address, or with the address allocated from pool",
+		dryRun:      true,
+		newRequest: func(fs *flag.FlagSet) func(args map[string]string) *commandRequest {
+			address := fs.String("address", "", "IPv4 or IPv6 address of the host")
+			pool := fs.String("pool", "", "pool UUID to allocate the address from")
+			expiresAt := fs.String("expires-at", "", "RFC 3339 time the host expires at")
+			ttlLease := fs.String("ttl-lease", "", "lease of the host like 1h30m")
+			return func(args map[string]string) *commandRequest {
+				body := controllers.HostRequest{
+					Name:      args["hostname"],
+					Address:   *address,
+					Pool:      *pool,
+					ExpiresAt: *expiresAt,
+					TtlLease:  *ttlLease}
+				return &commandRequest{method: http.MethodPost, path: "/v1/domains/{domain_uuid}/hosts", body: body}
+			}
+		},
+		print: printDomainInfo,
+	},
+	{
+		resource:    "hosts",
+		verb:        "update",
+		args:        []string{"domain_uuid", "host_uuid"},
+		description: "Update hostname, address or lease of host",
+		dryRun:      true,
+		newRequest: func(fs *flag.FlagSet) func(args map[string]string) *commandRequest {
+			hostname := fs.String("hostname", "", "new hostname")
+			address := fs.String("address", "", "new IPv4 or IPv6 address")
+			expiresAt := fs.String("expires-at", "", "RFC 3339 time the host expires at")
+			ttlLease := fs.String("ttl-lease", "", "lease of the host like 1h30m")
+			return func(args map[string]string) *commandRequest {
+				body := controllers.HostRequest{
+					Name:      *hostname,
+					Address:   *address,
+					ExpiresAt: *expiresAt,
+					TtlLease:  *ttlLease}
+				return &commandRequest{method: http.MethodPatch, path: "/v1/domains/{domain_uuid}/hosts/{host_uuid}", body: body}
+			}
+		},
+		print: printDomainInfo,
+	},
+	{
+		resource:    "hosts",
+		verb:        "rm",
+		args:        []string{"domain_uuid", "host_uuid"},
+		description: "Remove host",
+		dryRun:      true,
+		newRequest:  simpleRequest(http.MethodDelete, "/v1/domains/{domain_uuid}/hosts/{host_uuid}"),
+		print:       printNothing,
+	},
+	{
+		resource:    "hosts",
+		verb:        "renew",
+		args:        []string{"domain_uuid", "host_uuid"},
+		description: "Extend the expiry of leased host",
+		newRequest: func(fs *flag.FlagSet) func(args map[string]string) *commandRequest {
+			ttlLease := fs.String("ttl-lease", "", "new lease of the host like 1h30m")
+			return func(args map[string]string) *commandRequest {
+				body := controllers.HostRenewRequest{TtlLease: *ttlLease}
+				return &commandRequest{method: http.MethodPost, path: "/v1/domains/{domain_uuid}/hosts/{host_uuid}:renew", body: body}
+			}
+		},
+		print: printHost,
+	},
+	{
+		resource:    "hosts",
+		verb:        "search",
+		description: "Search hosts across the domains of the tenant",
+		newRequest: func(fs *flag.FlagSet) func(args map[string]string) *commandRequest {
+			name := fs.String("name", "", "hostname, or hostname prefix ending with *")
+			address := fs.String("address", "", "address or CIDR of the hosts")
+			return func(args map[string]string) *commandRequest {
+				query := map[string]string{}
+				if *name != "" {
+					query["name"] = *name
+				}
+				if *address != "" {
+					query["address"] = *address
+				}
+				return &commandRequest{method: http.MethodGet, path: "/v1/search", query: query}
+			}
+		},
+		print: printSearchResult,
+	},
+	{
+		resource:    "tenants",
+		verb:        "list",
+		description: "List tenants and their accessible domains",
+		newRequest:  simpleRequest(http.MethodGet, "/v1/tenants"),
+		print:       printTenantList,
+	},
+}
+
+func findCommand(resource, verb string) *command {
+	for _, c := range commands {
+		if c.resource == resource && c.verb == verb {
+			return c
+		}
+	}
+	return nil
+}
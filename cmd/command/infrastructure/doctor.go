@@ -0,0 +1,95 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	inf "coredns_api/internal/infrastructure"
+)
+
+type doctorProblemResult struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+	Fixable bool   `json:"fixable"`
+	Fixed   bool   `json:"fixed"`
+}
+
+type doctorResult struct {
+	Problems []doctorProblemResult `json:"problems"`
+}
+
+// runDoctor checks HOSTS_DIR and CONF_PATH directly, even when the API server can't start with them.
+// Exit status is 0 when no problem is left.
+func runDoctor(options *globalOptions, arguments []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet(commandName+" doctor", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	options.register(fs)
+	fix := fs.Bool("fix", false, "write the safe repairs. the API server has to be stopped")
+	positional, err := parseInterspersed(fs, arguments)
+	if err != nil {
+		return 2
+	}
+	if len(positional) != 0 {
+		fmt.Fprintf(stderr, "usage: %s doctor [--fix]\n", commandName)
+		return 2
+	}
+
+	if *fix {
+		confLock, err := inf.LockConf()
+		if err != nil {
+			fmt.Fprintln(stderr, "error: "+err.Error()+". stop the API server to fix the files")
+			return 1
+		}
+		defer confLock.Unlock()
+	}
+
+	diagnosis, err := InitializeDoctorInteractor().Diagnose(*fix)
+	if err != nil {
+		fmt.Fprintln(stderr, "error: "+err.Error())
+		return 1
+	}
+
+	if options.output == "json" {
+		result := doctorResult{Problems: []doctorProblemResult{}}
+		for _, p := range diagnosis.Problems {
+			result.Problems = append(result.Problems, doctorProblemResult{
+				File: p.File, Line: p.Line, Message: p.Message, Fixable: p.Fixable, Fixed: p.Fixed})
+		}
+		data, err := json.Marshal(result)
+		if err != nil {
+			fmt.Fprintln(stderr, "error: "+err.Error())
+			return 1
+		}
+		err = printJSON(stdout, data)
+		if err != nil {
+			fmt.Fprintln(stderr, "error: "+err.Error())
+			return 1
+		}
+	} else {
+		fixable := 0
+		for _, p := range diagnosis.Problems {
+			status := ""
+			if p.Fixed {
+				status = " (fixed)"
+			} else if p.Fixable {
+				status = " (fixable with --fix)"
+				fixable++
+			}
+			fmt.Fprintln(stdout, p.String()+status)
+		}
+
+		if len(diagnosis.Problems) == 0 {
+			fmt.Fprintln(stdout, "no problem is found")
+		} else if fixable > 0 {
+			fmt.Fprintf(stdout, "\n%d problems found, %d can be fixed with --fix\n", len(diagnosis.Problems), fixable)
+		}
+	}
+
+	if diagnosis.HasUnfixed() {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,202 @@
+package infrastructure
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"text/tabwriter"
+
+	"coredns_api/pkg/interface/controllers"
+)
+
+// printer prints the response body as tables.
+type printer func(w io.Writer, body []byte) error
+
+func newTableWriter(w io.Writer) *tabwriter.Writer {
+	return tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+}
+
+func printJSON(w io.Writer, body []byte) error {
+	if len(body) == 0 {
+		return nil
+	}
+
+	var out bytes.Buffer
+	err := json.Indent(&out, body, "", "    ")
+	if err != nil {
+		return err
+	}
+	out.WriteString("\n")
+	_, err = out.WriteTo(w)
+	return err
+}
+
+func printNothing(w io.Writer, body []byte) error {
+	return nil
+}
+
+func printDomainList(w io.Writer, body []byte) error {
+	var result controllers.DomainListResult
+	err := json.Unmarshal(body, &result)
+	if err != nil {
+		return err
+	}
+
+	tw := newTableWriter(w)
+	fmt.Fprintln(tw, "UUID\tDOMAIN\tTENANTS\tSTATUS")
+	for _, d := range result.Domains {
+		status := d.Status
+		if status == "" {
+			status = "active"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", d.Uuid, d.Domain, strings.Join(d.Tenants, ","), status)
+	}
+	err = tw.Flush()
+	if err != nil {
+		return err
+	}
+	return printPage(w, result.Total, result.Next)
+}
+
+func printHostList(w io.Writer, body []byte) error {
+	var result controllers.HostListResult
+	err := json.Unmarshal(body, &result)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "DOMAIN: %s\n\n", result.Domain)
+	err = printHostTable(w, result.Hosts)
+	if err != nil {
+		return err
+	}
+	return printPage(w, result.Total, result.Next)
+}
+
+// printPage prints the cursor of the next page when the list is paged.
+func printPage(w io.Writer, total int, next string) error {
+	if next == "" {
+		return nil
+	}
+
+	nextUrl, err := url.Parse(next)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "\ntotal: %d, next page: --cursor %s\n", total, nextUrl.Query().Get("cursor"))
+	return err
+}
+
+func printDomainInfo(w io.Writer, body []byte) error {
+	if len(body) == 0 {
+		return nil
+	}
+
+	var result controllers.DomainInfoResult
+	err := json.Unmarshal(body, &result)
+	if err != nil {
+		return err
+	}
+
+	tw := newTableWriter(w)
+	fmt.Fprintf(tw, "DOMAIN:\t%s\n", result.Domain)
+	fmt.Fprintf(tw, "UUID:\t%s\n", result.Uuid)
+	if len(result.Tenants) > 0 {
+		fmt.Fprintf(tw, "TENANTS:\t%s\n", strings.Join(result.Tenants, ","))
+	}
+	if result.Status != "" {
+		fmt.Fprintf(tw, "STATUS:\t%s\n", result.Status)
+		fmt.Fprintf(tw, "ERROR:\t%s\n", result.Error)
+	}
+	err = tw.Flush()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	return printHostTable(w, result.Hosts)
+}
+
+func printHost(w io.Writer, body []byte) error {
+	var result controllers.HostResult
+	err := json.Unmarshal(body, &result)
+	if err != nil {
+		return err
+	}
+	return printHostTable(w, []controllers.HostResult{result})
+}
+
+func printHostTable(w io.Writer, hosts []controllers.HostResult) error {
+	tw := newTableWriter(w)
+	fmt.Fprintln(tw, "UUID\tHOSTNAME\tADDRESS\tEXPIRES_AT")
+	for _, h := range hosts {
+		expiresAt := h.ExpiresAt
+		if expiresAt == "" {
+			expiresAt = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", h.Uuid, h.Name, h.Address, expiresAt)
+	}
+	return tw.Flush()
+}
+
+func printSearchResult(w io.Writer, body []byte) error {
+	var result controllers.SearchResult
+	err := json.Unmarshal(body, &result)
+	if err != nil {
+		return err
+	}
+
+	tw := newTableWriter(w)
+	fmt.Fprintln(tw, "DOMAIN\tUUID\tHOSTNAME\tADDRESS")
+	for _, h := range result.Hosts {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", h.Domain, h.Uuid, h.Name, h.Address)
+	}
+	return tw.Flush()
+}
+
+func printTenantList(w io.Writer, body []byte) error {
+	var result controllers.TenantInfoResult
+	err := json.Unmarshal(body, &result)
+	if err != nil {
+		return err
+	}
+
+	tw := newTableWriter(w)
+	fmt.Fprintln(tw, "TENANT\tDOMAINS")
+	for _, t := range result.Tenants {
+		fmt.Fprintf(tw, "%s\t%s\n", t.Uuid, strings.Join(t.Domains, ","))
+	}
+	return tw.Flush()
+}
+
+// printDryRun prints the file changes as unified diffs.
+func printDryRun(w io.Writer, body []byte) error {
+	var result controllers.DryRunResult
+	err := json.Unmarshal(body, &result)
+	if err != nil {
+		return err
+	}
+
+	if len(result.Changes) == 0 {
+		fmt.Fprintln(w, "no file is changed")
+		return nil
+	}
+	for _, c := range result.Changes {
+		fmt.Fprint(w, c.Diff)
+	}
+	return nil
+}
+
+// printError prints the error response from the controller.
+func printError(w io.Writer, code int, body []byte) {
+	var result controllers.HTTPError
+	err := json.Unmarshal(body, &result)
+	if err != nil || result.Message == "" {
+		fmt.Fprintf(w, "error: %d %s\n", code, strings.TrimSpace(string(body)))
+		return
+	}
+	fmt.Fprintf(w, "error: %d %s\n", code, result.Message)
+}
@@ -1,41 +1,207 @@
 package infrastructure
 
 import (
-	"encoding/json"
+	"flag"
 	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
 
-	"coredns_api/pkg/interface/controllers"
+	inf "coredns_api/internal/infrastructure"
+	"coredns_api/pkg"
 )
 
-type CommandContext struct{}
+const commandName = "corednsctl"
 
-func (c *CommandContext) GetHeader(key string) string {
-	return ""
+type usageError struct {
+	err string
 }
-func (c *CommandContext) ShouldBindJSON(obj interface{}) error {
-	return nil
+
+func newUsageError(err string) error {
+	return &usageError{err: err}
+}
+
+func (e *usageError) Error() string {
+	return e.err
 }
-func (c *CommandContext) Param(key string) string {
-	return ""
+
+// globalOptions are the options available before and after the subcommand.
+type globalOptions struct {
+	server  string
+	tenant  string
+	output  string
+	dryRun  bool
+	verbose bool
 }
-func (c *CommandContext) Bind(obj interface{}) error {
-	return nil
+
+func (o *globalOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.server, "server", o.server, "API server URL like http://127.0.0.1:8080. HOSTS_DIR and CONF_PATH are changed directly if it is not set")
+	fs.StringVar(&o.tenant, "tenant", o.tenant, "tenant UUID to set access control")
+	fs.StringVar(&o.output, "output", o.output, "output format, table or json")
+	fs.StringVar(&o.output, "o", o.output, "shorthand of -output")
+	fs.BoolVar(&o.dryRun, "dry-run", o.dryRun, "show the file changes without writing them")
+	fs.BoolVar(&o.verbose, "verbose", o.verbose, "print logs of the controllers in offline mode")
 }
-func (c *CommandContext) Status(code int) {
-	fmt.Println(code)
+
+// Router runs the subcommand given with the command line arguments, and exits with its status.
+//
+// Exit status is 0 on success, 1 when the request fails, and 2 on invalid usage.
+func Router() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
 }
-func (c *CommandContext) JSON(code int, obj interface{}) {
-	data, err := json.Marshal(obj)
+
+func run(arguments []string, stdout, stderr io.Writer) int {
+	options := &globalOptions{
+		server: os.Getenv("COREDNS_API_SERVER"),
+		tenant: os.Getenv("TENANT"),
+		output: "table",
+	}
+
+	globalFlags := flag.NewFlagSet(commandName, flag.ContinueOnError)
+	globalFlags.SetOutput(stderr)
+	options.register(globalFlags)
+	globalFlags.Usage = func() { printUsage(stderr, globalFlags) }
+	err := globalFlags.Parse(arguments)
 	if err != nil {
-		panic(err)
+		return 2
+	}
+
+	rest := globalFlags.Args()
+	if len(rest) >= 1 && rest[0] == "doctor" {
+		return runDoctor(options, rest[1:], stdout, stderr)
+	}
+	if len(rest) < 2 {
+		printUsage(stderr, globalFlags)
+		return 2
+	}
+
+	cmd := findCommand(rest[0], rest[1])
+	if cmd == nil {
+		fmt.Fprintf(stderr, "unknown command: %s %s\n\n", rest[0], rest[1])
+		printUsage(stderr, globalFlags)
+		return 2
+	}
+
+	fs := flag.NewFlagSet(commandName+" "+cmd.usage(), flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	options.register(fs)
+	newRequest := cmd.newRequest(fs)
+	positional, err := parseInterspersed(fs, rest[2:])
+	if err != nil {
+		return 2
+	}
+	if len(positional) != len(cmd.args) {
+		fmt.Fprintf(stderr, "usage: %s %s\n", commandName, cmd.usage())
+		fs.PrintDefaults()
+		return 2
+	}
+	if options.output != "table" && options.output != "json" {
+		fmt.Fprintln(stderr, "invalid output format: "+options.output)
+		return 2
+	}
+	if options.dryRun && !cmd.dryRun {
+		fmt.Fprintln(stderr, "dry run is not supported by: "+cmd.resource+" "+cmd.verb)
+		return 2
+	}
+
+	args := map[string]string{}
+	for i, name := range cmd.args {
+		args[name] = positional[i]
 	}
-	fmt.Println(string(data))
+
+	request := newRequest(args)
+	request.params = args
+	if options.dryRun {
+		if request.query == nil {
+			request.query = map[string]string{}
+		}
+		request.query["dry_run"] = "true"
+	}
+
+	var executor requestExecutor
+	if options.server != "" {
+		executor = newHTTPExecutor(options.server)
+	} else {
+		// Logs of the controllers are written to stderr not to be mixed with the output.
+		logOutput := io.Discard
+		if options.verbose {
+			logOutput = stderr
+		}
+		slog.SetDefault(pkg.NewLogger(logOutput, "text", slog.LevelDebug))
+
+		// The files are changed only when the API server isn't running, since it doesn't reload them.
+		if request.method != http.MethodGet && !options.dryRun {
+			confLock, err := inf.LockConf()
+			if err != nil {
+				fmt.Fprintln(stderr, "error: "+err.Error()+". use -server while the API server is running")
+				return 1
+			}
+			defer confLock.Unlock()
+		}
+		executor = newOfflineExecutor()
+	}
+
+	code, body, err := executor.Do(options.tenant, request)
+	if err != nil {
+		fmt.Fprintln(stderr, "error: "+err.Error())
+		if _, ok := err.(*usageError); ok {
+			return 2
+		}
+		return 1
+	}
+	if code >= 400 {
+		printError(stderr, code, body)
+		return 1
+	}
+
+	printResult := cmd.print
+	if options.output == "json" {
+		printResult = printJSON
+	} else if options.dryRun {
+		printResult = printDryRun
+	}
+
+	err = printResult(stdout, body)
+	if err != nil {
+		fmt.Fprintln(stderr, "error: invalid response. "+err.Error())
+		return 1
+	}
+	return 0
 }
 
-// Functions in this router has to be not processes with updating domain data.
-// They can only read functions.
-func Router() {
-	tcntr := InitializeTenantController()
-	var c controllers.Context = &CommandContext{}
-	tcntr.List(c)
+// parseInterspersed parses the flags given before and after the positional arguments.
+func parseInterspersed(fs *flag.FlagSet, arguments []string) ([]string, error) {
+	var positional []string
+	for {
+		err := fs.Parse(arguments)
+		if err != nil {
+			return nil, err
+		}
+
+		arguments = fs.Args()
+		if len(arguments) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, arguments[0])
+		arguments = arguments[1:]
+	}
+}
+
+func printUsage(w io.Writer, globalFlags *flag.FlagSet) {
+	fmt.Fprintf(w, "usage: %s [options] <resource> <verb> [args] [flags]\n\n", commandName)
+	fmt.Fprintln(w, "commands:")
+
+	var lines []string
+	for _, c := range commands {
+		lines = append(lines, fmt.Sprintf("  %-48s %s", c.usage(), c.description))
+	}
+	lines = append(lines, fmt.Sprintf("  %-48s %s", "doctor [--fix]", "Check hosts dir and Corefile, and repair them safely"))
+	sort.Strings(lines)
+	fmt.Fprintln(w, strings.Join(lines, "\n"))
+
+	fmt.Fprintln(w, "\noptions:")
+	globalFlags.PrintDefaults()
 }
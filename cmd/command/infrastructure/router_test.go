@@ -0,0 +1,203 @@
+package infrastructure
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	inf "coredns_api/internal/infrastructure"
+	"coredns_api/pkg/interface/controllers"
+)
+
+const testTenant = "df397e50-8006-450e-b18b-5c5bd940baff"
+
+// setTestDirs sets the empty HOSTS_DIR and CONF_PATH in a temporary directory for the offline mode.
+func setTestDirs(t *testing.T) string {
+	dir := t.TempDir()
+	hostsDir := filepath.Join(dir, "hosts")
+	err := os.Mkdir(hostsDir, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOSTS_DIR", hostsDir)
+	t.Setenv("CONF_PATH", filepath.Join(dir, "coredns.conf"))
+	t.Setenv("COREDNS_API_SERVER", "")
+	t.Setenv("TENANT", testTenant)
+	return hostsDir
+}
+
+// runTest runs the command, and returns its exit status and outputs.
+func runTest(arguments ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(arguments, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRunOffline(t *testing.T) {
+	hostsDir := setTestDirs(t)
+
+	code, stdout, stderr := runTest("-o", "json", "domains", "create", "hogehoge.hoge", "--tenants", testTenant)
+	if code != 0 {
+		t.Errorf("domain is not created: %d %s", code, stderr)
+		return
+	}
+	var domain controllers.DomainInfoResult
+	err := json.Unmarshal([]byte(stdout), &domain)
+	if err != nil || domain.Domain != "hogehoge.hoge" {
+		t.Error("domain is not printed as json: " + stdout)
+		return
+	}
+
+	// Flags are given after the positional args as well.
+	code, stdout, stderr = runTest("hosts", "add", domain.Uuid, "hogeserver1", "--address", "172.21.1.1")
+	if code != 0 {
+		t.Errorf("host is not added: %d %s", code, stderr)
+		return
+	}
+	if !strings.Contains(stdout, "hogeserver1.hogehoge.hoge") || !strings.Contains(stdout, "172.21.1.1") {
+		t.Error("added host is not printed: " + stdout)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(hostsDir, "hogehoge.hoge"))
+	if err != nil || !strings.Contains(string(data), "172.21.1.1") {
+		t.Error("hosts file is not written")
+	}
+
+	code, stdout, _ = runTest("hosts", "list", domain.Uuid)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if code != 0 || lines[0] != "DOMAIN: hogehoge.hoge" || !strings.HasPrefix(lines[2], "UUID") || !strings.Contains(lines[3], "hogeserver1.hogehoge.hoge") {
+		t.Error("hosts are not printed as a table: " + stdout)
+	}
+
+	// Dry run prints the diff, and doesn't write the file.
+	code, stdout, stderr = runTest("hosts", "add", domain.Uuid, "hogeserver2", "--address", "172.21.1.2", "--dry-run")
+	if code != 0 || !strings.Contains(stdout, "+172.21.1.2") {
+		t.Errorf("diff is not printed: %d %s %s", code, stdout, stderr)
+	}
+	data, _ = ioutil.ReadFile(filepath.Join(hostsDir, "hogehoge.hoge"))
+	if strings.Contains(string(data), "172.21.1.2") {
+		t.Error("hosts file is written in dry run")
+	}
+
+	code, _, stderr = runTest("-tenant", "02c03bd4-fe2e-45f2-85b6-b535af15215d", "hosts", "list", domain.Uuid)
+	if code != 1 || !strings.HasPrefix(stderr, "error: 400 ") {
+		t.Errorf("error response is not printed: %d %s", code, stderr)
+	}
+}
+
+func TestRunUsage(t *testing.T) {
+	setTestDirs(t)
+
+	usages := [][]string{
+		{},
+		{"domains"},
+		{"domains", "hoge"},
+		{"domains", "get"},
+		{"-o", "yaml", "domains", "list"},
+		{"domains", "list", "--dry-run"},
+		{"domains", "list", "--hoge"},
+	}
+	for _, arguments := range usages {
+		code, _, _ := runTest(arguments...)
+		if code != 2 {
+			t.Errorf("invalid usage is accepted: %v %d", arguments, code)
+		}
+	}
+}
+
+func TestRunOfflineLocked(t *testing.T) {
+	setTestDirs(t)
+	code, _, _ := runTest("domains", "create", "hogehoge.hoge", "--tenants", testTenant)
+	if code != 0 {
+		t.Error("domain is not created")
+		return
+	}
+
+	// The lock is taken by the API server while it runs.
+	lock, err := inf.LockConf()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer lock.Unlock()
+
+	code, _, stderr := runTest("domains", "create", "fugafuga.fuga", "--tenants", testTenant)
+	if code != 1 || !strings.Contains(stderr, "-server") {
+		t.Errorf("files are changed while the server runs: %d %s", code, stderr)
+	}
+	code, _, _ = runTest("doctor", "--fix")
+	if code != 1 {
+		t.Error("files are fixed while the server runs")
+	}
+
+	// Reads and dry runs don't change the files.
+	code, stdout, _ := runTest("domains", "list")
+	if code != 0 || !strings.Contains(stdout, "hogehoge.hoge") || strings.Contains(stdout, "fugafuga.fuga") {
+		t.Error("domains are not listed while the server runs: " + stdout)
+	}
+	code, _, stderr = runTest("domains", "create", "fugafuga.fuga", "--tenants", testTenant, "--dry-run")
+	if code != 0 {
+		t.Errorf("dry run is refused while the server runs: %s", stderr)
+	}
+}
+
+func TestRunServer(t *testing.T) {
+	setTestDirs(t)
+
+	var got *http.Request
+	var gotBody controllers.HostRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		if strings.HasSuffix(r.URL.Path, "/missing/hosts") {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":404,"message":"target domain is not found"}`))
+			return
+		}
+		if r.URL.Query().Get("dry_run") == "true" {
+			w.Write([]byte(`{"dry_run":true,"changes":[{"path":"hogehoge.hoge","diff":"+172.21.1.1 hogeserver1.hogehoge.hoge\n"}]}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"domain":"hogehoge.hoge","uuid":"hoge","hosts":[{"uuid":"fuga","hostname":"hogeserver1.hogehoge.hoge","address":"172.21.1.1"}]}`))
+	}))
+	defer server.Close()
+
+	// The files aren't locked for the requests to the server.
+	lock, err := inf.LockConf()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer lock.Unlock()
+
+	code, stdout, stderr := runTest("-server", server.URL+"/", "hosts", "add", "hoge/hoge", "hogeserver1", "--address", "172.21.1.1", "--dry-run")
+	if code != 0 {
+		t.Errorf("request failed: %d %s", code, stderr)
+		return
+	}
+	if got.Method != http.MethodPost || got.URL.EscapedPath() != "/v1/domains/hoge%2Fhoge/hosts" || got.URL.Query().Get("dry_run") != "true" {
+		t.Errorf("request is missmatched: %s %s", got.Method, got.URL.String())
+	}
+	if got.Header.Get("Tenant") != testTenant || gotBody.Name != "hogeserver1" || gotBody.Address != "172.21.1.1" {
+		t.Error("tenant or body of the request is missmatched")
+	}
+	if stdout != "+172.21.1.1 hogeserver1.hogehoge.hoge\n" {
+		t.Error("diff is not printed: " + stdout)
+	}
+
+	code, stdout, _ = runTest("-server", server.URL, "hosts", "add", "hoge", "hogeserver1", "--address", "172.21.1.1")
+	if code != 0 || !strings.Contains(stdout, "DOMAIN:  hogehoge.hoge") || !strings.Contains(stdout, "hogeserver1.hogehoge.hoge") {
+		t.Error("domain is not printed as a table: " + stdout)
+	}
+
+	code, _, stderr = runTest("-server", server.URL, "hosts", "add", "missing", "hogeserver1", "--address", "172.21.1.1")
+	if code != 1 || stderr != "error: 404 target domain is not found\n" {
+		t.Errorf("error response is not printed: %d %s", code, stderr)
+	}
+}
@@ -11,6 +11,38 @@ import (
 	"coredns_api/pkg/interface/controllers"
 )
 
+func InitializeFilesystemRepository() usecase.IFilesystemRepository {
+	wire.Build(
+		repository.NewFileRepository,
+		inf.NewFilesystem,
+	)
+	return nil
+}
+
+func InitializeDomainController() *controllers.DomainController {
+	wire.Build(
+		controllers.NewDomainController,
+		usecase.NewDomainInteractor,
+		repository.NewFileRepository,
+		repository.NewEventBus,
+		repository.NewMetrics,
+		inf.NewFilesystem,
+	)
+	return nil
+}
+
+func InitializeHostController() *controllers.HostController {
+	wire.Build(
+		controllers.NewHostController,
+		usecase.NewHostInteractor,
+		repository.NewFileRepository,
+		repository.NewEventBus,
+		repository.NewMetrics,
+		inf.NewFilesystem,
+	)
+	return nil
+}
+
 func InitializeTenantController() *controllers.TenantController {
 	wire.Build(
 		controllers.NewTenantController,
@@ -20,3 +52,22 @@ func InitializeTenantController() *controllers.TenantController {
 	)
 	return nil
 }
+
+func InitializeSearchController() *controllers.SearchController {
+	wire.Build(
+		controllers.NewSearchController,
+		usecase.NewSearchInteractor,
+		repository.NewFileRepository,
+		inf.NewFilesystem,
+	)
+	return nil
+}
+
+func InitializeDoctorInteractor() *usecase.DoctorInteractor {
+	wire.Build(
+		usecase.NewDoctorInteractor,
+		repository.NewDiagnosisRepository,
+		inf.NewFilesystem,
+	)
+	return nil
+}
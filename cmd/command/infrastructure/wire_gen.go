@@ -14,6 +14,32 @@ import (
 
 // Injectors from wire.go:
 
+func InitializeFilesystemRepository() usecase.IFilesystemRepository {
+	iFilesystem := infrastructure.NewFilesystem()
+	iFilesystemRepository := repository.NewFileRepository(iFilesystem)
+	return iFilesystemRepository
+}
+
+func InitializeDomainController() *controllers.DomainController {
+	iFilesystem := infrastructure.NewFilesystem()
+	iFilesystemRepository := repository.NewFileRepository(iFilesystem)
+	iEventBus := repository.NewEventBus()
+	iMetrics := repository.NewMetrics()
+	domainInteractor := usecase.NewDomainInteractor(iFilesystemRepository, iEventBus, iMetrics)
+	domainController := controllers.NewDomainController(domainInteractor)
+	return domainController
+}
+
+func InitializeHostController() *controllers.HostController {
+	iFilesystem := infrastructure.NewFilesystem()
+	iFilesystemRepository := repository.NewFileRepository(iFilesystem)
+	iEventBus := repository.NewEventBus()
+	iMetrics := repository.NewMetrics()
+	hostInteractor := usecase.NewHostInteractor(iFilesystemRepository, iEventBus, iMetrics)
+	hostController := controllers.NewHostController(hostInteractor)
+	return hostController
+}
+
 func InitializeTenantController() *controllers.TenantController {
 	iFilesystem := infrastructure.NewFilesystem()
 	iFilesystemRepository := repository.NewFileRepository(iFilesystem)
@@ -21,3 +47,18 @@ func InitializeTenantController() *controllers.TenantController {
 	tenantController := controllers.NewTenantController(tenantInteractor)
 	return tenantController
 }
+
+func InitializeSearchController() *controllers.SearchController {
+	iFilesystem := infrastructure.NewFilesystem()
+	iFilesystemRepository := repository.NewFileRepository(iFilesystem)
+	searchInteractor := usecase.NewSearchInteractor(iFilesystemRepository)
+	searchController := controllers.NewSearchController(searchInteractor)
+	return searchController
+}
+
+func InitializeDoctorInteractor() *usecase.DoctorInteractor {
+	iFilesystem := infrastructure.NewFilesystem()
+	iDiagnosisRepository := repository.NewDiagnosisRepository(iFilesystem)
+	doctorInteractor := usecase.NewDoctorInteractor(iDiagnosisRepository)
+	return doctorInteractor
+}
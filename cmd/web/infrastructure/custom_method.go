@@ -0,0 +1,68 @@
+package infrastructure
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Gin handles ':' in a path as the beginning of a path parameter,
+// so custom methods like "/v1/domains/:domain_uuid/hosts:import" can not be registered as routes.
+// They are dispatched from NoRoute with customMethodRouter instead.
+type customMethodRouter struct {
+	routes []*customMethodRoute
+}
+
+type customMethodRoute struct {
+	method  string
+	path    string
+	pattern *regexp.Regexp
+	handler gin.HandlerFunc
+}
+
+// customMethodRouteKey is the key of the path of the custom method handling the request in gin.Context,
+// which is used like gin.Context.FullPath() of the routes.
+const customMethodRouteKey = "custom_method_route"
+
+var pathParamMatcher = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+// Handle registers a handler for a path written like "/v1/domains/{domain_uuid}/hosts:import".
+func (r *customMethodRouter) Handle(method, path string, handler gin.HandlerFunc) {
+	pattern := "^"
+	rest := path
+	for _, loc := range pathParamMatcher.FindAllStringSubmatchIndex(path, -1) {
+		offset := len(path) - len(rest)
+		pattern += regexp.QuoteMeta(rest[:loc[0]-offset])
+		pattern += "(?P<" + path[loc[2]:loc[3]] + ">[^/:]+)"
+		rest = path[loc[1]:]
+	}
+	pattern += regexp.QuoteMeta(rest) + "$"
+
+	r.routes = append(r.routes, &customMethodRoute{
+		method:  method,
+		path:    path,
+		pattern: regexp.MustCompile(pattern),
+		handler: handler,
+	})
+}
+
+func (r *customMethodRouter) NoRoute(c *gin.Context) {
+	for _, route := range r.routes {
+		matched := route.pattern.FindStringSubmatch(c.Request.URL.Path)
+		if matched == nil || !strings.EqualFold(route.method, c.Request.Method) {
+			continue
+		}
+
+		for i, name := range route.pattern.SubexpNames() {
+			if name != "" {
+				c.Params = append(c.Params, gin.Param{Key: name, Value: matched[i]})
+			}
+		}
+		c.Set(customMethodRouteKey, route.path)
+		route.handler(c)
+		return
+	}
+
+	// Gin responds its default 404 if nothing is written here.
+}
@@ -0,0 +1,41 @@
+package infrastructure
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"coredns_api/internal/model"
+	"coredns_api/pkg/interface/controllers"
+)
+
+// probePaths are requested every few seconds by the orchestrator, so they are logged only at debug level.
+var probePaths = map[string]bool{"/healthz": true, "/readyz": true}
+
+// requestLogger gives the request ID to the request, and logs the request after it is handled.
+// The request ID is set to the request header, so that the controllers write it in their logs,
+// and returned in the response header.
+func requestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestId := model.NewRequestId(c.GetHeader(controllers.RequestIdHeader))
+		c.Request.Header.Set(controllers.RequestIdHeader, requestId)
+		c.Header(controllers.RequestIdHeader, requestId)
+
+		start := time.Now()
+		c.Next()
+
+		level := slog.LevelInfo
+		if probePaths[c.FullPath()] {
+			level = slog.LevelDebug
+		}
+
+		controllers.NewRequestLogger(c).Log(c, level, "request",
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"status", c.Writer.Status(),
+			"latency_ms", time.Since(start).Milliseconds(),
+			"client_ip", c.ClientIP(),
+		)
+	}
+}
@@ -0,0 +1,43 @@
+package infrastructure
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "coredns_api_http_requests_total",
+		Help: "Number of the HTTP requests by the route and the status.",
+	}, []string{"method", "route", "status"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "coredns_api_http_request_duration_seconds",
+		Help: "Duration of the HTTP requests by the route and the status.",
+	}, []string{"method", "route", "status"})
+)
+
+// requestMetrics records the requests by the registered route, not by the path,
+// so that the UUIDs in the paths don't make a series for every domain and host.
+func requestMetrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = c.GetString(customMethodRouteKey)
+		}
+		if route == "" {
+			route = "unmatched"
+		}
+
+		status := strconv.Itoa(c.Writer.Status())
+		httpRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+		httpRequestDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
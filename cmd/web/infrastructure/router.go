@@ -1,37 +1,248 @@
 package infrastructure
 
 import (
+	"log/slog"
 	"os"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"github.com/swaggo/gin-swagger/swaggerFiles"
 
 	_ "coredns_api/docs"
+	inf "coredns_api/internal/infrastructure"
+	"coredns_api/internal/model"
+	"coredns_api/pkg"
+	"coredns_api/pkg/interface/controllers"
+	"coredns_api/pkg/interface/dnsserver"
 )
 
-func Router() {
+// idempotent handles the request with Idempotency-Key only once, and replays its response for the retries.
+func idempotent(icntr *controllers.IdempotencyController, handler func(c controllers.Context)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		icntr.Handle(c, c.Request.Method+" "+c.Request.URL.RequestURI(), handler)
+	}
+}
+
+// NewRouter returns the router with the API routes.
+func NewRouter() *gin.Engine {
 	dcntr := InitializeDomainController()
 	hcntr := InitializeHostController()
-
-	var Server = os.Getenv("SERVER")
-	var Port = os.Getenv("PORT")
+	zcntr := InitializeZoneController()
+	rcntr := InitializeReverseZoneController()
+	pcntr := InitializePoolController()
+	tcntr := InitializeTenantController()
+	scntr := InitializeSearchController()
+	icntr := InitializeIdempotencyController()
+	ecntr := InitializeEventController()
+	pdcntr := InitializePowerDNSController()
+	hlcntr := InitializeHealthController()
 
 	var Router *gin.Engine
-	Router = gin.Default()
+	Router = gin.New()
+	Router.Use(requestLogger(), requestMetrics(), gin.Recovery())
 
-	Router.POST("/v1/domains", func(c *gin.Context) { dcntr.Add(c) })
+	Router.POST("/v1/domains", idempotent(icntr, dcntr.Add))
 	Router.GET("/v1/domains", func(c *gin.Context) { dcntr.List(c) })
 	Router.GET("/v1/domains/:domain_uuid", func(c *gin.Context) { dcntr.Get(c) })
 	Router.PATCH("/v1/domains/:domain_uuid", func(c *gin.Context) { dcntr.Update(c) })
 	Router.DELETE("/v1/domains/:domain_uuid", func(c *gin.Context) { dcntr.Delete(c) })
 
-	Router.POST("/v1/domains/:domain_uuid/hosts", func(c *gin.Context) { hcntr.Add(c) })
+	Router.POST("/v1/domains/:domain_uuid/hosts", idempotent(icntr, hcntr.Add))
 	Router.GET("/v1/domains/:domain_uuid/hosts", func(c *gin.Context) { hcntr.List(c) })
+	Router.PUT("/v1/domains/:domain_uuid/hosts", func(c *gin.Context) { hcntr.Apply(c) })
 	Router.PATCH("/v1/domains/:domain_uuid/hosts/:host_uuid", func(c *gin.Context) { hcntr.Update(c) })
 	Router.GET("/v1/domains/:domain_uuid/hosts/:host_uuid", func(c *gin.Context) { hcntr.Get(c) })
 	Router.DELETE("/v1/domains/:domain_uuid/hosts/:host_uuid", func(c *gin.Context) { hcntr.Delete(c) })
 
+	Router.GET("/v1/tenants", func(c *gin.Context) { tcntr.List(c) })
+
+	Router.GET("/v1/search", func(c *gin.Context) { scntr.Search(c) })
+
+	Router.GET("/v1/events", func(c *gin.Context) { ecntr.Stream(c) })
+
+	Router.GET("/v1/reverse_zones", func(c *gin.Context) { rcntr.List(c) })
+
+	Router.POST("/v1/pools", idempotent(icntr, pcntr.Add))
+	Router.GET("/v1/pools", func(c *gin.Context) { pcntr.List(c) })
+	Router.GET("/v1/pools/:pool_uuid", func(c *gin.Context) { pcntr.Get(c) })
+	Router.DELETE("/v1/pools/:pool_uuid", func(c *gin.Context) { pcntr.Delete(c) })
+
+	// PowerDNS compatible API for the existing tools.
+	Router.GET("/api/v1/servers", func(c *gin.Context) { pdcntr.ListServers(c) })
+	Router.GET("/api/v1/servers/:server_id", func(c *gin.Context) { pdcntr.GetServer(c) })
+	Router.GET("/api/v1/servers/:server_id/zones", func(c *gin.Context) { pdcntr.ListZones(c) })
+	Router.POST("/api/v1/servers/:server_id/zones", func(c *gin.Context) { pdcntr.AddZone(c) })
+	Router.GET("/api/v1/servers/:server_id/zones/:zone_id", func(c *gin.Context) { pdcntr.GetZone(c) })
+	Router.PATCH("/api/v1/servers/:server_id/zones/:zone_id", func(c *gin.Context) { pdcntr.PatchZone(c) })
+	Router.DELETE("/api/v1/servers/:server_id/zones/:zone_id", func(c *gin.Context) { pdcntr.DeleteZone(c) })
+
+	var customMethods customMethodRouter
+	customMethods.Handle("POST", "/v1/domains:import", idempotent(icntr, zcntr.Import))
+	customMethods.Handle("POST", "/v1/domains/{domain_uuid}/hosts:import", idempotent(icntr, hcntr.Import))
+	customMethods.Handle("GET", "/v1/domains/{domain_uuid}/hosts:export", func(c *gin.Context) { hcntr.Export(c) })
+	customMethods.Handle("POST", "/v1/domains/{domain_uuid}/hosts/{host_uuid}:renew", idempotent(icntr, hcntr.Renew))
+	Router.NoRoute(customMethods.NoRoute)
+
+	Router.GET("/healthz", func(c *gin.Context) { hlcntr.Healthz(c) })
+	Router.GET("/readyz", func(c *gin.Context) { hlcntr.Readyz(c) })
+
+	return Router
+}
+
+// NewExternalDNSRouter returns the router of the external-dns webhook provider.
+// It is served on its own listener, since external-dns expects the provider at the root path.
+func NewExternalDNSRouter() *gin.Engine {
+	edcntr := InitializeExternalDNSController()
+
+	var Router *gin.Engine
+	Router = gin.New()
+	Router.Use(requestLogger(), requestMetrics(), gin.Recovery())
+
+	Router.GET("/", func(c *gin.Context) { edcntr.Negotiate(c) })
+	Router.GET("/records", func(c *gin.Context) { edcntr.Records(c) })
+	Router.POST("/records", func(c *gin.Context) { edcntr.ApplyChanges(c) })
+	Router.POST("/adjustendpoints", func(c *gin.Context) { edcntr.AdjustEndpoints(c) })
+
+	return Router
+}
+
+// NewMetricsRouter returns the router of the Prometheus metrics.
+// It is served on its own listener, since the metrics have the tenant UUIDs which the tenants must not see.
+func NewMetricsRouter() *gin.Engine {
+	var Router *gin.Engine
+	Router = gin.New()
+	Router.Use(gin.Recovery())
+
+	Router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	return Router
+}
+
+// serve runs the listener in background, and exits when it stops.
+func serve(name string, listen func() error) {
+	go func() {
+		err := listen()
+		slog.Error(name+" listener is stopped", "error", err)
+		os.Exit(1)
+	}()
+}
+
+func Router() {
+	var Server = os.Getenv("SERVER")
+	var Port = os.Getenv("PORT")
+
+	err := pkg.SetLogger()
+	if err != nil {
+		panic(err)
+	}
+
+	// The lock is kept while the server runs, so that the offline commands don't change the files under it.
+	confLock, err := inf.LockConf()
+	if err != nil {
+		panic(err)
+	}
+	defer confLock.Unlock()
+
+	// The hosts files are loaded once before any listener or background job uses the cache.
+	InitializeFilesystemRepository().Initialize()
+
+	Router := NewRouter()
+
+	// The window and the max keys are got on every request, so invalid ones are found at the start.
+	_, err = model.GetIdempotencyWindow()
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = model.GetIdempotencyMaxKeys()
+	if err != nil {
+		panic(err)
+	}
+
+	// The API keys are got on every request, so invalid ones are found at the start.
+	_, err = model.GetPowerDNSApiKeys()
+	if err != nil {
+		panic(err)
+	}
+
+	// The transfer servers are got on every import, so invalid ones are found at the start.
+	_, err = model.GetZoneTransferServers()
+	if err != nil {
+		panic(err)
+	}
+
+	// The probe is got on every readiness check, so an invalid one is found at the start.
+	probe, err := model.GetReadinessProbe()
+	if err != nil {
+		panic(err)
+	}
+
+	// The canary is written only here, so that the readiness checks never change the domains.
+	// The server still starts without it, and the readiness reports CoreDNS is not ready.
+	if probe != nil {
+		err = InitializeHealthInteractor().WriteCanary(probe)
+		if err != nil {
+			slog.Error("failed to write the canary", "domain", probe.Domain, "error", err)
+		}
+	}
+
+	webhooks, err := model.GetWebhooks()
+	if err != nil {
+		panic(err)
+	}
+
+	maxRetries, err := model.GetWebhookMaxRetries()
+	if err != nil {
+		panic(err)
+	}
+
+	for _, webhook := range webhooks {
+		dispatcher := InitializeWebhookDispatcher()
+		go dispatcher.Run(webhook, maxRetries)
+	}
+
+	reapInterval, err := model.GetHostReapInterval()
+	if err != nil {
+		panic(err)
+	}
+
+	if reapInterval > 0 {
+		hostReaper := InitializeHostReaper()
+		go hostReaper.Run(reapInterval)
+	}
+
+	if os.Getenv("DNS_LISTEN") != "" {
+		dnsConfig, err := dnsserver.NewConfigFromEnv()
+		if err != nil {
+			panic(err)
+		}
+
+		dnsServer := InitializeDNSServer()
+		serve("DNS", func() error { return dnsServer.ListenAndServe(dnsConfig) })
+	}
+
+	if os.Getenv("GRPC_LISTEN") != "" {
+		grpcServer := InitializeGRPCServer()
+		serve("gRPC", func() error { return grpcServer.ListenAndServe(os.Getenv("GRPC_LISTEN")) })
+	}
+
+	if os.Getenv("EXTERNAL_DNS_LISTEN") != "" {
+		// The tenant is got on every request, so an invalid one is found at the start.
+		_, err := model.GetExternalDNSTenant()
+		if err != nil {
+			panic(err)
+		}
+
+		externalDNSRouter := NewExternalDNSRouter()
+		serve("external-dns", func() error { return externalDNSRouter.Run(os.Getenv("EXTERNAL_DNS_LISTEN")) })
+	}
+
+	if os.Getenv("METRICS_LISTEN") != "" {
+		metricsRouter := NewMetricsRouter()
+		serve("metrics", func() error { return metricsRouter.Run(os.Getenv("METRICS_LISTEN")) })
+	}
+
 	url := ginSwagger.URL("http://" + Server + ":" + Port + "/swagger/doc.json")
 	Router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler, url))
 
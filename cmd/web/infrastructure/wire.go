@@ -9,13 +9,25 @@ import (
 	"coredns_api/internal/interface/repository"
 	"coredns_api/internal/usecase"
 	"coredns_api/pkg/interface/controllers"
+	"coredns_api/pkg/interface/dnsserver"
+	"coredns_api/pkg/interface/grpcserver"
 )
 
+func InitializeFilesystemRepository() usecase.IFilesystemRepository {
+	wire.Build(
+		repository.NewFileRepository,
+		inf.NewFilesystem,
+	)
+	return nil
+}
+
 func InitializeDomainController() *controllers.DomainController {
 	wire.Build(
 		controllers.NewDomainController,
 		usecase.NewDomainInteractor,
 		repository.NewFileRepository,
+		repository.NewEventBus,
+		repository.NewMetrics,
 		inf.NewFilesystem,
 	)
 	return nil
@@ -26,7 +38,186 @@ func InitializeHostController() *controllers.HostController {
 		controllers.NewHostController,
 		usecase.NewHostInteractor,
 		repository.NewFileRepository,
+		repository.NewEventBus,
+		repository.NewMetrics,
+		inf.NewFilesystem,
+	)
+	return nil
+}
+
+func InitializeZoneController() *controllers.ZoneController {
+	wire.Build(
+		controllers.NewZoneController,
+		usecase.NewZoneInteractor,
+		repository.NewFileRepository,
+		repository.NewEventBus,
+		repository.NewMetrics,
+		repository.NewZoneRepository,
+		inf.NewFilesystem,
+		inf.NewZoneReader,
+	)
+	return nil
+}
+
+func InitializePowerDNSController() *controllers.PowerDNSController {
+	wire.Build(
+		controllers.NewPowerDNSController,
+		usecase.NewDomainInteractor,
+		usecase.NewHostInteractor,
+		repository.NewFileRepository,
+		repository.NewEventBus,
+		repository.NewMetrics,
+		inf.NewFilesystem,
+	)
+	return nil
+}
+
+func InitializeExternalDNSController() *controllers.ExternalDNSController {
+	wire.Build(
+		controllers.NewExternalDNSController,
+		usecase.NewDomainInteractor,
+		usecase.NewHostInteractor,
+		repository.NewFileRepository,
+		repository.NewEventBus,
+		repository.NewMetrics,
+		inf.NewFilesystem,
+	)
+	return nil
+}
+
+func InitializeReverseZoneController() *controllers.ReverseZoneController {
+	wire.Build(
+		controllers.NewReverseZoneController,
+		usecase.NewReverseZoneInteractor,
+		repository.NewFileRepository,
+		inf.NewFilesystem,
+	)
+	return nil
+}
+
+func InitializeSearchController() *controllers.SearchController {
+	wire.Build(
+		controllers.NewSearchController,
+		usecase.NewSearchInteractor,
+		repository.NewFileRepository,
+		inf.NewFilesystem,
+	)
+	return nil
+}
+
+func InitializeEventController() *controllers.EventController {
+	wire.Build(
+		controllers.NewEventController,
+		usecase.NewEventInteractor,
+		repository.NewEventBus,
+	)
+	return nil
+}
+
+func InitializeWebhookDispatcher() *usecase.WebhookDispatcher {
+	wire.Build(
+		usecase.NewWebhookDispatcher,
+		repository.NewEventBus,
+		repository.NewWebhookRepository,
+		inf.NewWebhookClient,
+		inf.NewFilesystem,
+	)
+	return nil
+}
+
+func InitializeIdempotencyController() *controllers.IdempotencyController {
+	wire.Build(
+		controllers.NewIdempotencyController,
+		usecase.NewIdempotencyInteractor,
+		repository.NewIdempotencyRepository,
+	)
+	return nil
+}
+
+func InitializePoolController() *controllers.PoolController {
+	wire.Build(
+		controllers.NewPoolController,
+		usecase.NewPoolInteractor,
+		repository.NewFileRepository,
+		inf.NewFilesystem,
+	)
+	return nil
+}
+
+func InitializeHostReaper() *usecase.HostReaper {
+	wire.Build(
+		usecase.NewHostReaper,
+		usecase.NewHostInteractor,
+		repository.NewFileRepository,
+		repository.NewEventBus,
+		repository.NewMetrics,
+		inf.NewFilesystem,
+	)
+	return nil
+}
+
+func InitializeTenantController() *controllers.TenantController {
+	wire.Build(
+		controllers.NewTenantController,
+		usecase.NewTenantInteractor,
+		repository.NewFileRepository,
+		inf.NewFilesystem,
+	)
+	return nil
+}
+
+func InitializeDNSServer() *dnsserver.Server {
+	wire.Build(
+		dnsserver.NewServer,
+		usecase.NewZoneInteractor,
+		usecase.NewHostInteractor,
+		repository.NewFileRepository,
+		repository.NewEventBus,
+		repository.NewMetrics,
+		repository.NewZoneRepository,
+		inf.NewFilesystem,
+		inf.NewZoneReader,
+	)
+	return nil
+}
+
+func InitializeGRPCServer() *grpcserver.Server {
+	wire.Build(
+		grpcserver.NewServer,
+		controllers.NewDomainController,
+		controllers.NewHostController,
+		controllers.NewTenantController,
+		usecase.NewDomainInteractor,
+		usecase.NewHostInteractor,
+		usecase.NewTenantInteractor,
+		usecase.NewEventInteractor,
+		repository.NewFileRepository,
+		repository.NewEventBus,
+		repository.NewMetrics,
+		inf.NewFilesystem,
+	)
+	return nil
+}
+
+func InitializeHealthInteractor() *usecase.HealthInteractor {
+	wire.Build(
+		usecase.NewHealthInteractor,
+		repository.NewFileRepository,
+		repository.NewHealthRepository,
+		inf.NewFilesystem,
+		inf.NewDNSProber,
+	)
+	return nil
+}
+
+func InitializeHealthController() *controllers.HealthController {
+	wire.Build(
+		controllers.NewHealthController,
+		usecase.NewHealthInteractor,
+		repository.NewFileRepository,
+		repository.NewHealthRepository,
 		inf.NewFilesystem,
+		inf.NewDNSProber,
 	)
 	return nil
 }
@@ -10,14 +10,24 @@ import (
 	"coredns_api/internal/interface/repository"
 	"coredns_api/internal/usecase"
 	"coredns_api/pkg/interface/controllers"
+	"coredns_api/pkg/interface/dnsserver"
+	"coredns_api/pkg/interface/grpcserver"
 )
 
 // Injectors from wire.go:
 
+func InitializeFilesystemRepository() usecase.IFilesystemRepository {
+	iFilesystem := infrastructure.NewFilesystem()
+	iFilesystemRepository := repository.NewFileRepository(iFilesystem)
+	return iFilesystemRepository
+}
+
 func InitializeDomainController() *controllers.DomainController {
 	iFilesystem := infrastructure.NewFilesystem()
 	iFilesystemRepository := repository.NewFileRepository(iFilesystem)
-	domainInteractor := usecase.NewDomainInteractor(iFilesystemRepository)
+	iEventBus := repository.NewEventBus()
+	iMetrics := repository.NewMetrics()
+	domainInteractor := usecase.NewDomainInteractor(iFilesystemRepository, iEventBus, iMetrics)
 	domainController := controllers.NewDomainController(domainInteractor)
 	return domainController
 }
@@ -25,7 +35,156 @@ func InitializeDomainController() *controllers.DomainController {
 func InitializeHostController() *controllers.HostController {
 	iFilesystem := infrastructure.NewFilesystem()
 	iFilesystemRepository := repository.NewFileRepository(iFilesystem)
-	hostInteractor := usecase.NewHostInteractor(iFilesystemRepository)
+	iEventBus := repository.NewEventBus()
+	iMetrics := repository.NewMetrics()
+	hostInteractor := usecase.NewHostInteractor(iFilesystemRepository, iEventBus, iMetrics)
 	hostController := controllers.NewHostController(hostInteractor)
 	return hostController
 }
+
+func InitializeZoneController() *controllers.ZoneController {
+	iFilesystem := infrastructure.NewFilesystem()
+	iFilesystemRepository := repository.NewFileRepository(iFilesystem)
+	iEventBus := repository.NewEventBus()
+	iMetrics := repository.NewMetrics()
+	iZoneReader := infrastructure.NewZoneReader()
+	iZoneRepository := repository.NewZoneRepository(iZoneReader)
+	zoneInteractor := usecase.NewZoneInteractor(iFilesystemRepository, iZoneRepository, iEventBus, iMetrics)
+	zoneController := controllers.NewZoneController(zoneInteractor)
+	return zoneController
+}
+
+func InitializePowerDNSController() *controllers.PowerDNSController {
+	iFilesystem := infrastructure.NewFilesystem()
+	iFilesystemRepository := repository.NewFileRepository(iFilesystem)
+	iEventBus := repository.NewEventBus()
+	iMetrics := repository.NewMetrics()
+	domainInteractor := usecase.NewDomainInteractor(iFilesystemRepository, iEventBus, iMetrics)
+	hostInteractor := usecase.NewHostInteractor(iFilesystemRepository, iEventBus, iMetrics)
+	powerDNSController := controllers.NewPowerDNSController(domainInteractor, hostInteractor)
+	return powerDNSController
+}
+
+func InitializeExternalDNSController() *controllers.ExternalDNSController {
+	iFilesystem := infrastructure.NewFilesystem()
+	iFilesystemRepository := repository.NewFileRepository(iFilesystem)
+	iEventBus := repository.NewEventBus()
+	iMetrics := repository.NewMetrics()
+	domainInteractor := usecase.NewDomainInteractor(iFilesystemRepository, iEventBus, iMetrics)
+	hostInteractor := usecase.NewHostInteractor(iFilesystemRepository, iEventBus, iMetrics)
+	externalDNSController := controllers.NewExternalDNSController(domainInteractor, hostInteractor)
+	return externalDNSController
+}
+
+func InitializeReverseZoneController() *controllers.ReverseZoneController {
+	iFilesystem := infrastructure.NewFilesystem()
+	iFilesystemRepository := repository.NewFileRepository(iFilesystem)
+	reverseZoneInteractor := usecase.NewReverseZoneInteractor(iFilesystemRepository)
+	reverseZoneController := controllers.NewReverseZoneController(reverseZoneInteractor)
+	return reverseZoneController
+}
+
+func InitializeSearchController() *controllers.SearchController {
+	iFilesystem := infrastructure.NewFilesystem()
+	iFilesystemRepository := repository.NewFileRepository(iFilesystem)
+	searchInteractor := usecase.NewSearchInteractor(iFilesystemRepository)
+	searchController := controllers.NewSearchController(searchInteractor)
+	return searchController
+}
+
+func InitializeEventController() *controllers.EventController {
+	iEventBus := repository.NewEventBus()
+	eventInteractor := usecase.NewEventInteractor(iEventBus)
+	eventController := controllers.NewEventController(eventInteractor)
+	return eventController
+}
+
+func InitializeWebhookDispatcher() *usecase.WebhookDispatcher {
+	iEventBus := repository.NewEventBus()
+	iWebhookClient := infrastructure.NewWebhookClient()
+	iFilesystem := infrastructure.NewFilesystem()
+	iWebhookRepository := repository.NewWebhookRepository(iWebhookClient, iFilesystem)
+	webhookDispatcher := usecase.NewWebhookDispatcher(iEventBus, iWebhookRepository)
+	return webhookDispatcher
+}
+
+func InitializeIdempotencyController() *controllers.IdempotencyController {
+	iIdempotencyRepository := repository.NewIdempotencyRepository()
+	idempotencyInteractor := usecase.NewIdempotencyInteractor(iIdempotencyRepository)
+	idempotencyController := controllers.NewIdempotencyController(idempotencyInteractor)
+	return idempotencyController
+}
+
+func InitializePoolController() *controllers.PoolController {
+	iFilesystem := infrastructure.NewFilesystem()
+	iFilesystemRepository := repository.NewFileRepository(iFilesystem)
+	poolInteractor := usecase.NewPoolInteractor(iFilesystemRepository)
+	poolController := controllers.NewPoolController(poolInteractor)
+	return poolController
+}
+
+func InitializeHostReaper() *usecase.HostReaper {
+	iFilesystem := infrastructure.NewFilesystem()
+	iFilesystemRepository := repository.NewFileRepository(iFilesystem)
+	iEventBus := repository.NewEventBus()
+	iMetrics := repository.NewMetrics()
+	hostInteractor := usecase.NewHostInteractor(iFilesystemRepository, iEventBus, iMetrics)
+	hostReaper := usecase.NewHostReaper(hostInteractor)
+	return hostReaper
+}
+
+func InitializeTenantController() *controllers.TenantController {
+	iFilesystem := infrastructure.NewFilesystem()
+	iFilesystemRepository := repository.NewFileRepository(iFilesystem)
+	tenantInteractor := usecase.NewTenantInteractor(iFilesystemRepository)
+	tenantController := controllers.NewTenantController(tenantInteractor)
+	return tenantController
+}
+
+func InitializeDNSServer() *dnsserver.Server {
+	iFilesystem := infrastructure.NewFilesystem()
+	iFilesystemRepository := repository.NewFileRepository(iFilesystem)
+	iEventBus := repository.NewEventBus()
+	iMetrics := repository.NewMetrics()
+	iZoneReader := infrastructure.NewZoneReader()
+	iZoneRepository := repository.NewZoneRepository(iZoneReader)
+	zoneInteractor := usecase.NewZoneInteractor(iFilesystemRepository, iZoneRepository, iEventBus, iMetrics)
+	hostInteractor := usecase.NewHostInteractor(iFilesystemRepository, iEventBus, iMetrics)
+	server := dnsserver.NewServer(zoneInteractor, hostInteractor)
+	return server
+}
+
+func InitializeGRPCServer() *grpcserver.Server {
+	iFilesystem := infrastructure.NewFilesystem()
+	iFilesystemRepository := repository.NewFileRepository(iFilesystem)
+	iEventBus := repository.NewEventBus()
+	iMetrics := repository.NewMetrics()
+	domainInteractor := usecase.NewDomainInteractor(iFilesystemRepository, iEventBus, iMetrics)
+	domainController := controllers.NewDomainController(domainInteractor)
+	hostInteractor := usecase.NewHostInteractor(iFilesystemRepository, iEventBus, iMetrics)
+	hostController := controllers.NewHostController(hostInteractor)
+	tenantInteractor := usecase.NewTenantInteractor(iFilesystemRepository)
+	tenantController := controllers.NewTenantController(tenantInteractor)
+	eventInteractor := usecase.NewEventInteractor(iEventBus)
+	server := grpcserver.NewServer(domainController, hostController, tenantController, eventInteractor)
+	return server
+}
+
+func InitializeHealthInteractor() *usecase.HealthInteractor {
+	iFilesystem := infrastructure.NewFilesystem()
+	iFilesystemRepository := repository.NewFileRepository(iFilesystem)
+	iDNSProber := infrastructure.NewDNSProber()
+	iHealthRepository := repository.NewHealthRepository(iFilesystem, iDNSProber)
+	healthInteractor := usecase.NewHealthInteractor(iFilesystemRepository, iHealthRepository)
+	return healthInteractor
+}
+
+func InitializeHealthController() *controllers.HealthController {
+	iFilesystem := infrastructure.NewFilesystem()
+	iFilesystemRepository := repository.NewFileRepository(iFilesystem)
+	iDNSProber := infrastructure.NewDNSProber()
+	iHealthRepository := repository.NewHealthRepository(iFilesystem, iDNSProber)
+	healthInteractor := usecase.NewHealthInteractor(iFilesystemRepository, iHealthRepository)
+	healthController := controllers.NewHealthController(healthInteractor)
+	return healthController
+}
@@ -0,0 +1,44 @@
+package infrastructure
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"coredns_api/internal/model"
+)
+
+// ConfLock is the advisory lock of HOSTS_DIR and CONF_PATH between the API server and the offline commands.
+// It is released when the process exits, even if it is not unlocked.
+type ConfLock struct {
+	file *os.File
+}
+
+// LockConf takes the lock file next to CONF_PATH without waiting.
+// ConfLockedError is returned when another process has the lock.
+func LockConf() (*ConfLock, error) {
+	path := model.GetConfPath() + ".lock"
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	err = syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err != nil {
+		file.Close()
+		if err == syscall.EWOULDBLOCK {
+			return nil, model.NewConfLockedError(path)
+		}
+		return nil, err
+	}
+	return &ConfLock{file: file}, nil
+}
+
+func (l *ConfLock) Unlock() error {
+	return l.file.Close()
+}
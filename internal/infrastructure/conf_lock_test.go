@@ -0,0 +1,35 @@
+package infrastructure
+
+import (
+	"path/filepath"
+	"testing"
+
+	"coredns_api/internal/model"
+)
+
+func TestLockConf(t *testing.T) {
+	t.Setenv("CONF_PATH", filepath.Join(t.TempDir(), "coredns.conf"))
+
+	lock, err := LockConf()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// flock is held by the open file, so another open file can't take it even in the same process.
+	_, err = LockConf()
+	if _, ok := err.(*model.ConfLockedError); !ok {
+		t.Error("lock is taken twice")
+	}
+
+	err = lock.Unlock()
+	if err != nil {
+		t.Error(err)
+	}
+	lock, err = LockConf()
+	if err != nil {
+		t.Error("lock is not taken after it is unlocked")
+		return
+	}
+	lock.Unlock()
+}
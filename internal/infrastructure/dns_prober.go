@@ -0,0 +1,39 @@
+package infrastructure
+
+import (
+	"time"
+
+	"github.com/miekg/dns"
+
+	"coredns_api/internal/interface/repository"
+	"coredns_api/internal/model"
+)
+
+type DNSProber struct{}
+
+func NewDNSProber() repository.IDNSProber {
+	return &DNSProber{}
+}
+
+// LookupAddresses asks the server like "127.0.0.1:53" for the A records of the name.
+func (p *DNSProber) LookupAddresses(server, fqdn string) ([]string, error) {
+	msg := &dns.Msg{}
+	msg.SetQuestion(dns.Fqdn(fqdn), dns.TypeA)
+
+	client := &dns.Client{Timeout: 2 * time.Second}
+	response, _, err := client.Exchange(msg, server)
+	if err != nil {
+		return nil, err
+	}
+	if response.Rcode != dns.RcodeSuccess {
+		return nil, model.NewServerSideError(server + " answered " + dns.RcodeToString[response.Rcode] + " for " + fqdn)
+	}
+
+	var addresses []string
+	for _, rr := range response.Answer {
+		if a, ok := rr.(*dns.A); ok {
+			addresses = append(addresses, a.A.String())
+		}
+	}
+	return addresses, nil
+}
@@ -3,6 +3,7 @@ package infrastructure
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 
 	"coredns_api/internal/interface/repository"
 )
@@ -23,6 +24,11 @@ func (f *Filesystem) LoadTextFile(filePath string) (string, error) {
 }
 
 func (f *Filesystem) WriteTextFile(filePath, fileInfo string) error {
+	err := os.MkdirAll(filepath.Dir(filePath), 0755)
+	if err != nil {
+		return err
+	}
+
 	file, err := os.Create(filePath)
 	if err != nil {
 		return err
@@ -38,6 +44,23 @@ func (f *Filesystem) WriteTextFile(filePath, fileInfo string) error {
 	return nil
 }
 
+func (f *Filesystem) AppendTextFile(filePath, text string) error {
+	err := os.MkdirAll(filepath.Dir(filePath), 0755)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	_, err = file.Write([]byte(text))
+	return err
+}
+
 func (f *Filesystem) DeleteFile(filePath string) error {
 	return os.Remove(filePath)
 }
@@ -58,3 +81,27 @@ func (f *Filesystem) GetFilenameList(directory string) ([]string, error) {
 
 	return fileNameList, nil
 }
+
+// CheckWritable checks a file can be written into the directory, or the file can be opened to write.
+// Nothing is changed, since the file is opened without truncating it and the temporary file is removed.
+func (f *Filesystem) CheckWritable(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		file, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			return err
+		}
+		return file.Close()
+	}
+
+	file, err := os.CreateTemp(path, ".writable-*")
+	if err != nil {
+		return err
+	}
+	file.Close()
+	return os.Remove(file.Name())
+}
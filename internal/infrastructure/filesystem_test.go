@@ -0,0 +1,42 @@
+package infrastructure
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckWritable(t *testing.T) {
+	dir := t.TempDir()
+	confPath := filepath.Join(dir, "coredns.conf")
+	err := os.WriteFile(confPath, []byte("hogehoge.hoge {\n}\n"), 0644)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	fs := NewFilesystem()
+	err = fs.CheckWritable(dir)
+	if err != nil {
+		t.Error(err)
+	}
+	err = fs.CheckWritable(confPath)
+	if err != nil {
+		t.Error(err)
+	}
+
+	// Nothing is left or changed by the check.
+	files, _ := fs.GetFilenameList(dir)
+	if len(files) != 1 {
+		t.Error("temporary file is left")
+	}
+	conf, _ := fs.LoadTextFile(confPath)
+	if conf != "hogehoge.hoge {\n}\n" {
+		t.Error("file is changed")
+	}
+
+	err = fs.CheckWritable(filepath.Join(dir, "missing"))
+	if err == nil {
+		t.Error("missing file is writable")
+	}
+}
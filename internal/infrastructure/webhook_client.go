@@ -0,0 +1,45 @@
+package infrastructure
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"coredns_api/internal/interface/repository"
+)
+
+const webhookTimeout = 10 * time.Second
+
+type WebhookClient struct {
+	client *http.Client
+}
+
+func NewWebhookClient() repository.IWebhookClient {
+	return &WebhookClient{client: &http.Client{Timeout: webhookTimeout}}
+}
+
+// Post sends the body to the url. Any response other than 2xx is an error.
+func (w *WebhookClient) Post(url string, headers map[string]string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package infrastructure
+
+import (
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+
+	"coredns_api/internal/interface/repository"
+	"coredns_api/internal/model"
+)
+
+type ZoneReader struct{}
+
+func NewZoneReader() repository.IZoneReader {
+	return &ZoneReader{}
+}
+
+// ParseZoneFile reads RFC 1035 master file.
+func (z *ZoneReader) ParseZoneFile(origin, zoneFile string) ([]*model.ZoneRecord, error) {
+	parser := dns.NewZoneParser(strings.NewReader(zoneFile), dns.Fqdn(origin), "")
+
+	var records []*model.ZoneRecord
+	for rr, ok := parser.Next(); ok; rr, ok = parser.Next() {
+		records = append(records, newZoneRecord(rr))
+	}
+
+	err := parser.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// TransferZone gets every record in the zone with AXFR from the server like "192.0.2.1:53".
+func (z *ZoneReader) TransferZone(origin, server string, tsigKey *model.TsigKey) ([]*model.ZoneRecord, error) {
+	transfer := &dns.Transfer{}
+	msg := &dns.Msg{}
+	msg.SetAxfr(dns.Fqdn(origin))
+	if tsigKey != nil {
+		name := dns.Fqdn(tsigKey.Name)
+		transfer.TsigSecret = map[string]string{name: tsigKey.Secret}
+		msg.SetTsig(name, dns.Fqdn(tsigKey.Algorithm), 300, time.Now().Unix())
+	}
+
+	envelopes, err := transfer.In(msg, server)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []*model.ZoneRecord
+	soaCount := 0
+	for envelope := range envelopes {
+		if envelope.Error != nil {
+			return nil, envelope.Error
+		}
+
+		for _, rr := range envelope.RR {
+			// The transfer is closed with the SOA record sent at first.
+			if rr.Header().Rrtype == dns.TypeSOA {
+				soaCount++
+				if soaCount > 1 {
+					continue
+				}
+			}
+			records = append(records, newZoneRecord(rr))
+		}
+	}
+
+	return records, nil
+}
+
+func newZoneRecord(rr dns.RR) *model.ZoneRecord {
+	header := rr.Header()
+	record := &model.ZoneRecord{
+		Name:  strings.TrimSuffix(header.Name, "."),
+		Type:  dns.TypeToString[header.Rrtype],
+		Value: strings.TrimSpace(strings.TrimPrefix(rr.String(), header.String())),
+	}
+
+	switch r := rr.(type) {
+	case *dns.A:
+		record.Value = r.A.String()
+	case *dns.AAAA:
+		record.Value = r.AAAA.String()
+	}
+
+	return record
+}
@@ -0,0 +1,126 @@
+package infrastructure
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/miekg/dns"
+
+	"coredns_api/internal/model"
+)
+
+const testZoneFile = `$ORIGIN hogehoge.hoge.
+$TTL 3600
+@            IN SOA  ns1 admin 1 3600 600 86400 3600
+@            IN NS   ns1
+ns1          IN A    172.21.1.1
+hogeserver1  IN A    172.21.1.2
+hogeserver2  IN AAAA fd00::2
+www          IN CNAME hogeserver1
+`
+
+func TestParseZoneFile(t *testing.T) {
+	records, err := NewZoneReader().ParseZoneFile("hogehoge.hoge", testZoneFile)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if len(records) != 6 {
+		t.Error("zone file is not parsed")
+		return
+	}
+	if records[3].Name != "hogeserver1.hogehoge.hoge" || records[3].Type != "A" || records[3].Value != "172.21.1.2" {
+		t.Error(records[3])
+	}
+	if records[5].Type != "CNAME" || records[5].Value != "hogeserver1.hogehoge.hoge." {
+		t.Error(records[5])
+	}
+}
+
+func TestParseZoneFileInvalid(t *testing.T) {
+	_, err := NewZoneReader().ParseZoneFile("hogehoge.hoge", "hogeserver1 IN A 172.21.1\n")
+	if err == nil {
+		t.Error("invalid zone file is accepted")
+	}
+}
+
+func startTransferServer(t *testing.T, tsigSecret map[string]string) (string, func()) {
+	var rrs []dns.RR
+	parser := dns.NewZoneParser(strings.NewReader(testZoneFile), "hogehoge.hoge.", "")
+	for rr, ok := parser.Next(); ok; rr, ok = parser.Next() {
+		rrs = append(rrs, rr)
+	}
+	rrs = append(rrs, rrs[0])
+
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		if tsigSecret != nil && (r.IsTsig() == nil || w.TsigStatus() != nil) {
+			m := &dns.Msg{}
+			m.SetRcode(r, dns.RcodeNotAuth)
+			_ = w.WriteMsg(m)
+			return
+		}
+
+		ch := make(chan *dns.Envelope)
+		tr := &dns.Transfer{}
+		go func() {
+			ch <- &dns.Envelope{RR: rrs}
+			close(ch)
+		}()
+		_ = tr.Out(w, r, ch)
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	started := make(chan struct{})
+	server := &dns.Server{Listener: listener, Handler: handler, TsigSecret: tsigSecret, NotifyStartedFunc: func() { close(started) }}
+	go func() { _ = server.ActivateAndServe() }()
+	<-started
+
+	return listener.Addr().String(), func() { _ = server.Shutdown() }
+}
+
+func TestTransferZone(t *testing.T) {
+	server, shutdown := startTransferServer(t, nil)
+	defer shutdown()
+
+	records, err := NewZoneReader().TransferZone("hogehoge.hoge", server, nil)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if len(records) != 6 {
+		t.Error("zone is not transferred")
+	}
+
+	domain, skipped, err := model.NewDomainFromZoneRecords("hogehoge.hoge", []string{"df397e50-8006-450e-b18b-5c5bd940baff"}, records)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(domain.Hosts) != 3 || len(skipped) != 3 {
+		t.Error("transferred zone is not imported")
+	}
+}
+
+func TestTransferZoneTsig(t *testing.T) {
+	secret := map[string]string{"transfer-key.": "c2VjcmV0c2VjcmV0c2VjcmV0c2VjcmV0"}
+	server, shutdown := startTransferServer(t, secret)
+	defer shutdown()
+
+	key := &model.TsigKey{Name: "transfer-key", Algorithm: dns.HmacSHA256, Secret: secret["transfer-key."]}
+	records, err := NewZoneReader().TransferZone("hogehoge.hoge", server, key)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(records) != 6 {
+		t.Error("zone is not transferred with TSIG")
+	}
+
+	_, err = NewZoneReader().TransferZone("hogehoge.hoge", server, nil)
+	if err == nil {
+		t.Error("zone is transferred without TSIG")
+	}
+}
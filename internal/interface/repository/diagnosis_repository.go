@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"path/filepath"
+
+	"coredns_api/internal/model"
+	"coredns_api/internal/usecase"
+)
+
+type DiagnosisRepository struct {
+	filesystem IFilesystem
+}
+
+func NewDiagnosisRepository(fs IFilesystem) usecase.IDiagnosisRepository {
+	return &DiagnosisRepository{fs}
+}
+
+func (d *DiagnosisRepository) GetDomainFileNameList() ([]string, error) {
+	return d.filesystem.GetFilenameList(model.GetHostsDir())
+}
+
+func (d *DiagnosisRepository) LoadDomainFileInfo(fileName string) (string, error) {
+	return d.filesystem.LoadTextFile(filepath.Join(model.GetHostsDir(), fileName))
+}
+
+func (d *DiagnosisRepository) WriteDomainFileInfo(fileName, fileInfo string) error {
+	return d.filesystem.WriteTextFile(filepath.Join(model.GetHostsDir(), fileName), fileInfo)
+}
+
+func (d *DiagnosisRepository) LoadConfFileInfo() (string, error) {
+	return d.filesystem.LoadTextFile(model.GetConfPath())
+}
+
+func (d *DiagnosisRepository) WriteConfFileInfo(confInfo string) error {
+	return d.filesystem.WriteTextFile(model.GetConfPath(), confInfo)
+}
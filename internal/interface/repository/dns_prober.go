@@ -0,0 +1,5 @@
+package repository
+
+type IDNSProber interface {
+	LookupAddresses(server, fqdn string) ([]string, error)
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"sync"
+
+	"coredns_api/internal/model"
+	"coredns_api/internal/usecase"
+)
+
+var (
+	domainWatchersLock sync.Mutex
+	domainWatchers     []*DomainWatcher
+)
+
+// DomainWatcher collects the names of the domains whose file is written or deleted.
+// Changes to the same domain are merged until they are taken,
+// so a slow watcher never blocks the repository.
+type DomainWatcher struct {
+	lock    sync.Mutex
+	pending map[model.DomainName]bool
+	changed chan struct{}
+}
+
+func (f *FilesystemRepository) Watch() usecase.IDomainWatcher {
+	w := &DomainWatcher{pending: map[model.DomainName]bool{}, changed: make(chan struct{}, 1)}
+
+	domainWatchersLock.Lock()
+	defer domainWatchersLock.Unlock()
+	domainWatchers = append(domainWatchers, w)
+	return w
+}
+
+func (w *DomainWatcher) Changed() <-chan struct{} {
+	return w.changed
+}
+
+func (w *DomainWatcher) TakeChanged() []model.DomainName {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	var names []model.DomainName
+	for name := range w.pending {
+		names = append(names, name)
+	}
+	w.pending = map[model.DomainName]bool{}
+	return names
+}
+
+func (w *DomainWatcher) notify(domainName model.DomainName) {
+	w.lock.Lock()
+	w.pending[domainName] = true
+	w.lock.Unlock()
+
+	select {
+	case w.changed <- struct{}{}:
+	default:
+	}
+}
+
+// notifyDomainChanged is called after a domain file of coreDNSConfCache is changed.
+// Staged repositories don't call it, since they never write to disk.
+func notifyDomainChanged(domainName model.DomainName) {
+	domainWatchersLock.Lock()
+	defer domainWatchersLock.Unlock()
+
+	for _, w := range domainWatchers {
+		w.notify(domainName)
+	}
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"sync"
+
+	"coredns_api/internal/model"
+	"coredns_api/internal/usecase"
+)
+
+var (
+	eventSubscriptionsLock sync.Mutex
+	eventSubscriptions     []*EventSubscription
+)
+
+// EventBus is shared by every interactor, so the subscribers receive the events of all of them.
+type EventBus struct{}
+
+func NewEventBus() usecase.IEventBus {
+	return &EventBus{}
+}
+
+// EventSubscription keeps up to the size of events until they are received.
+type EventSubscription struct {
+	events chan *model.Event
+	closed bool
+}
+
+func (b *EventBus) Publish(event *model.Event) {
+	eventSubscriptionsLock.Lock()
+	defer eventSubscriptionsLock.Unlock()
+
+	var subscriptions []*EventSubscription
+	for _, s := range eventSubscriptions {
+		select {
+		case s.events <- event:
+			subscriptions = append(subscriptions, s)
+		default:
+			// The subscriber is too slow. It is told by the closed channel instead of blocking the publisher.
+			s.close()
+		}
+	}
+	eventSubscriptions = subscriptions
+}
+
+func (b *EventBus) Subscribe(size int) usecase.IEventSubscription {
+	s := &EventSubscription{events: make(chan *model.Event, size)}
+
+	eventSubscriptionsLock.Lock()
+	defer eventSubscriptionsLock.Unlock()
+	eventSubscriptions = append(eventSubscriptions, s)
+	return s
+}
+
+func (s *EventSubscription) Events() <-chan *model.Event {
+	return s.events
+}
+
+func (s *EventSubscription) Close() {
+	eventSubscriptionsLock.Lock()
+	defer eventSubscriptionsLock.Unlock()
+
+	for i, subscription := range eventSubscriptions {
+		if subscription == s {
+			eventSubscriptions = append(eventSubscriptions[:i], eventSubscriptions[i+1:]...)
+			break
+		}
+	}
+	s.close()
+}
+
+func (s *EventSubscription) close() {
+	if !s.closed {
+		s.closed = true
+		close(s.events)
+	}
+}
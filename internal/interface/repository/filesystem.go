@@ -3,6 +3,8 @@ package repository
 type IFilesystem interface {
 	LoadTextFile(fileName string) (string, error)
 	WriteTextFile(name, fileInfo string) error
+	AppendTextFile(name, text string) error
 	DeleteFile(fileName string) error
 	GetFilenameList(directory string) ([]string, error)
+	CheckWritable(path string) error
 }
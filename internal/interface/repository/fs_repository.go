@@ -1,7 +1,11 @@
 package repository
 
 import (
-	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"coredns_api/internal/model"
 	"coredns_api/internal/usecase"
@@ -11,143 +15,437 @@ var coreDNSConfCache *model.CoreDNSConf
 
 type FilesystemRepository struct {
 	filesystem IFilesystem
+
+	// conf is set only on a staged repository.
+	// Otherwise the shared coreDNSConfCache is used.
+	conf *model.CoreDNSConf
+
+	// logger has the attributes of the request like the request ID.
+	// The default logger is used when it is nil.
+	logger *slog.Logger
 }
 
 func NewFileRepository(fs IFilesystem) usecase.IFilesystemRepository {
-	return &FilesystemRepository{fs}
+	return &FilesystemRepository{filesystem: fs}
 }
 
 func (f *FilesystemRepository) Initialize() {
-	allDomainInfo, err := f.loadAllDomainFiles()
+	allDomainInfo, degradedDomains, err := f.loadAllDomainFiles()
 	if err != nil {
 		panic(err)
 	}
 
 	coreDNSConfCache = model.NewCoreDNSConf(allDomainInfo)
+	for _, domain := range degradedDomains {
+		coreDNSConfCache.AddDegraded(domain)
+	}
+
+	allPools, err := f.loadAllPoolFiles()
+	if err != nil {
+		panic(err)
+	}
+	for _, pool := range allPools {
+		coreDNSConfCache.AddPool(pool)
+	}
+
+	reverseZones, err := model.NewReverseZoneList(model.GetReverseZonesConf())
+	if err != nil {
+		panic(err)
+	}
+	if len(reverseZones) > 0 {
+		coreDNSConfCache.SetReverseZones(reverseZones)
+		err = f.initializeReverseZones()
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// initializeReverseZones writes the reverse zone files,
+// and the conf if it doesn't have the reverse zones yet.
+func (f *FilesystemRepository) initializeReverseZones() error {
+	f.Lock()
+	defer f.UnLock()
+
+	err := f.writeReverseZoneFiles()
+	if err != nil {
+		return err
+	}
+
+	confInfo, err := f.cache().GetFileInfo()
+	if err != nil {
+		return err
+	}
+	current, err := f.filesystem.LoadTextFile(f.cache().ConfPath)
+	if err == nil && current == confInfo {
+		return nil
+	}
+	return f.WriteConfCache()
+}
+
+// WithLogger returns the repository which writes its logs with the logger.
+func (f *FilesystemRepository) WithLogger(logger *slog.Logger) usecase.IFilesystemRepository {
+	return &FilesystemRepository{filesystem: f.filesystem, conf: f.conf, logger: logger}
+}
+
+func (f *FilesystemRepository) log() *slog.Logger {
+	if f.logger != nil {
+		return f.logger
+	}
+	return slog.Default()
+}
+
+func (f *FilesystemRepository) cache() *model.CoreDNSConf {
+	if f.conf != nil {
+		return f.conf
+	}
+	return coreDNSConfCache
 }
 
 func (f *FilesystemRepository) Lock() {
-	coreDNSConfCache.SetLocke()
+	start := time.Now()
+	f.cache().SetLocke()
+	if f.conf == nil {
+		observeLockTaken(start)
+	}
 }
 
 func (f *FilesystemRepository) UnLock() {
-	coreDNSConfCache.UnSetLocke()
+	if f.conf == nil {
+		observeLockReleased()
+	}
+	f.cache().UnSetLocke()
+}
+
+// writeFile writes the file, and records its duration to the metrics by the kind of the file.
+// The writes of a staged repository aren't recorded, since they don't touch the disk.
+func (f *FilesystemRepository) writeFile(kind string, path string, fileInfo string) error {
+	if f.conf != nil {
+		return f.filesystem.WriteTextFile(path, fileInfo)
+	}
+
+	start := time.Now()
+	err := f.filesystem.WriteTextFile(path, fileInfo)
+	observeFileWrite(kind, start, err)
+	return err
+}
+
+// ValidateConfCache checks the Corefile rendered from the cache without writing it,
+// so that a change can be refused before any file is written.
+func (f *FilesystemRepository) ValidateConfCache() error {
+	if !f.cache().IsLocked() {
+		return usecase.NewIsNotLockedError()
+	}
+
+	_, err := f.renderConfCache()
+	return err
 }
 
 func (f *FilesystemRepository) WriteConfCache() error {
-	if !coreDNSConfCache.IsLocked() {
+	if !f.cache().IsLocked() {
 		return usecase.NewIsNotLockedError()
 	}
 
-	confPath := coreDNSConfCache.ConfPath
-	confInfo, err := coreDNSConfCache.GetFileInfo()
+	confInfo, err := f.renderConfCache()
 	if err != nil {
-		log.Print(err)
 		return err
 	}
 
-	return f.filesystem.WriteTextFile(confPath, confInfo)
+	return f.writeFile(fileKindCorefile, f.cache().ConfPath, confInfo)
+}
+
+// renderConfCache returns the Corefile rendered from the cache, after it is validated.
+func (f *FilesystemRepository) renderConfCache() (string, error) {
+	confInfo, err := f.cache().GetFileInfo()
+	if err != nil {
+		f.log().Error("failed to make the Corefile", "error", err)
+		return "", err
+	}
+
+	err = model.ValidateCorefile(confInfo)
+	if err != nil {
+		f.log().Error("Corefile is invalid", "error", err)
+		return "", err
+	}
+
+	err = f.cache().CheckDegradedDomainsKept(confInfo)
+	if err != nil {
+		f.log().Error("Corefile doesn't keep the degraded domains", "error", err)
+		return "", err
+	}
+	return confInfo, nil
 }
 
 func (f *FilesystemRepository) WriteDomainFile(domain *model.Domain) error {
-	if !coreDNSConfCache.IsLocked() {
+	if !f.cache().IsLocked() {
 		return usecase.NewIsNotLockedError()
 	}
+
+	err := f.cache().CheckNotDegraded(domain.Name)
+	if err != nil {
+		return err
+	}
+
+	// Every write is a new revision, so that the requests based on the older one are rejected.
+	domain.Revision++
+
 	domainInfoFIlePath := model.GetHostsFilePath(domain.Name)
 	fileInfo, err := domain.GetFileInfo()
 	if err != nil {
-		log.Print(err)
+		domain.Revision--
+		f.log().Error("failed to make the hosts file", "domain", domain.Name, "error", err)
 		return err
 	}
 
-	err = f.filesystem.WriteTextFile(domainInfoFIlePath, fileInfo)
+	err = f.writeFile(fileKindHosts, domainInfoFIlePath, fileInfo)
 	if err != nil {
-		log.Print(err)
+		domain.Revision--
+		f.log().Error("failed to write the hosts file", "path", domainInfoFIlePath, "error", err)
 		return err
 	}
 
-	coreDNSConfCache.Add(domain)
-	return nil
+	f.cache().Add(domain)
+	if f.conf == nil {
+		notifyDomainChanged(domain.Name)
+	}
+	return f.writeReverseZoneFiles()
 }
 
 func (f *FilesystemRepository) LoadTenantAllDomains(requestTenantUuid model.Uuid) ([]*model.Domain, error) {
-	if !coreDNSConfCache.IsLocked() {
+	if !f.cache().IsLocked() {
 		return nil, usecase.NewIsNotLockedError()
 	}
-	return coreDNSConfCache.GetTenantAll(requestTenantUuid), nil
+	return f.cache().GetTenantAll(requestTenantUuid), nil
 }
 
 func (f *FilesystemRepository) LoadAllDomains() ([]*model.Domain, error) {
-	if !coreDNSConfCache.IsLocked() {
+	if !f.cache().IsLocked() {
 		return nil, usecase.NewIsNotLockedError()
 	}
-	return coreDNSConfCache.GetAll(), nil
+	return f.cache().GetAll(), nil
 }
 
 func (f *FilesystemRepository) GetDomainByUuid(domainUuid model.Uuid, requestTenantUuid model.Uuid) (*model.Domain, error) {
-	if !coreDNSConfCache.IsLocked() {
+	if !f.cache().IsLocked() {
 		return nil, usecase.NewIsNotLockedError()
 	}
 
-	return coreDNSConfCache.GetByUuid(domainUuid, requestTenantUuid)
+	return f.cache().GetByUuid(domainUuid, requestTenantUuid)
+}
+
+func (f *FilesystemRepository) LoadTenantDegradedDomains(requestTenantUuid model.Uuid) ([]*model.DegradedDomain, error) {
+	if !f.cache().IsLocked() {
+		return nil, usecase.NewIsNotLockedError()
+	}
+	return f.cache().GetTenantAllDegraded(requestTenantUuid), nil
+}
+
+func (f *FilesystemRepository) LoadAllDegradedDomains() ([]*model.DegradedDomain, error) {
+	if !f.cache().IsLocked() {
+		return nil, usecase.NewIsNotLockedError()
+	}
+	return f.cache().GetAllDegraded(), nil
+}
+
+func (f *FilesystemRepository) GetDegradedDomainByUuid(domainUuid model.Uuid, requestTenantUuid model.Uuid) (*model.DegradedDomain, error) {
+	if !f.cache().IsLocked() {
+		return nil, usecase.NewIsNotLockedError()
+	}
+
+	return f.cache().GetDegradedByUuid(domainUuid, requestTenantUuid)
+}
+
+func (f *FilesystemRepository) SearchHosts(search *model.HostSearch, requestTenantUuid model.Uuid, allTenants bool) ([]*model.SearchHit, error) {
+	if !f.cache().IsLocked() {
+		return nil, usecase.NewIsNotLockedError()
+	}
+	return f.cache().SearchHosts(search, requestTenantUuid, allTenants), nil
 }
 
 func (f *FilesystemRepository) DeleteDomainFile(domain *model.Domain) error {
-	if !coreDNSConfCache.IsLocked() {
+	if !f.cache().IsLocked() {
 		return usecase.NewIsNotLockedError()
 	}
 
+	err := f.cache().CheckNotDegraded(domain.Name)
+	if err != nil {
+		return err
+	}
+
 	domainInfoFilePath := model.GetHostsFilePath(domain.Name)
-	err := f.filesystem.DeleteFile(domainInfoFilePath)
+	err = f.filesystem.DeleteFile(domainInfoFilePath)
 	if err != nil {
 		return err
 	}
 
-	coreDNSConfCache.Delete(domain)
+	f.cache().Delete(domain)
+	if f.conf == nil {
+		notifyDomainChanged(domain.Name)
+	}
+
+	return f.writeReverseZoneFiles()
+}
+
+func (f *FilesystemRepository) LoadReverseZones() ([]*model.ReverseZone, error) {
+	if !f.cache().IsLocked() {
+		return nil, usecase.NewIsNotLockedError()
+	}
+
+	var zones []*model.ReverseZone
+	for _, zone := range f.cache().ReverseZones {
+		zones = append(zones, zone.Clone())
+	}
+	return zones, nil
+}
+
+// writeReverseZoneFiles remakes the reverse zones from the cache,
+// and writes the files which are changed.
+func (f *FilesystemRepository) writeReverseZoneFiles() error {
+	f.cache().UpdateReverseZones()
+	for _, zone := range f.cache().ReverseZones {
+		fileInfo, err := zone.GetFileInfo()
+		if err != nil {
+			f.log().Error("failed to make the reverse zone file", "zone", zone.Name, "error", err)
+			return err
+		}
+
+		current, err := f.filesystem.LoadTextFile(zone.DomainFilePath)
+		if err == nil && current == fileInfo {
+			continue
+		}
 
+		for _, c := range zone.Conflicts {
+			f.log().Warn("address is claimed by multiple hostnames",
+				"address", c.Address, "hostnames", strings.Join(c.Names, ", "), "ptr", c.Winner)
+		}
+
+		err = f.writeFile(fileKindReverseZone, zone.DomainFilePath, fileInfo)
+		if err != nil {
+			f.log().Error("failed to write the reverse zone file", "path", zone.DomainFilePath, "error", err)
+			return err
+		}
+	}
 	return nil
 }
 
-func (f *FilesystemRepository) loadDomainFileInitial(domainName model.DomainName, domainInfoFilePath string) (*model.Domain, error) {
+func (f *FilesystemRepository) loadDomainFileInitial(domainName model.DomainName, domainInfoFilePath string) (*model.Domain, *model.DegradedDomain) {
 	fileInfo, err := f.filesystem.LoadTextFile(domainInfoFilePath)
 	if err != nil {
-		return nil, err
+		return nil, model.NewDegradedDomain(domainName, "", err)
 	}
 
 	domain, err := model.NewDomain(domainName.String(), fileInfo)
 	if err != nil {
-		return nil, err
+		return nil, model.NewDegradedDomain(domainName, fileInfo, err)
 	}
 
 	return domain, nil
 }
 
-func (f *FilesystemRepository) loadAllDomainFiles() ([]*model.Domain, error) {
+// loadAllDomainFiles loads the hosts files in the hosts dir.
+// A hosts file which can't be loaded doesn't stop the others,
+// and it is returned as a degraded domain with the error.
+func (f *FilesystemRepository) loadAllDomainFiles() ([]*model.Domain, []*model.DegradedDomain, error) {
 	domainFileDir := model.GetHostsDir()
 	fileNameList, err := f.filesystem.GetFilenameList(domainFileDir)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	var domainList []*model.Domain
+	var degradedList []*model.DegradedDomain
 	for _, domainFile := range fileNameList {
 		domainName, err := model.NewDomainName(domainFile)
 		if err != nil {
-			log.Print(domainFile)
-			log.Print(err)
-			return nil, err
+			// This file can't be in the conf, so it is just skipped.
+			f.log().Warn("file in the hosts dir is skipped", "file", domainFile, "error", err)
+			continue
 		}
 
 		filePath := model.GetHostsFilePath(domainName)
-		domain, err := f.loadDomainFileInitial(domainName, filePath)
+		domain, degraded := f.loadDomainFileInitial(domainName, filePath)
+		if degraded != nil {
+			f.log().Warn("domain is degraded", "file", domainFile, "error", degraded.Err)
+			degradedList = append(degradedList, degraded)
+			continue
+		}
+
+		domainList = append(domainList, domain)
+	}
+	return domainList, degradedList, nil
+}
+
+func (f *FilesystemRepository) WritePoolFile(pool *model.Pool) error {
+	if !f.cache().IsLocked() {
+		return usecase.NewIsNotLockedError()
+	}
 
+	fileInfo, err := pool.GetFileInfo()
+	if err != nil {
+		f.log().Error("failed to make the pool file", "pool_uuid", pool.Uuid, "error", err)
+		return err
+	}
+
+	err = f.writeFile(fileKindPool, model.GetPoolFilePath(pool.Uuid), fileInfo)
+	if err != nil {
+		f.log().Error("failed to write the pool file", "pool_uuid", pool.Uuid, "error", err)
+		return err
+	}
+
+	f.cache().AddPool(pool)
+	return nil
+}
+
+func (f *FilesystemRepository) DeletePoolFile(pool *model.Pool) error {
+	if !f.cache().IsLocked() {
+		return usecase.NewIsNotLockedError()
+	}
+
+	err := f.filesystem.DeleteFile(model.GetPoolFilePath(pool.Uuid))
+	if err != nil {
+		return err
+	}
+
+	f.cache().DeletePool(pool)
+	return nil
+}
+
+func (f *FilesystemRepository) LoadTenantAllPools(requestTenantUuid model.Uuid) ([]*model.Pool, error) {
+	if !f.cache().IsLocked() {
+		return nil, usecase.NewIsNotLockedError()
+	}
+	return f.cache().GetTenantAllPools(requestTenantUuid), nil
+}
+
+func (f *FilesystemRepository) GetPoolByUuid(poolUuid model.Uuid, requestTenantUuid model.Uuid) (*model.Pool, error) {
+	if !f.cache().IsLocked() {
+		return nil, usecase.NewIsNotLockedError()
+	}
+	return f.cache().GetPoolByUuid(poolUuid, requestTenantUuid)
+}
+
+func (f *FilesystemRepository) loadAllPoolFiles() ([]*model.Pool, error) {
+	fileNameList, err := f.filesystem.GetFilenameList(model.GetPoolDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var poolList []*model.Pool
+	for _, poolFile := range fileNameList {
+		fileInfo, err := f.filesystem.LoadTextFile(filepath.Join(model.GetPoolDir(), poolFile))
 		if err != nil {
-			log.Print(domainFile)
-			log.Print(err)
 			return nil, err
 		}
 
-		domainList = append(domainList, domain)
+		pool, err := model.NewPoolFromFileInfo(fileInfo)
+		if err != nil {
+			f.log().Error("pool file is invalid", "file", poolFile, "error", err)
+			return nil, err
+		}
+		poolList = append(poolList, pool)
 	}
-	return domainList, nil
+	return poolList, nil
 }
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+
+	"coredns_api/internal/model"
+)
+
+const testTenant = "df397e50-8006-450e-b18b-5c5bd940baff"
+
+// newTestDegradedRepository returns the repository initialized with hogehoge.hoge
+// and fugafuga.fuga whose hosts file can't be parsed.
+func newTestDegradedRepository(t *testing.T) (*FilesystemRepository, *memFilesystem) {
+	t.Setenv("HOSTS_DIR", "/hosts")
+	t.Setenv("CONF_PATH", "/coredns.conf")
+	t.Setenv("REVERSE_ZONES", "")
+
+	fs := &memFilesystem{files: map[string]string{
+		"/hosts/hogehoge.hoge": "# DomainUUID: 3e8fc6b1-0a93-4c57-9f2b-95d3e66a66e0\n# Tenats:\n#   - " + testTenant + "\n" +
+			"172.21.1.1  hogeserver1.hogehoge.hoge  # 5b9ea8eb-5ce5-422a-9d70-37d25fa896ae\n",
+		"/hosts/fugafuga.fuga": "# DomainUUID: 8a1f2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c4d\n# TXT: broken\n",
+	}}
+	fsRepository := &FilesystemRepository{filesystem: fs}
+	fsRepository.Initialize()
+	return fsRepository, fs
+}
+
+func TestInitializeDegraded(t *testing.T) {
+	fsRepository, _ := newTestDegradedRepository(t)
+	fsRepository.Lock()
+	defer fsRepository.UnLock()
+
+	domains, err := fsRepository.LoadAllDomains()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(domains) != 1 || domains[0].Name != "hogehoge.hoge" || len(domains[0].Hosts) != 1 {
+		t.Error("other domains are not loaded with the degraded domain")
+	}
+
+	degraded := fsRepository.cache().GetAllDegraded()
+	if len(degraded) != 1 || degraded[0].Name != "fugafuga.fuga" {
+		t.Error("unparsable hosts file is not degraded")
+	}
+}
+
+func TestWriteDegraded(t *testing.T) {
+	fsRepository, fs := newTestDegradedRepository(t)
+	fsRepository.Lock()
+	defer fsRepository.UnLock()
+
+	original := fs.files["/hosts/fugafuga.fuga"]
+	domain, _ := model.NewOriginalDomain("fugafuga.fuga", []string{testTenant})
+	err := fsRepository.WriteDomainFile(domain)
+	if _, ok := err.(*model.DomainDegradedError); !ok {
+		t.Errorf("degraded domain is written: %v", err)
+	}
+	err = fsRepository.DeleteDomainFile(domain)
+	if _, ok := err.(*model.DomainDegradedError); !ok {
+		t.Errorf("degraded domain is deleted: %v", err)
+	}
+	if fs.files["/hosts/fugafuga.fuga"] != original {
+		t.Error("hosts file of the degraded domain is changed")
+	}
+
+	// The Corefile keeps serving the degraded domain after another domain is written.
+	domains, _ := fsRepository.LoadAllDomains()
+	host, _ := model.NewOriginalHost("hogeserver2", "172.21.1.2", domains[0].Name)
+	domains[0].Hosts = append(domains[0].Hosts, host)
+	err = fsRepository.WriteDomainFile(domains[0])
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = fsRepository.WriteConfCache()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	conf := fs.files["/coredns.conf"]
+	if !strings.Contains(conf, "fugafuga.fuga. {") || !strings.Contains(conf, "hosts /hosts/fugafuga.fuga") {
+		t.Errorf("Corefile block of the degraded domain is not kept:\n%s", conf)
+	}
+	if !strings.Contains(fs.files["/hosts/hogehoge.hoge"], "hogeserver2.hogehoge.hoge") {
+		t.Error("other domain is not written")
+	}
+}
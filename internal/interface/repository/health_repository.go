@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"strings"
+
+	"coredns_api/internal/model"
+	"coredns_api/internal/usecase"
+)
+
+type HealthRepository struct {
+	filesystem IFilesystem
+	prober     IDNSProber
+}
+
+func NewHealthRepository(fs IFilesystem, prober IDNSProber) usecase.IHealthRepository {
+	return &HealthRepository{filesystem: fs, prober: prober}
+}
+
+func (h *HealthRepository) CheckHostsDirWritable() error {
+	return h.filesystem.CheckWritable(model.GetHostsDir())
+}
+
+func (h *HealthRepository) CheckConfPathWritable() error {
+	return h.filesystem.CheckWritable(model.GetConfPath())
+}
+
+// CheckCache checks the domain cache is loaded, and no domain in it is degraded.
+func (h *HealthRepository) CheckCache() error {
+	if coreDNSConfCache == nil {
+		return model.NewServerSideError("domain cache is not initialized")
+	}
+
+	coreDNSConfCache.SetLocke()
+	degraded := coreDNSConfCache.GetAllDegraded()
+	coreDNSConfCache.UnSetLocke()
+
+	if len(degraded) == 0 {
+		return nil
+	}
+
+	var names []string
+	for _, d := range degraded {
+		names = append(names, d.Name.String())
+	}
+	return model.NewServerSideError("domains are degraded: " + strings.Join(names, ", "))
+}
+
+func (h *HealthRepository) LookupCanary(probe *model.ReadinessProbe) ([]string, error) {
+	return h.prober.LookupAddresses(probe.Server, probe.CanaryFqdn())
+}
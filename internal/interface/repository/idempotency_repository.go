@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"sync"
+	"time"
+
+	"coredns_api/internal/model"
+	"coredns_api/internal/usecase"
+)
+
+// idempotentRequests are kept in memory, since they are only for the retries in a short window.
+// They are lost when the server restarts, and not shared between the servers.
+var (
+	idempotentRequestsLock sync.Mutex
+	idempotentRequests     = map[string]*model.IdempotentRequest{}
+)
+
+type IdempotencyRepository struct{}
+
+func NewIdempotencyRepository() usecase.IIdempotencyRepository {
+	return &IdempotencyRepository{}
+}
+
+func idempotentRequestKey(tenant, key string) string {
+	return tenant + "\x00" + key
+}
+
+func (r *IdempotencyRepository) Lock() {
+	idempotentRequestsLock.Lock()
+}
+
+func (r *IdempotencyRepository) UnLock() {
+	idempotentRequestsLock.Unlock()
+}
+
+func (r *IdempotencyRepository) Get(tenant, key string) *model.IdempotentRequest {
+	return idempotentRequests[idempotentRequestKey(tenant, key)]
+}
+
+func (r *IdempotencyRepository) Put(request *model.IdempotentRequest) {
+	idempotentRequests[idempotentRequestKey(request.Tenant, request.Key)] = request
+}
+
+func (r *IdempotencyRepository) Delete(request *model.IdempotentRequest) {
+	delete(idempotentRequests, idempotentRequestKey(request.Tenant, request.Key))
+}
+
+func (r *IdempotencyRepository) Count() int {
+	return len(idempotentRequests)
+}
+
+func (r *IdempotencyRepository) DeleteExpired(now time.Time) {
+	for key, request := range idempotentRequests {
+		if request.IsExpired(now) {
+			delete(idempotentRequests, key)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"coredns_api/internal/usecase"
+)
+
+// The metrics are registered to the default registry, which is served at /metrics on METRICS_LISTEN.
+var (
+	operationDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "coredns_api_operation_duration_seconds",
+		Help: "Duration of the operations of the interactors.",
+	}, []string{"operation", "result"})
+
+	lockWaitDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "coredns_api_lock_wait_seconds",
+		Help:    "Time waited for the lock of the domain cache.",
+		Buckets: prometheus.ExponentialBuckets(0.0001, 4, 10),
+	})
+
+	lockHoldDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "coredns_api_lock_hold_seconds",
+		Help:    "Time the lock of the domain cache is held.",
+		Buckets: prometheus.ExponentialBuckets(0.0001, 4, 10),
+	})
+
+	fileWriteDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "coredns_api_file_write_duration_seconds",
+		Help: "Duration of the file writes by the kind of the file.",
+	}, []string{"file"})
+
+	fileWriteFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "coredns_api_file_write_failures_total",
+		Help: "Number of the failed file writes by the kind of the file.",
+	}, []string{"file"})
+
+	_ = promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "coredns_api_corefile_last_write_age_seconds",
+		Help: "Seconds since the last successful write of the Corefile. It is NaN until the first write.",
+	}, corefileLastWriteAge)
+)
+
+func init() {
+	prometheus.MustRegister(&tenantCollector{})
+}
+
+var (
+	// lockedAt is when the lock of coreDNSConfCache is taken. It is changed only in the lock.
+	lockedAt time.Time
+
+	corefileWriteLock sync.Mutex
+	corefileWrittenAt time.Time
+)
+
+// The kinds of the files in the metrics.
+const (
+	fileKindCorefile    = "corefile"
+	fileKindHosts       = "hosts"
+	fileKindReverseZone = "reverse_zone"
+	fileKindPool        = "pool"
+)
+
+func observeLockTaken(start time.Time) {
+	lockedAt = time.Now()
+	lockWaitDuration.Observe(lockedAt.Sub(start).Seconds())
+}
+
+func observeLockReleased() {
+	lockHoldDuration.Observe(time.Since(lockedAt).Seconds())
+}
+
+func observeFileWrite(kind string, start time.Time, err error) {
+	fileWriteDuration.WithLabelValues(kind).Observe(time.Since(start).Seconds())
+	if err != nil {
+		fileWriteFailures.WithLabelValues(kind).Inc()
+		return
+	}
+
+	if kind == fileKindCorefile {
+		corefileWriteLock.Lock()
+		corefileWrittenAt = time.Now()
+		corefileWriteLock.Unlock()
+	}
+}
+
+func corefileLastWriteAge() float64 {
+	corefileWriteLock.Lock()
+	defer corefileWriteLock.Unlock()
+
+	if corefileWrittenAt.IsZero() {
+		return math.NaN()
+	}
+	return time.Since(corefileWrittenAt).Seconds()
+}
+
+// tenantCollector counts the domains and the hosts of every tenant in the domain cache when it is scraped.
+type tenantCollector struct{}
+
+var (
+	tenantDomainsDesc = prometheus.NewDesc("coredns_api_tenant_domains", "Number of the domains of the tenant.", []string{"tenant"}, nil)
+	tenantHostsDesc   = prometheus.NewDesc("coredns_api_tenant_hosts", "Number of the hosts in the domains of the tenant.", []string{"tenant"}, nil)
+)
+
+func (c *tenantCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- tenantDomainsDesc
+	ch <- tenantHostsDesc
+}
+
+func (c *tenantCollector) Collect(ch chan<- prometheus.Metric) {
+	if coreDNSConfCache == nil {
+		return
+	}
+
+	coreDNSConfCache.SetLocke()
+	counts := coreDNSConfCache.CountByTenant()
+	coreDNSConfCache.UnSetLocke()
+
+	for tenant, count := range counts {
+		ch <- prometheus.MustNewConstMetric(tenantDomainsDesc, prometheus.GaugeValue, float64(count.Domains), tenant.String())
+		ch <- prometheus.MustNewConstMetric(tenantHostsDesc, prometheus.GaugeValue, float64(count.Hosts), tenant.String())
+	}
+}
+
+// Metrics records the operations of the interactors to the default registry.
+type Metrics struct{}
+
+func NewMetrics() usecase.IMetrics {
+	return &Metrics{}
+}
+
+func (m *Metrics) ObserveOperation(operation string, duration time.Duration, err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	operationDuration.WithLabelValues(operation, result).Observe(duration.Seconds())
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+
+	"coredns_api/internal/usecase"
+)
+
+// StagedRepository is a FilesystemRepository working on a copy of coreDNSConfCache.
+// File writes are kept in memory, so the changes can be shown without touching disk.
+type StagedRepository struct {
+	FilesystemRepository
+	stagedFilesystem *stagedFilesystem
+}
+
+func (f *FilesystemRepository) Stage() usecase.IStagedRepository {
+	f.Lock()
+	defer f.UnLock()
+
+	fs := newStagedFilesystem(f.filesystem)
+	return &StagedRepository{
+		FilesystemRepository: FilesystemRepository{filesystem: fs, conf: f.cache().Clone(), logger: f.logger},
+		stagedFilesystem:     fs,
+	}
+}
+
+func (s *StagedRepository) WithLogger(logger *slog.Logger) usecase.IFilesystemRepository {
+	return &StagedRepository{
+		FilesystemRepository: FilesystemRepository{filesystem: s.filesystem, conf: s.conf, logger: logger},
+		stagedFilesystem:     s.stagedFilesystem,
+	}
+}
+
+func (s *StagedRepository) Initialize() {}
+
+func (s *StagedRepository) GetChanges() ([]*usecase.FileChange, error) {
+	var changes []*usecase.FileChange
+	for _, path := range s.stagedFilesystem.order {
+		file := s.stagedFilesystem.files[path]
+
+		fromFile := "a" + path
+		if !file.existed {
+			fromFile = "/dev/null"
+		}
+
+		toFile := "b" + path
+		if file.deleted {
+			toFile = "/dev/null"
+		}
+
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        splitLines(file.before),
+			B:        splitLines(file.after),
+			FromFile: fromFile,
+			ToFile:   toFile,
+			Context:  3,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if diff == "" {
+			continue
+		}
+		changes = append(changes, &usecase.FileChange{Path: path, Diff: diff})
+	}
+
+	return changes, nil
+}
+
+func splitLines(fileInfo string) []string {
+	if fileInfo == "" {
+		return nil
+	}
+
+	lines := strings.SplitAfter(fileInfo, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+type stagedFile struct {
+	before  string
+	after   string
+	existed bool
+	deleted bool
+}
+
+// stagedFilesystem reads files from the base filesystem and keeps writes in memory.
+type stagedFilesystem struct {
+	base  IFilesystem
+	files map[string]*stagedFile
+	order []string
+}
+
+func newStagedFilesystem(base IFilesystem) *stagedFilesystem {
+	return &stagedFilesystem{base: base, files: map[string]*stagedFile{}}
+}
+
+func (s *stagedFilesystem) stage(filePath string) *stagedFile {
+	file, ok := s.files[filePath]
+	if ok {
+		return file
+	}
+
+	file = &stagedFile{}
+	before, err := s.base.LoadTextFile(filePath)
+	if err == nil {
+		file.before = before
+		file.after = before
+		file.existed = true
+	}
+
+	s.files[filePath] = file
+	s.order = append(s.order, filePath)
+	return file
+}
+
+func (s *stagedFilesystem) LoadTextFile(filePath string) (string, error) {
+	file, ok := s.files[filePath]
+	if !ok {
+		return s.base.LoadTextFile(filePath)
+	}
+
+	if file.deleted || (!file.existed && file.after == "") {
+		return "", os.ErrNotExist
+	}
+	return file.after, nil
+}
+
+func (s *stagedFilesystem) WriteTextFile(filePath, fileInfo string) error {
+	file := s.stage(filePath)
+	file.after = fileInfo
+	file.deleted = false
+	return nil
+}
+
+func (s *stagedFilesystem) AppendTextFile(filePath, text string) error {
+	fileInfo, err := s.LoadTextFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return s.WriteTextFile(filePath, fileInfo+text)
+}
+
+func (s *stagedFilesystem) DeleteFile(filePath string) error {
+	file := s.stage(filePath)
+	if !file.existed && file.after == "" {
+		return os.ErrNotExist
+	}
+
+	file.after = ""
+	file.deleted = true
+	return nil
+}
+
+func (s *stagedFilesystem) GetFilenameList(directory string) ([]string, error) {
+	return s.base.GetFilenameList(directory)
+}
+
+func (s *stagedFilesystem) CheckWritable(path string) error {
+	return s.base.CheckWritable(path)
+}
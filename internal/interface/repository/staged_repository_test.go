@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"coredns_api/internal/model"
+)
+
+// memFilesystem is the IFilesystem on memory, to check that the staged writes don't reach it.
+type memFilesystem struct {
+	files map[string]string
+}
+
+func (m *memFilesystem) LoadTextFile(filePath string) (string, error) {
+	fileInfo, ok := m.files[filePath]
+	if !ok {
+		return "", os.ErrNotExist
+	}
+	return fileInfo, nil
+}
+
+func (m *memFilesystem) WriteTextFile(filePath, fileInfo string) error {
+	m.files[filePath] = fileInfo
+	return nil
+}
+
+func (m *memFilesystem) AppendTextFile(filePath, text string) error {
+	m.files[filePath] += text
+	return nil
+}
+
+func (m *memFilesystem) DeleteFile(filePath string) error {
+	if _, ok := m.files[filePath]; !ok {
+		return os.ErrNotExist
+	}
+	delete(m.files, filePath)
+	return nil
+}
+
+func (m *memFilesystem) GetFilenameList(directory string) ([]string, error) {
+	var fileNameList []string
+	for filePath := range m.files {
+		if filepath.Dir(filePath) == directory {
+			fileNameList = append(fileNameList, filepath.Base(filePath))
+		}
+	}
+	return fileNameList, nil
+}
+
+func (m *memFilesystem) CheckWritable(path string) error {
+	return nil
+}
+
+func newTestStagedRepository() (*StagedRepository, *memFilesystem) {
+	base := &memFilesystem{files: map[string]string{
+		"/hosts/hogehoge.hoge": "# DomainUUID: hoge\n172.21.1.1 hogeserver1.hogehoge.hoge # hoge1\n172.21.1.2 hogeserver2.hogehoge.hoge # hoge2\n",
+		"/hosts/fugafuga.fuga": "# DomainUUID: fuga\n172.21.2.1 fugaserver1.fugafuga.fuga # fuga1\n",
+		"/hosts/piyopiyo.piyo": "# DomainUUID: piyo\n",
+	}}
+	fs := newStagedFilesystem(base)
+	return &StagedRepository{FilesystemRepository: FilesystemRepository{filesystem: fs}, stagedFilesystem: fs}, base
+}
+
+func TestStagedFilesystem(t *testing.T) {
+	staged, base := newTestStagedRepository()
+	fs := staged.stagedFilesystem
+
+	err := fs.WriteTextFile("/hosts/hogehoge.hoge", "# DomainUUID: hoge\n")
+	if err != nil {
+		t.Error(err)
+	}
+	err = fs.AppendTextFile("/hosts/new.hoge", "# DomainUUID: new\n")
+	if err != nil {
+		t.Error(err)
+	}
+	err = fs.DeleteFile("/hosts/fugafuga.fuga")
+	if err != nil {
+		t.Error(err)
+	}
+
+	expects := map[string]string{
+		"/hosts/hogehoge.hoge": "# DomainUUID: hoge\n",
+		"/hosts/new.hoge":      "# DomainUUID: new\n",
+		"/hosts/piyopiyo.piyo": "# DomainUUID: piyo\n",
+	}
+	for path, expect := range expects {
+		fileInfo, err := fs.LoadTextFile(path)
+		if err != nil || fileInfo != expect {
+			t.Errorf("staged file is missmatched: %s %q", path, fileInfo)
+		}
+	}
+	_, err = fs.LoadTextFile("/hosts/fugafuga.fuga")
+	if !os.IsNotExist(err) {
+		t.Error("deleted file is loaded")
+	}
+	err = fs.DeleteFile("/hosts/missing.hoge")
+	if !os.IsNotExist(err) {
+		t.Error("file which doesn't exist is deleted")
+	}
+
+	// The base filesystem is never changed.
+	if len(base.files) != 3 || base.files["/hosts/hogehoge.hoge"] == expects["/hosts/hogehoge.hoge"] {
+		t.Error("staged writes reach the base filesystem")
+	}
+
+	// The deleted file can be written again.
+	err = fs.WriteTextFile("/hosts/fugafuga.fuga", "# DomainUUID: fuga\n")
+	if err != nil {
+		t.Error(err)
+	}
+	fileInfo, err := fs.LoadTextFile("/hosts/fugafuga.fuga")
+	if err != nil || fileInfo != "# DomainUUID: fuga\n" {
+		t.Error("deleted file is not written again")
+	}
+}
+
+func TestGetChanges(t *testing.T) {
+	staged, _ := newTestStagedRepository()
+	fs := staged.stagedFilesystem
+
+	fs.WriteTextFile("/hosts/hogehoge.hoge", "# DomainUUID: hoge\n172.21.1.1 hogeserver1.hogehoge.hoge # hoge1\n172.21.1.3 hogeserver2.hogehoge.hoge # hoge2\n")
+	fs.WriteTextFile("/hosts/new.hoge", "# DomainUUID: new\n172.21.3.1 newserver1.new.hoge # new1\n")
+	fs.DeleteFile("/hosts/fugafuga.fuga")
+	// The file written with the same content is not changed.
+	fs.WriteTextFile("/hosts/piyopiyo.piyo", "# DomainUUID: piyo\n")
+
+	changes, err := staged.GetChanges()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	expects := []struct {
+		path string
+		diff string
+	}{
+		{"/hosts/hogehoge.hoge", `--- a/hosts/hogehoge.hoge
++++ b/hosts/hogehoge.hoge
+@@ -1,3 +1,3 @@
+ # DomainUUID: hoge
+ 172.21.1.1 hogeserver1.hogehoge.hoge # hoge1
+-172.21.1.2 hogeserver2.hogehoge.hoge # hoge2
++172.21.1.3 hogeserver2.hogehoge.hoge # hoge2
+`},
+		{"/hosts/new.hoge", `--- /dev/null
++++ b/hosts/new.hoge
+@@ -0,0 +1,2 @@
++# DomainUUID: new
++172.21.3.1 newserver1.new.hoge # new1
+`},
+		{"/hosts/fugafuga.fuga", `--- a/hosts/fugafuga.fuga
++++ /dev/null
+@@ -1,2 +0,0 @@
+-# DomainUUID: fuga
+-172.21.2.1 fugaserver1.fugafuga.fuga # fuga1
+`},
+	}
+	if len(changes) != len(expects) {
+		t.Errorf("changes are missmatched: %d", len(changes))
+		return
+	}
+	// Changes are in the order of the writes.
+	for i, expect := range expects {
+		if changes[i].Path != expect.path || changes[i].Diff != expect.diff {
+			t.Errorf("change is missmatched: %s\n%s", changes[i].Path, changes[i].Diff)
+		}
+	}
+}
+
+func TestStage(t *testing.T) {
+	t.Setenv("HOSTS_DIR", "/hosts")
+	t.Setenv("CONF_PATH", "/coredns.conf")
+	t.Setenv("REVERSE_ZONES", "")
+	base := &memFilesystem{files: map[string]string{}}
+	fsRepository := NewFileRepository(base)
+	fsRepository.Initialize()
+
+	staged := fsRepository.Stage()
+	domain, _ := model.NewOriginalDomain("hogehoge.hoge", []string{"df397e50-8006-450e-b18b-5c5bd940baff"})
+	staged.Lock()
+	err := staged.WriteDomainFile(domain)
+	if err == nil {
+		err = staged.WriteConfCache()
+	}
+	staged.UnLock()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	changes, err := staged.GetChanges()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(changes) != 2 || changes[0].Path != "/hosts/hogehoge.hoge" || changes[1].Path != "/coredns.conf" {
+		t.Errorf("changes are missmatched: %v", changes)
+	}
+
+	// Neither the files nor the cache of the original repository are changed.
+	fsRepository.Lock()
+	domains, _ := fsRepository.LoadAllDomains()
+	fsRepository.UnLock()
+	if len(base.files) != 0 || len(domains) != 0 {
+		t.Error("staged changes reach the original repository")
+	}
+}
@@ -0,0 +1,5 @@
+package repository
+
+type IWebhookClient interface {
+	Post(url string, headers map[string]string, body []byte) error
+}
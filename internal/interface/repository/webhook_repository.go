@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"encoding/json"
+	"log/slog"
+
+	"coredns_api/internal/model"
+	"coredns_api/internal/usecase"
+)
+
+type WebhookRepository struct {
+	client     IWebhookClient
+	filesystem IFilesystem
+}
+
+func NewWebhookRepository(client IWebhookClient, fs IFilesystem) usecase.IWebhookRepository {
+	return &WebhookRepository{client: client, filesystem: fs}
+}
+
+// Send posts the event as JSON. X-Signature-256 is the HMAC-SHA256 of the body with the secret of the webhook.
+func (r *WebhookRepository) Send(webhook *model.Webhook, event *model.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return model.NewServerSideError(err.Error())
+	}
+
+	headers := map[string]string{
+		"Content-Type":    "application/json",
+		"X-Event-Id":      string(event.Uuid),
+		"X-Event-Type":    string(event.Type),
+		"X-Signature-256": webhook.Sign(body),
+	}
+	return r.client.Post(webhook.Url, headers, body)
+}
+
+// WriteDeadLetter appends the letter as a JSON line to the dead letter file, or writes it to the log without the file.
+func (r *WebhookRepository) WriteDeadLetter(letter *model.DeadLetter) error {
+	line, err := json.Marshal(letter)
+	if err != nil {
+		return model.NewServerSideError(err.Error())
+	}
+
+	path := model.GetWebhookDeadLetterPath()
+	if path == "" {
+		slog.Warn("dead letter", "domain_uuid", letter.Event.DomainUuid, "dead_letter", string(line))
+		return nil
+	}
+
+	err = r.filesystem.AppendTextFile(path, string(line)+"\n")
+	if err != nil {
+		slog.Error("failed to append the dead letter", "path", path, "error", err)
+		return model.NewServerSideError("failed to write the dead letter. " + string(line))
+	}
+	return nil
+}
@@ -0,0 +1,8 @@
+package repository
+
+import "coredns_api/internal/model"
+
+type IZoneReader interface {
+	ParseZoneFile(origin, zoneFile string) ([]*model.ZoneRecord, error)
+	TransferZone(origin, server string, tsigKey *model.TsigKey) ([]*model.ZoneRecord, error)
+}
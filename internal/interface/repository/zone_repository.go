@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"log/slog"
+
+	"coredns_api/internal/model"
+	"coredns_api/internal/usecase"
+)
+
+type ZoneRepository struct {
+	zoneReader IZoneReader
+
+	// logger has the attributes of the request like the request ID.
+	// The default logger is used when it is nil.
+	logger *slog.Logger
+}
+
+func NewZoneRepository(zr IZoneReader) usecase.IZoneRepository {
+	return &ZoneRepository{zoneReader: zr}
+}
+
+// WithLogger returns the repository which writes its logs with the logger.
+func (z *ZoneRepository) WithLogger(logger *slog.Logger) usecase.IZoneRepository {
+	return &ZoneRepository{zoneReader: z.zoneReader, logger: logger}
+}
+
+func (z *ZoneRepository) log() *slog.Logger {
+	if z.logger != nil {
+		return z.logger
+	}
+	return slog.Default()
+}
+
+func (z *ZoneRepository) LoadZoneFile(domainName model.DomainName, zoneFile string) ([]*model.ZoneRecord, error) {
+	records, err := z.zoneReader.ParseZoneFile(domainName.String(), zoneFile)
+	if err != nil {
+		z.log().Warn("invalid zone file is given", "domain", domainName, "error", err)
+		return nil, model.NewInvalidParameterGiven("invalid zone file is given. " + err.Error())
+	}
+
+	return records, nil
+}
+
+func (z *ZoneRepository) TransferZone(domainName model.DomainName, server string, tsigKey *model.TsigKey) ([]*model.ZoneRecord, error) {
+	records, err := z.zoneReader.TransferZone(domainName.String(), server, tsigKey)
+	if err != nil {
+		z.log().Warn("zone transfer failed", "domain", domainName, "server", server, "error", err)
+		return nil, model.NewZoneTransferError(server, err.Error())
+	}
+
+	return records, nil
+}
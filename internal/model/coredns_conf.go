@@ -2,8 +2,8 @@ package model
 
 import (
 	"bytes"
-	"log"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"text/template"
@@ -26,10 +26,12 @@ import (
 //     forward . 8.8.8.8
 // }
 
-var hostsDir = os.Getenv("HOSTS_DIR")
-
 func GetHostsDir() string {
-	return hostsDir
+	return os.Getenv("HOSTS_DIR")
+}
+
+func GetConfPath() string {
+	return os.Getenv("CONF_PATH")
 }
 
 type CoreDNSConf struct {
@@ -38,12 +40,25 @@ type CoreDNSConf struct {
 
 	Cache map[DomainName]*Domain
 
+	// ReverseZones are generated from the hosts of all domains in Cache.
+	ReverseZones []*ReverseZone
+
+	// Pools are the address pools to allocate host addresses from.
+	Pools map[Uuid]*Pool
+
+	// Degraded are the domains whose hosts file can't be loaded.
+	// They are read-only, and their server blocks are kept in the conf.
+	Degraded map[DomainName]*DegradedDomain
+
+	// index is the search index of the hosts in Cache.
+	// It is updated when a domain is added to or deleted from Cache.
+	index *SearchIndex
+
 	forward  string
 	ConfPath string
 }
 
 func NewCoreDNSConf(allDomainInfo []*Domain) *CoreDNSConf {
-	confPath := os.Getenv("CONF_PATH")
 	forward := `
 . {
     forward . 8.8.8.8
@@ -54,11 +69,60 @@ func NewCoreDNSConf(allDomainInfo []*Domain) *CoreDNSConf {
 	for _, dom := range allDomainInfo {
 		cache[dom.Name] = dom
 	}
-	return &CoreDNSConf{locked: 0, Cache: cache, forward: forward, ConfPath: confPath}
+	return &CoreDNSConf{locked: 0, Cache: cache, Pools: map[Uuid]*Pool{}, Degraded: map[DomainName]*DegradedDomain{},
+		index: NewSearchIndex(allDomainInfo), forward: forward, ConfPath: GetConfPath()}
+}
+
+// Clone returns a deep copy of the conf which can be changed without affecting the original.
+func (d *CoreDNSConf) Clone() *CoreDNSConf {
+	cache := map[DomainName]*Domain{}
+	for name, domain := range d.Cache {
+		cache[name] = domain.Clone()
+	}
+
+	var reverseZones []*ReverseZone
+	for _, zone := range d.ReverseZones {
+		reverseZones = append(reverseZones, zone.Clone())
+	}
+
+	// Pools are not changed in place, so they are shared with the original.
+	pools := map[Uuid]*Pool{}
+	for poolUuid, pool := range d.Pools {
+		pools[poolUuid] = pool
+	}
+
+	// Degraded domains are read-only, so they are shared with the original too.
+	degraded := map[DomainName]*DegradedDomain{}
+	for name, domain := range d.Degraded {
+		degraded[name] = domain
+	}
+
+	var domains []*Domain
+	for _, domain := range cache {
+		domains = append(domains, domain)
+	}
+
+	return &CoreDNSConf{locked: 0, Cache: cache, ReverseZones: reverseZones, Pools: pools, Degraded: degraded,
+		index: NewSearchIndex(domains), forward: d.forward, ConfPath: d.ConfPath}
+}
+
+// SetReverseZones sets the reverse zones, and makes their records from the cache.
+func (d *CoreDNSConf) SetReverseZones(zones []*ReverseZone) {
+	d.ReverseZones = zones
+	d.UpdateReverseZones()
+}
+
+// UpdateReverseZones remakes the records of the reverse zones from the cache.
+func (d *CoreDNSConf) UpdateReverseZones() {
+	domains := d.GetAll()
+	for _, zone := range d.ReverseZones {
+		zone.SetHosts(domains)
+	}
 }
 
 func (d *CoreDNSConf) Add(domain *Domain) {
 	d.Cache[domain.Name] = domain
+	d.index.Set(domain)
 }
 
 func (d *CoreDNSConf) GetByName(domainName DomainName) (*Domain, error) {
@@ -73,19 +137,70 @@ func (d *CoreDNSConf) GetByName(domainName DomainName) (*Domain, error) {
 func (d *CoreDNSConf) GetByUuid(domainUuid Uuid, requestTenantUuid Uuid) (*Domain, error) {
 	for _, domain := range d.Cache {
 		if domain.Uuid == domainUuid {
-			for _, t := range domain.Tenants {
-				if t == requestTenantUuid {
-					return domain, nil
-				} else {
-					return nil, NewDomainPermissionError()
-				}
+			if !domain.HasTenant(requestTenantUuid) {
+				return nil, NewDomainPermissionError()
+			}
+			return domain, nil
+		}
+	}
+
+	for _, domain := range d.Degraded {
+		if domain.Uuid == domainUuid {
+			if !domain.HasTenant(requestTenantUuid) {
+				return nil, NewDomainPermissionError()
 			}
+			return nil, NewDomainDegradedError(domain.Name, domain.Err)
 		}
 	}
 	return nil, NewDomainNotFoundError()
 
 }
 
+func (d *CoreDNSConf) AddDegraded(domain *DegradedDomain) {
+	d.Degraded[domain.Name] = domain
+}
+
+// CheckNotDegraded returns an error if the domain name is used by a degraded domain.
+func (d *CoreDNSConf) CheckNotDegraded(domainName DomainName) error {
+	domain, ok := d.Degraded[domainName]
+	if ok {
+		return NewDomainDegradedError(domain.Name, domain.Err)
+	}
+	return nil
+}
+
+func (d *CoreDNSConf) GetDegradedByUuid(domainUuid Uuid, requestTenantUuid Uuid) (*DegradedDomain, error) {
+	for _, domain := range d.Degraded {
+		if domain.Uuid == domainUuid {
+			if !domain.HasTenant(requestTenantUuid) {
+				return nil, NewDomainPermissionError()
+			}
+			return domain, nil
+		}
+	}
+	return nil, NewDomainNotFoundError()
+}
+
+func (d *CoreDNSConf) GetAllDegraded() []*DegradedDomain {
+	var domains []*DegradedDomain
+	for _, domain := range d.Degraded {
+		domains = append(domains, domain)
+	}
+	sort.Slice(domains, func(i, j int) bool { return domains[i].Name < domains[j].Name })
+	return domains
+}
+
+func (d *CoreDNSConf) GetTenantAllDegraded(requestTenantUuid Uuid) []*DegradedDomain {
+	var domains []*DegradedDomain
+	for _, domain := range d.Degraded {
+		if domain.HasTenant(requestTenantUuid) {
+			domains = append(domains, domain)
+		}
+	}
+	sort.Slice(domains, func(i, j int) bool { return domains[i].Name < domains[j].Name })
+	return domains
+}
+
 func (d *CoreDNSConf) GetAll() []*Domain {
 	var domains []*Domain
 	for _, domain := range d.Cache {
@@ -94,6 +209,30 @@ func (d *CoreDNSConf) GetAll() []*Domain {
 	return domains
 }
 
+// TenantCount is the number of the domains and their hosts which a tenant can access.
+type TenantCount struct {
+	Domains int
+	Hosts   int
+}
+
+// CountByTenant returns the numbers of the domains and the hosts of every tenant.
+// A domain shared by the tenants is counted for each of them.
+func (d *CoreDNSConf) CountByTenant() map[Uuid]*TenantCount {
+	counts := map[Uuid]*TenantCount{}
+	for _, domain := range d.Cache {
+		for _, tenantUuid := range domain.Tenants {
+			count, ok := counts[tenantUuid]
+			if !ok {
+				count = &TenantCount{}
+				counts[tenantUuid] = count
+			}
+			count.Domains++
+			count.Hosts += len(domain.Hosts)
+		}
+	}
+	return counts
+}
+
 func (d *CoreDNSConf) GetTenantAll(requestTenantUuid Uuid) []*Domain {
 	var domains []*Domain
 	for _, domain := range d.Cache {
@@ -109,6 +248,65 @@ func (d *CoreDNSConf) GetTenantAll(requestTenantUuid Uuid) []*Domain {
 
 func (d *CoreDNSConf) Delete(domain *Domain) {
 	delete(d.Cache, domain.Name)
+	d.index.Remove(domain.Name)
+}
+
+// SearchHosts returns the hosts matching the search in the domains the tenant can access,
+// or in every domain with allTenants. They are sorted by the domain name and the hostname.
+func (d *CoreDNSConf) SearchHosts(search *HostSearch, requestTenantUuid Uuid, allTenants bool) []*SearchHit {
+	var hits []*SearchHit
+	for _, entry := range d.index.candidates(search) {
+		if !search.matchName(entry.host) || !search.matchAddress(entry.host) {
+			continue
+		}
+
+		domain, ok := d.Cache[entry.domainName]
+		if !ok {
+			continue
+		}
+		if !allTenants && !domain.HasTenant(requestTenantUuid) {
+			continue
+		}
+		hits = append(hits, &SearchHit{Domain: domain, Host: entry.host})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Domain.Name != hits[j].Domain.Name {
+			return hits[i].Domain.Name < hits[j].Domain.Name
+		}
+		return HostSortKey(hits[i].Host) < HostSortKey(hits[j].Host)
+	})
+	return hits
+}
+
+func (d *CoreDNSConf) AddPool(pool *Pool) {
+	d.Pools[pool.Uuid] = pool
+}
+
+func (d *CoreDNSConf) DeletePool(pool *Pool) {
+	delete(d.Pools, pool.Uuid)
+}
+
+func (d *CoreDNSConf) GetPoolByUuid(poolUuid Uuid, requestTenantUuid Uuid) (*Pool, error) {
+	pool, ok := d.Pools[poolUuid]
+	if !ok {
+		return nil, NewPoolNotFoundError()
+	}
+	if !pool.HasTenant(requestTenantUuid) {
+		return nil, NewPoolPermissionError()
+	}
+	return pool, nil
+}
+
+func (d *CoreDNSConf) GetTenantAllPools(requestTenantUuid Uuid) []*Pool {
+	var pools []*Pool
+	for _, pool := range d.Pools {
+		if pool.HasTenant(requestTenantUuid) {
+			pools = append(pools, pool)
+		}
+	}
+	sort.Slice(pools, func(i, j int) bool { return pools[i].Name < pools[j].Name })
+	return pools
 }
 
 func (d *CoreDNSConf) GetFileInfo() (string, error) {
@@ -121,24 +319,61 @@ func (d *CoreDNSConf) GetFileInfo() (string, error) {
 `
 	tmpl := template.Must(template.New("").Parse(domainBottomTemplate))
 
-	for domName, dom := range d.Cache {
+	// Server blocks are sorted by domain name to keep the rendered conf stable.
+	var domainNameList []DomainName
+	for domName := range d.Cache {
+		domainNameList = append(domainNameList, domName)
+	}
+	for domName := range d.Degraded {
+		if _, ok := d.Cache[domName]; !ok {
+			domainNameList = append(domainNameList, domName)
+		}
+	}
+	sort.Slice(domainNameList, func(i, j int) bool { return domainNameList[i] < domainNameList[j] })
+
+	for _, domName := range domainNameList {
+		// Degraded domains are rendered with their original hosts file,
+		// so that CoreDNS keeps serving what it has loaded from them.
+		var dom interface{} = d.Cache[domName]
+		if degraded, ok := d.Degraded[domName]; ok {
+			dom = degraded
+		}
 		domainInfoTop := strings.TrimSpace(domName.String()) + `. {
 `
 
 		var out bytes.Buffer
 		err := tmpl.Execute(&out, dom)
 		if err != nil {
-			log.Print(err)
 			return "", err
 		}
 		domainInfoBottom := out.String()
 		conf = conf + domainInfoTop + domainInfoBottom
 	}
 
+	// Reverse zones are rendered with the same template, since their files are hosts files too.
+	reverseZones := append([]*ReverseZone{}, d.ReverseZones...)
+	sort.Slice(reverseZones, func(i, j int) bool { return reverseZones[i].Name < reverseZones[j].Name })
+	for _, zone := range reverseZones {
+		zoneInfoTop := zone.Name.String() + `. {
+`
+
+		var out bytes.Buffer
+		err := tmpl.Execute(&out, zone)
+		if err != nil {
+			return "", err
+		}
+		conf = conf + zoneInfoTop + out.String()
+	}
+
 	conf = conf + d.forward
 	return conf, nil
 }
 
+// CheckDegradedDomainsKept returns an error if the conf drops a degraded domain.
+func (d *CoreDNSConf) CheckDegradedDomainsKept(confInfo string) error {
+	return checkDegradedDomainsKept(confInfo, d.Degraded)
+}
+
 func (d *CoreDNSConf) IsLocked() bool {
 	if d.locked == 0 {
 		return false
@@ -81,3 +81,38 @@ func TestAddCoreDNSConf(t *testing.T) {
 		t.Error(addedDomainList[0])
 	}
 }
+
+func TestCountByTenant(t *testing.T) {
+	domain1, err := NewDomain("hogehoge.hoge", `# DomainUUID: 3e8fc6b1-0a93-4c57-9f2b-95d3e66a66e0
+# Tenats:
+#   - df397e50-8006-450e-b18b-5c5bd940baff
+#   - 02c03bd4-fe2e-45f2-85b6-b535af15215d
+172.21.1.1  hogeserver1.hogehoge.hoge  # 5b9ea8eb-5ce5-422a-9d70-37d25fa896ae
+172.21.1.2  hogeserver2.hogehoge.hoge  # f0c5edcd-3b18-4c26-a8e1-3f3495504dd6
+`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	domain2, err := NewDomain("fugafuga.fuga", `# DomainUUID: 9b0e4c2a-6f55-4a4f-a1f6-3c1d0f5b8e21
+# Tenats:
+#   - df397e50-8006-450e-b18b-5c5bd940baff
+172.21.2.1  fugaserver1.fugafuga.fuga  # 1c8d3f4e-2b7a-4f0e-9a6b-5d4c3b2a1f0e
+`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	counts := NewCoreDNSConf([]*Domain{domain1, domain2}).CountByTenant()
+	if len(counts) != 2 {
+		t.Error("number of the tenants is missmatched")
+		return
+	}
+	if counts["df397e50-8006-450e-b18b-5c5bd940baff"].Domains != 2 || counts["df397e50-8006-450e-b18b-5c5bd940baff"].Hosts != 3 {
+		t.Error("counts of the tenant with both domains are missmatched")
+	}
+	if counts["02c03bd4-fe2e-45f2-85b6-b535af15215d"].Domains != 1 || counts["02c03bd4-fe2e-45f2-85b6-b535af15215d"].Hosts != 2 {
+		t.Error("counts of the tenant with one domain are missmatched")
+	}
+}
@@ -0,0 +1,99 @@
+package model
+
+import (
+	"strings"
+
+	"github.com/coredns/caddy/caddyfile"
+)
+
+// Plugins which this API renders into the Corefile.
+// Any other directive in a server block is rejected.
+var knownPlugins = []string{
+	"hosts",
+	"reload",
+	"log",
+	"forward",
+}
+
+// ValidateCorefile parses the rendered Corefile with the caddyfile parser used by CoreDNS
+// and checks that CoreDNS is able to load it.
+func ValidateCorefile(confInfo string) error {
+	serverBlocks, err := caddyfile.Parse("Corefile", strings.NewReader(confInfo), knownPlugins)
+	if err != nil {
+		return NewInvalidCorefileError(err.Error())
+	}
+
+	zones := map[string]bool{}
+	for _, block := range serverBlocks {
+		blockZones := map[string]bool{}
+		for _, key := range block.Keys {
+			zone, err := normalizeServerBlockKey(key)
+			if err != nil {
+				return err
+			}
+
+			if blockZones[zone] {
+				return NewInvalidCorefileError("duplicate zone in server block: " + key)
+			}
+			if zones[zone] {
+				return NewInvalidCorefileError("zone is overlapped with another server block: " + key)
+			}
+			blockZones[zone] = true
+		}
+
+		for zone := range blockZones {
+			zones[zone] = true
+		}
+	}
+
+	return nil
+}
+
+// normalizeServerBlockKey returns a server block key like "dns://hogehoge.hoge.:53"
+// in the form which CoreDNS uses to identify a zone.
+func normalizeServerBlockKey(key string) (string, error) {
+	scheme := "dns://"
+	zone := strings.ToLower(key)
+	if i := strings.Index(zone, "://"); i >= 0 {
+		scheme = zone[:i+3]
+		zone = zone[i+3:]
+	}
+
+	port := "53"
+	if i := strings.LastIndex(zone, ":"); i >= 0 {
+		port = zone[i+1:]
+		zone = zone[:i]
+	}
+
+	if !strings.HasSuffix(zone, ".") {
+		zone = zone + "."
+	}
+
+	if !isValidZoneName(zone) {
+		return "", NewInvalidCorefileError("invalid zone name in server block: " + key)
+	}
+
+	return scheme + zone + ":" + port, nil
+}
+
+func isValidZoneName(zone string) bool {
+	if zone == "." {
+		return true
+	}
+
+	name := strings.TrimSuffix(zone, ".")
+	if len(name) == 0 || len(name) > 253 {
+		return false
+	}
+
+	for _, label := range strings.Split(name, ".") {
+		if len(label) == 0 || len(label) > 63 {
+			return false
+		}
+		if strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,82 @@
+package model
+
+import "testing"
+
+func TestValidateCorefile(t *testing.T) {
+	confInfo := `hogehoge.hoge. {
+    hosts /var/lib/coredns/hosts/hogehoge.hoge
+    reload 10s 5s
+    log
+}
+
+fugafuga.fuga. {
+    hosts /var/lib/coredns/hosts/fugafuga.fuga
+    reload 10s 5s
+    log
+}
+
+. {
+    forward . 8.8.8.8
+}
+`
+	err := ValidateCorefile(confInfo)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestValidateCorefileGenerated(t *testing.T) {
+	name := "hogehoge.hoge"
+	tenant := []string{"5cdc62c5-a110-4d89-9cdd-5e19f1983f0f"}
+	domain, err := NewOriginalDomain(name, tenant)
+	if err != nil {
+		t.Error(err)
+	}
+
+	conf := NewCoreDNSConf([]*Domain{domain})
+	confInfo, err := conf.GetFileInfo()
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = ValidateCorefile(confInfo)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestValidateCorefileInvalid(t *testing.T) {
+	invalidConfList := map[string]string{
+		"syntax": `hogehoge.hoge. {
+    hosts /var/lib/coredns/hosts/hogehoge.hoge
+`,
+		"duplicate zone": `hogehoge.hoge. hogehoge.hoge {
+    hosts /var/lib/coredns/hosts/hogehoge.hoge
+}
+`,
+		"overlapped server block": `hogehoge.hoge. {
+    hosts /var/lib/coredns/hosts/hogehoge.hoge
+}
+
+hogehoge.hoge:53 {
+    hosts /var/lib/coredns/hosts/hogehoge.hoge
+}
+`,
+		"unknown plugin": `hogehoge.hoge. {
+    hosts /var/lib/coredns/hosts/hogehoge.hoge
+    unknown
+}
+`,
+		"invalid zone name": `hogehoge..hoge. {
+    hosts /var/lib/coredns/hosts/hogehoge..hoge
+}
+`,
+	}
+
+	for name, confInfo := range invalidConfList {
+		err := ValidateCorefile(confInfo)
+		if _, ok := err.(*InvalidCorefileError); !ok {
+			t.Error(name + " is not rejected")
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package model
+
+import (
+	"strings"
+
+	"github.com/coredns/caddy/caddyfile"
+)
+
+// DegradedDomain is a domain whose hosts file can't be loaded.
+// Its server block is kept in the conf as it is, and it is read-only until the file is fixed.
+type DegradedDomain struct {
+	Uuid           Uuid
+	Name           DomainName
+	Tenants        []Uuid
+	DomainFilePath string
+	ReloadInterval string
+	ReloadJitter   string
+	Err            string
+}
+
+// NewDegradedDomain makes the degraded domain with the load error.
+// The domain UUID and the tenants are read from the file header as far as possible,
+// and they are left empty when the header is broken too.
+func NewDegradedDomain(name DomainName, fileInfo string, loadErr error) *DegradedDomain {
+	domain := &DegradedDomain{
+		Name:           name,
+		DomainFilePath: GetHostsFilePath(name),
+		ReloadInterval: "10s",
+		ReloadJitter:   "5s",
+		Err:            loadErr.Error()}
+
+	inTenats := false
+	for _, line := range strings.Split(fileInfo, "\n") {
+		if !strings.HasPrefix(line, "#") {
+			inTenats = false
+			continue
+		}
+
+		splitComment := strings.Fields(strings.TrimPrefix(line, "#"))
+		if len(splitComment) == 0 {
+			continue
+		}
+
+		if splitComment[0] == "DomainUUID:" && len(splitComment) == 2 && domain.Uuid == "" {
+			domainUuid, err := NewUuid(splitComment[1])
+			if err == nil {
+				domain.Uuid = domainUuid
+			}
+		} else if splitComment[0] == "Tenats:" {
+			inTenats = true
+		} else if inTenats && splitComment[0] == "-" && len(splitComment) == 2 {
+			tenantUuid, err := NewUuid(splitComment[1])
+			if err == nil {
+				domain.Tenants = append(domain.Tenants, tenantUuid)
+			}
+		}
+	}
+
+	return domain
+}
+
+func (d *DegradedDomain) HasTenant(requestTenantUuid Uuid) bool {
+	for _, t := range d.Tenants {
+		if t == requestTenantUuid {
+			return true
+		}
+	}
+	return false
+}
+
+// checkDegradedDomainsKept returns an error if the conf doesn't have the server block of every degraded domain.
+func checkDegradedDomainsKept(confInfo string, degraded map[DomainName]*DegradedDomain) error {
+	if len(degraded) == 0 {
+		return nil
+	}
+
+	serverBlocks, err := caddyfile.Parse("Corefile", strings.NewReader(confInfo), knownPlugins)
+	if err != nil {
+		return NewInvalidCorefileError(err.Error())
+	}
+
+	kept := map[DomainName]bool{}
+	for _, block := range serverBlocks {
+		tokens := block.Tokens["hosts"]
+		for _, key := range block.Keys {
+			zone, err := serverBlockZoneName(key)
+			if err != nil {
+				continue
+			}
+
+			dom, ok := degraded[DomainName(zone)]
+			if ok && len(tokens) >= 2 && tokens[1].Text == dom.DomainFilePath {
+				kept[dom.Name] = true
+			}
+		}
+	}
+
+	for name := range degraded {
+		if !kept[name] {
+			return NewInvalidCorefileError("degraded domain is dropped. domain: " + name.String())
+		}
+	}
+	return nil
+}
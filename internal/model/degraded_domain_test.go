@@ -0,0 +1,68 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewDegradedDomain(t *testing.T) {
+	domainFileInfo := `# DomainUUID: 3e8fc6b1-0a93-4c57-9f2b-95d3e66a66e0
+# Tenats:
+#   - df397e50-8006-450e-b18b-5c5bd940baff
+172.21.1.1  hoge-server1.hogehoge.hoge  #
+`
+	_, err := NewDomain("hogehoge.hoge", domainFileInfo)
+	if err == nil {
+		t.Error("broken host line is accepted")
+		return
+	}
+
+	domain := NewDegradedDomain("hogehoge.hoge", domainFileInfo, err)
+	if domain.Uuid != "3e8fc6b1-0a93-4c57-9f2b-95d3e66a66e0" {
+		t.Error("domain uuid is not read from the header")
+	}
+	if !domain.HasTenant("df397e50-8006-450e-b18b-5c5bd940baff") {
+		t.Error("tenant is not read from the header")
+	}
+	if domain.Err != err.Error() {
+		t.Error("load error is not attached")
+	}
+}
+
+func TestCoreDNSConfDegradedDomain(t *testing.T) {
+	domainFileInfo := `# DomainUUID: 3e8fc6b1-0a93-4c57-9f2b-95d3e66a66e0
+# Tenats:
+#   - df397e50-8006-450e-b18b-5c5bd940baff
+172.21.1.1
+`
+	conf := NewCoreDNSConf(nil)
+	conf.AddDegraded(NewDegradedDomain("hogehoge.hoge", domainFileInfo, NewServerSideError("broken")))
+
+	_, err := conf.GetByUuid("3e8fc6b1-0a93-4c57-9f2b-95d3e66a66e0", "df397e50-8006-450e-b18b-5c5bd940baff")
+	if _, ok := err.(*DomainDegradedError); !ok {
+		t.Error("degraded domain is not read-only")
+	}
+	_, err = conf.GetByUuid("3e8fc6b1-0a93-4c57-9f2b-95d3e66a66e0", "02c03bd4-fe2e-45f2-85b6-b535af15215d")
+	if _, ok := err.(*DomainPermissionError); !ok {
+		t.Error("degraded domain is visible to other tenants")
+	}
+	if conf.CheckNotDegraded("hogehoge.hoge") == nil {
+		t.Error("degraded domain name can be written")
+	}
+
+	confInfo, err := conf.GetFileInfo()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !strings.Contains(confInfo, "hogehoge.hoge. {\n    hosts "+GetHostsFilePath("hogehoge.hoge")+"\n") {
+		t.Error("degraded domain is dropped from the conf: " + confInfo)
+	}
+	if err := conf.CheckDegradedDomainsKept(confInfo); err != nil {
+		t.Error(err)
+	}
+
+	if conf.CheckDegradedDomainsKept(". {\n    forward . 8.8.8.8\n}\n") == nil {
+		t.Error("conf without degraded domain is accepted")
+	}
+}
@@ -0,0 +1,402 @@
+package model
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/coredns/caddy/caddyfile"
+	"github.com/google/uuid"
+)
+
+// DiagnosisProblem is a problem found in a hosts file or the Corefile.
+// Line is 0 when the problem is about the whole file.
+type DiagnosisProblem struct {
+	File    string
+	Line    int
+	Message string
+	// Fixable is true when the problem is repaired safely by rewriting fixFile.
+	Fixable bool
+	Fixed   bool
+	fixFile string
+}
+
+func (p *DiagnosisProblem) String() string {
+	location := p.File
+	if p.Line > 0 {
+		location += ":" + strconv.Itoa(p.Line)
+	}
+	return location + ": " + p.Message
+}
+
+// Diagnosis checks the hosts files and the Corefile in the same way as the API server loads them.
+// Hosts files have to be checked before the Corefile, since the Corefile is compared with them.
+type Diagnosis struct {
+	Problems []*DiagnosisProblem
+
+	domainUuids map[Uuid]string
+	hostUuids   map[Uuid]string
+	// domains are the hosts files which can be loaded after the repairs.
+	domains []*Domain
+	// brokenFiles are the hosts files which can not be loaded even after the repairs.
+	brokenFiles []string
+}
+
+func NewDiagnosis() *Diagnosis {
+	return &Diagnosis{domainUuids: map[Uuid]string{}, hostUuids: map[Uuid]string{}}
+}
+
+func (d *Diagnosis) addProblem(file string, line int, message string, fixFile string) *DiagnosisProblem {
+	problem := &DiagnosisProblem{File: file, Line: line, Message: message, Fixable: fixFile != "", fixFile: fixFile}
+	d.Problems = append(d.Problems, problem)
+	return problem
+}
+
+// MarkFixed marks the fixable problems repaired by rewriting the file.
+func (d *Diagnosis) MarkFixed(file string) {
+	for _, p := range d.Problems {
+		if p.Fixable && p.fixFile == file {
+			p.Fixed = true
+		}
+	}
+}
+
+// HasUnfixed returns whether any problem is left.
+func (d *Diagnosis) HasUnfixed() bool {
+	for _, p := range d.Problems {
+		if !p.Fixed {
+			return true
+		}
+	}
+	return false
+}
+
+// NeedsFix returns whether the file has problems which are repaired by rewriting it.
+func (d *Diagnosis) NeedsFix(file string) bool {
+	for _, p := range d.Problems {
+		if p.Fixable && p.fixFile == file {
+			return true
+		}
+	}
+	return false
+}
+
+func location(file string, line int) string {
+	return file + ":" + strconv.Itoa(line)
+}
+
+func newRandomUuid() Uuid {
+	u, _ := uuid.NewRandom()
+	return Uuid(u.String())
+}
+
+// CheckDomainFile checks the hosts file, and returns the file info with the safe repairs applied.
+// Safe repairs are adding missing DomainUUID, giving new UUIDs to duplicated hosts,
+// and adding UUIDs to the host lines which don't have them.
+func (d *Diagnosis) CheckDomainFile(path, fileName, fileInfo string) string {
+	domainName, err := NewDomainName(fileName)
+	if err != nil {
+		d.addProblem(path, 0, "file name is not a domain name. "+err.Error(), "")
+		d.brokenFiles = append(d.brokenFiles, fileName)
+		return fileInfo
+	}
+
+	lines := strings.Split(fileInfo, "\n")
+	broken := false
+	domainUuidLine := 0
+	hasTenants := false
+	inTenats := false
+	names := map[string]int{}
+	addresses := map[string]int{}
+
+	for i, line := range lines {
+		n := i + 1
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		// Lines are classified in the same order as NewDomain.
+		if strings.HasPrefix(line, txtRecordPrefix) {
+			if _, ok := parseTxtRecordLine(line); !ok {
+				d.addProblem(path, n, "invalid TXT record", "")
+				broken = true
+			}
+			continue
+		}
+
+		splitLine := strings.Split(line, "#")
+		hostInfo := splitLine[0]
+		commentInfo := splitLine[len(splitLine)-1]
+		splitComment := strings.Fields(commentInfo)
+
+		switch {
+		case strings.Contains(commentInfo, "DomainUUID:"):
+			if domainUuidLine > 0 {
+				d.addProblem(path, n, "DomainUUID is written again. first: line "+strconv.Itoa(domainUuidLine), "")
+				broken = true
+				continue
+			}
+			domainUuidLine = n
+
+			if len(splitComment) < 2 {
+				d.addProblem(path, n, "DomainUUID is empty", "")
+				broken = true
+				continue
+			}
+			domainUuid, err := NewUuid(splitComment[len(splitComment)-1])
+			if err != nil {
+				d.addProblem(path, n, "invalid DomainUUID", "")
+				broken = true
+				continue
+			}
+			if first, ok := d.domainUuids[domainUuid]; ok {
+				d.addProblem(path, n, "DomainUUID is duplicated with "+first, "")
+				continue
+			}
+			d.domainUuids[domainUuid] = location(path, n)
+
+		case strings.Contains(commentInfo, "Revision:") && strings.HasPrefix(line, "#"):
+			_, err := strconv.ParseUint(splitComment[len(splitComment)-1], 10, 64)
+			if err != nil {
+				d.addProblem(path, n, "invalid revision", "")
+				broken = true
+			}
+
+		case strings.Contains(commentInfo, "Tenats:"):
+			inTenats = true
+
+		case inTenats && strings.Contains(commentInfo, " - ") && strings.HasPrefix(line, "#"):
+			_, err := NewUuid(splitComment[len(splitComment)-1])
+			if err != nil {
+				d.addProblem(path, n, "invalid tenant UUID. "+err.Error(), "")
+				broken = true
+				continue
+			}
+			hasTenants = true
+
+		case strings.Contains(line, "-") && strings.Contains(line, ".") && strings.Contains(line, "#"):
+			inTenats = false
+			fixedLine, ok := d.checkHostLine(path, n, line, hostInfo, splitComment, domainName, names, addresses)
+			if !ok {
+				broken = true
+				continue
+			}
+			lines[i] = fixedLine
+
+		case !strings.HasPrefix(strings.TrimSpace(line), "#"):
+			// CoreDNS answers this line, but the API ignores it since it doesn't have UUID.
+			fields := strings.Fields(hostInfo)
+			if len(fields) != 2 {
+				d.addProblem(path, n, "unparsable line. address, hostname and UUID comment are expected", "")
+				continue
+			}
+
+			host, err := NewHost(newRandomUuid(), fields[1], fields[0])
+			if err != nil {
+				d.addProblem(path, n, "unparsable line. "+err.Error(), "")
+				continue
+			}
+
+			hostLine, err := host.GetHostInfo()
+			if err != nil {
+				d.addProblem(path, n, "unparsable line. "+err.Error(), "")
+				continue
+			}
+			if !d.checkHost(path, n, host, domainName, names, addresses) {
+				continue
+			}
+			d.addProblem(path, n, "host does not have UUID, and it is not managed by the API", path)
+			d.hostUuids[host.Uuid] = location(path, n)
+			lines[i] = strings.TrimSuffix(hostLine, "\n")
+		}
+	}
+
+	if domainUuidLine == 0 {
+		domainUuid := newRandomUuid()
+		d.addProblem(path, 0, "DomainUUID is not written", path)
+		d.domainUuids[domainUuid] = location(path, 1)
+		lines = append([]string{"# DomainUUID: " + domainUuid.String()}, lines...)
+	}
+	if !hasTenants {
+		d.addProblem(path, 0, "no tenant can access the domain", "")
+	}
+
+	fixed := strings.Join(lines, "\n")
+	if broken {
+		d.brokenFiles = append(d.brokenFiles, fileName)
+		return fixed
+	}
+
+	domain, err := NewDomain(fileName, fixed)
+	if err != nil {
+		d.addProblem(path, 0, "hosts file can not be loaded. "+err.Error(), "")
+		d.brokenFiles = append(d.brokenFiles, fileName)
+		return fixed
+	}
+	d.domains = append(d.domains, domain)
+	return fixed
+}
+
+// checkHostLine checks the host line with UUID comment, and returns the line with the repairs.
+// It returns false when NewDomain fails to load the line.
+func (d *Diagnosis) checkHostLine(path string, n int, line, hostInfo string, splitComment []string, domainName DomainName, names, addresses map[string]int) (string, bool) {
+	fields := strings.Fields(hostInfo)
+	if len(fields) < 2 || len(splitComment) == 0 {
+		d.addProblem(path, n, "unparsable host line. address, hostname and UUID comment are expected", "")
+		return line, false
+	}
+
+	hostUuid, err := NewUuid(splitComment[0])
+	if err != nil {
+		d.addProblem(path, n, "invalid host UUID. "+err.Error(), "")
+		return line, false
+	}
+
+	host, err := NewHost(hostUuid, fields[1], fields[0])
+	if err != nil {
+		d.addProblem(path, n, err.Error(), "")
+		return line, false
+	}
+
+	err = host.setLeaseInfo(splitComment[1:])
+	if err != nil {
+		d.addProblem(path, n, err.Error(), "")
+		return line, false
+	}
+
+	d.checkHost(path, n, host, domainName, names, addresses)
+
+	first, ok := d.hostUuids[host.Uuid]
+	if !ok {
+		d.hostUuids[host.Uuid] = location(path, n)
+		return line, true
+	}
+
+	d.addProblem(path, n, "host UUID is duplicated with "+first, path)
+	host.Uuid = newRandomUuid()
+	d.hostUuids[host.Uuid] = location(path, n)
+	hostLine, err := host.GetHostInfo()
+	if err != nil {
+		return line, true
+	}
+	return strings.TrimSuffix(hostLine, "\n"), true
+}
+
+// checkHost checks the host against the domain and the other hosts in the file.
+func (d *Diagnosis) checkHost(path string, n int, host *Host, domainName DomainName, names, addresses map[string]int) bool {
+	valid := true
+	domain := domainName.String()
+	if host.Name != domain && !strings.HasSuffix(host.Name, "."+domain) {
+		d.addProblem(path, n, "host is outside of the domain. host: "+host.Name, "")
+		valid = false
+	}
+
+	if first, ok := names[host.Name]; ok {
+		d.addProblem(path, n, "hostname is duplicated with line "+strconv.Itoa(first), "")
+		valid = false
+	} else {
+		names[host.Name] = n
+	}
+
+	if first, ok := addresses[host.Address]; ok {
+		d.addProblem(path, n, "address is duplicated with line "+strconv.Itoa(first), "")
+		valid = false
+	} else {
+		addresses[host.Address] = n
+	}
+	return valid
+}
+
+// CheckCorefile compares the Corefile with the hosts files.
+// Its problems are fixable only when every hosts file can be loaded,
+// so the Corefile rendered by FixedCorefile never drops a broken hosts file.
+func (d *Diagnosis) CheckCorefile(path, confInfo string, fileNames []string) {
+	fixFile := path
+	if len(d.brokenFiles) > 0 {
+		fixFile = ""
+	}
+
+	err := ValidateCorefile(confInfo)
+	if err != nil {
+		d.addProblem(path, 0, err.Error(), fixFile)
+	}
+
+	serverBlocks, err := caddyfile.Parse(path, strings.NewReader(confInfo), knownPlugins)
+	if err != nil {
+		return
+	}
+
+	files := map[string]bool{}
+	for _, f := range fileNames {
+		files[f] = true
+	}
+
+	referenced := map[string]bool{}
+	for _, block := range serverBlocks {
+		for _, key := range block.Keys {
+			zone, err := serverBlockZoneName(key)
+			if err != nil || zone == "" || strings.HasSuffix(zone, ".arpa") {
+				continue
+			}
+			referenced[zone] = true
+
+			tokens := block.Tokens["hosts"]
+			if len(tokens) < 2 {
+				d.addProblem(path, 0, "server block of "+zone+" does not have hosts file", fixFile)
+				continue
+			}
+
+			hostsPath := tokens[1]
+			if !files[zone] {
+				d.addProblem(path, hostsPath.Line, "hosts file of "+zone+" is not found in hosts dir", fixFile)
+				continue
+			}
+			expected := filepath.Join(GetHostsDir(), zone)
+			if hostsPath.Text != expected {
+				d.addProblem(path, hostsPath.Line, "hosts file of "+zone+" has to be "+expected+". path: "+hostsPath.Text, fixFile)
+			}
+		}
+	}
+
+	for _, f := range fileNames {
+		if !referenced[f] {
+			d.addProblem(filepath.Join(GetHostsDir(), f), 0, "hosts file is not in Corefile", fixFile)
+		}
+	}
+}
+
+// serverBlockZoneName returns the zone name without the trailing dot, like "hogehoge.hoge".
+func serverBlockZoneName(key string) (string, error) {
+	zone, err := normalizeServerBlockKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	zone = zone[strings.Index(zone, "://")+3 : strings.LastIndex(zone, ":")]
+	return strings.TrimSuffix(zone, "."), nil
+}
+
+// FixedCorefile renders the Corefile from the hosts files in the same way as the API server.
+func (d *Diagnosis) FixedCorefile() (string, error) {
+	if len(d.brokenFiles) > 0 {
+		return "", NewInvalidCorefileError("Corefile can not be rendered while hosts files are broken: " + strings.Join(d.brokenFiles, ", "))
+	}
+
+	conf := NewCoreDNSConf(d.domains)
+	reverseZones, err := NewReverseZoneList(GetReverseZonesConf())
+	if err != nil {
+		return "", err
+	}
+	conf.SetReverseZones(reverseZones)
+
+	confInfo, err := conf.GetFileInfo()
+	if err != nil {
+		return "", err
+	}
+
+	err = ValidateCorefile(confInfo)
+	if err != nil {
+		return "", err
+	}
+	return confInfo, nil
+}
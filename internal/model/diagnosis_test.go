@@ -0,0 +1,118 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiagnosisCheckDomainFile(t *testing.T) {
+	fileInfo := `# DomainUUID: 3e8fc6b1-0a93-4c57-9f2b-95d3e66a66e0
+# Tenats:
+#   - df397e50-8006-450e-b18b-5c5bd940baff
+172.21.1.1  hogeserver1.hogehoge.hoge  # 5b9ea8eb-5ce5-422a-9d70-37d25fa896ae
+172.21.1.2  hogeserver2.hogehoge.hoge  # 5b9ea8eb-5ce5-422a-9d70-37d25fa896ae
+172.21.1.3  hogeserver3.hogehoge.hoge
+172.21.1.4  fugaserver1.fugafuga.fuga  # eac1b92b-31b2-4b7d-a26b-fd487b6669ca
+`
+	diagnosis := NewDiagnosis()
+	fixed := diagnosis.CheckDomainFile("hogehoge.hoge", "hogehoge.hoge", fileInfo)
+
+	expects := map[string]bool{
+		"hogehoge.hoge:5: host UUID is duplicated with hogehoge.hoge:4":                   true,
+		"hogehoge.hoge:6: host does not have UUID, and it is not managed by the API":      true,
+		"hogehoge.hoge:7: host is outside of the domain. host: fugaserver1.fugafuga.fuga": false,
+	}
+	if len(diagnosis.Problems) != len(expects) {
+		t.Error(diagnosis.Problems)
+	}
+	for _, p := range diagnosis.Problems {
+		fixable, ok := expects[p.String()]
+		if !ok || fixable != p.Fixable {
+			t.Error(p.String())
+		}
+	}
+
+	domain, err := NewDomain("hogehoge.hoge", fixed)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(domain.Hosts) != 4 || domain.Hosts[1].Uuid == domain.Hosts[0].Uuid {
+		t.Error(fixed)
+	}
+	if !strings.Contains(fixed, "172.21.1.3  hogeserver3.hogehoge.hoge  # ") {
+		t.Error("UUID is not added to host line")
+	}
+}
+
+func TestDiagnosisCheckBrokenDomainFile(t *testing.T) {
+	fileInfo := `# Tenats:
+#   - df397e50-8006-450e-b18b-5c5bd940baff
+172.21.1.999  hogeserver1.hogehoge.hoge  # 5b9ea8eb-5ce5-422a-9d70-37d25fa896ae
+`
+	diagnosis := NewDiagnosis()
+	diagnosis.CheckDomainFile("hogehoge.hoge", "hogehoge.hoge", fileInfo)
+
+	if len(diagnosis.Problems) != 2 || diagnosis.Problems[0].Line != 3 || diagnosis.Problems[0].Fixable {
+		t.Error(diagnosis.Problems)
+	}
+	if diagnosis.Problems[1].Message != "DomainUUID is not written" || !diagnosis.Problems[1].Fixable {
+		t.Error(diagnosis.Problems[1].String())
+	}
+
+	diagnosis.CheckCorefile("coredns.conf", "", []string{"hogehoge.hoge"})
+	for _, p := range diagnosis.Problems[2:] {
+		if p.Fixable {
+			t.Error("Corefile is fixable with broken hosts file. " + p.String())
+		}
+	}
+	_, err := diagnosis.FixedCorefile()
+	if err == nil {
+		t.Error("Corefile is rendered without broken hosts file")
+	}
+}
+
+func TestDiagnosisCheckCorefile(t *testing.T) {
+	fileInfo := `# DomainUUID: 3e8fc6b1-0a93-4c57-9f2b-95d3e66a66e0
+# Tenats:
+#   - df397e50-8006-450e-b18b-5c5bd940baff
+172.21.1.1  hogeserver1.hogehoge.hoge  # 5b9ea8eb-5ce5-422a-9d70-37d25fa896ae
+`
+	confInfo := `hogehoge.hoge. {
+    hosts /var/lib/coredns/hosts/hogehoge.hoge
+    reload 10s 5s
+    log
+}
+
+fugafuga.fuga. {
+    hosts fugafuga.fuga
+    reload 10s 5s
+    log
+}
+`
+	diagnosis := NewDiagnosis()
+	diagnosis.CheckDomainFile("hogehoge.hoge", "hogehoge.hoge", fileInfo)
+	diagnosis.CheckCorefile("coredns.conf", confInfo, []string{"hogehoge.hoge"})
+
+	expects := []string{
+		"coredns.conf:2: hosts file of hogehoge.hoge has to be hogehoge.hoge. path: /var/lib/coredns/hosts/hogehoge.hoge",
+		"coredns.conf:8: hosts file of fugafuga.fuga is not found in hosts dir",
+	}
+	if len(diagnosis.Problems) != len(expects) {
+		t.Error(diagnosis.Problems)
+		return
+	}
+	for i, p := range diagnosis.Problems {
+		if p.String() != expects[i] || !p.Fixable {
+			t.Error(p.String())
+		}
+	}
+
+	fixedConf, err := diagnosis.FixedCorefile()
+	if err != nil {
+		t.Error(err)
+	}
+	if !strings.Contains(fixedConf, "hosts hogehoge.hoge\n") || strings.Contains(fixedConf, "fugafuga.fuga") {
+		t.Error(fixedConf)
+	}
+}
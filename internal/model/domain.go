@@ -3,6 +3,7 @@ package model
 import (
 	"bytes"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"text/template"
 
@@ -21,6 +22,11 @@ type Domain struct {
 	DomainFilePath string
 	ReloadInterval string
 	ReloadJitter   string
+	// Revision is incremented on every write of the hosts file,
+	// and kept in its header to survive restarts.
+	Revision uint64
+	// TxtRecords are kept in the header, since hosts file can't have them.
+	TxtRecords []*TxtRecord
 }
 
 func NewOriginalDomain(name string, tenantList []string) (*Domain, error) {
@@ -73,6 +79,8 @@ func NewDomain(name, fileInfo string) (*Domain, error) {
 	var domain *Domain
 	var hosts []*Host
 	var tenants []Uuid
+	var revision uint64
+	var txtRecords []*TxtRecord
 	inTenats := false
 	var err error
 
@@ -81,13 +89,26 @@ func NewDomain(name, fileInfo string) (*Domain, error) {
 		//
 		// ```
 		// # DomainUUID: 3e8fc6b1-0a93-4c57-9f2b-95d3e66a66e0
+		// # Revision: 3
 		// # Tenats:
 		// #   - df397e50-8006-450e-b18b-5c5bd940baff
 		// #   - 02c03bd4-fe2e-45f2-85b6-b535af15215d
+		// # TXT: a-hogeserver1.hogehoge.hoge "heritage=external-dns,external-dns/owner=default"
 		// 172.21.1.1  hogeserver1.hogehoge.hoge  # 5b9ea8eb-5ce5-422a-9d70-37d25fa896ae
 		// 172.21.1.2  hogeserver2.hogehoge.hoge  # f0c5edcd-3b18-4c26-a8e1-3f3495504dd6
+		// 172.21.1.3  hogeserver3.hogehoge.hoge  # eac1b92b-31b2-4b7d-a26b-fd487b6669ca expires_at=2021-04-01T00:00:00Z ttl_lease=1h0m0s
 		// ````
 
+		// TXT record may have any character in the text.
+		if strings.HasPrefix(line, txtRecordPrefix) {
+			record, ok := parseTxtRecordLine(line)
+			if !ok {
+				return nil, NewServerSideError("invalid TXT record is in hosts file info for " + name + ". line: " + line)
+			}
+			txtRecords = append(txtRecords, record)
+			continue
+		}
+
 		splitLine := strings.Split(line, "#")
 		hostInfo := splitLine[0]
 		commentInfo := splitLine[len(splitLine)-1]
@@ -101,6 +122,11 @@ func NewDomain(name, fileInfo string) (*Domain, error) {
 			if err != nil {
 				return nil, err
 			}
+		} else if strings.Contains(commentInfo, "Revision:") && strings.HasPrefix(line, "#") {
+			revision, err = strconv.ParseUint(splitComment[len(splitComment)-1], 10, 64)
+			if err != nil {
+				return nil, NewServerSideError("invalid revision is in hosts file info for " + name + ". line: " + line)
+			}
 		} else if strings.Contains(commentInfo, "Tenats:") {
 			inTenats = true
 		} else if inTenats && strings.Contains(commentInfo, " - ") && strings.HasPrefix(line, "#") {
@@ -113,8 +139,11 @@ func NewDomain(name, fileInfo string) (*Domain, error) {
 		} else if strings.Contains(line, "-") && strings.Contains(line, ".") && strings.Contains(line, "#") {
 			inTenats = false
 
-			hostId := splitComment[0]
 			splitHost := strings.Fields(hostInfo)
+			if len(splitComment) == 0 || len(splitHost) < 2 {
+				return nil, NewServerSideError("invalid host line is in hosts file info for " + name + ". line: " + line)
+			}
+			hostId := splitComment[0]
 			address := splitHost[0]
 			hostName := splitHost[1]
 
@@ -128,6 +157,11 @@ func NewDomain(name, fileInfo string) (*Domain, error) {
 				return nil, err
 			}
 
+			err = host.setLeaseInfo(splitComment[1:])
+			if err != nil {
+				return nil, err
+			}
+
 			hosts = append(hosts, host)
 		}
 	}
@@ -138,12 +172,37 @@ func NewDomain(name, fileInfo string) (*Domain, error) {
 
 	domain.Hosts = hosts
 	domain.Tenants = tenants
+	domain.Revision = revision
+	domain.TxtRecords = txtRecords
 	return domain, nil
 }
 
+// Clone returns a deep copy of the domain.
+func (d *Domain) Clone() *Domain {
+	domain := *d
+	domain.Tenants = append([]Uuid(nil), d.Tenants...)
+
+	var hosts []*Host
+	for _, h := range d.Hosts {
+		host := *h
+		hosts = append(hosts, &host)
+	}
+	domain.Hosts = hosts
+
+	var txtRecords []*TxtRecord
+	for _, r := range d.TxtRecords {
+		record := *r
+		txtRecords = append(txtRecords, &record)
+	}
+	domain.TxtRecords = txtRecords
+
+	return &domain
+}
+
 func (d *Domain) GetFileInfo() (string, error) {
 	fileInfo := `# DomainUUID: {{ .Uuid }}
-`
+{{ if .Revision }}# Revision: {{ .Revision }}
+{{ end }}`
 	tmpl := template.Must(template.New("").Parse(fileInfo))
 
 	var out bytes.Buffer
@@ -161,6 +220,10 @@ func (d *Domain) GetFileInfo() (string, error) {
 `
 	}
 
+	for _, r := range d.TxtRecords {
+		result += r.GetTxtRecordInfo()
+	}
+
 	for _, h := range d.Hosts {
 		i, err := h.GetHostInfo()
 		if err != nil {
@@ -172,6 +235,25 @@ func (d *Domain) GetFileInfo() (string, error) {
 	return result, nil
 }
 
+func (d *Domain) HasTenant(requestTenantUuid Uuid) bool {
+	for _, t := range d.Tenants {
+		if t == requestTenantUuid {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchRevision tells whether the domain is at any of the revisions.
+func (d *Domain) MatchRevision(revisions []uint64) bool {
+	for _, r := range revisions {
+		if r == d.Revision {
+			return true
+		}
+	}
+	return false
+}
+
 func (d *Domain) UpdateTenants(requestTenantUuid Uuid, tenantUuidList []Uuid) error {
 	accessible := false
 	for _, t := range d.Tenants {
@@ -64,3 +64,62 @@ func TestNewOriginalDomain(t *testing.T) {
 		t.Error("domainFileInfo is missmatched")
 	}
 }
+
+func TestCloneDomain(t *testing.T) {
+	name := "hogehoge.hoge"
+	domainFileInfo := `# DomainUUID: 3e8fc6b1-0a93-4c57-9f2b-95d3e66a66e0
+# Tenats:
+#   - df397e50-8006-450e-b18b-5c5bd940baff
+172.21.1.1  hogeserver1.hogehoge.hoge  # 5b9ea8eb-5ce5-422a-9d70-37d25fa896ae
+`
+	domain, err := NewDomain(name, domainFileInfo)
+	if err != nil {
+		t.Error(err)
+	}
+
+	cloned := domain.Clone()
+	cloned.Hosts[0].Address = "172.21.1.2"
+	cloned.Tenants[0] = "02c03bd4-fe2e-45f2-85b6-b535af15215d"
+
+	info, err := domain.GetFileInfo()
+	if err != nil {
+		t.Error(err)
+	}
+	if info != domainFileInfo {
+		t.Error("original domain is changed by its clone")
+	}
+}
+
+func TestDomainRevision(t *testing.T) {
+	domainFileInfo := `# DomainUUID: 3e8fc6b1-0a93-4c57-9f2b-95d3e66a66e0
+# Revision: 3
+# Tenats:
+#   - df397e50-8006-450e-b18b-5c5bd940baff
+172.21.1.1  hogeserver1.hogehoge.hoge  # 5b9ea8eb-5ce5-422a-9d70-37d25fa896ae
+`
+	domain, err := NewDomain("hogehoge.hoge", domainFileInfo)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if domain.Revision != 3 {
+		t.Errorf("revision is not read from the header: %d", domain.Revision)
+	}
+
+	info, err := domain.GetFileInfo()
+	if err != nil {
+		t.Error(err)
+	}
+	if info != domainFileInfo {
+		t.Error("revision is not written to the header: " + info)
+	}
+
+	if !domain.MatchRevision([]uint64{2, 3}) || domain.MatchRevision([]uint64{2}) || domain.MatchRevision(nil) {
+		t.Error("revision is missmatched")
+	}
+
+	_, err = NewDomain("hogehoge.hoge", strings.Replace(domainFileInfo, "# Revision: 3", "# Revision: three", 1))
+	if err == nil {
+		t.Error("invalid revision is accepted")
+	}
+}
@@ -1,5 +1,7 @@
 package model
 
+import "strconv"
+
 type InvalidParameterGiven struct {
 	err string
 }
@@ -59,3 +61,135 @@ func NewDomainPermissionError() error {
 func (e *DomainPermissionError) Error() string {
 	return e.err
 }
+
+type InvalidCorefileError struct {
+	err string
+}
+
+func NewInvalidCorefileError(text string) error {
+	return &InvalidCorefileError{err: "generated Corefile is invalid. " + text}
+}
+
+func (e *InvalidCorefileError) Error() string {
+	return e.err
+}
+
+type ZoneTransferError struct {
+	err string
+}
+
+func NewZoneTransferError(server, text string) error {
+	return &ZoneTransferError{err: "zone transfer from " + server + " is failed. " + text}
+}
+
+func (e *ZoneTransferError) Error() string {
+	return e.err
+}
+
+type PoolNotFoundError struct {
+	err string
+}
+
+func NewPoolNotFoundError() error {
+	return &PoolNotFoundError{err: "target pool is not found"}
+}
+
+func (e *PoolNotFoundError) Error() string {
+	return e.err
+}
+
+type PoolPermissionError struct {
+	err string
+}
+
+func NewPoolPermissionError() error {
+	return &PoolPermissionError{err: "specified tenant does not have permission to the pool"}
+}
+
+func (e *PoolPermissionError) Error() string {
+	return e.err
+}
+
+type PoolExhaustedError struct {
+	err string
+}
+
+func NewPoolExhaustedError(name string) error {
+	return &PoolExhaustedError{err: "no free address is left in the pool. pool: " + name}
+}
+
+func (e *PoolExhaustedError) Error() string {
+	return e.err
+}
+
+type DomainDegradedError struct {
+	err string
+}
+
+func NewDomainDegradedError(name DomainName, reason string) error {
+	return &DomainDegradedError{err: "target domain is degraded and read-only. domain: " + name.String() + ", error: " + reason}
+}
+
+func (e *DomainDegradedError) Error() string {
+	return e.err
+}
+
+type DomainRevisionMismatchError struct {
+	err string
+}
+
+func NewDomainRevisionMismatchError(name DomainName, revision uint64) error {
+	return &DomainRevisionMismatchError{err: "target domain is changed by another request. domain: " + name.String() + ", current revision: " + strconv.FormatUint(revision, 10)}
+}
+
+func (e *DomainRevisionMismatchError) Error() string {
+	return e.err
+}
+
+type ConfLockedError struct {
+	err string
+}
+
+func NewConfLockedError(path string) error {
+	return &ConfLockedError{err: "hosts files are used by the API server or another command. lock: " + path}
+}
+
+func (e *ConfLockedError) Error() string {
+	return e.err
+}
+
+type IdempotencyKeysFullError struct {
+	err string
+}
+
+func NewIdempotencyKeysFullError(maxKeys int) error {
+	return &IdempotencyKeysFullError{err: "too many idempotency keys are kept. max keys: " + strconv.Itoa(maxKeys)}
+}
+
+func (e *IdempotencyKeysFullError) Error() string {
+	return e.err
+}
+
+type IdempotencyKeyConflictError struct {
+	err string
+}
+
+func NewIdempotencyKeyConflictError(text string) error {
+	return &IdempotencyKeyConflictError{err: text}
+}
+
+func (e *IdempotencyKeyConflictError) Error() string {
+	return e.err
+}
+
+type RRsetMismatchError struct {
+	err string
+}
+
+func NewRRsetMismatchError(name, recordType string) error {
+	return &RRsetMismatchError{err: "records are changed by another request. name: " + name + ", type: " + recordType}
+}
+
+func (e *RRsetMismatchError) Error() string {
+	return e.err
+}
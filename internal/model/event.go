@@ -0,0 +1,115 @@
+package model
+
+import (
+	"time"
+)
+
+type EventType string
+
+const (
+	EventDomainAdded    EventType = "DomainAdded"
+	EventDomainDeleted  EventType = "DomainDeleted"
+	EventTenantsChanged EventType = "TenantsChanged"
+	EventHostAdded      EventType = "HostAdded"
+	EventHostUpdated    EventType = "HostUpdated"
+	EventHostDeleted    EventType = "HostDeleted"
+)
+
+// Event is a change of a domain or a host.
+// Before is null for the added ones, and After is null for the deleted ones.
+type Event struct {
+	Uuid       Uuid        `json:"id"`
+	Type       EventType   `json:"type"`
+	Time       time.Time   `json:"time"`
+	DomainUuid Uuid        `json:"domain_uuid"`
+	Domain     DomainName  `json:"domain"`
+	Before     interface{} `json:"before"`
+	After      interface{} `json:"after"`
+
+	// tenants can receive the event.
+	// Both of the tenants before and after the change can receive TenantsChanged.
+	tenants []Uuid
+}
+
+// EventDomain is the domain in the events. Hosts are sent as their own events.
+type EventDomain struct {
+	Name    DomainName `json:"domain"`
+	Uuid    Uuid       `json:"uuid"`
+	Tenants []Uuid     `json:"tenants"`
+}
+
+type EventHost struct {
+	Name      string `json:"hostname"`
+	Address   string `json:"address"`
+	Uuid      Uuid   `json:"uuid"`
+	ExpiresAt string `json:"expires_at,omitempty"`
+	TtlLease  string `json:"ttl_lease,omitempty"`
+}
+
+func newEventDomain(domain *Domain) *EventDomain {
+	return &EventDomain{Name: domain.Name, Uuid: domain.Uuid, Tenants: append([]Uuid{}, domain.Tenants...)}
+}
+
+func newEventHost(host *Host) *EventHost {
+	result := &EventHost{Name: host.Name, Address: host.Address, Uuid: host.Uuid}
+	if !host.ExpiresAt.IsZero() {
+		result.ExpiresAt = host.ExpiresAt.Format(time.RFC3339)
+	}
+	if host.Lease != 0 {
+		result.TtlLease = host.Lease.String()
+	}
+	return result
+}
+
+func newEvent(eventType EventType, domain *Domain) *Event {
+	return &Event{
+		Uuid:       newRandomUuid(),
+		Type:       eventType,
+		Time:       time.Now().UTC(),
+		DomainUuid: domain.Uuid,
+		Domain:     domain.Name,
+		tenants:    append([]Uuid{}, domain.Tenants...),
+	}
+}
+
+// NewDomainEvent returns the event of the domain. before or after is nil for the added or deleted domain.
+func NewDomainEvent(eventType EventType, before, after *Domain) *Event {
+	var event *Event
+	if after != nil {
+		event = newEvent(eventType, after)
+		event.After = newEventDomain(after)
+	}
+	if before != nil {
+		if event == nil {
+			event = newEvent(eventType, before)
+		}
+		event.Before = newEventDomain(before)
+		for _, t := range before.Tenants {
+			if !event.IsVisibleTo(t) {
+				event.tenants = append(event.tenants, t)
+			}
+		}
+	}
+	return event
+}
+
+// NewHostEvent returns the event of the host in the domain. before or after is nil for the added or deleted host.
+func NewHostEvent(eventType EventType, domain *Domain, before, after *Host) *Event {
+	event := newEvent(eventType, domain)
+	if before != nil {
+		event.Before = newEventHost(before)
+	}
+	if after != nil {
+		event.After = newEventHost(after)
+	}
+	return event
+}
+
+func (e *Event) IsVisibleTo(tenant Uuid) bool {
+	for _, t := range e.tenants {
+		if t == tenant {
+			return true
+		}
+	}
+	return false
+}
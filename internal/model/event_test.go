@@ -0,0 +1,74 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestNewDomainEvent(t *testing.T) {
+	before, err := NewOriginalDomain("hogehoge.hoge", []string{"df397e50-8006-450e-b18b-5c5bd940baff"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	after := before.Clone()
+	after.Tenants = []Uuid{"02c03bd4-fe2e-45f2-85b6-b535af15215d"}
+
+	event := NewDomainEvent(EventTenantsChanged, before, after)
+	if event.DomainUuid != before.Uuid || event.Domain != before.Name {
+		t.Error("domain of the event is missmatched")
+	}
+	if event.Before.(*EventDomain).Tenants[0] != "df397e50-8006-450e-b18b-5c5bd940baff" {
+		t.Error("tenants before the change are missmatched")
+	}
+	if event.After.(*EventDomain).Tenants[0] != "02c03bd4-fe2e-45f2-85b6-b535af15215d" {
+		t.Error("tenants after the change are missmatched")
+	}
+
+	if !event.IsVisibleTo("df397e50-8006-450e-b18b-5c5bd940baff") || !event.IsVisibleTo("02c03bd4-fe2e-45f2-85b6-b535af15215d") {
+		t.Error("event is not visible to the tenants before and after the change")
+	}
+	if event.IsVisibleTo("3e8fc6b1-0a93-4c57-9f2b-95d3e66a66e0") {
+		t.Error("event is visible to another tenant")
+	}
+
+	deleted := NewDomainEvent(EventDomainDeleted, before, nil)
+	if deleted.After != nil || deleted.Before == nil {
+		t.Error("deleted domain has after")
+	}
+	if !deleted.IsVisibleTo("df397e50-8006-450e-b18b-5c5bd940baff") {
+		t.Error("deleted domain is not visible to its tenant")
+	}
+}
+
+func TestNewHostEvent(t *testing.T) {
+	domain, err := NewOriginalDomain("hogehoge.hoge", []string{"df397e50-8006-450e-b18b-5c5bd940baff"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	before, err := NewOriginalHost("hogeserver1", "172.21.1.1", domain.Name)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	after := *before
+	after.Address = "172.21.1.2"
+
+	event := NewHostEvent(EventHostUpdated, domain, before, &after)
+	if event.Before.(*EventHost).Address != "172.21.1.1" || event.After.(*EventHost).Address != "172.21.1.2" {
+		t.Error("addresses of the event are missmatched")
+	}
+	if event.Before.(*EventHost).Uuid != event.After.(*EventHost).Uuid {
+		t.Error("uuid of the host is missmatched")
+	}
+
+	added := NewHostEvent(EventHostAdded, domain, nil, before)
+	if added.Before != nil {
+		t.Error("added host has before")
+	}
+	if !added.IsVisibleTo("df397e50-8006-450e-b18b-5c5bd940baff") || added.IsVisibleTo("02c03bd4-fe2e-45f2-85b6-b535af15215d") {
+		t.Error("visibility of the host event is missmatched")
+	}
+}
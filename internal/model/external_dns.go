@@ -0,0 +1,13 @@
+package model
+
+import "os"
+
+// GetExternalDNSTenant returns the tenant whose domains are managed by external-dns.
+func GetExternalDNSTenant() (Uuid, error) {
+	tenant := os.Getenv("EXTERNAL_DNS_TENANT")
+	if tenant == "" {
+		return "", NewInvalidParameterGiven("EXTERNAL_DNS_TENANT is not specified")
+	}
+
+	return NewUuid(tenant)
+}
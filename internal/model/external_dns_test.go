@@ -0,0 +1,32 @@
+package model
+
+import (
+	"os"
+	"testing"
+)
+
+func TestGetExternalDNSTenant(t *testing.T) {
+	defer os.Unsetenv("EXTERNAL_DNS_TENANT")
+
+	os.Setenv("EXTERNAL_DNS_TENANT", "df397e50-8006-450e-b18b-5c5bd940baff")
+	tenant, err := GetExternalDNSTenant()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if tenant != "df397e50-8006-450e-b18b-5c5bd940baff" {
+		t.Error("tenant is missmatched")
+	}
+
+	os.Setenv("EXTERNAL_DNS_TENANT", "df397e50-8006-450e-b18b-5c5bd940baff-hoge")
+	_, err = GetExternalDNSTenant()
+	if err == nil {
+		t.Error("invalid tenant is accepted")
+	}
+
+	os.Unsetenv("EXTERNAL_DNS_TENANT")
+	_, err = GetExternalDNSTenant()
+	if err == nil {
+		t.Error("empty tenant is accepted")
+	}
+}
@@ -0,0 +1,82 @@
+package model
+
+import (
+	"net"
+	"os"
+)
+
+// The canary host is written by the API to the probe domain,
+// and the readiness check asks CoreDNS for it to see the written hosts are served.
+// The address is in TEST-NET-1, so that it never conflicts with a real host.
+const (
+	CanaryHostname = "coredns-api-canary"
+	CanaryAddress  = "192.0.2.254"
+)
+
+// ReadinessProbe is the CoreDNS instance like "127.0.0.1:53" asked for the canary host in the domain.
+type ReadinessProbe struct {
+	Server string
+	Domain DomainName
+}
+
+// GetReadinessProbe returns the probe from READINESS_PROBE_SERVER and READINESS_PROBE_DOMAIN.
+// It is nil when no server is specified, and CoreDNS isn't checked then.
+func GetReadinessProbe() (*ReadinessProbe, error) {
+	server := os.Getenv("READINESS_PROBE_SERVER")
+	if server == "" {
+		return nil, nil
+	}
+
+	_, _, err := net.SplitHostPort(server)
+	if err != nil {
+		return nil, NewInvalidParameterGiven("invalid READINESS_PROBE_SERVER is specified. server: " + server)
+	}
+
+	domainConf := os.Getenv("READINESS_PROBE_DOMAIN")
+	if domainConf == "" {
+		return nil, NewInvalidParameterGiven("READINESS_PROBE_DOMAIN is not specified")
+	}
+
+	domain, err := NewDomainName(domainConf)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReadinessProbe{Server: server, Domain: domain}, nil
+}
+
+func (p *ReadinessProbe) CanaryFqdn() string {
+	return GetFQDN(CanaryHostname, p.Domain.String())
+}
+
+// ReadinessCheck is the result of a dependency. Error is empty when it is ready.
+type ReadinessCheck struct {
+	Name  string `json:"name"`
+	Error string `json:"error,omitempty"`
+}
+
+// Readiness has the results of the dependencies in the order they are checked.
+type Readiness struct {
+	Checks []*ReadinessCheck
+}
+
+func NewReadiness() *Readiness {
+	return &Readiness{Checks: []*ReadinessCheck{}}
+}
+
+func (r *Readiness) Add(name string, err error) {
+	check := &ReadinessCheck{Name: name}
+	if err != nil {
+		check.Error = err.Error()
+	}
+	r.Checks = append(r.Checks, check)
+}
+
+func (r *Readiness) IsReady() bool {
+	for _, c := range r.Checks {
+		if c.Error != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestGetReadinessProbe(t *testing.T) {
+	defer os.Unsetenv("READINESS_PROBE_SERVER")
+	defer os.Unsetenv("READINESS_PROBE_DOMAIN")
+
+	probe, err := GetReadinessProbe()
+	if err != nil || probe != nil {
+		t.Error("probe is given without the server")
+	}
+
+	os.Setenv("READINESS_PROBE_SERVER", "127.0.0.1:53")
+	os.Setenv("READINESS_PROBE_DOMAIN", "hogehoge.hoge")
+	probe, err = GetReadinessProbe()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if probe.Server != "127.0.0.1:53" || probe.Domain != "hogehoge.hoge" {
+		t.Error("probe is missmatched")
+	}
+	if probe.CanaryFqdn() != "coredns-api-canary.hogehoge.hoge" {
+		t.Error("canary is missmatched: " + probe.CanaryFqdn())
+	}
+
+	os.Setenv("READINESS_PROBE_SERVER", "127.0.0.1")
+	_, err = GetReadinessProbe()
+	if err == nil {
+		t.Error("server without port is accepted")
+	}
+
+	os.Setenv("READINESS_PROBE_SERVER", "127.0.0.1:53")
+	os.Unsetenv("READINESS_PROBE_DOMAIN")
+	_, err = GetReadinessProbe()
+	if err == nil {
+		t.Error("empty domain is accepted")
+	}
+}
+
+func TestReadiness(t *testing.T) {
+	readiness := NewReadiness()
+	readiness.Add("hosts_dir", nil)
+	if !readiness.IsReady() {
+		t.Error("readiness is not ready without errors")
+	}
+
+	readiness.Add("cache", errors.New("not initialized"))
+	if readiness.IsReady() {
+		t.Error("readiness is ready with an error")
+	}
+	if len(readiness.Checks) != 2 || readiness.Checks[1].Error != "not initialized" {
+		t.Error("checks are missmatched")
+	}
+}
@@ -2,17 +2,39 @@ package model
 
 import (
 	"bytes"
+	"net"
+	"os"
 	"regexp"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+// GetHostReapInterval returns the interval to remove expired hosts.
+// Zero means expired hosts are not removed.
+func GetHostReapInterval() (time.Duration, error) {
+	hostReapIntervalConf := os.Getenv("HOST_REAP_INTERVAL")
+	if hostReapIntervalConf == "" {
+		return 30 * time.Second, nil
+	}
+
+	interval, err := time.ParseDuration(hostReapIntervalConf)
+	if err != nil || interval < 0 {
+		return 0, NewInvalidParameterGiven("invalid HOST_REAP_INTERVAL is specified. interval: " + hostReapIntervalConf)
+	}
+	return interval, nil
+}
+
 type Host struct {
 	Uuid    Uuid
 	Name    string
 	Address string
+	// ExpiresAt is the time the host is removed at. Zero means the host never expires.
+	ExpiresAt time.Time
+	// Lease is the duration the expiry is extended by when the host is renewed.
+	Lease time.Duration
 }
 
 func GetFQDN(hostname, domain string) string {
@@ -45,17 +67,90 @@ func NewHost(uuid Uuid, hostFqdn, address string) (*Host, error) {
 		return nil, NewInvalidParameterGiven(mes)
 	}
 
-	ipMatcher := regexp.MustCompile("^[0-9.]+$").MatchString
-	if len(address) < 7 || len(address) > 15 || strings.Count(address, ".") != 3 || !ipMatcher(address) {
+	// Both of IPv4 (A record) and IPv6 (AAAA record) addresses are available in hosts file.
+	ip := net.ParseIP(address)
+	if ip == nil {
 		mes := "invalid IP address is specified with hostFqdn: '" + hostFqdn + "', address: '" + address + "'"
 		return nil, NewInvalidParameterGiven(mes)
 	}
 
-	return &Host{uuid, hostFqdn, address}, nil
+	return &Host{Uuid: uuid, Name: hostFqdn, Address: ip.String()}, nil
+}
+
+// SetLease makes the host expire.
+// When expiresAt is zero, the host expires when the lease passes from now.
+func (h *Host) SetLease(expiresAt time.Time, lease time.Duration, now time.Time) error {
+	if lease < 0 {
+		return NewInvalidParameterGiven("negative lease is specified to host: " + h.Name)
+	}
+
+	if expiresAt.IsZero() {
+		expiresAt = now.Add(lease)
+	}
+	if !expiresAt.After(now) {
+		return NewInvalidParameterGiven("expiry in the past is specified to host: " + h.Name)
+	}
+
+	// The expiry is kept in seconds like in the hosts file.
+	h.ExpiresAt = expiresAt.UTC().Truncate(time.Second)
+	h.Lease = lease
+	return nil
+}
+
+// Renew extends the expiry of the host by its lease from now.
+func (h *Host) Renew(now time.Time) error {
+	if h.Lease == 0 {
+		return NewInvalidParameterGiven("host does not have lease to renew: " + h.Name)
+	}
+
+	h.ExpiresAt = now.Add(h.Lease).UTC().Truncate(time.Second)
+	return nil
+}
+
+func (h *Host) IsExpired(now time.Time) bool {
+	return !h.ExpiresAt.IsZero() && !now.Before(h.ExpiresAt)
+}
+
+// GetLeaseInfo returns the lease written after the host UUID in the hosts file.
+func (h *Host) GetLeaseInfo() string {
+	var info string
+	if !h.ExpiresAt.IsZero() {
+		info += " expires_at=" + h.ExpiresAt.Format(time.RFC3339)
+	}
+	if h.Lease != 0 {
+		info += " ttl_lease=" + h.Lease.String()
+	}
+	return info
+}
+
+// setLeaseInfo sets the lease from "key=value" fields written after the host UUID.
+func (h *Host) setLeaseInfo(fields []string) error {
+	for _, f := range fields {
+		keyValue := strings.SplitN(f, "=", 2)
+		if len(keyValue) != 2 {
+			continue
+		}
+
+		switch keyValue[0] {
+		case "expires_at":
+			expiresAt, err := time.Parse(time.RFC3339, keyValue[1])
+			if err != nil {
+				return NewServerSideError("invalid expires_at of host: " + h.Name)
+			}
+			h.ExpiresAt = expiresAt.UTC()
+		case "ttl_lease":
+			lease, err := time.ParseDuration(keyValue[1])
+			if err != nil {
+				return NewServerSideError("invalid ttl_lease of host: " + h.Name)
+			}
+			h.Lease = lease
+		}
+	}
+	return nil
 }
 
 func (h *Host) GetHostInfo() (string, error) {
-	hostInfo := `{{ .Address }}  {{ .Name }}  # {{ .Uuid }}
+	hostInfo := `{{ .Address }}  {{ .Name }}  # {{ .Uuid }}{{ .GetLeaseInfo }}
 `
 	tmpl := template.Must(template.New("").Parse(hostInfo))
 
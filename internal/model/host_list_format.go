@@ -0,0 +1,183 @@
+package model
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// Formats of a host list to import and export.
+const (
+	HostListFormatCSV   = "csv"
+	HostListFormatJSON  = "json"
+	HostListFormatHosts = "hosts"
+)
+
+// HostLine is a host record read from a host list with its line number.
+// It is not validated yet.
+type HostLine struct {
+	Line    int
+	Name    string
+	Address string
+	Err     error
+}
+
+type hostListEntry struct {
+	Name    string `json:"hostname"`
+	Address string `json:"address"`
+	Uuid    string `json:"uuid,omitempty"`
+}
+
+// ParseHostList reads host records from CSV, JSON array or hosts file syntax.
+// A line which can not be read is returned with Err.
+func ParseHostList(format, data string) ([]*HostLine, error) {
+	switch format {
+	case HostListFormatCSV:
+		return parseHostListCSV(data)
+	case HostListFormatJSON:
+		return parseHostListJSON(data)
+	case HostListFormatHosts:
+		return parseHostListHosts(data), nil
+	default:
+		return nil, NewInvalidParameterGiven("invalid host list format is specified. format: " + format)
+	}
+}
+
+// CSV is expected as `hostname,address` with optional header.
+// The whole data is read with one reader, so that quoted fields can have newlines and lines can end with CRLF.
+// The line number is the one where the record starts.
+func parseHostListCSV(data string) ([]*HostLine, error) {
+	reader := csv.NewReader(strings.NewReader(data))
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	var lines []*HostLine
+	first := true
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			parseErr, ok := err.(*csv.ParseError)
+			if !ok {
+				return nil, err
+			}
+			lines = append(lines, &HostLine{Line: parseErr.StartLine, Err: NewInvalidParameterGiven(err.Error())})
+			first = false
+			continue
+		}
+
+		line, _ := reader.FieldPos(0)
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+
+		header := first && strings.EqualFold(strings.TrimSpace(record[0]), "hostname")
+		first = false
+		if header {
+			continue
+		}
+
+		if len(record) < 2 {
+			lines = append(lines, &HostLine{Line: line, Err: NewInvalidParameterGiven("hostname and address are required")})
+			continue
+		}
+
+		lines = append(lines, &HostLine{
+			Line:    line,
+			Name:    strings.TrimSpace(record[0]),
+			Address: strings.TrimSpace(record[1])})
+	}
+
+	return lines, nil
+}
+
+// JSON is expected as `[{"hostname": "...", "address": "..."}]`.
+// The index of the array starting from 1 is used as line number.
+func parseHostListJSON(data string) ([]*HostLine, error) {
+	var entries []json.RawMessage
+	err := json.Unmarshal([]byte(data), &entries)
+	if err != nil {
+		return nil, NewInvalidParameterGiven("invalid JSON host list is given. " + err.Error())
+	}
+
+	var lines []*HostLine
+	for i, raw := range entries {
+		var entry hostListEntry
+		err := json.Unmarshal(raw, &entry)
+		if err != nil {
+			lines = append(lines, &HostLine{Line: i + 1, Err: NewInvalidParameterGiven(err.Error())})
+			continue
+		}
+		lines = append(lines, &HostLine{Line: i + 1, Name: entry.Name, Address: entry.Address})
+	}
+
+	return lines, nil
+}
+
+// Hosts file syntax is expected as `address hostname  # comment`.
+func parseHostListHosts(data string) []*HostLine {
+	var lines []*HostLine
+	for i, text := range strings.Split(data, "\n") {
+		hostInfo := strings.Split(text, "#")[0]
+		fields := strings.Fields(hostInfo)
+		if len(fields) == 0 {
+			continue
+		}
+
+		line := &HostLine{Line: i + 1, Address: fields[0]}
+		switch {
+		case len(fields) == 1:
+			line.Err = NewInvalidParameterGiven("hostname is not specified")
+		case len(fields) > 2:
+			line.Err = NewInvalidParameterGiven("multiple hostnames in a line is not supported")
+		default:
+			line.Name = fields[1]
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// FormatHostList writes hosts in the format which ParseHostList reads.
+func FormatHostList(format string, hosts []*Host) (string, error) {
+	switch format {
+	case HostListFormatCSV:
+		var out bytes.Buffer
+		writer := csv.NewWriter(&out)
+		records := [][]string{{"hostname", "address", "uuid"}}
+		for _, h := range hosts {
+			records = append(records, []string{h.Name, h.Address, h.Uuid.String()})
+		}
+		err := writer.WriteAll(records)
+		if err != nil {
+			return "", err
+		}
+		return out.String(), nil
+
+	case HostListFormatJSON:
+		entries := make([]hostListEntry, 0)
+		for _, h := range hosts {
+			entries = append(entries, hostListEntry{Name: h.Name, Address: h.Address, Uuid: h.Uuid.String()})
+		}
+		data, err := json.MarshalIndent(entries, "", "    ")
+		if err != nil {
+			return "", err
+		}
+		return string(data) + "\n", nil
+
+	case HostListFormatHosts:
+		result := ""
+		for _, h := range hosts {
+			result += h.Address + "  " + h.Name + "\n"
+		}
+		return result, nil
+
+	default:
+		return "", NewInvalidParameterGiven("invalid host list format is specified. format: " + format)
+	}
+}
@@ -0,0 +1,116 @@
+package model
+
+import "testing"
+
+func TestParseHostList(t *testing.T) {
+	hostListInfo := map[string]string{
+		HostListFormatCSV: `hostname,address
+hogeserver1,172.21.1.1
+hogeserver2.hogehoge.hoge, 172.21.1.2
+`,
+		HostListFormatJSON: `[{"hostname": "hogeserver1", "address": "172.21.1.1"},
+ {"hostname": "hogeserver2.hogehoge.hoge", "address": "172.21.1.2"}]`,
+		HostListFormatHosts: `# comment
+172.21.1.1  hogeserver1
+172.21.1.2  hogeserver2.hogehoge.hoge  # 5b9ea8eb-5ce5-422a-9d70-37d25fa896ae
+`,
+	}
+
+	for format, info := range hostListInfo {
+		lines, err := ParseHostList(format, info)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if len(lines) != 2 {
+			t.Error(format + " host list is not parsed")
+			continue
+		}
+		if lines[0].Name != "hogeserver1" || lines[0].Address != "172.21.1.1" || lines[0].Err != nil {
+			t.Error(format + " host list is missmatched")
+		}
+		if lines[1].Name != "hogeserver2.hogehoge.hoge" || lines[1].Address != "172.21.1.2" || lines[1].Err != nil {
+			t.Error(format + " host list is missmatched")
+		}
+	}
+}
+
+func TestParseHostListInvalidLine(t *testing.T) {
+	info := `172.21.1.1  hogeserver1
+172.21.1.2
+172.21.1.3  hogeserver3 hogeserver3-alias
+`
+	lines, err := ParseHostList(HostListFormatHosts, info)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if len(lines) != 3 || lines[0].Err != nil || lines[1].Err == nil || lines[2].Err == nil {
+		t.Error("invalid lines are not reported")
+	}
+	if lines[1].Line != 2 || lines[2].Line != 3 {
+		t.Error("line number is missmatched")
+	}
+}
+
+func TestFormatHostList(t *testing.T) {
+	host, err := NewHost("5b9ea8eb-5ce5-422a-9d70-37d25fa896ae", "hogeserver1.hogehoge.hoge", "172.21.1.1")
+	if err != nil {
+		t.Error(err)
+	}
+
+	for format := range map[string]bool{HostListFormatCSV: true, HostListFormatJSON: true, HostListFormatHosts: true} {
+		info, err := FormatHostList(format, []*Host{host})
+		if err != nil {
+			t.Error(err)
+		}
+
+		lines, err := ParseHostList(format, info)
+		if err != nil {
+			t.Error(err)
+		}
+		if len(lines) != 1 || lines[0].Name != host.Name || lines[0].Address != host.Address {
+			t.Error(format + " host list is not able to be imported again")
+		}
+	}
+}
+
+func TestParseHostListCSV(t *testing.T) {
+	// Lines end with CRLF, and a quoted field has a newline,
+	// so the records after it start on the later lines.
+	info := "hostname,address\r\n" +
+		"hogeserver1,172.21.1.1\r\n" +
+		"\"hoge\nserver2\",172.21.1.2\r\n" +
+		"hogeserver3, \"172.21.1.3\"\r\n" +
+		"\r\n" +
+		"hogeserver4\r\n" +
+		"hoge\"server5,172.21.1.5\r\n" +
+		"hogeserver6,172.21.1.6\r\n"
+	lines, err := ParseHostList(HostListFormatCSV, info)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	expects := []HostLine{
+		{Line: 2, Name: "hogeserver1", Address: "172.21.1.1"},
+		{Line: 3, Name: "hoge\nserver2", Address: "172.21.1.2"},
+		{Line: 5, Name: "hogeserver3", Address: "172.21.1.3"},
+		{Line: 7},
+		{Line: 8},
+		{Line: 9, Name: "hogeserver6", Address: "172.21.1.6"},
+	}
+	if len(lines) != len(expects) {
+		t.Errorf("csv host list is not parsed: %d", len(lines))
+		return
+	}
+	for i, expect := range expects {
+		line := lines[i]
+		if line.Line != expect.Line || line.Name != expect.Name || line.Address != expect.Address {
+			t.Errorf("csv host line is missmatched: %d %q %q", line.Line, line.Name, line.Address)
+		}
+		if (line.Err != nil) != (expect.Name == "") {
+			t.Errorf("error of csv host line is missmatched: %d %v", line.Line, line.Err)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package model
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestGetFQDN(t *testing.T) {
 	targetHostname := "hogeserver1"
@@ -10,3 +13,114 @@ func TestGetFQDN(t *testing.T) {
 		t.Error("FQDN is missmatched")
 	}
 }
+
+func TestNewHostAddress(t *testing.T) {
+	validAddressList := map[string]string{
+		"172.21.1.1":     "172.21.1.1",
+		"fd00::1":        "fd00::1",
+		"FD00:0:0:0::01": "fd00::1",
+	}
+	for address, expect := range validAddressList {
+		host, err := NewHost("5b9ea8eb-5ce5-422a-9d70-37d25fa896ae", "hogeserver1.hogehoge.hoge", address)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if host.Address != expect {
+			t.Error("address is missmatched: " + host.Address)
+		}
+	}
+
+	invalidAddressList := []string{"", "172.21.1", "172.21.1.256", "fd00::1::1", "hogehoge"}
+	for _, address := range invalidAddressList {
+		_, err := NewHost("5b9ea8eb-5ce5-422a-9d70-37d25fa896ae", "hogeserver1.hogehoge.hoge", address)
+		if err == nil {
+			t.Error("invalid address is accepted: " + address)
+		}
+	}
+}
+
+func TestHostLease(t *testing.T) {
+	host, err := NewHost("5b9ea8eb-5ce5-422a-9d70-37d25fa896ae", "hogeserver1.hogehoge.hoge", "172.21.1.1")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	now := time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC)
+	if host.IsExpired(now) {
+		t.Error("host without lease is expired")
+	}
+	if host.Renew(now) == nil {
+		t.Error("host without lease is renewed")
+	}
+
+	err = host.SetLease(time.Time{}, time.Hour, now)
+	if err != nil {
+		t.Error(err)
+	}
+	if host.IsExpired(now.Add(59*time.Minute)) || !host.IsExpired(now.Add(time.Hour)) {
+		t.Error("expiry is not set with the lease")
+	}
+
+	err = host.Renew(now.Add(30 * time.Minute))
+	if err != nil {
+		t.Error(err)
+	}
+	if host.IsExpired(now.Add(time.Hour)) {
+		t.Error("expiry is not extended")
+	}
+
+	if host.SetLease(now.Add(-time.Second), 0, now) == nil {
+		t.Error("expiry in the past is accepted")
+	}
+}
+
+func TestHostLeaseFileInfo(t *testing.T) {
+	domainFileInfo := `# DomainUUID: 3e8fc6b1-0a93-4c57-9f2b-95d3e66a66e0
+# Tenats:
+#   - df397e50-8006-450e-b18b-5c5bd940baff
+172.21.1.1  hogeserver1.hogehoge.hoge  # 5b9ea8eb-5ce5-422a-9d70-37d25fa896ae expires_at=2021-04-01T00:00:00Z ttl_lease=1h0m0s
+172.21.1.2  hogeserver2.hogehoge.hoge  # f0c5edcd-3b18-4c26-a8e1-3f3495504dd6
+`
+	domain, err := NewDomain("hogehoge.hoge", domainFileInfo)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	leased := domain.Hosts[0]
+	if !leased.ExpiresAt.Equal(time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC)) || leased.Lease != time.Hour {
+		t.Error("lease is not loaded")
+	}
+	if !domain.Hosts[1].ExpiresAt.IsZero() {
+		t.Error("host without lease has expiry")
+	}
+
+	info, err := domain.GetFileInfo()
+	if err != nil {
+		t.Error(err)
+	}
+	if info != domainFileInfo {
+		t.Error(info)
+	}
+}
+
+func TestGetHostReapInterval(t *testing.T) {
+	expects := map[string]time.Duration{"": 30 * time.Second, "1m": time.Minute, "0": 0}
+	for conf, expect := range expects {
+		t.Setenv("HOST_REAP_INTERVAL", conf)
+		interval, err := GetHostReapInterval()
+		if err != nil || interval != expect {
+			t.Errorf("reap interval is missmatched: %s %v", conf, interval)
+		}
+	}
+
+	for _, conf := range []string{"-1s", "hoge"} {
+		t.Setenv("HOST_REAP_INTERVAL", conf)
+		_, err := GetHostReapInterval()
+		if err == nil {
+			t.Error("invalid reap interval is accepted: " + conf)
+		}
+	}
+}
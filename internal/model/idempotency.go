@@ -0,0 +1,76 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"strconv"
+	"time"
+)
+
+const maxIdempotencyKeyLength = 255
+
+// GetIdempotencyWindow returns how long the responses of the requests with Idempotency-Key are kept.
+// Zero means Idempotency-Key is ignored.
+func GetIdempotencyWindow() (time.Duration, error) {
+	windowConf := os.Getenv("IDEMPOTENCY_WINDOW")
+	if windowConf == "" {
+		return 24 * time.Hour, nil
+	}
+
+	window, err := time.ParseDuration(windowConf)
+	if err != nil || window < 0 {
+		return 0, NewInvalidParameterGiven("invalid IDEMPOTENCY_WINDOW is specified. window: " + windowConf)
+	}
+	return window, nil
+}
+
+// GetIdempotencyMaxKeys returns how many Idempotency-Keys are kept at most.
+// The keys are kept in memory, so the requests with new keys are refused when they are full.
+func GetIdempotencyMaxKeys() (int, error) {
+	maxKeysConf := os.Getenv("IDEMPOTENCY_MAX_KEYS")
+	if maxKeysConf == "" {
+		return 10000, nil
+	}
+
+	maxKeys, err := strconv.Atoi(maxKeysConf)
+	if err != nil || maxKeys <= 0 {
+		return 0, NewInvalidParameterGiven("invalid IDEMPOTENCY_MAX_KEYS is specified. max keys: " + maxKeysConf)
+	}
+	return maxKeys, nil
+}
+
+// IdempotentRequest is a request with Idempotency-Key.
+// Keys are scoped by the tenant, and the request is identified by the hash of its method, path and body.
+// Response is nil while the request is handled.
+type IdempotentRequest struct {
+	Key         string
+	Tenant      string
+	RequestHash string
+	ExpiresAt   time.Time
+	Response    *IdempotentResponse
+}
+
+// IdempotentResponse is the response replayed for the retries of the request.
+type IdempotentResponse struct {
+	StatusCode  int
+	ContentType string
+	Headers     map[string]string
+	Body        []byte
+}
+
+func NewIdempotentRequest(key, tenant, request string, body []byte) (*IdempotentRequest, error) {
+	if len(key) > maxIdempotencyKeyLength {
+		return nil, NewInvalidParameterGiven("idempotency key is longer than " + strconv.Itoa(maxIdempotencyKeyLength) + " characters")
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(request))
+	hash.Write([]byte{0})
+	hash.Write(body)
+	return &IdempotentRequest{Key: key, Tenant: tenant, RequestHash: hex.EncodeToString(hash.Sum(nil))}, nil
+}
+
+func (r *IdempotentRequest) IsExpired(now time.Time) bool {
+	return !now.Before(r.ExpiresAt)
+}
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewIdempotentRequest(t *testing.T) {
+	request, err := NewIdempotentRequest("key1", "df397e50-8006-450e-b18b-5c5bd940baff", "POST /v1/domains", []byte(`{"domain":"hogehoge.hoge"}`))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	retried, _ := NewIdempotentRequest("key1", "df397e50-8006-450e-b18b-5c5bd940baff", "POST /v1/domains", []byte(`{"domain":"hogehoge.hoge"}`))
+	if request.RequestHash != retried.RequestHash {
+		t.Error("hash of the retried request is missmatched")
+	}
+
+	other, _ := NewIdempotentRequest("key1", "df397e50-8006-450e-b18b-5c5bd940baff", "POST /v1/domains", []byte(`{"domain":"fugafuga.fuga"}`))
+	if request.RequestHash == other.RequestHash {
+		t.Error("hash of the request with another body is same")
+	}
+	other, _ = NewIdempotentRequest("key1", "df397e50-8006-450e-b18b-5c5bd940baff", "POST /v1/pools", []byte(`{"domain":"hogehoge.hoge"}`))
+	if request.RequestHash == other.RequestHash {
+		t.Error("hash of the request to another path is same")
+	}
+
+	_, err = NewIdempotentRequest(strings.Repeat("k", 256), "df397e50-8006-450e-b18b-5c5bd940baff", "POST /v1/domains", nil)
+	if err == nil {
+		t.Error("too long key is accepted")
+	}
+
+	now := time.Now()
+	request.ExpiresAt = now.Add(time.Minute)
+	if request.IsExpired(now) || !request.IsExpired(now.Add(time.Minute)) {
+		t.Error("expiry is missmatched")
+	}
+}
+
+func TestGetIdempotencyWindow(t *testing.T) {
+	defer os.Unsetenv("IDEMPOTENCY_WINDOW")
+
+	expects := map[string]time.Duration{"": 24 * time.Hour, "10m": 10 * time.Minute, "0": 0}
+	for conf, expect := range expects {
+		os.Setenv("IDEMPOTENCY_WINDOW", conf)
+		window, err := GetIdempotencyWindow()
+		if err != nil || window != expect {
+			t.Errorf("window is missmatched: %s %v", conf, window)
+		}
+	}
+
+	for _, conf := range []string{"-1m", "hoge"} {
+		os.Setenv("IDEMPOTENCY_WINDOW", conf)
+		_, err := GetIdempotencyWindow()
+		if err == nil {
+			t.Error("invalid window is accepted: " + conf)
+		}
+	}
+}
+
+func TestGetIdempotencyMaxKeys(t *testing.T) {
+	defer os.Unsetenv("IDEMPOTENCY_MAX_KEYS")
+
+	expects := map[string]int{"": 10000, "100": 100}
+	for conf, expect := range expects {
+		os.Setenv("IDEMPOTENCY_MAX_KEYS", conf)
+		maxKeys, err := GetIdempotencyMaxKeys()
+		if err != nil || maxKeys != expect {
+			t.Errorf("max keys is missmatched: %s %v", conf, maxKeys)
+		}
+	}
+
+	for _, conf := range []string{"0", "-1", "hoge"} {
+		os.Setenv("IDEMPOTENCY_MAX_KEYS", conf)
+		_, err := GetIdempotencyMaxKeys()
+		if err == nil {
+			t.Error("invalid max keys is accepted: " + conf)
+		}
+	}
+}
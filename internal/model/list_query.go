@@ -0,0 +1,228 @@
+package model
+
+import (
+	"encoding/base64"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MaxListLimit is the max number of the items in a page.
+const MaxListLimit = 1000
+
+// GetAdminTenants returns the tenants which can list the domains of every tenant.
+// They are given as comma separated UUIDs with ADMIN_TENANTS.
+func GetAdminTenants() []Uuid {
+	var tenants []Uuid
+	for _, t := range strings.Split(os.Getenv("ADMIN_TENANTS"), ",") {
+		tenantUuid, err := NewUuid(strings.TrimSpace(t))
+		if err == nil {
+			tenants = append(tenants, tenantUuid)
+		}
+	}
+	return tenants
+}
+
+func IsAdminTenant(requestTenantUuid Uuid) bool {
+	for _, t := range GetAdminTenants() {
+		if t == requestTenantUuid {
+			return true
+		}
+	}
+	return false
+}
+
+// ListQuery is the filters and the page of a list request.
+//
+// Name matches the names which start with it,
+// or the names which match it as a glob pattern when it has any of "*?[".
+// Address matches the addresses in the CIDR, or the address itself.
+// Items are sorted by their keys, and the page starts after the item of the cursor.
+// Limit is zero when every item after the cursor is returned.
+type ListQuery struct {
+	Name    string
+	Address string
+	Tenant  Uuid
+	Limit   int
+	Cursor  string
+
+	network   *net.IPNet
+	cursorKey string
+}
+
+func NewListQuery(name, address, tenant, limit, cursor string) (*ListQuery, error) {
+	query := &ListQuery{Name: strings.ToLower(name), Address: address, Cursor: cursor}
+
+	if query.isGlob() {
+		_, err := path.Match(query.Name, "")
+		if err != nil {
+			return nil, NewInvalidParameterGiven("invalid name pattern is specified. name: " + name)
+		}
+	}
+
+	if address != "" {
+		cidr := address
+		if !strings.Contains(cidr, "/") {
+			ip := net.ParseIP(address)
+			if ip == nil {
+				return nil, NewInvalidParameterGiven("invalid address is specified. address: " + address)
+			}
+			if ip.To4() != nil {
+				cidr += "/32"
+			} else {
+				cidr += "/128"
+			}
+		}
+
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, NewInvalidParameterGiven("invalid address CIDR is specified. address: " + address)
+		}
+		query.network = network
+	}
+
+	if tenant != "" {
+		tenantUuid, err := NewUuid(tenant)
+		if err != nil {
+			return nil, err
+		}
+		query.Tenant = tenantUuid
+	}
+
+	if limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil || l <= 0 || l > MaxListLimit {
+			return nil, NewInvalidParameterGiven("limit has to be from 1 to " + strconv.Itoa(MaxListLimit) + ". limit: " + limit)
+		}
+		query.Limit = l
+	}
+
+	if cursor != "" {
+		key, err := base64.RawURLEncoding.DecodeString(cursor)
+		if err != nil || len(key) == 0 {
+			return nil, NewInvalidParameterGiven("invalid cursor is specified. cursor: " + cursor)
+		}
+		query.cursorKey = string(key)
+	}
+
+	return query, nil
+}
+
+func (q *ListQuery) isGlob() bool {
+	return strings.ContainsAny(q.Name, "*?[")
+}
+
+func (q *ListQuery) MatchName(name string) bool {
+	if q.Name == "" {
+		return true
+	}
+
+	name = strings.ToLower(name)
+	if q.isGlob() {
+		matched, _ := path.Match(q.Name, name)
+		return matched
+	}
+	return strings.HasPrefix(name, q.Name)
+}
+
+func (q *ListQuery) MatchAddress(address string) bool {
+	if q.network == nil {
+		return true
+	}
+
+	ip := net.ParseIP(address)
+	return ip != nil && q.network.Contains(ip)
+}
+
+func (q *ListQuery) MatchTenant(tenants []Uuid) bool {
+	if q.Tenant == "" {
+		return true
+	}
+
+	for _, t := range tenants {
+		if t == q.Tenant {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchDomain tells whether the domain matches the query.
+// The address matches the domains which have any host in it.
+func (q *ListQuery) MatchDomain(domain *Domain) bool {
+	if !q.MatchName(domain.Name.String()) || !q.MatchTenant(domain.Tenants) {
+		return false
+	}
+	if q.network == nil {
+		return true
+	}
+
+	for _, h := range domain.Hosts {
+		if q.MatchAddress(h.Address) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchDegradedDomain tells whether the degraded domain matches the query.
+// It never matches the address, since its hosts are not loaded.
+func (q *ListQuery) MatchDegradedDomain(domain *DegradedDomain) bool {
+	return q.network == nil && q.MatchName(domain.Name.String()) && q.MatchTenant(domain.Tenants)
+}
+
+func (q *ListQuery) MatchHost(host *Host) bool {
+	return q.MatchName(host.Name) && q.MatchAddress(host.Address)
+}
+
+// Page returns the range of the page in the sorted keys,
+// and the cursor of the next page. The cursor is empty on the last page.
+func (q *ListQuery) Page(keys []string) (int, int, string) {
+	start := 0
+	if q.cursorKey != "" {
+		start = sort.Search(len(keys), func(i int) bool { return keys[i] > q.cursorKey })
+	}
+
+	end := len(keys)
+	if q.Limit > 0 && start+q.Limit < end {
+		end = start + q.Limit
+	}
+
+	next := ""
+	if end < len(keys) {
+		next = base64.RawURLEncoding.EncodeToString([]byte(keys[end-1]))
+	}
+	return start, end, next
+}
+
+// NextLink returns the path of the next page with the same filters.
+func (q *ListQuery) NextLink(basePath, nextCursor string) string {
+	if nextCursor == "" {
+		return ""
+	}
+
+	values := url.Values{}
+	if q.Name != "" {
+		values.Set("name", q.Name)
+	}
+	if q.Address != "" {
+		values.Set("address", q.Address)
+	}
+	if q.Tenant != "" {
+		values.Set("tenant", q.Tenant.String())
+	}
+	if q.Limit > 0 {
+		values.Set("limit", strconv.Itoa(q.Limit))
+	}
+	values.Set("cursor", nextCursor)
+	return basePath + "?" + values.Encode()
+}
+
+// HostSortKey is the key to sort hosts by the name, and by the UUID for the same name.
+func HostSortKey(host *Host) string {
+	return host.Name + "\x00" + host.Uuid.String()
+}
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestListQueryMatch(t *testing.T) {
+	host, err := NewHost("5b9ea8eb-5ce5-422a-9d70-37d25fa896ae", "hogeserver1.hogehoge.hoge", "172.21.1.1")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	matched := map[[2]string]bool{
+		{"", ""}:                   true,
+		{"hogeserver", ""}:         true,
+		{"HogeServer", ""}:         true,
+		{"fugaserver", ""}:         false,
+		{"*1.hogehoge.hoge", ""}:   true,
+		{"hogeserver[23]*", ""}:    false,
+		{"", "172.21.1.0/24"}:      true,
+		{"", "172.21.2.0/24"}:      false,
+		{"", "172.21.1.1"}:         true,
+		{"hogeserver", "fd00::/8"}: false,
+	}
+	for params, expect := range matched {
+		query, err := NewListQuery(params[0], params[1], "", "", "")
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if query.MatchHost(host) != expect {
+			t.Error("host match is missmatched: " + params[0] + " " + params[1])
+		}
+	}
+
+	invalidParams := [][3]string{{"[", "", ""}, {"", "172.21.1", ""}, {"", "", "0"}, {"", "", "1001"}}
+	for _, params := range invalidParams {
+		_, err := NewListQuery(params[0], params[1], "", params[2], "")
+		if err == nil {
+			t.Error("invalid query is accepted: " + strings.Join(params[:], " "))
+		}
+	}
+}
+
+func TestListQueryPage(t *testing.T) {
+	keys := []string{"a", "b", "c", "d", "e"}
+
+	query, err := NewListQuery("", "", "", "2", "")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	var got []string
+	for {
+		start, end, next := query.Page(keys)
+		got = append(got, keys[start:end]...)
+		if next == "" {
+			break
+		}
+
+		link := query.NextLink("/v1/domains", next)
+		if !strings.HasPrefix(link, "/v1/domains?") || !strings.Contains(link, "limit=2") {
+			t.Error("next link is missmatched: " + link)
+		}
+
+		query, err = NewListQuery("", "", "", "2", next)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	if strings.Join(got, "") != "abcde" {
+		t.Error("pages are missmatched: " + strings.Join(got, ""))
+	}
+}
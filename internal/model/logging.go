@@ -0,0 +1,89 @@
+package model
+
+import (
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// GetLogLevel returns the lowest level of the logs written, from LOG_LEVEL.
+// It is one of debug, info, warn and error, and info by default.
+func GetLogLevel() (slog.Level, error) {
+	levelConf := os.Getenv("LOG_LEVEL")
+	if levelConf == "" {
+		return slog.LevelInfo, nil
+	}
+
+	var level slog.Level
+	err := level.UnmarshalText([]byte(levelConf))
+	if err != nil {
+		return 0, NewInvalidParameterGiven("invalid LOG_LEVEL is specified. level: " + levelConf)
+	}
+	return level, nil
+}
+
+// GetLogFormat returns the format of the logs from LOG_FORMAT, text by default or json.
+func GetLogFormat() (string, error) {
+	format := strings.ToLower(os.Getenv("LOG_FORMAT"))
+	switch format {
+	case "":
+		return "text", nil
+	case "text", "json":
+		return format, nil
+	}
+	return "", NewInvalidParameterGiven("invalid LOG_FORMAT is specified. format: " + os.Getenv("LOG_FORMAT"))
+}
+
+// GetLogOutput returns where the logs are written from LOG_OUTPUT.
+// It is stdout by default, stderr, or the path of the log file.
+func GetLogOutput() string {
+	output := os.Getenv("LOG_OUTPUT")
+	if output == "" {
+		return "stdout"
+	}
+	return output
+}
+
+// GetLogMaxSize returns the size in bytes the log file is rotated at, from LOG_MAX_SIZE_MB.
+// It is 100MB by default, and the file is not rotated with 0.
+func GetLogMaxSize() (int64, error) {
+	sizeConf := os.Getenv("LOG_MAX_SIZE_MB")
+	if sizeConf == "" {
+		return 100 * 1024 * 1024, nil
+	}
+
+	size, err := strconv.ParseInt(sizeConf, 10, 64)
+	if err != nil || size < 0 {
+		return 0, NewInvalidParameterGiven("invalid LOG_MAX_SIZE_MB is specified. size: " + sizeConf)
+	}
+	return size * 1024 * 1024, nil
+}
+
+// GetLogMaxBackups returns how many rotated log files are kept, from LOG_MAX_BACKUPS. It is 5 by default.
+func GetLogMaxBackups() (int, error) {
+	backupsConf := os.Getenv("LOG_MAX_BACKUPS")
+	if backupsConf == "" {
+		return 5, nil
+	}
+
+	backups, err := strconv.Atoi(backupsConf)
+	if err != nil || backups < 0 {
+		return 0, NewInvalidParameterGiven("invalid LOG_MAX_BACKUPS is specified. backups: " + backupsConf)
+	}
+	return backups, nil
+}
+
+// NewRequestId returns the request ID given by the client, or a new one when it is not given or invalid.
+// The given one is kept so that the logs can be traced across the services.
+func NewRequestId(given string) string {
+	if given == "" || len(given) > 128 {
+		return newRandomUuid().String()
+	}
+	for _, r := range given {
+		if r <= ' ' || r > '~' {
+			return newRandomUuid().String()
+		}
+	}
+	return given
+}
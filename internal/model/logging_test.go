@@ -0,0 +1,78 @@
+package model
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+)
+
+func TestGetLogLevel(t *testing.T) {
+	defer os.Unsetenv("LOG_LEVEL")
+
+	level, err := GetLogLevel()
+	if err != nil || level != slog.LevelInfo {
+		t.Error("default level is not info")
+	}
+
+	os.Setenv("LOG_LEVEL", "debug")
+	level, err = GetLogLevel()
+	if err != nil || level != slog.LevelDebug {
+		t.Error("level is missmatched")
+	}
+
+	os.Setenv("LOG_LEVEL", "verbose")
+	_, err = GetLogLevel()
+	if err == nil {
+		t.Error("invalid level is accepted")
+	}
+}
+
+func TestGetLogFormat(t *testing.T) {
+	defer os.Unsetenv("LOG_FORMAT")
+
+	format, err := GetLogFormat()
+	if err != nil || format != "text" {
+		t.Error("default format is not text")
+	}
+
+	os.Setenv("LOG_FORMAT", "JSON")
+	format, err = GetLogFormat()
+	if err != nil || format != "json" {
+		t.Error("format is missmatched")
+	}
+
+	os.Setenv("LOG_FORMAT", "xml")
+	_, err = GetLogFormat()
+	if err == nil {
+		t.Error("invalid format is accepted")
+	}
+}
+
+func TestGetLogMaxSize(t *testing.T) {
+	defer os.Unsetenv("LOG_MAX_SIZE_MB")
+
+	os.Setenv("LOG_MAX_SIZE_MB", "10")
+	size, err := GetLogMaxSize()
+	if err != nil || size != 10*1024*1024 {
+		t.Error("size is missmatched")
+	}
+
+	os.Setenv("LOG_MAX_SIZE_MB", "-1")
+	_, err = GetLogMaxSize()
+	if err == nil {
+		t.Error("negative size is accepted")
+	}
+}
+
+func TestNewRequestId(t *testing.T) {
+	if NewRequestId("abc-123") != "abc-123" {
+		t.Error("given request ID is not kept")
+	}
+
+	for _, given := range []string{"", "abc 123", "abc\n123"} {
+		requestId := NewRequestId(given)
+		if requestId == given || len(requestId) != 36 {
+			t.Error("invalid request ID is kept. request ID: " + given)
+		}
+	}
+}
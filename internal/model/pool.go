@@ -0,0 +1,286 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"net"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// GetPoolDir returns the directory of the address pool files.
+// It is inside of the hosts dir, and skipped when domain files are loaded.
+func GetPoolDir() string {
+	return filepath.Join(GetHostsDir(), "_pools")
+}
+
+func GetPoolFilePath(poolUuid Uuid) string {
+	return filepath.Join(GetPoolDir(), poolUuid.String()+".json")
+}
+
+// AddressRange is the addresses from Start to End, including both of them.
+type AddressRange struct {
+	Start net.IP
+	End   net.IP
+}
+
+// NewAddressRange creates a range from "start-end" or a single address.
+func NewAddressRange(addressRange string) (*AddressRange, error) {
+	startEnd := strings.SplitN(addressRange, "-", 2)
+	start := net.ParseIP(strings.TrimSpace(startEnd[0]))
+	end := start
+	if len(startEnd) == 2 {
+		end = net.ParseIP(strings.TrimSpace(startEnd[1]))
+	}
+
+	if start == nil || end == nil || (start.To4() == nil) != (end.To4() == nil) || bytes.Compare(start.To16(), end.To16()) > 0 {
+		return nil, NewInvalidParameterGiven("invalid address range is specified. range: " + addressRange)
+	}
+	return &AddressRange{Start: start, End: end}, nil
+}
+
+func (r *AddressRange) Contains(ip net.IP) bool {
+	return bytes.Compare(ip.To16(), r.Start.To16()) >= 0 && bytes.Compare(ip.To16(), r.End.To16()) <= 0
+}
+
+func (r *AddressRange) String() string {
+	if r.Start.Equal(r.End) {
+		return r.Start.String()
+	}
+	return r.Start.String() + "-" + r.End.String()
+}
+
+// Pool is a set of subnets to allocate host addresses from, scoped to tenants.
+// Reserved ranges and gateways are never allocated.
+type Pool struct {
+	Uuid     Uuid
+	Name     string
+	Tenants  []Uuid
+	Networks []*net.IPNet
+	Reserved []*AddressRange
+	Gateways []net.IP
+}
+
+// poolFile is the json format of the pool file.
+type poolFile struct {
+	Uuid     string   `json:"uuid"`
+	Name     string   `json:"name"`
+	Tenants  []string `json:"tenants"`
+	Cidrs    []string `json:"cidrs"`
+	Reserved []string `json:"reserved"`
+	Gateways []string `json:"gateways"`
+}
+
+func NewOriginalPool(name string, tenantList, cidrList, reservedList, gatewayList []string) (*Pool, error) {
+	u, _ := uuid.NewRandom()
+	return NewPool(u.String(), name, tenantList, cidrList, reservedList, gatewayList)
+}
+
+func NewPool(poolUuid, name string, tenantList, cidrList, reservedList, gatewayList []string) (*Pool, error) {
+	pUuid, err := NewUuid(poolUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(name) == "" {
+		return nil, NewInvalidParameterGiven("pool name is not specified")
+	}
+
+	pool := &Pool{Uuid: pUuid, Name: name}
+	for _, t := range tenantList {
+		tenantUuid, err := NewUuid(t)
+		if err != nil {
+			return nil, err
+		}
+		pool.Tenants = append(pool.Tenants, tenantUuid)
+	}
+
+	if len(cidrList) == 0 {
+		return nil, NewInvalidParameterGiven("pool CIDR is not specified")
+	}
+	for _, c := range cidrList {
+		_, network, err := net.ParseCIDR(c)
+		if err != nil {
+			return nil, NewInvalidParameterGiven("invalid CIDR is specified to pool. CIDR: " + c)
+		}
+		pool.Networks = append(pool.Networks, network)
+	}
+
+	for _, r := range reservedList {
+		addressRange, err := NewAddressRange(r)
+		if err != nil {
+			return nil, err
+		}
+		if !pool.contains(addressRange.Start) && !pool.contains(addressRange.End) {
+			return nil, NewInvalidParameterGiven("reserved range is out of pool CIDR. range: " + r)
+		}
+		pool.Reserved = append(pool.Reserved, addressRange)
+	}
+
+	for _, g := range gatewayList {
+		gateway := net.ParseIP(g)
+		if gateway == nil || !pool.contains(gateway) {
+			return nil, NewInvalidParameterGiven("invalid gateway is specified to pool. gateway: " + g)
+		}
+		pool.Gateways = append(pool.Gateways, gateway)
+	}
+
+	return pool, nil
+}
+
+// NewPoolFromFileInfo creates a pool from the json pool file.
+func NewPoolFromFileInfo(fileInfo string) (*Pool, error) {
+	var f poolFile
+	err := json.Unmarshal([]byte(fileInfo), &f)
+	if err != nil {
+		return nil, NewServerSideError("invalid pool file. " + err.Error())
+	}
+
+	return NewPool(f.Uuid, f.Name, f.Tenants, f.Cidrs, f.Reserved, f.Gateways)
+}
+
+func (p *Pool) GetFileInfo() (string, error) {
+	f := poolFile{
+		Uuid:     p.Uuid.String(),
+		Name:     p.Name,
+		Tenants:  []string{},
+		Cidrs:    p.GetCidrList(),
+		Reserved: p.GetReservedList(),
+		Gateways: p.GetGatewayList()}
+	for _, t := range p.Tenants {
+		f.Tenants = append(f.Tenants, t.String())
+	}
+
+	fileInfo, err := json.MarshalIndent(f, "", "    ")
+	if err != nil {
+		return "", err
+	}
+	return string(fileInfo) + "\n", nil
+}
+
+func (p *Pool) GetCidrList() []string {
+	cidrs := []string{}
+	for _, n := range p.Networks {
+		cidrs = append(cidrs, n.String())
+	}
+	return cidrs
+}
+
+func (p *Pool) GetReservedList() []string {
+	reserved := []string{}
+	for _, r := range p.Reserved {
+		reserved = append(reserved, r.String())
+	}
+	return reserved
+}
+
+func (p *Pool) GetGatewayList() []string {
+	gateways := []string{}
+	for _, g := range p.Gateways {
+		gateways = append(gateways, g.String())
+	}
+	return gateways
+}
+
+// HasTenant returns whether the tenant can use the pool or not.
+func (p *Pool) HasTenant(tenantUuid Uuid) bool {
+	for _, t := range p.Tenants {
+		if t == tenantUuid {
+			return true
+		}
+	}
+	return false
+}
+
+// Allocate returns the lowest free address in the pool.
+// The network address, IPv4 broadcast address, gateways, reserved ranges
+// and the used addresses are skipped.
+func (p *Pool) Allocate(usedAddresses map[string]bool) (string, error) {
+	for _, network := range p.Networks {
+		ip, last := networkRange(network)
+		for !ip.Equal(last) {
+			ip = nextIP(ip)
+			if !isIPv6(network) && ip.Equal(last) {
+				break
+			}
+
+			if r := p.getReserved(ip); r != nil {
+				// Whole reserved range is skipped at once, since it can be large in IPv6.
+				end := r.End.To16()
+				if len(ip) == net.IPv4len {
+					end = r.End.To4()
+				}
+				if bytes.Compare(end, last) >= 0 {
+					break
+				}
+				ip = end
+				continue
+			}
+
+			if p.isGateway(ip) || usedAddresses[ip.String()] {
+				continue
+			}
+			return ip.String(), nil
+		}
+	}
+	return "", NewPoolExhaustedError(p.Name)
+}
+
+func (p *Pool) contains(ip net.IP) bool {
+	for _, n := range p.Networks {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Pool) isGateway(ip net.IP) bool {
+	for _, g := range p.Gateways {
+		if g.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Pool) getReserved(ip net.IP) *AddressRange {
+	for _, r := range p.Reserved {
+		if r.Contains(ip) {
+			return r
+		}
+	}
+	return nil
+}
+
+func isIPv6(network *net.IPNet) bool {
+	return network.IP.To4() == nil
+}
+
+// networkRange returns the first and the last addresses of the network.
+func networkRange(network *net.IPNet) (net.IP, net.IP) {
+	first := network.IP.Mask(network.Mask)
+	if ip4 := first.To4(); ip4 != nil {
+		first = ip4
+	}
+
+	last := make(net.IP, len(first))
+	for i := range first {
+		last[i] = first[i] | ^network.Mask[len(network.Mask)-len(first)+i]
+	}
+	return first, last
+}
+
+// nextIP returns the next address. ip must not be the last address of its length.
+func nextIP(ip net.IP) net.IP {
+	i := new(big.Int).SetBytes(ip)
+	i.Add(i, big.NewInt(1))
+
+	next := make(net.IP, len(ip))
+	b := i.Bytes()
+	copy(next[len(next)-len(b):], b)
+	return next
+}
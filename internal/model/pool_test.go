@@ -0,0 +1,110 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestNewPool(t *testing.T) {
+	tenants := []string{"df397e50-8006-450e-b18b-5c5bd940baff"}
+
+	_, err := NewOriginalPool("hoge", tenants, []string{"172.21.1.0/24"}, []string{"172.21.1.1-172.21.1.9"}, []string{"172.21.1.254"})
+	if err != nil {
+		t.Error(err)
+	}
+
+	invalidCases := []struct {
+		cidrs    []string
+		reserved []string
+		gateways []string
+	}{
+		{[]string{}, nil, nil},
+		{[]string{"172.21.1.0"}, nil, nil},
+		{[]string{"172.21.1.0/24"}, []string{"172.21.2.1-172.21.2.9"}, nil},
+		{[]string{"172.21.1.0/24"}, []string{"172.21.1.9-172.21.1.1"}, nil},
+		{[]string{"172.21.1.0/24"}, nil, []string{"172.21.2.1"}},
+	}
+	for _, c := range invalidCases {
+		_, err := NewOriginalPool("hoge", tenants, c.cidrs, c.reserved, c.gateways)
+		if err == nil {
+			t.Error("invalid pool is accepted.", c.cidrs, c.reserved, c.gateways)
+		}
+	}
+
+	_, err = NewOriginalPool("", tenants, []string{"172.21.1.0/24"}, nil, nil)
+	if err == nil {
+		t.Error("pool without name is accepted")
+	}
+}
+
+func TestPoolAllocate(t *testing.T) {
+	tenants := []string{"df397e50-8006-450e-b18b-5c5bd940baff"}
+	pool, err := NewOriginalPool("hoge", tenants, []string{"172.21.1.0/29"}, []string{"172.21.1.3-172.21.1.4"}, []string{"172.21.1.1"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	used := map[string]bool{"172.21.1.2": true}
+	address, err := pool.Allocate(used)
+	if err != nil {
+		t.Error(err)
+	}
+	if address != "172.21.1.5" {
+		t.Error("gateway, reserved or used address is allocated. address: " + address)
+	}
+
+	used["172.21.1.5"] = true
+	used["172.21.1.6"] = true
+	_, err = pool.Allocate(used)
+	if _, ok := err.(*PoolExhaustedError); !ok {
+		t.Error("broadcast address is allocated")
+	}
+}
+
+func TestPoolAllocateIPv6(t *testing.T) {
+	tenants := []string{"df397e50-8006-450e-b18b-5c5bd940baff"}
+	pool, err := NewOriginalPool("hoge", tenants, []string{"fd00::/64"}, []string{"fd00::1-fd00::ff"}, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	address, err := pool.Allocate(map[string]bool{"fd00::100": true})
+	if err != nil {
+		t.Error(err)
+	}
+	if address != "fd00::101" {
+		t.Error("reserved or used address is allocated. address: " + address)
+	}
+}
+
+func TestPoolFileInfo(t *testing.T) {
+	tenants := []string{"df397e50-8006-450e-b18b-5c5bd940baff"}
+	pool, err := NewOriginalPool("hoge", tenants, []string{"172.21.1.0/24", "fd00::/64"}, []string{"172.21.1.1-172.21.1.9"}, []string{"172.21.1.254"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	fileInfo, err := pool.GetFileInfo()
+	if err != nil {
+		t.Error(err)
+	}
+
+	loaded, err := NewPoolFromFileInfo(fileInfo)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	loadedInfo, err := loaded.GetFileInfo()
+	if err != nil {
+		t.Error(err)
+	}
+	if loadedInfo != fileInfo {
+		t.Error(loadedInfo)
+	}
+	if !loaded.HasTenant(pool.Tenants[0]) {
+		t.Error("tenant is not loaded")
+	}
+}
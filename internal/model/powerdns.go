@@ -0,0 +1,31 @@
+package model
+
+import (
+	"os"
+	"strings"
+)
+
+// GetPowerDNSApiKeys returns the tenants of the API keys of the PowerDNS compatible API.
+// They are given as comma separated key:tenant list with PDNS_API_KEYS.
+func GetPowerDNSApiKeys() (map[string]Uuid, error) {
+	keys := map[string]Uuid{}
+	for _, k := range strings.Split(os.Getenv("PDNS_API_KEYS"), ",") {
+		k = strings.TrimSpace(k)
+		if k == "" {
+			continue
+		}
+
+		// API keys may have colons, so the tenant is taken from the last one.
+		i := strings.LastIndex(k, ":")
+		if i <= 0 {
+			return nil, NewInvalidParameterGiven("PDNS_API_KEYS has to be key:tenant format.")
+		}
+
+		tenant, err := NewUuid(k[i+1:])
+		if err != nil {
+			return nil, err
+		}
+		keys[k[:i]] = tenant
+	}
+	return keys, nil
+}
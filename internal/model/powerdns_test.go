@@ -0,0 +1,26 @@
+package model
+
+import (
+	"os"
+	"testing"
+)
+
+func TestGetPowerDNSApiKeys(t *testing.T) {
+	defer os.Unsetenv("PDNS_API_KEYS")
+
+	os.Setenv("PDNS_API_KEYS", "secret1:df397e50-8006-450e-b18b-5c5bd940baff, se:cret2:02c03bd4-fe2e-45f2-85b6-b535af15215d")
+	keys, err := GetPowerDNSApiKeys()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(keys) != 2 || keys["secret1"] != "df397e50-8006-450e-b18b-5c5bd940baff" || keys["se:cret2"] != "02c03bd4-fe2e-45f2-85b6-b535af15215d" {
+		t.Error("API keys are missmatched")
+	}
+
+	os.Setenv("PDNS_API_KEYS", "df397e50-8006-450e-b18b-5c5bd940baff")
+	_, err = GetPowerDNSApiKeys()
+	if err == nil {
+		t.Error("API key without tenant is accepted")
+	}
+}
@@ -0,0 +1,236 @@
+package model
+
+import (
+	"bytes"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// GetReverseZonesConf returns comma separated CIDR list of the reverse zones.
+func GetReverseZonesConf() string {
+	return os.Getenv("REVERSE_ZONES")
+}
+
+// GetReverseZoneDir returns the directory of the generated reverse zone files.
+// It is inside of the hosts dir, and skipped when domain files are loaded.
+func GetReverseZoneDir() string {
+	return filepath.Join(GetHostsDir(), "_reverse")
+}
+
+// PtrRecord is a PTR record from the address to the hostname.
+type PtrRecord struct {
+	Address string
+	Name    string
+}
+
+// ReverseConflict is an address claimed by multiple hostnames.
+// Winner is the hostname used as the PTR record.
+type ReverseConflict struct {
+	Address string
+	Names   []string
+	Winner  string
+}
+
+// ReverseZone is an in-addr.arpa or ip6.arpa zone generated from the hosts of all domains.
+// It is written as a hosts file, and CoreDNS hosts plugin answers PTR queries from it.
+type ReverseZone struct {
+	Network        *net.IPNet
+	Name           DomainName
+	Records        []*PtrRecord
+	Conflicts      []*ReverseConflict
+	DomainFilePath string
+	ReloadInterval string
+	ReloadJitter   string
+}
+
+// NewReverseZoneList creates reverse zones from comma separated CIDR list.
+func NewReverseZoneList(cidrList string) ([]*ReverseZone, error) {
+	var zones []*ReverseZone
+	names := map[DomainName]bool{}
+	for _, cidr := range strings.Split(cidrList, ",") {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+
+		zone, err := NewReverseZone(cidr)
+		if err != nil {
+			return nil, err
+		}
+		if names[zone.Name] {
+			return nil, NewInvalidParameterGiven("reverse zone is duplicated. zone: " + zone.Name.String())
+		}
+		names[zone.Name] = true
+		zones = append(zones, zone)
+	}
+	return zones, nil
+}
+
+// NewReverseZone creates an empty reverse zone of the network.
+// The prefix length has to be on the label boundary,
+// multiple of 8 for IPv4 and multiple of 4 for IPv6.
+func NewReverseZone(cidr string) (*ReverseZone, error) {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, NewInvalidParameterGiven("invalid CIDR is specified to reverse zone. CIDR: " + cidr)
+	}
+
+	ones, bits := network.Mask.Size()
+	var labels []string
+	if bits == 32 {
+		if ones == 0 || ones%8 != 0 {
+			return nil, NewInvalidParameterGiven("prefix length of IPv4 reverse zone has to be multiple of 8. CIDR: " + cidr)
+		}
+		ip := network.IP.To4()
+		for i := ones/8 - 1; i >= 0; i-- {
+			labels = append(labels, strconv.Itoa(int(ip[i])))
+		}
+		labels = append(labels, "in-addr", "arpa")
+	} else {
+		if ones == 0 || ones%4 != 0 {
+			return nil, NewInvalidParameterGiven("prefix length of IPv6 reverse zone has to be multiple of 4. CIDR: " + cidr)
+		}
+		ip := network.IP.To16()
+		for i := ones/4 - 1; i >= 0; i-- {
+			nibble := ip[i/2] >> 4
+			if i%2 == 1 {
+				nibble = ip[i/2] & 0xf
+			}
+			labels = append(labels, strconv.FormatInt(int64(nibble), 16))
+		}
+		labels = append(labels, "ip6", "arpa")
+	}
+
+	name, err := NewDomainName(strings.Join(labels, "."))
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReverseZone{
+		Network:        network,
+		Name:           name,
+		DomainFilePath: filepath.Join(GetReverseZoneDir(), name.String()),
+		ReloadInterval: "10s",
+		ReloadJitter:   "5s"}, nil
+}
+
+// Clone returns a copy of the zone which can be changed without affecting the original.
+func (z *ReverseZone) Clone() *ReverseZone {
+	zone := *z
+	zone.Records = append([]*PtrRecord{}, z.Records...)
+	zone.Conflicts = append([]*ReverseConflict{}, z.Conflicts...)
+	return &zone
+}
+
+// SetHosts makes the PTR records from the hosts of the domains in the network.
+// When an address is claimed by multiple hostnames, the lexicographically smallest one wins,
+// so the result doesn't depend on the order of the domains.
+func (z *ReverseZone) SetHosts(domains []*Domain) {
+	names := map[string][]string{}
+	ips := map[string]net.IP{}
+	for _, d := range domains {
+		for _, h := range d.Hosts {
+			ip := net.ParseIP(h.Address)
+			if ip == nil || !z.Network.Contains(ip) {
+				continue
+			}
+
+			address := ip.String()
+			ips[address] = ip.To16()
+			names[address] = append(names[address], h.Name)
+		}
+	}
+
+	var addresses []string
+	for address := range names {
+		addresses = append(addresses, address)
+	}
+	sort.Slice(addresses, func(i, j int) bool { return bytes.Compare(ips[addresses[i]], ips[addresses[j]]) < 0 })
+
+	z.Records = nil
+	z.Conflicts = nil
+	for _, address := range addresses {
+		nameList := uniqueSortedNames(names[address])
+		z.Records = append(z.Records, &PtrRecord{Address: address, Name: nameList[0]})
+		if len(nameList) > 1 {
+			z.Conflicts = append(z.Conflicts, &ReverseConflict{Address: address, Names: nameList, Winner: nameList[0]})
+		}
+	}
+}
+
+// FilterNames returns a copy of the zone which has only the records and conflicts of the hostnames.
+// The conflicts have only the hostnames, and the winner only when it is one of them,
+// so the hostnames of the other tenants are not shown.
+func (z *ReverseZone) FilterNames(hostnames map[string]bool) *ReverseZone {
+	zone := *z
+	zone.Records = nil
+	zone.Conflicts = nil
+
+	for _, r := range z.Records {
+		if hostnames[r.Name] {
+			zone.Records = append(zone.Records, r)
+		}
+	}
+
+	for _, c := range z.Conflicts {
+		conflict := &ReverseConflict{Address: c.Address}
+		for _, n := range c.Names {
+			if hostnames[n] {
+				conflict.Names = append(conflict.Names, n)
+			}
+		}
+		if len(conflict.Names) == 0 {
+			continue
+		}
+		if hostnames[c.Winner] {
+			conflict.Winner = c.Winner
+		}
+		zone.Conflicts = append(zone.Conflicts, conflict)
+	}
+	return &zone
+}
+
+func (z *ReverseZone) GetFileInfo() (string, error) {
+	conflicts := map[string]*ReverseConflict{}
+	for _, c := range z.Conflicts {
+		conflicts[c.Address] = c
+	}
+
+	var out bytes.Buffer
+	out.WriteString("# ReverseZone: " + z.Name.String() + "\n")
+	out.WriteString("# Network: " + z.Network.String() + "\n")
+	out.WriteString("# This file is generated from hosts of all domains.\n")
+
+	recordTemplate := `{{ .Address }}  {{ .Name }}
+`
+	tmpl := template.Must(template.New("").Parse(recordTemplate))
+	for _, r := range z.Records {
+		c, ok := conflicts[r.Address]
+		if ok {
+			out.WriteString("# conflict: " + strings.Join(c.Names, ", ") + "\n")
+		}
+
+		err := tmpl.Execute(&out, r)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return out.String(), nil
+}
+
+func uniqueSortedNames(names []string) []string {
+	sort.Strings(names)
+	var unique []string
+	for _, n := range names {
+		if len(unique) == 0 || unique[len(unique)-1] != n {
+			unique = append(unique, n)
+		}
+	}
+	return unique
+}
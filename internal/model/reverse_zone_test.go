@@ -0,0 +1,155 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewReverseZone(t *testing.T) {
+	cases := map[string]string{
+		"172.21.0.0/16":   "21.172.in-addr.arpa",
+		"10.0.0.0/8":      "10.in-addr.arpa",
+		"192.168.1.0/24":  "1.168.192.in-addr.arpa",
+		"fd00::/16":       "0.0.d.f.ip6.arpa",
+		"2001:db8:a::/52": "0.a.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa",
+	}
+
+	for cidr, name := range cases {
+		zone, err := NewReverseZone(cidr)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if zone.Name.String() != name {
+			t.Error(cidr + " is converted to " + zone.Name.String())
+		}
+	}
+
+	for _, cidr := range []string{"172.21.0.0/20", "fd00::/62", "0.0.0.0/0", "172.21.0.0"} {
+		_, err := NewReverseZone(cidr)
+		if err == nil {
+			t.Error("invalid reverse zone is accepted. CIDR: " + cidr)
+		}
+	}
+}
+
+func TestNewReverseZoneList(t *testing.T) {
+	zones, err := NewReverseZoneList("172.21.0.0/16, fd00::/16")
+	if err != nil {
+		t.Error(err)
+	}
+	if len(zones) != 2 {
+		t.Error("reverse zones are not parsed")
+	}
+
+	_, err = NewReverseZoneList("172.21.0.0/16,172.21.1.0/16")
+	if err == nil {
+		t.Error("duplicated reverse zone is accepted")
+	}
+}
+
+func TestReverseZoneSetHosts(t *testing.T) {
+	hoge, err := NewDomain("hogehoge.hoge", `# DomainUUID: 3e8fc6b1-0a93-4c57-9f2b-95d3e66a66e0
+172.21.1.10  hogeserver1.hogehoge.hoge  # 5b9ea8eb-5ce5-422a-9d70-37d25fa896ae
+172.21.1.2  hogeserver2.hogehoge.hoge  # f0c5edcd-3b18-4c26-a8e1-3f3495504dd6
+172.22.1.1  hogeserver3.hogehoge.hoge  # eac1b92b-31b2-4b7d-a26b-fd487b6669ca
+`)
+	if err != nil {
+		t.Error(err)
+	}
+	fuga, err := NewDomain("fugafuga.fuga", `# DomainUUID: 2d7bd1a4-8a0b-4f0d-9d3e-0a3a2f1c6b7e
+172.21.1.2  fugaserver1.fugafuga.fuga  # 0f8e6c3a-4a4e-4e53-8c55-4e7a3c0c4a11
+`)
+	if err != nil {
+		t.Error(err)
+	}
+
+	zone, err := NewReverseZone("172.21.0.0/16")
+	if err != nil {
+		t.Error(err)
+	}
+	zone.SetHosts([]*Domain{hoge, fuga})
+
+	if len(zone.Records) != 2 {
+		t.Error("PTR records are not made from the hosts in the network")
+		return
+	}
+	if zone.Records[0].Address != "172.21.1.2" || zone.Records[0].Name != "fugaserver1.fugafuga.fuga" {
+		t.Error("conflict is not resolved with the smallest hostname")
+	}
+	if len(zone.Conflicts) != 1 || len(zone.Conflicts[0].Names) != 2 {
+		t.Error("conflict is not reported")
+	}
+
+	fileInfo, err := zone.GetFileInfo()
+	if err != nil {
+		t.Error(err)
+	}
+	if !strings.Contains(fileInfo, "172.21.1.2  fugaserver1.fugafuga.fuga\n172.21.1.10  hogeserver1.hogehoge.hoge\n") {
+		t.Error(fileInfo)
+	}
+
+	filtered := zone.FilterNames(map[string]bool{"hogeserver2.hogehoge.hoge": true})
+	if len(filtered.Records) != 0 || len(filtered.Conflicts) != 1 {
+		t.Error("reverse zone is not filtered with hostnames")
+		return
+	}
+	conflict := filtered.Conflicts[0]
+	if len(conflict.Names) != 1 || conflict.Names[0] != "hogeserver2.hogehoge.hoge" {
+		t.Error("hostnames of the other tenants are in the conflict")
+	}
+	if conflict.Winner != "" {
+		t.Error("winner of the other tenant is in the conflict")
+	}
+	if len(zone.Conflicts[0].Names) != 2 || zone.Conflicts[0].Winner != "fugaserver1.fugafuga.fuga" {
+		t.Error("conflict of the zone is changed by filtering")
+	}
+
+	filtered = zone.FilterNames(map[string]bool{"fugaserver1.fugafuga.fuga": true})
+	if len(filtered.Conflicts) != 1 || filtered.Conflicts[0].Winner != "fugaserver1.fugafuga.fuga" {
+		t.Error("winner of the tenant is not in the conflict")
+	}
+}
+
+func TestGetInfoCoreDNSConfReverseZone(t *testing.T) {
+	domain, err := NewDomain("hogehoge.hoge", `# DomainUUID: 3e8fc6b1-0a93-4c57-9f2b-95d3e66a66e0
+172.21.1.1  hogeserver1.hogehoge.hoge  # 5b9ea8eb-5ce5-422a-9d70-37d25fa896ae
+`)
+	if err != nil {
+		t.Error(err)
+	}
+
+	zone, err := NewReverseZone("172.21.0.0/16")
+	if err != nil {
+		t.Error(err)
+	}
+
+	conf := NewCoreDNSConf([]*Domain{domain})
+	conf.SetReverseZones([]*ReverseZone{zone})
+	confInfo, err := conf.GetFileInfo()
+	if err != nil {
+		t.Error(err)
+	}
+
+	if !strings.Contains(confInfo, "21.172.in-addr.arpa. {\n    hosts _reverse/21.172.in-addr.arpa\n") {
+		t.Error(confInfo)
+	}
+	if err := ValidateCorefile(confInfo); err != nil {
+		t.Error(err)
+	}
+	if len(conf.ReverseZones[0].Records) != 1 {
+		t.Error("reverse zone is not made from the cache")
+	}
+}
+
+func TestGetReverseZonesConf(t *testing.T) {
+	t.Setenv("REVERSE_ZONES", "172.21.0.0/16")
+	if GetReverseZonesConf() != "172.21.0.0/16" {
+		t.Error("reverse zones are not got from the environment")
+	}
+
+	t.Setenv("REVERSE_ZONES", "")
+	if GetReverseZonesConf() != "" {
+		t.Error("reverse zones are kept after the environment is changed")
+	}
+}
@@ -0,0 +1,124 @@
+package model
+
+import (
+	"net"
+	"sort"
+	"strings"
+)
+
+// RRsetChange replaces or deletes the records with the same name and type.
+// Name is the owner name without the trailing dot. Empty Values deletes the records.
+type RRsetChange struct {
+	Name   string
+	Type   string
+	Values []string
+}
+
+// GetRecordType returns the type of the record the address is served as, A or AAAA.
+func GetRecordType(address string) string {
+	if net.ParseIP(address).To4() != nil {
+		return "A"
+	}
+	return "AAAA"
+}
+
+// PlanRRsetChanges returns the hosts of the domain after the changes are applied in order.
+// Hosts file has one address for one hostname, so only A and AAAA RRsets with at most one record can be changed,
+// and a hostname can't have both of them.
+func PlanRRsetChanges(domain *Domain, changes []*RRsetChange) ([]*Host, error) {
+	zone := strings.ToLower(domain.Name.String())
+	hosts := append([]*Host{}, domain.Hosts...)
+	for _, c := range changes {
+		name := strings.ToLower(strings.TrimSuffix(c.Name, "."))
+		if name != zone && !strings.HasSuffix(name, "."+zone) {
+			return nil, NewInvalidParameterGiven("record is out of zone " + zone + ". name: " + c.Name)
+		}
+
+		if c.Type != "A" && c.Type != "AAAA" {
+			return nil, NewInvalidParameterGiven(c.Type + " record is not supported in hosts file. name: " + c.Name)
+		}
+		if len(c.Values) > 1 {
+			return nil, NewInvalidParameterGiven("hostname can't have multiple addresses in hosts file. name: " + c.Name)
+		}
+
+		index := -1
+		for i, h := range hosts {
+			if strings.ToLower(h.Name) == name {
+				index = i
+				break
+			}
+		}
+
+		if index >= 0 && GetRecordType(hosts[index].Address) != c.Type {
+			if len(c.Values) == 0 {
+				continue
+			}
+			return nil, NewInvalidParameterGiven("hostname already has " + GetRecordType(hosts[index].Address) + " record. name: " + c.Name)
+		}
+
+		if len(c.Values) == 0 {
+			if index >= 0 {
+				hosts = append(hosts[:index], hosts[index+1:]...)
+			}
+			continue
+		}
+
+		if net.ParseIP(c.Values[0]) == nil || GetRecordType(c.Values[0]) != c.Type {
+			return nil, NewInvalidParameterGiven("invalid " + c.Type + " record is given. name: " + c.Name + ", content: " + c.Values[0])
+		}
+
+		if index >= 0 {
+			host, err := NewHost(hosts[index].Uuid, hosts[index].Name, c.Values[0])
+			if err != nil {
+				return nil, err
+			}
+			hosts[index] = host
+			continue
+		}
+
+		host, err := NewOriginalHost(name, c.Values[0], domain.Name)
+		if err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts, nil
+}
+
+// CheckRRsets tells whether the domain has exactly the records of the RRsets, like the old records of the updates.
+// Empty Values expects no record. RRsetMismatchError is returned otherwise.
+func CheckRRsets(domain *Domain, rrsets []*RRsetChange) error {
+	for _, r := range rrsets {
+		name := strings.ToLower(strings.TrimSuffix(r.Name, "."))
+
+		var current []string
+		switch r.Type {
+		case "TXT":
+			for _, t := range domain.TxtRecords {
+				if t.Name == name {
+					current = append(current, t.Text)
+				}
+			}
+		default:
+			for _, h := range domain.Hosts {
+				if strings.ToLower(h.Name) == name && GetRecordType(h.Address) == r.Type {
+					current = append(current, net.ParseIP(h.Address).String())
+				}
+			}
+		}
+
+		var expected []string
+		for _, v := range r.Values {
+			if ip := net.ParseIP(v); ip != nil && r.Type != "TXT" {
+				v = ip.String()
+			}
+			expected = append(expected, v)
+		}
+		sort.Strings(current)
+		sort.Strings(expected)
+		if strings.Join(current, "\n") != strings.Join(expected, "\n") {
+			return NewRRsetMismatchError(r.Name, r.Type)
+		}
+	}
+	return nil
+}
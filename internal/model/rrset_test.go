@@ -0,0 +1,101 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestPlanRRsetChanges(t *testing.T) {
+	domainFileInfo := `# DomainUUID: 3e8fc6b1-0a93-4c57-9f2b-95d3e66a66e0
+# Tenats:
+#   - df397e50-8006-450e-b18b-5c5bd940baff
+172.21.1.1  hogeserver1.hogehoge.hoge  # 5b9ea8eb-5ce5-422a-9d70-37d25fa896ae
+172.21.1.2  hogeserver2.hogehoge.hoge  # f0c5edcd-3b18-4c26-a8e1-3f3495504dd6
+fd00::3  hogeserver3.hogehoge.hoge  # eac1b92b-31b2-4b7d-a26b-fd487b6669ca
+`
+	domain, err := NewDomain("hogehoge.hoge", domainFileInfo)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	hosts, err := PlanRRsetChanges(domain, []*RRsetChange{
+		{Name: "hogeserver1.hogehoge.hoge.", Type: "A", Values: []string{"172.21.1.11"}},
+		{Name: "hogeserver2.hogehoge.hoge.", Type: "A"},
+		{Name: "hogeserver3.hogehoge.hoge.", Type: "A"},
+		{Name: "HogeServer4.hogehoge.hoge.", Type: "AAAA", Values: []string{"fd00::4"}},
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if len(hosts) != 3 {
+		t.Error("number of the hosts is missmatched")
+		return
+	}
+	if hosts[0].Uuid != "5b9ea8eb-5ce5-422a-9d70-37d25fa896ae" || hosts[0].Address != "172.21.1.11" {
+		t.Error("replaced host is missmatched")
+	}
+	if hosts[1].Name != "hogeserver3.hogehoge.hoge" {
+		t.Error("AAAA record is deleted with A rrset")
+	}
+	if hosts[2].Name != "hogeserver4.hogehoge.hoge" || hosts[2].Address != "fd00::4" {
+		t.Error("added host is missmatched")
+	}
+	if len(domain.Hosts) != 3 || domain.Hosts[1].Name != "hogeserver2.hogehoge.hoge" || domain.Hosts[0].Address != "172.21.1.1" {
+		t.Error("hosts of the domain are changed")
+	}
+
+	invalidChanges := []*RRsetChange{
+		{Name: "hogeserver1.fugafuga.fuga.", Type: "A", Values: []string{"172.21.1.11"}},
+		{Name: "hogeserver1.hogehoge.hoge.", Type: "TXT", Values: []string{`"text"`}},
+		{Name: "hogeserver1.hogehoge.hoge.", Type: "A", Values: []string{"172.21.1.11", "172.21.1.12"}},
+		{Name: "hogeserver1.hogehoge.hoge.", Type: "AAAA", Values: []string{"fd00::1"}},
+		{Name: "hogeserver5.hogehoge.hoge.", Type: "A", Values: []string{"fd00::5"}},
+	}
+	for _, c := range invalidChanges {
+		_, err = PlanRRsetChanges(domain, []*RRsetChange{c})
+		if err == nil {
+			t.Error("invalid change is accepted. name: " + c.Name + ", type: " + c.Type)
+		}
+	}
+}
+
+func TestCheckRRsets(t *testing.T) {
+	domainFileInfo := `# DomainUUID: 3e8fc6b1-0a93-4c57-9f2b-95d3e66a66e0
+# Tenats:
+#   - df397e50-8006-450e-b18b-5c5bd940baff
+# TXT: hogeserver1.hogehoge.hoge "owner=a"
+172.21.1.1  hogeserver1.hogehoge.hoge  # 5b9ea8eb-5ce5-422a-9d70-37d25fa896ae
+fd00::3  hogeserver3.hogehoge.hoge  # eac1b92b-31b2-4b7d-a26b-fd487b6669ca
+`
+	domain, err := NewDomain("hogehoge.hoge", domainFileInfo)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	err = CheckRRsets(domain, []*RRsetChange{
+		{Name: "hogeserver1.hogehoge.hoge.", Type: "A", Values: []string{"172.21.1.1"}},
+		{Name: "hogeserver1.hogehoge.hoge.", Type: "TXT", Values: []string{`"owner=a"`}},
+		{Name: "HogeServer3.hogehoge.hoge.", Type: "AAAA", Values: []string{"fd00:0::3"}},
+		{Name: "hogeserver3.hogehoge.hoge.", Type: "A"},
+	})
+	if err != nil {
+		t.Error(err)
+	}
+
+	mismatchedRRsets := []*RRsetChange{
+		{Name: "hogeserver1.hogehoge.hoge.", Type: "A", Values: []string{"172.21.1.11"}},
+		{Name: "hogeserver1.hogehoge.hoge.", Type: "A"},
+		{Name: "hogeserver1.hogehoge.hoge.", Type: "TXT", Values: []string{`"owner=b"`}},
+		{Name: "hogeserver2.hogehoge.hoge.", Type: "A", Values: []string{"172.21.1.2"}},
+		{Name: "hogeserver3.hogehoge.hoge.", Type: "A", Values: []string{"fd00::3"}},
+	}
+	for _, r := range mismatchedRRsets {
+		err = CheckRRsets(domain, []*RRsetChange{r})
+		if _, ok := err.(*RRsetMismatchError); !ok {
+			t.Error("mismatched rrset is accepted. name: " + r.Name + ", type: " + r.Type)
+		}
+	}
+}
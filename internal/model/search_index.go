@@ -0,0 +1,211 @@
+package model
+
+import (
+	"bytes"
+	"net"
+	"sort"
+	"strings"
+)
+
+// HostSearch is the query to search hosts across domains.
+//
+// Name matches the hostname exactly, or the hostnames which start with it when it ends with "*".
+// Address matches the address exactly, or the addresses in it when it is a CIDR.
+// Both of them are matched when both are given.
+type HostSearch struct {
+	Name    string
+	Address string
+
+	namePrefix bool
+	network    *net.IPNet
+}
+
+func NewHostSearch(name, address string) (*HostSearch, error) {
+	if name == "" && address == "" {
+		return nil, NewInvalidParameterGiven("name or address has to be specified")
+	}
+
+	search := &HostSearch{Name: strings.ToLower(name), Address: address}
+	if strings.HasSuffix(search.Name, "*") {
+		search.Name = strings.TrimSuffix(search.Name, "*")
+		search.namePrefix = true
+		if search.Name == "" || strings.Contains(search.Name, "*") {
+			return nil, NewInvalidParameterGiven("invalid name is specified. name: " + name)
+		}
+	}
+
+	if strings.Contains(address, "/") {
+		_, network, err := net.ParseCIDR(address)
+		if err != nil {
+			return nil, NewInvalidParameterGiven("invalid address CIDR is specified. address: " + address)
+		}
+		search.network = network
+	} else if address != "" {
+		ip := net.ParseIP(address)
+		if ip == nil {
+			return nil, NewInvalidParameterGiven("invalid address is specified. address: " + address)
+		}
+		// Addresses are kept in the normalized form like NewHost does.
+		search.Address = ip.String()
+	}
+
+	return search, nil
+}
+
+func (s *HostSearch) matchName(host *Host) bool {
+	if s.Name == "" {
+		return true
+	}
+
+	name := strings.ToLower(host.Name)
+	if s.namePrefix {
+		return strings.HasPrefix(name, s.Name)
+	}
+	return name == s.Name
+}
+
+func (s *HostSearch) matchAddress(host *Host) bool {
+	if s.network != nil {
+		ip := net.ParseIP(host.Address)
+		return ip != nil && s.network.Contains(ip)
+	}
+	return s.Address == "" || host.Address == s.Address
+}
+
+// SearchHit is a host found by HostSearch, with its domain.
+type SearchHit struct {
+	Domain *Domain
+	Host   *Host
+}
+
+// searchEntry is a host in the search index.
+// The domain is kept by name, so that the hit is checked against the cache.
+type searchEntry struct {
+	domainName DomainName
+	host       *Host
+	name       string
+	ip         net.IP
+}
+
+// SearchIndex indexes the hosts of every domain by the hostname and the address.
+//
+// Exact matches are looked up in the maps, which are updated with the domains.
+// Prefix and CIDR matches are binary searched in the sorted lists,
+// which are rebuilt on the next search after the domains are changed.
+type SearchIndex struct {
+	byDomain  map[DomainName][]*searchEntry
+	byName    map[string][]*searchEntry
+	byAddress map[string][]*searchEntry
+
+	sorted          bool
+	sortedByName    []*searchEntry
+	sortedByAddress []*searchEntry
+}
+
+func NewSearchIndex(domains []*Domain) *SearchIndex {
+	index := &SearchIndex{
+		byDomain:  map[DomainName][]*searchEntry{},
+		byName:    map[string][]*searchEntry{},
+		byAddress: map[string][]*searchEntry{},
+	}
+	for _, domain := range domains {
+		index.Set(domain)
+	}
+	return index
+}
+
+// Set indexes the current hosts of the domain, and removes its old hosts.
+func (x *SearchIndex) Set(domain *Domain) {
+	x.Remove(domain.Name)
+
+	var entries []*searchEntry
+	for _, h := range domain.Hosts {
+		entry := &searchEntry{domainName: domain.Name, host: h, name: strings.ToLower(h.Name), ip: net.ParseIP(h.Address).To16()}
+		entries = append(entries, entry)
+		x.byName[entry.name] = append(x.byName[entry.name], entry)
+		x.byAddress[h.Address] = append(x.byAddress[h.Address], entry)
+	}
+	x.byDomain[domain.Name] = entries
+	x.sorted = false
+}
+
+func (x *SearchIndex) Remove(domainName DomainName) {
+	for _, entry := range x.byDomain[domainName] {
+		x.byName[entry.name] = removeSearchEntry(x.byName[entry.name], entry)
+		if len(x.byName[entry.name]) == 0 {
+			delete(x.byName, entry.name)
+		}
+		x.byAddress[entry.host.Address] = removeSearchEntry(x.byAddress[entry.host.Address], entry)
+		if len(x.byAddress[entry.host.Address]) == 0 {
+			delete(x.byAddress, entry.host.Address)
+		}
+	}
+	delete(x.byDomain, domainName)
+	x.sorted = false
+}
+
+func removeSearchEntry(entries []*searchEntry, target *searchEntry) []*searchEntry {
+	var result []*searchEntry
+	for _, e := range entries {
+		if e != target {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+func (x *SearchIndex) sort() {
+	if x.sorted {
+		return
+	}
+
+	x.sortedByName = nil
+	x.sortedByAddress = nil
+	for _, entries := range x.byDomain {
+		for _, e := range entries {
+			x.sortedByName = append(x.sortedByName, e)
+			if e.ip != nil {
+				x.sortedByAddress = append(x.sortedByAddress, e)
+			}
+		}
+	}
+	sort.Slice(x.sortedByName, func(i, j int) bool { return x.sortedByName[i].name < x.sortedByName[j].name })
+	sort.Slice(x.sortedByAddress, func(i, j int) bool {
+		return bytes.Compare(x.sortedByAddress[i].ip, x.sortedByAddress[j].ip) < 0
+	})
+	x.sorted = true
+}
+
+// candidates returns the entries matching the address, or the name if the address is not given.
+func (x *SearchIndex) candidates(search *HostSearch) []*searchEntry {
+	if search.network != nil {
+		x.sort()
+		first, last := networkRange(search.network)
+		first, last = first.To16(), last.To16()
+		start := sort.Search(len(x.sortedByAddress), func(i int) bool {
+			return bytes.Compare(x.sortedByAddress[i].ip, first) >= 0
+		})
+
+		var entries []*searchEntry
+		for i := start; i < len(x.sortedByAddress) && bytes.Compare(x.sortedByAddress[i].ip, last) <= 0; i++ {
+			entries = append(entries, x.sortedByAddress[i])
+		}
+		return entries
+	}
+
+	if search.Address != "" {
+		return x.byAddress[search.Address]
+	}
+
+	if !search.namePrefix {
+		return x.byName[search.Name]
+	}
+
+	x.sort()
+	start := sort.Search(len(x.sortedByName), func(i int) bool { return x.sortedByName[i].name >= search.Name })
+	var entries []*searchEntry
+	for i := start; i < len(x.sortedByName) && strings.HasPrefix(x.sortedByName[i].name, search.Name); i++ {
+		entries = append(entries, x.sortedByName[i])
+	}
+	return entries
+}
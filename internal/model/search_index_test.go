@@ -0,0 +1,97 @@
+package model
+
+import (
+	"testing"
+)
+
+func newSearchTestConf(t *testing.T) *CoreDNSConf {
+	hogeInfo := `# DomainUUID: 3e8fc6b1-0a93-4c57-9f2b-95d3e66a66e0
+# Tenats:
+#   - df397e50-8006-450e-b18b-5c5bd940baff
+172.21.1.1  hogeserver1.hogehoge.hoge  # 5b9ea8eb-5ce5-422a-9d70-37d25fa896ae
+172.21.1.2  hogeserver2.hogehoge.hoge  # f0c5edcd-3b18-4c26-a8e1-3f3495504dd6
+fd00::1  hogeserver3.hogehoge.hoge  # eac1b92b-31b2-4b7d-a26b-fd487b6669ca
+`
+	fugaInfo := `# DomainUUID: 1cf4caeb-f474-44d1-8eda-b9596cc22f00
+# Tenats:
+#   - 02c03bd4-fe2e-45f2-85b6-b535af15215d
+172.21.1.1  fugaserver1.fugafuga.fuga  # 9d4b0c4e-7f43-4f6e-a1a4-6f0e4a4c1b71
+`
+	hoge, err := NewDomain("hogehoge.hoge", hogeInfo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fuga, err := NewDomain("fugafuga.fuga", fugaInfo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewCoreDNSConf([]*Domain{hoge, fuga})
+}
+
+func TestSearchHosts(t *testing.T) {
+	conf := newSearchTestConf(t)
+	tenant := Uuid("df397e50-8006-450e-b18b-5c5bd940baff")
+
+	expects := map[[2]string]int{
+		{"", "172.21.1.1"}:                1,
+		{"", "172.21.0.0/16"}:             2,
+		{"", "fd00::/8"}:                  1,
+		{"", "FD00:0:0:0::01"}:            1,
+		{"hogeserver1.hogehoge.hoge", ""}: 1,
+		{"HogeServer*", ""}:               3,
+		{"hogeserver*", "172.21.1.2"}:     1,
+		{"hogeserver2*", "172.21.1.1"}:    0,
+		{"fugaserver1.fugafuga.fuga", ""}: 0,
+		{"hogeserver1.hogehoge", ""}:      0,
+	}
+	for params, expect := range expects {
+		search, err := NewHostSearch(params[0], params[1])
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		hits := conf.SearchHosts(search, tenant, false)
+		if len(hits) != expect {
+			t.Errorf("search result is missmatched: %s %s: %d", params[0], params[1], len(hits))
+		}
+	}
+
+	search, _ := NewHostSearch("", "172.21.1.1")
+	hits := conf.SearchHosts(search, tenant, true)
+	if len(hits) != 2 || hits[0].Domain.Name != "fugafuga.fuga" {
+		t.Error("search result of every tenant is missmatched")
+	}
+
+	invalidParams := [][2]string{{"", ""}, {"*", ""}, {"", "172.21.1"}, {"", "172.21.1.0/33"}}
+	for _, params := range invalidParams {
+		_, err := NewHostSearch(params[0], params[1])
+		if err == nil {
+			t.Error("invalid search is accepted: " + params[0] + " " + params[1])
+		}
+	}
+}
+
+func TestSearchIndexUpdate(t *testing.T) {
+	conf := newSearchTestConf(t)
+	tenant := Uuid("df397e50-8006-450e-b18b-5c5bd940baff")
+	search, _ := NewHostSearch("", "172.21.1.0/24")
+
+	domain, _ := conf.GetByName("hogehoge.hoge")
+	domain = domain.Clone()
+	domain.Hosts = domain.Hosts[1:]
+	conf.Add(domain)
+
+	hits := conf.SearchHosts(search, tenant, false)
+	if len(hits) != 1 || hits[0].Host.Name != "hogeserver2.hogehoge.hoge" {
+		t.Error("index is not updated with the domain")
+	}
+
+	cloned := conf.Clone()
+	cloned.Delete(domain)
+	if len(cloned.SearchHosts(search, tenant, false)) != 0 {
+		t.Error("index is not updated with the deleted domain")
+	}
+	if len(conf.SearchHosts(search, tenant, false)) != 1 {
+		t.Error("original index is changed by its clone")
+	}
+}
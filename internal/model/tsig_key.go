@@ -0,0 +1,9 @@
+package model
+
+// TsigKey is a TSIG key to authenticate DNS messages.
+// Secret is base64 encoded, and Algorithm is like "hmac-sha256.".
+type TsigKey struct {
+	Name      string
+	Algorithm string
+	Secret    string
+}
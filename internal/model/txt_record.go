@@ -0,0 +1,88 @@
+package model
+
+import (
+	"strings"
+)
+
+// txtRecordPrefix starts the header line of a TXT record in the hosts file.
+const txtRecordPrefix = "# TXT: "
+
+// TxtRecord is a TXT record of the domain. CoreDNS doesn't serve it since hosts file can't have it,
+// and it is kept in the header of the hosts file for the ownership registry of external-dns.
+// Name is the owner name without the trailing dot.
+type TxtRecord struct {
+	Name string
+	Text string
+}
+
+func NewTxtRecord(name, text string, domainName DomainName) (*TxtRecord, error) {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	zone := strings.ToLower(domainName.String())
+	if name != zone && !strings.HasSuffix(name, "."+zone) {
+		return nil, NewInvalidParameterGiven("record is out of zone " + zone + ". name: " + name)
+	}
+	if strings.ContainsAny(name, " \t#") {
+		return nil, NewInvalidParameterGiven("invalid TXT record name is specified. name: " + name)
+	}
+
+	if text == "" || strings.ContainsAny(text, "\r\n") {
+		return nil, NewInvalidParameterGiven("invalid TXT record text is specified. name: " + name)
+	}
+	return &TxtRecord{Name: name, Text: text}, nil
+}
+
+// parseTxtRecordLine parses the header line like "# TXT: <name> <text>".
+func parseTxtRecordLine(line string) (*TxtRecord, bool) {
+	fields := strings.SplitN(strings.TrimPrefix(line, txtRecordPrefix), " ", 2)
+	if len(fields) != 2 || fields[0] == "" || fields[1] == "" {
+		return nil, false
+	}
+	return &TxtRecord{Name: fields[0], Text: fields[1]}, true
+}
+
+func (r *TxtRecord) GetTxtRecordInfo() string {
+	return txtRecordPrefix + r.Name + " " + r.Text + "\n"
+}
+
+// PlanTxtRecordChanges returns the TXT records of the domain after the TXT changes are applied in order.
+// It never returns nil records, even when all of them are deleted.
+func PlanTxtRecordChanges(domain *Domain, changes []*RRsetChange) ([]*TxtRecord, error) {
+	records := append(make([]*TxtRecord, 0), domain.TxtRecords...)
+	for _, c := range changes {
+		if c.Type != "TXT" {
+			return nil, NewInvalidParameterGiven(c.Type + " record is not TXT record. name: " + c.Name)
+		}
+
+		var newRecords []*TxtRecord
+		for _, v := range c.Values {
+			record, err := NewTxtRecord(c.Name, v, domain.Name)
+			if err != nil {
+				return nil, err
+			}
+			newRecords = append(newRecords, record)
+		}
+
+		name := strings.ToLower(strings.TrimSuffix(c.Name, "."))
+		kept := make([]*TxtRecord, 0)
+		for _, r := range records {
+			if r.Name != name {
+				kept = append(kept, r)
+			}
+		}
+		records = append(kept, newRecords...)
+	}
+	return records, nil
+}
+
+// EqualTxtRecords tells whether both have the same records in the same order.
+func EqualTxtRecords(a, b []*TxtRecord) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if *a[i] != *b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,101 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestDomainWithTxtRecords(t *testing.T) {
+	domainFileInfo := `# DomainUUID: 3e8fc6b1-0a93-4c57-9f2b-95d3e66a66e0
+# Tenats:
+#   - df397e50-8006-450e-b18b-5c5bd940baff
+# TXT: a-hogeserver1.hogehoge.hoge "heritage=external-dns,external-dns/owner=default,external-dns/resource=service/default/hoge"
+172.21.1.1  hogeserver1.hogehoge.hoge  # 5b9ea8eb-5ce5-422a-9d70-37d25fa896ae
+`
+	domain, err := NewDomain("hogehoge.hoge", domainFileInfo)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if len(domain.TxtRecords) != 1 || domain.TxtRecords[0].Name != "a-hogeserver1.hogehoge.hoge" || domain.TxtRecords[0].Text != `"heritage=external-dns,external-dns/owner=default,external-dns/resource=service/default/hoge"` {
+		t.Error("TXT record is missmatched")
+	}
+	if len(domain.Hosts) != 1 {
+		t.Error("TXT record is read as host")
+	}
+	fileInfo, err := domain.GetFileInfo()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if fileInfo != domainFileInfo {
+		t.Error("hosts file info is missmatched: " + fileInfo)
+	}
+
+	cloned := domain.Clone()
+	cloned.TxtRecords[0].Text = "changed"
+	if domain.TxtRecords[0].Text == "changed" {
+		t.Error("TXT records are not copied")
+	}
+
+	_, err = NewDomain("hogehoge.hoge", "# TXT: a-hogeserver1.hogehoge.hoge\n")
+	if err == nil {
+		t.Error("TXT record without text is accepted")
+	}
+}
+
+func TestPlanTxtRecordChanges(t *testing.T) {
+	domainFileInfo := `# DomainUUID: 3e8fc6b1-0a93-4c57-9f2b-95d3e66a66e0
+# Tenats:
+#   - df397e50-8006-450e-b18b-5c5bd940baff
+# TXT: a-hogeserver1.hogehoge.hoge "heritage=external-dns"
+# TXT: a-hogeserver2.hogehoge.hoge "heritage=external-dns"
+`
+	domain, err := NewDomain("hogehoge.hoge", domainFileInfo)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	records, err := PlanTxtRecordChanges(domain, []*RRsetChange{
+		{Name: "a-hogeserver1.hogehoge.hoge.", Type: "TXT"},
+		{Name: "A-HogeServer3.hogehoge.hoge.", Type: "TXT", Values: []string{`"heritage=external-dns"`, `"other"`}},
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if len(records) != 3 || records[0].Name != "a-hogeserver2.hogehoge.hoge" || records[1].Name != "a-hogeserver3.hogehoge.hoge" || records[2].Text != `"other"` {
+		t.Error("TXT records are missmatched")
+	}
+	if len(domain.TxtRecords) != 2 {
+		t.Error("TXT records of the domain are changed")
+	}
+
+	records, err = PlanTxtRecordChanges(domain, []*RRsetChange{
+		{Name: "a-hogeserver1.hogehoge.hoge.", Type: "TXT"},
+		{Name: "a-hogeserver2.hogehoge.hoge.", Type: "TXT"},
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if records == nil || len(records) != 0 {
+		t.Error("all TXT records are not deleted")
+	}
+
+	invalidChanges := []*RRsetChange{
+		{Name: "a-hogeserver1.fugafuga.fuga.", Type: "TXT", Values: []string{`"text"`}},
+		{Name: "hogeserver1.hogehoge.hoge.", Type: "A", Values: []string{"172.21.1.1"}},
+		{Name: "a hogeserver1.hogehoge.hoge.", Type: "TXT", Values: []string{`"text"`}},
+		{Name: "a-hogeserver1.hogehoge.hoge.", Type: "TXT", Values: []string{"text\n172.21.1.1 hogeserver1.hogehoge.hoge"}},
+		{Name: "a-hogeserver1.hogehoge.hoge.", Type: "TXT", Values: []string{""}},
+	}
+	for _, c := range invalidChanges {
+		_, err = PlanTxtRecordChanges(domain, []*RRsetChange{c})
+		if err == nil {
+			t.Error("invalid change is accepted. name: " + c.Name + ", type: " + c.Type)
+		}
+	}
+}
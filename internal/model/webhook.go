@@ -0,0 +1,77 @@
+package model
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetWebhooks returns the webhooks which every event is sent to.
+// They are given as comma separated URLs with WEBHOOK_URLS, and signed with WEBHOOK_SECRET.
+func GetWebhooks() ([]*Webhook, error) {
+	var webhooks []*Webhook
+	for _, u := range strings.Split(os.Getenv("WEBHOOK_URLS"), ",") {
+		u = strings.TrimSpace(u)
+		if u == "" {
+			continue
+		}
+		if !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+			return nil, NewInvalidParameterGiven("invalid WEBHOOK_URLS is specified. url: " + u)
+		}
+		webhooks = append(webhooks, &Webhook{Url: u, Secret: os.Getenv("WEBHOOK_SECRET")})
+	}
+
+	if len(webhooks) > 0 && os.Getenv("WEBHOOK_SECRET") == "" {
+		return nil, NewInvalidParameterGiven("WEBHOOK_SECRET is not specified")
+	}
+	return webhooks, nil
+}
+
+// GetWebhookMaxRetries returns how many times an event is resent when the webhook fails.
+func GetWebhookMaxRetries() (int, error) {
+	retriesConf := os.Getenv("WEBHOOK_MAX_RETRIES")
+	if retriesConf == "" {
+		return 5, nil
+	}
+
+	retries, err := strconv.Atoi(retriesConf)
+	if err != nil || retries < 0 {
+		return 0, NewInvalidParameterGiven("invalid WEBHOOK_MAX_RETRIES is specified. retries: " + retriesConf)
+	}
+	return retries, nil
+}
+
+// GetWebhookDeadLetterPath returns the file the events which can't be sent are appended to.
+// They are written to the log when it is not set.
+func GetWebhookDeadLetterPath() string {
+	return os.Getenv("WEBHOOK_DEAD_LETTER_PATH")
+}
+
+type Webhook struct {
+	Url    string
+	Secret string
+}
+
+// Sign returns the HMAC-SHA256 signature of the body, like "sha256=<hex>".
+func (w *Webhook) Sign(body []byte) string {
+	mac := hmac.New(sha256.New, []byte(w.Secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// DeadLetter is the event which could not be sent to the webhook.
+type DeadLetter struct {
+	Time     time.Time `json:"time"`
+	Url      string    `json:"url"`
+	Attempts int       `json:"attempts"`
+	Error    string    `json:"error"`
+	Event    *Event    `json:"event"`
+}
+
+func NewDeadLetter(webhook *Webhook, event *Event, attempts int, err error) *DeadLetter {
+	return &DeadLetter{Time: time.Now().UTC(), Url: webhook.Url, Attempts: attempts, Error: err.Error(), Event: event}
+}
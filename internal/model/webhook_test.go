@@ -0,0 +1,55 @@
+package model
+
+import (
+	"os"
+	"testing"
+)
+
+func TestWebhookSign(t *testing.T) {
+	webhook := &Webhook{Url: "https://example.com/hook", Secret: "secret"}
+
+	// echo -n '{"type":"HostAdded"}' | openssl dgst -sha256 -hmac secret
+	expect := "sha256=b0c905acced749bee092001293ef158228893282dad7859eb9adc3efca58d24b"
+	signature := webhook.Sign([]byte(`{"type":"HostAdded"}`))
+	if signature != expect {
+		t.Error("signature is missmatched. signature: " + signature)
+	}
+
+	other := &Webhook{Url: "https://example.com/hook", Secret: "other"}
+	if other.Sign([]byte(`{"type":"HostAdded"}`)) == signature {
+		t.Error("signature with another secret is same")
+	}
+}
+
+func TestGetWebhooks(t *testing.T) {
+	defer os.Unsetenv("WEBHOOK_URLS")
+	defer os.Unsetenv("WEBHOOK_SECRET")
+
+	os.Setenv("WEBHOOK_URLS", "")
+	webhooks, err := GetWebhooks()
+	if err != nil || len(webhooks) != 0 {
+		t.Error("webhooks are returned without WEBHOOK_URLS")
+	}
+
+	os.Setenv("WEBHOOK_URLS", "https://example.com/hook, http://example.net/hook")
+	_, err = GetWebhooks()
+	if err == nil {
+		t.Error("webhooks are returned without WEBHOOK_SECRET")
+	}
+
+	os.Setenv("WEBHOOK_SECRET", "secret")
+	webhooks, err = GetWebhooks()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(webhooks) != 2 || webhooks[1].Url != "http://example.net/hook" || webhooks[1].Secret != "secret" {
+		t.Error("webhooks are missmatched")
+	}
+
+	os.Setenv("WEBHOOK_URLS", "ftp://example.com/hook")
+	_, err = GetWebhooks()
+	if err == nil {
+		t.Error("webhook without http is accepted")
+	}
+}
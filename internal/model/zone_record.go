@@ -0,0 +1,72 @@
+package model
+
+import "strings"
+
+// ZoneRecord is a resource record read from a DNS zone.
+// Name is the owner name without the trailing dot.
+type ZoneRecord struct {
+	Name  string
+	Type  string
+	Value string
+}
+
+// SkippedZoneRecord is a zone record which can not be stored as a host, with the reason.
+type SkippedZoneRecord struct {
+	ZoneRecord
+	Reason string
+}
+
+// NewDomainFromZoneRecords creates a new domain which has A and AAAA records in the zone as hosts.
+// Other records are returned as skipped records, because hosts file can not represent them.
+func NewDomainFromZoneRecords(name string, tenantList []string, records []*ZoneRecord) (*Domain, []*SkippedZoneRecord, error) {
+	domain, err := NewOriginalDomain(name, tenantList)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	zone := strings.ToLower(domain.Name.String())
+	names := map[string]bool{}
+	addresses := map[string]bool{}
+	var skipped []*SkippedZoneRecord
+	for _, r := range records {
+		recordName := strings.ToLower(strings.TrimSuffix(r.Name, "."))
+		if recordName != zone && !strings.HasSuffix(recordName, "."+zone) {
+			skipped = append(skipped, &SkippedZoneRecord{*r, "record is out of zone " + zone})
+			continue
+		}
+
+		switch r.Type {
+		case "A", "AAAA":
+		case "SOA", "NS":
+			if recordName == zone {
+				skipped = append(skipped, &SkippedZoneRecord{*r, "zone apex record is not stored in hosts file"})
+				continue
+			}
+			fallthrough
+		default:
+			skipped = append(skipped, &SkippedZoneRecord{*r, r.Type + " record is not supported in hosts file"})
+			continue
+		}
+
+		host, err := NewOriginalHost(recordName, r.Value, domain.Name)
+		if err != nil {
+			skipped = append(skipped, &SkippedZoneRecord{*r, err.Error()})
+			continue
+		}
+
+		if names[host.Name] {
+			skipped = append(skipped, &SkippedZoneRecord{*r, "hostname has multiple addresses. only the first one is imported"})
+			continue
+		}
+		if addresses[host.Address] {
+			skipped = append(skipped, &SkippedZoneRecord{*r, "address is already assigned to another hostname"})
+			continue
+		}
+
+		names[host.Name] = true
+		addresses[host.Address] = true
+		domain.Hosts = append(domain.Hosts, host)
+	}
+
+	return domain, skipped, nil
+}
@@ -0,0 +1,33 @@
+package model
+
+import "testing"
+
+func TestNewDomainFromZoneRecords(t *testing.T) {
+	name := "hogehoge.hoge"
+	tenant := []string{"5cdc62c5-a110-4d89-9cdd-5e19f1983f0f"}
+	records := []*ZoneRecord{
+		{Name: "hogehoge.hoge.", Type: "SOA", Value: "ns1.hogehoge.hoge. admin.hogehoge.hoge. 1 3600 600 86400 3600"},
+		{Name: "hogehoge.hoge.", Type: "NS", Value: "ns1.hogehoge.hoge."},
+		{Name: "ns1.hogehoge.hoge.", Type: "A", Value: "172.21.1.1"},
+		{Name: "hogeserver1.hogehoge.hoge.", Type: "A", Value: "172.21.1.2"},
+		{Name: "hogeserver1.hogehoge.hoge.", Type: "A", Value: "172.21.1.3"},
+		{Name: "hogeserver2.hogehoge.hoge.", Type: "AAAA", Value: "fd00::2"},
+		{Name: "www.hogehoge.hoge.", Type: "CNAME", Value: "hogeserver1.hogehoge.hoge."},
+		{Name: "fugaserver.fugafuga.fuga.", Type: "A", Value: "172.21.1.4"},
+	}
+
+	domain, skipped, err := NewDomainFromZoneRecords(name, tenant, records)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if len(domain.Hosts) != 3 {
+		t.Error("A and AAAA records are not imported as hosts")
+	}
+	if domain.Hosts[2].Name != "hogeserver2.hogehoge.hoge" || domain.Hosts[2].Address != "fd00::2" {
+		t.Error("AAAA record is missmatched")
+	}
+	if len(skipped) != 5 {
+		t.Error("unsupported records are not reported")
+	}
+}
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// GetZoneTransferServers returns the DNS servers which domains can be imported from with zone transfer.
+// They are given as comma separated `host[:port]` with ZONE_TRANSFER_SERVERS, and the port is 53 by default.
+// Zone transfer is disabled if it is not set, since the API server connects to the server given by the request.
+func GetZoneTransferServers() ([]string, error) {
+	var servers []string
+	for _, s := range strings.Split(os.Getenv("ZONE_TRANSFER_SERVERS"), ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+
+		server, err := NormalizeTransferServer(s)
+		if err != nil {
+			return nil, NewInvalidParameterGiven("invalid ZONE_TRANSFER_SERVERS is specified. server: " + s)
+		}
+		servers = append(servers, server)
+	}
+	return servers, nil
+}
+
+// NormalizeTransferServer returns the server as `host:port` with the default port 53,
+// so that the same server is written in one way.
+func NormalizeTransferServer(server string) (string, error) {
+	host, port, err := net.SplitHostPort(server)
+	if err != nil {
+		host = strings.TrimSuffix(strings.TrimPrefix(server, "["), "]")
+		port = "53"
+	}
+
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if ip := net.ParseIP(host); ip != nil {
+		host = ip.String()
+	}
+	if host == "" || strings.ContainsAny(host, "[]/ ") {
+		return "", NewInvalidParameterGiven("invalid zone transfer server is specified. server: " + server)
+	}
+
+	portNumber, err := strconv.Atoi(port)
+	if err != nil || portNumber <= 0 || portNumber > 65535 {
+		return "", NewInvalidParameterGiven("invalid zone transfer server is specified. server: " + server)
+	}
+	return net.JoinHostPort(host, strconv.Itoa(portNumber)), nil
+}
+
+// AllowZoneTransfer returns the normalized server if it is in ZONE_TRANSFER_SERVERS.
+func AllowZoneTransfer(server string) (string, error) {
+	servers, err := GetZoneTransferServers()
+	if err != nil {
+		return "", err
+	}
+
+	server, err = NormalizeTransferServer(server)
+	if err != nil {
+		return "", err
+	}
+	for _, s := range servers {
+		if s == server {
+			return server, nil
+		}
+	}
+	return "", NewInvalidParameterGiven("zone transfer from the server is not allowed. server: " + server)
+}
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"os"
+	"testing"
+)
+
+func TestNormalizeTransferServer(t *testing.T) {
+	expects := map[string]string{
+		"192.0.2.53":          "192.0.2.53:53",
+		"192.0.2.53:5353":     "192.0.2.53:5353",
+		"NS1.Hogehoge.Hoge.":  "ns1.hogehoge.hoge:53",
+		"2001:db8::0053":      "[2001:db8::53]:53",
+		"[2001:db8::53]":      "[2001:db8::53]:53",
+		"[2001:db8::53]:5353": "[2001:db8::53]:5353",
+	}
+	for server, expect := range expects {
+		normalized, err := NormalizeTransferServer(server)
+		if err != nil || normalized != expect {
+			t.Errorf("server is missmatched: %s %s", server, normalized)
+		}
+	}
+
+	for _, server := range []string{"", ":53", "192.0.2.53:0", "192.0.2.53:hoge", "192.0.2.53:65536", "hoge/fuga"} {
+		_, err := NormalizeTransferServer(server)
+		if err == nil {
+			t.Error("invalid server is accepted: " + server)
+		}
+	}
+}
+
+func TestAllowZoneTransfer(t *testing.T) {
+	defer os.Unsetenv("ZONE_TRANSFER_SERVERS")
+
+	os.Setenv("ZONE_TRANSFER_SERVERS", "")
+	_, err := AllowZoneTransfer("192.0.2.53")
+	if err == nil {
+		t.Error("zone transfer is allowed without ZONE_TRANSFER_SERVERS")
+	}
+
+	os.Setenv("ZONE_TRANSFER_SERVERS", "192.0.2.53, ns1.hogehoge.hoge:5353")
+	for _, server := range []string{"192.0.2.53", "192.0.2.53:53", "NS1.hogehoge.hoge:5353"} {
+		_, err := AllowZoneTransfer(server)
+		if err != nil {
+			t.Error("allowed server is refused: " + server)
+		}
+	}
+	for _, server := range []string{"192.0.2.54", "192.0.2.53:5353", "ns1.hogehoge.hoge", "127.0.0.1:8080"} {
+		_, err := AllowZoneTransfer(server)
+		if _, ok := err.(*InvalidParameterGiven); !ok {
+			t.Error("server which is not allowed is accepted: " + server)
+		}
+	}
+
+	os.Setenv("ZONE_TRANSFER_SERVERS", "192.0.2.53:hoge")
+	_, err = GetZoneTransferServers()
+	if err == nil {
+		t.Error("invalid ZONE_TRANSFER_SERVERS is accepted")
+	}
+}
@@ -0,0 +1,11 @@
+package usecase
+
+// IDiagnosisRepository reads and writes the raw files,
+// since the files to diagnose may not be loaded by IFilesystemRepository.
+type IDiagnosisRepository interface {
+	GetDomainFileNameList() ([]string, error)
+	LoadDomainFileInfo(fileName string) (string, error)
+	WriteDomainFileInfo(fileName, fileInfo string) error
+	LoadConfFileInfo() (string, error)
+	WriteConfFileInfo(confInfo string) error
+}
@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"path/filepath"
+	"sort"
+
+	"coredns_api/internal/model"
+)
+
+// DoctorInteractor checks the hosts files and the Corefile without loading them into the cache,
+// so it works even when the API server fails to start.
+type DoctorInteractor struct {
+	repository IDiagnosisRepository
+}
+
+func NewDoctorInteractor(repo IDiagnosisRepository) *DoctorInteractor {
+	return &DoctorInteractor{repo}
+}
+
+// Diagnose reports the problems of the files. When fix is true, the safe repairs are written.
+// The API server has to be stopped while the files are fixed.
+func (i *DoctorInteractor) Diagnose(fix bool) (*model.Diagnosis, error) {
+	fileNames, err := i.repository.GetDomainFileNameList()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(fileNames)
+
+	diagnosis := model.NewDiagnosis()
+	for _, fileName := range fileNames {
+		path := filepath.Join(model.GetHostsDir(), fileName)
+		fileInfo, err := i.repository.LoadDomainFileInfo(fileName)
+		if err != nil {
+			return nil, err
+		}
+
+		fixed := diagnosis.CheckDomainFile(path, fileName, fileInfo)
+		if !fix || !diagnosis.NeedsFix(path) {
+			continue
+		}
+
+		err = i.repository.WriteDomainFileInfo(fileName, fixed)
+		if err != nil {
+			return nil, err
+		}
+		diagnosis.MarkFixed(path)
+	}
+
+	confPath := model.GetConfPath()
+	confInfo, err := i.repository.LoadConfFileInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	diagnosis.CheckCorefile(confPath, confInfo, fileNames)
+	if !fix || !diagnosis.NeedsFix(confPath) {
+		return diagnosis, nil
+	}
+
+	fixedConf, err := diagnosis.FixedCorefile()
+	if err != nil {
+		return nil, err
+	}
+	err = i.repository.WriteConfFileInfo(fixedConf)
+	if err != nil {
+		return nil, err
+	}
+	diagnosis.MarkFixed(confPath)
+
+	return diagnosis, nil
+}
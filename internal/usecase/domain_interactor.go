@@ -1,22 +1,58 @@
 package usecase
 
-import "coredns_api/internal/model"
+import (
+	"log/slog"
+	"time"
+
+	"coredns_api/internal/model"
+)
 
 type DomainInteractor struct {
 	fsRepository IFilesystemRepository
+	events       IEventBus
+	metrics      IMetrics
+}
+
+func NewDomainInteractor(fRepo IFilesystemRepository, events IEventBus, metrics IMetrics) *DomainInteractor {
+	return &DomainInteractor{fsRepository: fRepo, events: events, metrics: metrics}
 }
 
-func NewDomainInteractor(fRepo IFilesystemRepository) *DomainInteractor {
-	r := &DomainInteractor{fsRepository: fRepo}
-	r.fsRepository.Initialize()
-	return r
+// WithLogger returns a DomainInteractor which writes the logs with the logger, like the one of a request.
+func (i *DomainInteractor) WithLogger(logger *slog.Logger) *DomainInteractor {
+	return &DomainInteractor{fsRepository: i.fsRepository.WithLogger(logger), events: i.events, metrics: i.metrics}
 }
 
-func (i *DomainInteractor) Add(domain *model.Domain) error {
+// Stage returns a DomainInteractor which works against a staged repository,
+// and the staged repository to get the changes from. It publishes no events and records no metrics.
+func (i *DomainInteractor) Stage() (*DomainInteractor, IStagedRepository) {
+	staged := i.fsRepository.Stage()
+	return &DomainInteractor{fsRepository: staged}, staged
+}
+
+// IfMatch returns a DomainInteractor which changes the domain only when it is at any of the revisions.
+// DomainRevisionMismatchError is returned otherwise.
+func (i *DomainInteractor) IfMatch(revisions []uint64) *DomainInteractor {
+	return &DomainInteractor{fsRepository: newRevisionRepository(i.fsRepository, revisions), events: i.events, metrics: i.metrics}
+}
+
+func (i *DomainInteractor) Add(domain *model.Domain) (err error) {
+	defer observe(i.metrics, "domain_add", time.Now(), &err)
+
 	i.fsRepository.Lock()
 	defer i.fsRepository.UnLock()
 
-	err := i.fsRepository.WriteDomainFile(domain)
+	domains, err := i.fsRepository.LoadAllDomains()
+	if err != nil {
+		return err
+	}
+
+	for _, d := range domains {
+		if d.Name == domain.Name {
+			return NewDomainDuplicatedError(domain.Name.String())
+		}
+	}
+
+	err = i.fsRepository.WriteDomainFile(domain)
 	if err != nil {
 		return err
 	}
@@ -27,6 +63,7 @@ func (i *DomainInteractor) Add(domain *model.Domain) error {
 		return err
 	}
 
+	publish(i.events, model.NewDomainEvent(model.EventDomainAdded, nil, domain))
 	return nil
 }
 
@@ -42,7 +79,9 @@ func (i *DomainInteractor) Get(domainUuid model.Uuid, requestTenantUuid model.Uu
 	return targetDomain, nil
 }
 
-func (i *DomainInteractor) Update(domainUuid model.Uuid, requestTenantUuid model.Uuid, tenantUuidList []model.Uuid) (*model.Domain, error) {
+func (i *DomainInteractor) Update(domainUuid model.Uuid, requestTenantUuid model.Uuid, tenantUuidList []model.Uuid) (_ *model.Domain, err error) {
+	defer observe(i.metrics, "domain_update", time.Now(), &err)
+
 	i.fsRepository.Lock()
 	defer i.fsRepository.UnLock()
 
@@ -51,26 +90,40 @@ func (i *DomainInteractor) Update(domainUuid model.Uuid, requestTenantUuid model
 		return nil, err
 	}
 
+	before := domain.Clone()
 	err = domain.UpdateTenants(requestTenantUuid, tenantUuidList)
 	if err != nil {
 		return nil, err
 	}
 
+	// The Corefile is checked before any file is written, and the former tenants are restored on failure,
+	// since the domain is the one in the cache.
+	err = i.fsRepository.ValidateConfCache()
+	if err != nil {
+		domain.Tenants = before.Tenants
+		return nil, err
+	}
+
 	err = i.fsRepository.WriteDomainFile(domain)
 	if err != nil {
+		domain.Tenants = before.Tenants
 		return nil, err
 	}
 
 	err = i.fsRepository.WriteConfCache()
 	if err != nil {
-		_ = i.fsRepository.DeleteDomainFile(domain)
+		domain.Tenants = before.Tenants
+		_ = i.fsRepository.WriteDomainFile(domain)
 		return nil, err
 	}
 
+	publish(i.events, model.NewDomainEvent(model.EventTenantsChanged, before, domain))
 	return domain, nil
 }
 
-func (i *DomainInteractor) Delete(domainUuid model.Uuid, requestTenantUuid model.Uuid) error {
+func (i *DomainInteractor) Delete(domainUuid model.Uuid, requestTenantUuid model.Uuid) (err error) {
+	defer observe(i.metrics, "domain_delete", time.Now(), &err)
+
 	i.fsRepository.Lock()
 	defer i.fsRepository.UnLock()
 
@@ -90,6 +143,10 @@ func (i *DomainInteractor) Delete(domainUuid model.Uuid, requestTenantUuid model
 		return err
 	}
 
+	for _, host := range domain.Hosts {
+		publish(i.events, model.NewHostEvent(model.EventHostDeleted, domain, host, nil))
+	}
+	publish(i.events, model.NewDomainEvent(model.EventDomainDeleted, domain, nil))
 	return nil
 }
 
@@ -99,3 +156,35 @@ func (i *DomainInteractor) GetDomainsList(requestTenantUuid model.Uuid) ([]*mode
 
 	return i.fsRepository.LoadTenantAllDomains(requestTenantUuid)
 }
+
+// GetAllDomainsList returns the domains of every tenant, and the degraded ones.
+func (i *DomainInteractor) GetAllDomainsList() ([]*model.Domain, []*model.DegradedDomain, error) {
+	i.fsRepository.Lock()
+	defer i.fsRepository.UnLock()
+
+	domains, err := i.fsRepository.LoadAllDomains()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	degraded, err := i.fsRepository.LoadAllDegradedDomains()
+	if err != nil {
+		return nil, nil, err
+	}
+	return domains, degraded, nil
+}
+
+// GetDegradedDomainsList returns the domains whose hosts file can't be loaded.
+func (i *DomainInteractor) GetDegradedDomainsList(requestTenantUuid model.Uuid) ([]*model.DegradedDomain, error) {
+	i.fsRepository.Lock()
+	defer i.fsRepository.UnLock()
+
+	return i.fsRepository.LoadTenantDegradedDomains(requestTenantUuid)
+}
+
+func (i *DomainInteractor) GetDegraded(domainUuid model.Uuid, requestTenantUuid model.Uuid) (*model.DegradedDomain, error) {
+	i.fsRepository.Lock()
+	defer i.fsRepository.UnLock()
+
+	return i.fsRepository.GetDegradedDomainByUuid(domainUuid, requestTenantUuid)
+}
@@ -0,0 +1,112 @@
+package usecase_test
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"coredns_api/internal/model"
+	"coredns_api/internal/usecase"
+)
+
+// confFailingRepository fails to validate or to write the Corefile.
+type confFailingRepository struct {
+	usecase.IFilesystemRepository
+	validateErr error
+	writeErr    error
+}
+
+func (r *confFailingRepository) ValidateConfCache() error {
+	if r.validateErr != nil {
+		return r.validateErr
+	}
+	return r.IFilesystemRepository.ValidateConfCache()
+}
+
+func (r *confFailingRepository) WriteConfCache() error {
+	if r.writeErr != nil {
+		return r.writeErr
+	}
+	return r.IFilesystemRepository.WriteConfCache()
+}
+
+func TestAddDuplicated(t *testing.T) {
+	fsRepository := newTestRepository(t)
+	interactor := usecase.NewDomainInteractor(fsRepository, nil, nil)
+	domain := addTestDomain(t, fsRepository, "hogehoge.hoge", testTenant)
+
+	// The domain name is refused even for another tenant, since CoreDNS serves one zone for a name.
+	duplicated, err := model.NewOriginalDomain("hogehoge.hoge", []string{testOtherTenant})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = interactor.Add(duplicated)
+	if _, ok := err.(*usecase.DomainDuplicatedError); !ok {
+		t.Error("duplicated domain name is accepted")
+	}
+
+	domains, _, err := interactor.GetAllDomainsList()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(domains) != 1 || domains[0].Uuid != domain.Uuid {
+		t.Error("domains are changed by the duplicated domain")
+	}
+
+	_, err = interactor.Get(duplicated.Uuid, testOtherTenant)
+	if _, ok := err.(*model.DomainNotFoundError); !ok {
+		t.Error("duplicated domain is added")
+	}
+}
+
+func TestUpdateConfFailed(t *testing.T) {
+	fsRepository := newTestRepository(t)
+	domain := addTestDomain(t, fsRepository, "hogehoge.hoge", testTenant)
+	hostsFile := model.GetHostsFilePath(domain.Name)
+
+	failures := []*confFailingRepository{
+		{IFilesystemRepository: fsRepository, validateErr: errors.New("invalid Corefile")},
+		{IFilesystemRepository: fsRepository, writeErr: errors.New("failed to write Corefile")},
+	}
+	for _, repository := range failures {
+		original, err := os.ReadFile(hostsFile)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		interactor := usecase.NewDomainInteractor(repository, nil, nil)
+		_, err = interactor.Update(domain.Uuid, testTenant, []model.Uuid{testTenant, testOtherTenant})
+		if err == nil {
+			t.Error("tenants are updated without the Corefile")
+			continue
+		}
+
+		// The domain is kept with the former tenants, instead of being deleted.
+		gotDomain, err := interactor.Get(domain.Uuid, testTenant)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if len(gotDomain.Tenants) != 1 || gotDomain.Tenants[0] != testTenant {
+			t.Errorf("tenants are not restored: %v", gotDomain.Tenants)
+		}
+
+		fileInfo, err := os.ReadFile(hostsFile)
+		if err != nil {
+			t.Errorf("hosts file is deleted: %v", err)
+			continue
+		}
+		if strings.Contains(string(fileInfo), testOtherTenant) {
+			t.Error("hosts file keeps the updated tenants")
+		}
+
+		// Nothing is written when the Corefile is invalid.
+		if repository.validateErr != nil && string(fileInfo) != string(original) {
+			t.Error("hosts file is written with the invalid Corefile")
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package usecase
 
+import "strconv"
+
 // error status with HTTP 500
 type IsNotLockedError struct {
 	err string
@@ -25,3 +27,43 @@ func NewHostDuplicatedError(param, value string) error {
 func (e *HostDuplicatedError) Error() string {
 	return e.err
 }
+
+// error status with HTTP 400
+type HostLineError struct {
+	Line int
+	Err  error
+}
+
+func NewHostLineError(line int, err error) *HostLineError {
+	return &HostLineError{Line: line, Err: err}
+}
+
+func (e *HostLineError) Error() string {
+	return "line " + strconv.Itoa(e.Line) + ": " + e.Err.Error()
+}
+
+// error status with HTTP 400
+type HostImportError struct {
+	err string
+}
+
+func NewHostImportError(count int) error {
+	return &HostImportError{err: "host list is not imported because of " + strconv.Itoa(count) + " invalid lines"}
+}
+
+func (e *HostImportError) Error() string {
+	return e.err
+}
+
+// error status with HTTP 400
+type DomainDuplicatedError struct {
+	err string
+}
+
+func NewDomainDuplicatedError(name string) error {
+	return &DomainDuplicatedError{err: "specified domain is already registered. 'domain: " + name + "'"}
+}
+
+func (e *DomainDuplicatedError) Error() string {
+	return e.err
+}
@@ -0,0 +1,25 @@
+package usecase
+
+import "coredns_api/internal/model"
+
+// IEventBus delivers the events published by the interactors to the subscribers.
+type IEventBus interface {
+	Publish(event *model.Event)
+	Subscribe(size int) IEventSubscription
+}
+
+// IEventSubscription receives the published events until it is closed.
+// Events is closed when more than size events are not received yet,
+// so that a slow subscriber never blocks the publishers.
+type IEventSubscription interface {
+	Events() <-chan *model.Event
+	Close()
+}
+
+// publish sends the event unless the interactor works against a staged repository,
+// which never writes the changes.
+func publish(events IEventBus, event *model.Event) {
+	if events != nil {
+		events.Publish(event)
+	}
+}
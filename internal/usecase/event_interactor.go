@@ -0,0 +1,44 @@
+package usecase
+
+import "coredns_api/internal/model"
+
+const eventStreamSize = 256
+
+type EventInteractor struct {
+	events IEventBus
+}
+
+func NewEventInteractor(events IEventBus) *EventInteractor {
+	return &EventInteractor{events}
+}
+
+// Subscribe returns the subscription of the events to stream them to a client.
+func (i *EventInteractor) Subscribe() IEventSubscription {
+	return i.events.Subscribe(eventStreamSize)
+}
+
+// CanReceive tells whether the tenant can receive the event.
+// Admin tenants receive the events of every domain.
+func (i *EventInteractor) CanReceive(event *model.Event, requestTenantUuid model.Uuid) bool {
+	return event.IsVisibleTo(requestTenantUuid) || model.IsAdminTenant(requestTenantUuid)
+}
+
+// IsLastFor tells whether the tenant can't receive the events of the domain any more after the event.
+func (i *EventInteractor) IsLastFor(event *model.Event, requestTenantUuid model.Uuid) bool {
+	switch event.Type {
+	case model.EventDomainDeleted:
+		return true
+	case model.EventTenantsChanged:
+		after, ok := event.After.(*model.EventDomain)
+		if !ok || model.IsAdminTenant(requestTenantUuid) {
+			return false
+		}
+		for _, t := range after.Tenants {
+			if t == requestTenantUuid {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"coredns_api/internal/interface/repository"
+	"coredns_api/internal/model"
+	"coredns_api/internal/usecase"
+)
+
+const testThirdTenant = "8b2a4f67-3f5c-4f6e-9b1d-2a7c5e0d9f10"
+
+func TestCanReceiveTenantsChanged(t *testing.T) {
+	t.Setenv("ADMIN_TENANTS", testThirdTenant)
+	interactor := usecase.NewEventInteractor(repository.NewEventBus())
+
+	before, _ := model.NewOriginalDomain("hogehoge.hoge", []string{testTenant, testOtherTenant})
+	after := before.Clone()
+	after.Tenants = []model.Uuid{testTenant}
+	event := model.NewDomainEvent(model.EventTenantsChanged, before, after)
+
+	// The removed tenant receives the change, and it is the last event of the domain for it.
+	if !interactor.CanReceive(event, testTenant) || interactor.IsLastFor(event, testTenant) {
+		t.Error("tenant kept in the domain doesn't receive the change")
+	}
+	if !interactor.CanReceive(event, testOtherTenant) || !interactor.IsLastFor(event, testOtherTenant) {
+		t.Error("tenant removed from the domain doesn't receive the change as the last event")
+	}
+	if !interactor.CanReceive(event, testThirdTenant) || interactor.IsLastFor(event, testThirdTenant) {
+		t.Error("admin tenant doesn't receive the change")
+	}
+
+	t.Setenv("ADMIN_TENANTS", "")
+	if interactor.CanReceive(event, testThirdTenant) {
+		t.Error("tenant out of the domain receives the change")
+	}
+
+	// The added tenant receives the change too.
+	added := model.NewDomainEvent(model.EventTenantsChanged, after, before)
+	if !interactor.CanReceive(added, testOtherTenant) || interactor.IsLastFor(added, testOtherTenant) {
+		t.Error("tenant added to the domain doesn't receive the change")
+	}
+
+	host, _ := model.NewOriginalHost("hogeserver1", "172.21.1.1", after.Name)
+	hostEvent := model.NewHostEvent(model.EventHostAdded, after, nil, host)
+	if !interactor.CanReceive(hostEvent, testTenant) || interactor.CanReceive(hostEvent, testOtherTenant) {
+		t.Error("host event is received by the tenants out of the domain")
+	}
+
+	deleted := model.NewDomainEvent(model.EventDomainDeleted, after, nil)
+	if !interactor.CanReceive(deleted, testTenant) || !interactor.IsLastFor(deleted, testTenant) {
+		t.Error("deleted domain is not the last event")
+	}
+}
+
+func TestPublishEvents(t *testing.T) {
+	fsRepository := newTestRepository(t)
+	events := repository.NewEventBus()
+	domainInteractor := usecase.NewDomainInteractor(fsRepository, events, nil)
+	hostInteractor := usecase.NewHostInteractor(fsRepository, events, nil)
+
+	subscription := events.Subscribe(16)
+	defer subscription.Close()
+
+	domain, _ := model.NewOriginalDomain("hogehoge.hoge", []string{testTenant})
+	err := domainInteractor.Add(domain)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	host, _ := model.NewOriginalHost("hogeserver1", "172.21.1.1", domain.Name)
+	_, err = hostInteractor.Add(host, domain.Uuid, testTenant)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	updated, _ := model.NewHost(host.Uuid, host.Name, "172.21.1.2")
+	err = hostInteractor.Update(updated, domain.Uuid, testTenant)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// Dry runs publish nothing.
+	staged, _ := hostInteractor.Stage()
+	err = staged.Delete(updated, domain.Uuid, testTenant)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	_, err = domainInteractor.Update(domain.Uuid, testTenant, []model.Uuid{testTenant, testOtherTenant})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	err = hostInteractor.Delete(updated, domain.Uuid, testTenant)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	err = domainInteractor.Delete(domain.Uuid, testTenant)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	expected := []model.EventType{
+		model.EventDomainAdded,
+		model.EventHostAdded,
+		model.EventHostUpdated,
+		model.EventTenantsChanged,
+		model.EventHostDeleted,
+		model.EventDomainDeleted,
+	}
+	for _, eventType := range expected {
+		select {
+		case event := <-subscription.Events():
+			if event.Type != eventType || event.DomainUuid != domain.Uuid {
+				t.Error("event is missmatched: " + string(event.Type) + ", expected: " + string(eventType))
+			}
+		default:
+			t.Error("event is not published: " + string(eventType))
+		}
+	}
+
+	select {
+	case event := <-subscription.Events():
+		t.Error("unexpected event is published: " + string(event.Type))
+	default:
+	}
+}
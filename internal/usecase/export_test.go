@@ -0,0 +1,8 @@
+package usecase
+
+import "time"
+
+// SetRetryWait shortens the first wait of the retries not to wait seconds in the tests.
+func (d *WebhookDispatcher) SetRetryWait(wait time.Duration) {
+	d.retryWait = wait
+}
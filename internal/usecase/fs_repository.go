@@ -1,15 +1,53 @@
 package usecase
 
-import "coredns_api/internal/model"
+import (
+	"log/slog"
+
+	"coredns_api/internal/model"
+)
 
 type IFilesystemRepository interface {
 	Initialize()
 	Lock()
 	UnLock()
+	ValidateConfCache() error
 	WriteConfCache() error
 	WriteDomainFile(domain *model.Domain) error
 	LoadTenantAllDomains(requestTenantUuid model.Uuid) ([]*model.Domain, error)
 	LoadAllDomains() ([]*model.Domain, error)
 	GetDomainByUuid(domainUuid model.Uuid, requestTenantUuid model.Uuid) (*model.Domain, error)
 	DeleteDomainFile(domain *model.Domain) error
+	SearchHosts(search *model.HostSearch, requestTenantUuid model.Uuid, allTenants bool) ([]*model.SearchHit, error)
+	LoadTenantDegradedDomains(requestTenantUuid model.Uuid) ([]*model.DegradedDomain, error)
+	LoadAllDegradedDomains() ([]*model.DegradedDomain, error)
+	GetDegradedDomainByUuid(domainUuid model.Uuid, requestTenantUuid model.Uuid) (*model.DegradedDomain, error)
+	LoadReverseZones() ([]*model.ReverseZone, error)
+	WritePoolFile(pool *model.Pool) error
+	DeletePoolFile(pool *model.Pool) error
+	LoadTenantAllPools(requestTenantUuid model.Uuid) ([]*model.Pool, error)
+	GetPoolByUuid(poolUuid model.Uuid, requestTenantUuid model.Uuid) (*model.Pool, error)
+	Stage() IStagedRepository
+	Watch() IDomainWatcher
+	WithLogger(logger *slog.Logger) IFilesystemRepository
+}
+
+// IStagedRepository works against a copy of the domain cache
+// and keeps every file write in memory instead of writing it to disk.
+type IStagedRepository interface {
+	IFilesystemRepository
+	GetChanges() ([]*FileChange, error)
+}
+
+// FileChange is a file which would be changed by a staged repository,
+// with the unified diff from the current file.
+type FileChange struct {
+	Path string
+	Diff string
+}
+
+// IDomainWatcher tells the names of the domains changed on disk.
+// Changed is signaled when there are changes, and TakeChanged returns them only once.
+type IDomainWatcher interface {
+	Changed() <-chan struct{}
+	TakeChanged() []model.DomainName
 }
@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"log/slog"
+
+	"coredns_api/internal/model"
+)
+
+// HealthInteractor checks whether the API server can change the domains,
+// and whether CoreDNS serves the hosts written by it.
+type HealthInteractor struct {
+	fsRepository     IFilesystemRepository
+	healthRepository IHealthRepository
+}
+
+func NewHealthInteractor(fRepo IFilesystemRepository, hRepo IHealthRepository) *HealthInteractor {
+	return &HealthInteractor{fsRepository: fRepo, healthRepository: hRepo}
+}
+
+// WithLogger returns a HealthInteractor which writes the logs with the logger, like the one of a request.
+func (i *HealthInteractor) WithLogger(logger *slog.Logger) *HealthInteractor {
+	return &HealthInteractor{fsRepository: i.fsRepository.WithLogger(logger), healthRepository: i.healthRepository}
+}
+
+// CheckReadiness checks the dependencies. CoreDNS is checked only when the probe is given.
+func (i *HealthInteractor) CheckReadiness(probe *model.ReadinessProbe) *model.Readiness {
+	readiness := model.NewReadiness()
+	readiness.Add("hosts_dir", i.healthRepository.CheckHostsDirWritable())
+	readiness.Add("conf_path", i.healthRepository.CheckConfPathWritable())
+
+	cacheErr := i.healthRepository.CheckCache()
+	readiness.Add("cache", cacheErr)
+
+	if probe != nil {
+		readiness.Add("coredns", i.checkCanary(probe))
+	}
+	return readiness
+}
+
+// checkCanary asks CoreDNS for the canary host. Nothing is written, so the checks never change the domains.
+func (i *HealthInteractor) checkCanary(probe *model.ReadinessProbe) error {
+	addresses, err := i.healthRepository.LookupCanary(probe)
+	if err != nil {
+		return err
+	}
+
+	for _, address := range addresses {
+		if address == model.CanaryAddress {
+			return nil
+		}
+	}
+	return model.NewServerSideError(probe.Server + " doesn't answer the canary " + probe.CanaryFqdn())
+}
+
+// WriteCanary adds the canary host to the probe domain, unless it is already there.
+// It is called once at the start. The probe domain is added without tenants when it doesn't exist,
+// so that no tenant sees the canary.
+func (i *HealthInteractor) WriteCanary(probe *model.ReadinessProbe) error {
+	i.fsRepository.Lock()
+	defer i.fsRepository.UnLock()
+
+	domains, err := i.fsRepository.LoadAllDomains()
+	if err != nil {
+		return err
+	}
+
+	var domain *model.Domain
+	for _, d := range domains {
+		if d.Name == probe.Domain {
+			domain = d
+		}
+	}
+	if domain == nil {
+		return i.addProbeDomain(probe)
+	}
+
+	// The hosts in the cache are shared with the other requests, so the canary is replaced instead of changed.
+	canaryFqdn := probe.CanaryFqdn()
+	var newHosts []*model.Host
+	found := false
+	for _, h := range domain.Hosts {
+		if h.Name != canaryFqdn {
+			newHosts = append(newHosts, h)
+			continue
+		}
+		if h.Address == model.CanaryAddress {
+			return nil
+		}
+
+		canary := *h
+		canary.Address = model.CanaryAddress
+		newHosts = append(newHosts, &canary)
+		found = true
+	}
+
+	if !found {
+		canary, err := model.NewOriginalHost(model.CanaryHostname, model.CanaryAddress, domain.Name)
+		if err != nil {
+			return err
+		}
+		newHosts = append(newHosts, canary)
+	}
+
+	domain.Hosts = newHosts
+	return i.fsRepository.WriteDomainFile(domain)
+}
+
+// addProbeDomain adds the probe domain with the canary host and without tenants.
+func (i *HealthInteractor) addProbeDomain(probe *model.ReadinessProbe) error {
+	domain, err := model.NewOriginalDomain(probe.Domain.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	canary, err := model.NewOriginalHost(model.CanaryHostname, model.CanaryAddress, domain.Name)
+	if err != nil {
+		return err
+	}
+	domain.Hosts = []*model.Host{canary}
+
+	err = i.fsRepository.WriteDomainFile(domain)
+	if err != nil {
+		return err
+	}
+
+	err = i.fsRepository.WriteConfCache()
+	if err != nil {
+		_ = i.fsRepository.DeleteDomainFile(domain)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"coredns_api/internal/model"
+	"coredns_api/internal/usecase"
+)
+
+// fakeHealthRepository answers the canary with the address, and every other check is ready.
+type fakeHealthRepository struct {
+	address string
+}
+
+func (f *fakeHealthRepository) CheckHostsDirWritable() error { return nil }
+func (f *fakeHealthRepository) CheckConfPathWritable() error { return nil }
+func (f *fakeHealthRepository) CheckCache() error            { return nil }
+func (f *fakeHealthRepository) LookupCanary(probe *model.ReadinessProbe) ([]string, error) {
+	return []string{f.address}, nil
+}
+
+func TestWriteCanary(t *testing.T) {
+	fsRepository := newTestRepository(t)
+	hRepository := &fakeHealthRepository{}
+	interactor := usecase.NewHealthInteractor(fsRepository, hRepository)
+	domainInteractor := usecase.NewDomainInteractor(fsRepository, nil, nil)
+	probe := &model.ReadinessProbe{Server: "127.0.0.1:53", Domain: "probe.hoge"}
+
+	// The probe domain is added without tenants, so no tenant sees the canary.
+	err := interactor.WriteCanary(probe)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	domains, _, err := domainInteractor.GetAllDomainsList()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(domains) != 1 || domains[0].Name != probe.Domain || len(domains[0].Tenants) != 0 {
+		t.Error("probe domain is missmatched")
+		return
+	}
+	if len(domains[0].Hosts) != 1 || domains[0].Hosts[0].Name != probe.CanaryFqdn() || domains[0].Hosts[0].Address != model.CanaryAddress {
+		t.Error("canary is not written")
+	}
+	tenantDomains, err := domainInteractor.GetDomainsList(testTenant)
+	if err != nil || len(tenantDomains) != 0 {
+		t.Error("probe domain is seen by the tenant")
+	}
+
+	revision := domains[0].Revision
+	err = interactor.WriteCanary(probe)
+	if err != nil || domains[0].Revision != revision {
+		t.Error("canary is written again")
+	}
+}
+
+func TestCheckReadinessReadOnly(t *testing.T) {
+	fsRepository := newTestRepository(t)
+	hRepository := &fakeHealthRepository{}
+	interactor := usecase.NewHealthInteractor(fsRepository, hRepository)
+	domain := addTestDomain(t, fsRepository, "hogehoge.hoge", testTenant)
+	probe := &model.ReadinessProbe{Server: "127.0.0.1:53", Domain: domain.Name}
+
+	// The canary which is not written is not ready, and it is not written by the check.
+	if interactor.CheckReadiness(probe).IsReady() {
+		t.Error("ready without the canary")
+	}
+	gotDomain, err := usecase.NewHostInteractor(fsRepository, nil, nil).GetDomain(domain.Uuid, testTenant)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(gotDomain.Hosts) != 0 {
+		t.Error("canary is written by the readiness check")
+	}
+
+	hRepository.address = model.CanaryAddress
+	if !interactor.CheckReadiness(probe).IsReady() {
+		t.Error("not ready with the canary")
+	}
+}
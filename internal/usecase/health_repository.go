@@ -0,0 +1,11 @@
+package usecase
+
+import "coredns_api/internal/model"
+
+// IHealthRepository checks the dependencies of the API server for the readiness.
+type IHealthRepository interface {
+	CheckHostsDirWritable() error
+	CheckConfPathWritable() error
+	CheckCache() error
+	LookupCanary(probe *model.ReadinessProbe) ([]string, error)
+}
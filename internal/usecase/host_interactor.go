@@ -1,18 +1,58 @@
 package usecase
 
 import (
+	"log/slog"
+	"time"
+
 	"coredns_api/internal/model"
 )
 
+// HostChanges is the summary of the changes applied to the hosts of a domain.
+type HostChanges struct {
+	Added     []*model.Host
+	Updated   []*model.Host
+	Deleted   []*model.Host
+	Unchanged []*model.Host
+}
+
+// HostImportResult is the result of importing a host list.
+// Errors keeps every line which could not be imported.
+type HostImportResult struct {
+	Imported []*model.Host
+	Errors   []*HostLineError
+}
+
 type HostInteractor struct {
 	fsRepository IFilesystemRepository
+	events       IEventBus
+	metrics      IMetrics
+}
+
+func NewHostInteractor(fRepo IFilesystemRepository, events IEventBus, metrics IMetrics) *HostInteractor {
+	return &HostInteractor{fRepo, events, metrics}
 }
 
-func NewHostInteractor(fRepo IFilesystemRepository) *HostInteractor {
-	return &HostInteractor{fRepo}
+// WithLogger returns a HostInteractor which writes the logs with the logger, like the one of a request.
+func (i *HostInteractor) WithLogger(logger *slog.Logger) *HostInteractor {
+	return &HostInteractor{i.fsRepository.WithLogger(logger), i.events, i.metrics}
 }
 
-func (i *HostInteractor) Add(newHost *model.Host, domainUuid model.Uuid, requestTenantUuid model.Uuid) (*model.Domain, error) {
+// Stage returns a HostInteractor which works against a staged repository,
+// and the staged repository to get the changes from. It publishes no events and records no metrics.
+func (i *HostInteractor) Stage() (*HostInteractor, IStagedRepository) {
+	staged := i.fsRepository.Stage()
+	return &HostInteractor{staged, nil, nil}, staged
+}
+
+// IfMatch returns a HostInteractor which changes the domain only when it is at any of the revisions.
+// DomainRevisionMismatchError is returned otherwise.
+func (i *HostInteractor) IfMatch(revisions []uint64) *HostInteractor {
+	return &HostInteractor{newRevisionRepository(i.fsRepository, revisions), i.events, i.metrics}
+}
+
+func (i *HostInteractor) Add(newHost *model.Host, domainUuid model.Uuid, requestTenantUuid model.Uuid) (_ *model.Domain, err error) {
+	defer observe(i.metrics, "host_add", time.Now(), &err)
+
 	i.fsRepository.Lock()
 	defer i.fsRepository.UnLock()
 
@@ -21,24 +61,91 @@ func (i *HostInteractor) Add(newHost *model.Host, domainUuid model.Uuid, request
 		return nil, err
 	}
 
+	err = i.addHost(newHost, gotDomain)
+	if err != nil {
+		return nil, err
+	}
+
+	return gotDomain, nil
+}
+
+// AddFromPool adds a new host with the lowest free address of the pool.
+// Addresses of the hosts in every domain are regarded as used,
+// and the allocation and the write are done in one lock.
+// When lease or expiresAt is specified, the host expires.
+func (i *HostInteractor) AddFromPool(name string, expiresAt time.Time, lease time.Duration, poolUuid, domainUuid model.Uuid, requestTenantUuid model.Uuid) (_ *model.Domain, _ *model.Host, err error) {
+	defer observe(i.metrics, "host_add_from_pool", time.Now(), &err)
+
+	i.fsRepository.Lock()
+	defer i.fsRepository.UnLock()
+
+	gotDomain, err := i.fsRepository.GetDomainByUuid(domainUuid, requestTenantUuid)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pool, err := i.fsRepository.GetPoolByUuid(poolUuid, requestTenantUuid)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	domains, err := i.fsRepository.LoadAllDomains()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	usedAddresses := map[string]bool{}
+	for _, d := range domains {
+		for _, h := range d.Hosts {
+			usedAddresses[h.Address] = true
+		}
+	}
+
+	address, err := pool.Allocate(usedAddresses)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	newHost, err := model.NewOriginalHost(name, address, gotDomain.Name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !expiresAt.IsZero() || lease != 0 {
+		err = newHost.SetLease(expiresAt, lease, time.Now())
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	err = i.addHost(newHost, gotDomain)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return gotDomain, newHost, nil
+}
+
+func (i *HostInteractor) addHost(newHost *model.Host, gotDomain *model.Domain) error {
 	for _, h := range gotDomain.Hosts {
 		if h.Name == newHost.Name {
-			return nil, NewHostDuplicatedError("hostname", newHost.Name)
+			return NewHostDuplicatedError("hostname", newHost.Name)
 		}
 		if h.Address == newHost.Address {
-			return nil, NewHostDuplicatedError("address", newHost.Address)
+			return NewHostDuplicatedError("address", newHost.Address)
 		}
 	}
 
 	hosts := append(gotDomain.Hosts, newHost)
 	gotDomain.Hosts = hosts
 
-	err = i.fsRepository.WriteDomainFile(gotDomain)
+	err := i.fsRepository.WriteDomainFile(gotDomain)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return gotDomain, nil
+	publish(i.events, model.NewHostEvent(model.EventHostAdded, gotDomain, nil, newHost))
+	return nil
 }
 
 func (i *HostInteractor) Get(hostUuid, domainUuid model.Uuid, requestTenantUuid model.Uuid) (*model.Host, error) {
@@ -71,7 +178,9 @@ func (i *HostInteractor) GetDomain(domainUuid model.Uuid, requestTenantUuid mode
 	return targetDomain, nil
 }
 
-func (i *HostInteractor) Update(newHost *model.Host, domainUuid model.Uuid, requestTenantUuid model.Uuid) error {
+func (i *HostInteractor) Update(newHost *model.Host, domainUuid model.Uuid, requestTenantUuid model.Uuid) (err error) {
+	defer observe(i.metrics, "host_update", time.Now(), &err)
+
 	i.fsRepository.Lock()
 	defer i.fsRepository.UnLock()
 
@@ -81,32 +190,46 @@ func (i *HostInteractor) Update(newHost *model.Host, domainUuid model.Uuid, requ
 	}
 
 	var newHosts []*model.Host
-	found := false
+	var oldHost *model.Host
 	for _, h := range domain.Hosts {
-		if h.Name == newHost.Name {
+		// The host itself keeps its hostname or address when only the other one is changed.
+		if h.Uuid != newHost.Uuid && h.Name == newHost.Name {
 			return NewHostDuplicatedError("hostname", newHost.Name)
 		}
-		if h.Address == newHost.Address {
+		if h.Uuid != newHost.Uuid && h.Address == newHost.Address {
 			return NewHostDuplicatedError("address", newHost.Address)
 		}
 
 		if h.Uuid == newHost.Uuid {
+			// The lease is kept when the new host doesn't have its own.
+			if newHost.ExpiresAt.IsZero() && newHost.Lease == 0 {
+				newHost.ExpiresAt = h.ExpiresAt
+				newHost.Lease = h.Lease
+			}
 			newHosts = append(newHosts, newHost)
-			found = true
+			oldHost = h
 		} else {
 			newHosts = append(newHosts, h)
 		}
 	}
 
-	if !found {
+	if oldHost == nil {
 		return model.NewHostNotFoundError()
 	}
 
 	domain.Hosts = newHosts
-	return i.fsRepository.WriteDomainFile(domain)
+	err = i.fsRepository.WriteDomainFile(domain)
+	if err != nil {
+		return err
+	}
+
+	publish(i.events, model.NewHostEvent(model.EventHostUpdated, domain, oldHost, newHost))
+	return nil
 }
 
-func (i *HostInteractor) Delete(host *model.Host, domainUuid model.Uuid, requestTenantUuid model.Uuid) error {
+func (i *HostInteractor) Delete(host *model.Host, domainUuid model.Uuid, requestTenantUuid model.Uuid) (err error) {
+	defer observe(i.metrics, "host_delete", time.Now(), &err)
+
 	i.fsRepository.Lock()
 	defer i.fsRepository.UnLock()
 
@@ -116,19 +239,373 @@ func (i *HostInteractor) Delete(host *model.Host, domainUuid model.Uuid, request
 	}
 
 	var newHosts []*model.Host
-	found := false
+	var deletedHost *model.Host
 	for _, h := range domain.Hosts {
 		if h.Uuid == host.Uuid {
-			found = true
+			deletedHost = h
 		} else {
 			newHosts = append(newHosts, h)
 		}
 	}
 
-	if !found {
+	if deletedHost == nil {
 		return model.NewHostNotFoundError()
 	}
 
 	domain.Hosts = newHosts
-	return i.fsRepository.WriteDomainFile(domain)
+	err = i.fsRepository.WriteDomainFile(domain)
+	if err != nil {
+		return err
+	}
+
+	publish(i.events, model.NewHostEvent(model.EventHostDeleted, domain, deletedHost, nil))
+	return nil
+}
+
+// Renew extends the expiry of the host by the lease from now.
+// When lease is zero, the lease of the host is used.
+func (i *HostInteractor) Renew(hostUuid model.Uuid, lease time.Duration, domainUuid model.Uuid, requestTenantUuid model.Uuid) (_ *model.Host, err error) {
+	defer observe(i.metrics, "host_renew", time.Now(), &err)
+
+	i.fsRepository.Lock()
+	defer i.fsRepository.UnLock()
+
+	domain, err := i.fsRepository.GetDomainByUuid(domainUuid, requestTenantUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	var newHosts []*model.Host
+	var oldHost, renewedHost *model.Host
+	for _, h := range domain.Hosts {
+		if h.Uuid != hostUuid {
+			newHosts = append(newHosts, h)
+			continue
+		}
+
+		host := *h
+		if lease != 0 {
+			host.Lease = lease
+		}
+		err = host.Renew(time.Now())
+		if err != nil {
+			return nil, err
+		}
+		oldHost = h
+		renewedHost = &host
+		newHosts = append(newHosts, renewedHost)
+	}
+
+	if renewedHost == nil {
+		return nil, model.NewHostNotFoundError()
+	}
+
+	domain.Hosts = newHosts
+	err = i.fsRepository.WriteDomainFile(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	publish(i.events, model.NewHostEvent(model.EventHostUpdated, domain, oldHost, renewedHost))
+	return renewedHost, nil
+}
+
+// ReapExpired deletes the hosts expired at now from every domain.
+// The expiry is checked and the hosts are deleted in one lock, so a host renewed in between is never deleted.
+func (i *HostInteractor) ReapExpired(now time.Time) (_ []*model.Host, err error) {
+	defer observe(i.metrics, "host_reap_expired", time.Now(), &err)
+
+	i.fsRepository.Lock()
+	defer i.fsRepository.UnLock()
+
+	domains, err := i.fsRepository.LoadAllDomains()
+	if err != nil {
+		return nil, err
+	}
+
+	var reaped []*model.Host
+	for _, d := range domains {
+		var newHosts []*model.Host
+		var expiredHosts []*model.Host
+		for _, h := range d.Hosts {
+			if h.IsExpired(now) {
+				expiredHosts = append(expiredHosts, h)
+			} else {
+				newHosts = append(newHosts, h)
+			}
+		}
+		if len(expiredHosts) == 0 {
+			continue
+		}
+
+		d.Hosts = newHosts
+		err = i.fsRepository.WriteDomainFile(d)
+		if err != nil {
+			return reaped, err
+		}
+
+		for _, h := range expiredHosts {
+			publish(i.events, model.NewHostEvent(model.EventHostDeleted, d, h, nil))
+		}
+		reaped = append(reaped, expiredHosts...)
+	}
+
+	return reaped, nil
+}
+
+// Apply makes the hosts of the domain to be exactly the desired hosts with one write.
+// Hosts are matched by hostname, and matched hosts keep their UUID.
+func (i *HostInteractor) Apply(desiredHosts []*model.Host, domainUuid model.Uuid, requestTenantUuid model.Uuid) (*model.Domain, *HostChanges, error) {
+	return i.ApplyRecords(desiredHosts, nil, domainUuid, requestTenantUuid)
+}
+
+// ApplyRecords is Apply which also replaces the TXT records of the domain in the same write.
+// TXT records are kept when txtRecords is nil.
+func (i *HostInteractor) ApplyRecords(desiredHosts []*model.Host, txtRecords []*model.TxtRecord, domainUuid model.Uuid, requestTenantUuid model.Uuid) (_ *model.Domain, _ *HostChanges, err error) {
+	defer observe(i.metrics, "host_apply", time.Now(), &err)
+
+	i.fsRepository.Lock()
+	defer i.fsRepository.UnLock()
+
+	domain, err := i.fsRepository.GetDomainByUuid(domainUuid, requestTenantUuid)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	newHosts, changes, err := planHostChanges(domain, desiredHosts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = i.writeHostChanges(domain, newHosts, txtRecords, changes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return domain, changes, nil
+}
+
+// RRsetChanges is the changes of the RRsets of a domain.
+// Expected RRsets must be the current records of the domain, like the old records of the updates.
+// Hosts are the changes of A and AAAA RRsets, and TxtRecords are the ones of TXT RRsets.
+type RRsetChanges struct {
+	DomainUuid model.Uuid
+	Expected   []*model.RRsetChange
+	Hosts      []*model.RRsetChange
+	TxtRecords []*model.RRsetChange
+}
+
+// ApplyRRsetChanges applies the RRset changes to the domains.
+// Every domain is checked and planned in the lock before any of them is written,
+// so that nothing is written when any change is invalid or any expected RRset is mismatched.
+func (i *HostInteractor) ApplyRRsetChanges(rrsetChanges []*RRsetChanges, requestTenantUuid model.Uuid) (err error) {
+	defer observe(i.metrics, "rrset_apply", time.Now(), &err)
+
+	i.fsRepository.Lock()
+	defer i.fsRepository.UnLock()
+
+	type plan struct {
+		domain     *model.Domain
+		hosts      []*model.Host
+		txtRecords []*model.TxtRecord
+		changes    *HostChanges
+	}
+
+	var plans []*plan
+	for _, c := range rrsetChanges {
+		domain, err := i.fsRepository.GetDomainByUuid(c.DomainUuid, requestTenantUuid)
+		if err != nil {
+			return err
+		}
+
+		err = model.CheckRRsets(domain, c.Expected)
+		if err != nil {
+			return err
+		}
+
+		desiredHosts, err := model.PlanRRsetChanges(domain, c.Hosts)
+		if err != nil {
+			return err
+		}
+
+		var txtRecords []*model.TxtRecord
+		if len(c.TxtRecords) > 0 {
+			txtRecords, err = model.PlanTxtRecordChanges(domain, c.TxtRecords)
+			if err != nil {
+				return err
+			}
+		}
+
+		newHosts, changes, err := planHostChanges(domain, desiredHosts)
+		if err != nil {
+			return err
+		}
+		plans = append(plans, &plan{domain, newHosts, txtRecords, changes})
+	}
+
+	for _, p := range plans {
+		err = i.writeHostChanges(p.domain, p.hosts, p.txtRecords, p.changes)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// planHostChanges returns the hosts of the domain after it is made to be exactly the desired hosts, and the changes.
+// Hosts are matched by hostname, and matched hosts keep their UUID and lease.
+func planHostChanges(domain *model.Domain, desiredHosts []*model.Host) ([]*model.Host, *HostChanges, error) {
+	names := map[string]bool{}
+	addresses := map[string]bool{}
+	for _, h := range desiredHosts {
+		if names[h.Name] {
+			return nil, nil, NewHostDuplicatedError("hostname", h.Name)
+		}
+		if addresses[h.Address] {
+			return nil, nil, NewHostDuplicatedError("address", h.Address)
+		}
+		names[h.Name] = true
+		addresses[h.Address] = true
+	}
+
+	desired := map[string]*model.Host{}
+	for _, h := range desiredHosts {
+		desired[h.Name] = h
+	}
+
+	changes := &HostChanges{}
+	var newHosts []*model.Host
+	current := map[string]*model.Host{}
+	for _, h := range domain.Hosts {
+		current[h.Name] = h
+
+		d, ok := desired[h.Name]
+		if !ok {
+			changes.Deleted = append(changes.Deleted, h)
+			continue
+		}
+
+		if d.Address == h.Address {
+			changes.Unchanged = append(changes.Unchanged, h)
+			newHosts = append(newHosts, h)
+			continue
+		}
+
+		updatedHost, err := model.NewHost(h.Uuid, h.Name, d.Address)
+		if err != nil {
+			return nil, nil, err
+		}
+		updatedHost.ExpiresAt = h.ExpiresAt
+		updatedHost.Lease = h.Lease
+		changes.Updated = append(changes.Updated, updatedHost)
+		newHosts = append(newHosts, updatedHost)
+	}
+
+	for _, h := range desiredHosts {
+		if current[h.Name] == nil {
+			changes.Added = append(changes.Added, h)
+			newHosts = append(newHosts, h)
+		}
+	}
+	return newHosts, changes, nil
+}
+
+// writeHostChanges writes the planned hosts and TXT records to the domain, and publishes the changes.
+// Nothing is written when nothing is changed. TXT records are kept when txtRecords is nil.
+func (i *HostInteractor) writeHostChanges(domain *model.Domain, newHosts []*model.Host, txtRecords []*model.TxtRecord, changes *HostChanges) error {
+	txtChanged := txtRecords != nil && !model.EqualTxtRecords(domain.TxtRecords, txtRecords)
+	if len(changes.Added) == 0 && len(changes.Updated) == 0 && len(changes.Deleted) == 0 && !txtChanged {
+		return nil
+	}
+
+	current := map[string]*model.Host{}
+	for _, h := range domain.Hosts {
+		current[h.Name] = h
+	}
+
+	domain.Hosts = newHosts
+	if txtRecords != nil {
+		domain.TxtRecords = txtRecords
+	}
+	err := i.fsRepository.WriteDomainFile(domain)
+	if err != nil {
+		return err
+	}
+
+	for _, h := range changes.Added {
+		publish(i.events, model.NewHostEvent(model.EventHostAdded, domain, nil, h))
+	}
+	for _, h := range changes.Updated {
+		publish(i.events, model.NewHostEvent(model.EventHostUpdated, domain, current[h.Name], h))
+	}
+	for _, h := range changes.Deleted {
+		publish(i.events, model.NewHostEvent(model.EventHostDeleted, domain, h, nil))
+	}
+	return nil
+}
+
+// Import adds every host in the host list to the domain with one write.
+// When skipInvalid is false, nothing is written if any line is invalid.
+func (i *HostInteractor) Import(lines []*model.HostLine, skipInvalid bool, domainUuid model.Uuid, requestTenantUuid model.Uuid) (_ *model.Domain, _ *HostImportResult, err error) {
+	defer observe(i.metrics, "host_import", time.Now(), &err)
+
+	i.fsRepository.Lock()
+	defer i.fsRepository.UnLock()
+
+	domain, err := i.fsRepository.GetDomainByUuid(domainUuid, requestTenantUuid)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names := map[string]bool{}
+	addresses := map[string]bool{}
+	for _, h := range domain.Hosts {
+		names[h.Name] = true
+		addresses[h.Address] = true
+	}
+
+	result := &HostImportResult{}
+	for _, line := range lines {
+		if line.Err != nil {
+			result.Errors = append(result.Errors, NewHostLineError(line.Line, line.Err))
+			continue
+		}
+
+		newHost, err := model.NewOriginalHost(line.Name, line.Address, domain.Name)
+		if err != nil {
+			result.Errors = append(result.Errors, NewHostLineError(line.Line, err))
+			continue
+		}
+
+		if names[newHost.Name] {
+			result.Errors = append(result.Errors, NewHostLineError(line.Line, NewHostDuplicatedError("hostname", newHost.Name)))
+			continue
+		}
+		if addresses[newHost.Address] {
+			result.Errors = append(result.Errors, NewHostLineError(line.Line, NewHostDuplicatedError("address", newHost.Address)))
+			continue
+		}
+
+		names[newHost.Name] = true
+		addresses[newHost.Address] = true
+		result.Imported = append(result.Imported, newHost)
+	}
+
+	if len(result.Errors) > 0 && !skipInvalid {
+		return nil, result, NewHostImportError(len(result.Errors))
+	}
+
+	if len(result.Imported) == 0 {
+		return domain, result, nil
+	}
+
+	domain.Hosts = append(domain.Hosts, result.Imported...)
+	err = i.fsRepository.WriteDomainFile(domain)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, h := range result.Imported {
+		publish(i.events, model.NewHostEvent(model.EventHostAdded, domain, nil, h))
+	}
+	return domain, result, nil
 }
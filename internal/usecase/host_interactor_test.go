@@ -0,0 +1,296 @@
+package usecase_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"coredns_api/internal/infrastructure"
+	"coredns_api/internal/interface/repository"
+	"coredns_api/internal/model"
+	"coredns_api/internal/usecase"
+)
+
+const (
+	testTenant      = "df397e50-8006-450e-b18b-5c5bd940baff"
+	testOtherTenant = "02c03bd4-fe2e-45f2-85b6-b535af15215d"
+)
+
+// newTestRepository returns the repository initialized on the empty HOSTS_DIR and CONF_PATH in a temporary directory.
+func newTestRepository(t *testing.T) usecase.IFilesystemRepository {
+	dir := t.TempDir()
+	hostsDir := filepath.Join(dir, "hosts")
+	err := os.Mkdir(hostsDir, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOSTS_DIR", hostsDir)
+	t.Setenv("CONF_PATH", filepath.Join(dir, "coredns.conf"))
+
+	fsRepository := repository.NewFileRepository(infrastructure.NewFilesystem())
+	fsRepository.Initialize()
+	return fsRepository
+}
+
+// addTestDomain adds the domain of the tenants with the domain interactor.
+func addTestDomain(t *testing.T, fsRepository usecase.IFilesystemRepository, name string, tenants ...string) *model.Domain {
+	domain, err := model.NewOriginalDomain(name, tenants)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = usecase.NewDomainInteractor(fsRepository, nil, nil).Add(domain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return domain
+}
+
+func TestReapExpired(t *testing.T) {
+	fsRepository := newTestRepository(t)
+	events := repository.NewEventBus()
+	interactor := usecase.NewHostInteractor(fsRepository, events, nil)
+	domain := addTestDomain(t, fsRepository, "hogehoge.hoge", testTenant)
+
+	now := time.Now()
+	expired, _ := model.NewOriginalHost("hogeserver1", "172.21.1.1", domain.Name)
+	expired.SetLease(now.Add(time.Minute), 0, now)
+	leased, _ := model.NewOriginalHost("hogeserver2", "172.21.1.2", domain.Name)
+	leased.SetLease(time.Time{}, time.Hour, now)
+	static, _ := model.NewOriginalHost("hogeserver3", "172.21.1.3", domain.Name)
+	for _, h := range []*model.Host{expired, leased, static} {
+		_, err := interactor.Add(h, domain.Uuid, testTenant)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	subscription := events.Subscribe(16)
+	defer subscription.Close()
+
+	reapedAt := now.Add(10 * time.Minute)
+	reaped, err := interactor.ReapExpired(reapedAt)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(reaped) != 1 || reaped[0].Uuid != expired.Uuid {
+		t.Error("expired host is not reaped")
+	}
+
+	gotDomain, err := interactor.GetDomain(domain.Uuid, testTenant)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(gotDomain.Hosts) != 2 {
+		t.Error("hosts are missmatched after reaping")
+	}
+
+	select {
+	case event := <-subscription.Events():
+		if event.Type != model.EventHostDeleted || event.DomainUuid != domain.Uuid {
+			t.Error("event of the reaped host is missmatched")
+		}
+	default:
+		t.Error("event of the reaped host is not published")
+	}
+
+	reaped, err = interactor.ReapExpired(reapedAt)
+	if err != nil || len(reaped) != 0 {
+		t.Error("host is reaped twice")
+	}
+}
+
+func TestUpdateDuplicated(t *testing.T) {
+	fsRepository := newTestRepository(t)
+	interactor := usecase.NewHostInteractor(fsRepository, nil, nil)
+	domain := addTestDomain(t, fsRepository, "hogehoge.hoge", testTenant)
+
+	host1, _ := model.NewOriginalHost("hogeserver1", "172.21.1.1", domain.Name)
+	host2, _ := model.NewOriginalHost("hogeserver2", "172.21.1.2", domain.Name)
+	for _, h := range []*model.Host{host1, host2} {
+		_, err := interactor.Add(h, domain.Uuid, testTenant)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	// The host keeps its hostname when only the address is changed, and the other way around.
+	newAddress, _ := model.NewHost(host1.Uuid, host1.Name, "172.21.1.3")
+	err := interactor.Update(newAddress, domain.Uuid, testTenant)
+	if err != nil {
+		t.Error(err)
+	}
+	newName, _ := model.NewHost(host1.Uuid, "hogeserver3.hogehoge.hoge", "172.21.1.3")
+	err = interactor.Update(newName, domain.Uuid, testTenant)
+	if err != nil {
+		t.Error(err)
+	}
+
+	duplicatedName, _ := model.NewHost(host1.Uuid, host2.Name, "172.21.1.3")
+	err = interactor.Update(duplicatedName, domain.Uuid, testTenant)
+	if _, ok := err.(*usecase.HostDuplicatedError); !ok {
+		t.Error("hostname of another host is accepted")
+	}
+
+	duplicatedAddress, _ := model.NewHost(host1.Uuid, "hogeserver3.hogehoge.hoge", host2.Address)
+	err = interactor.Update(duplicatedAddress, domain.Uuid, testTenant)
+	if _, ok := err.(*usecase.HostDuplicatedError); !ok {
+		t.Error("address of another host is accepted")
+	}
+
+	got, err := interactor.Get(host1.Uuid, domain.Uuid, testTenant)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got.Name != "hogeserver3.hogehoge.hoge" || got.Address != "172.21.1.3" {
+		t.Error("host is changed by the duplicated update")
+	}
+}
+
+func TestApply(t *testing.T) {
+	fsRepository := newTestRepository(t)
+	interactor := usecase.NewHostInteractor(fsRepository, nil, nil)
+	domain := addTestDomain(t, fsRepository, "hogehoge.hoge", testTenant)
+
+	now := time.Now()
+	host1, _ := model.NewOriginalHost("hogeserver1", "172.21.1.1", domain.Name)
+	host1.SetLease(time.Time{}, time.Hour, now)
+	host2, _ := model.NewOriginalHost("hogeserver2", "172.21.1.2", domain.Name)
+	host3, _ := model.NewOriginalHost("hogeserver3", "172.21.1.3", domain.Name)
+	for _, h := range []*model.Host{host1, host2, host3} {
+		_, err := interactor.Add(h, domain.Uuid, testTenant)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+	gotDomain, _ := interactor.GetDomain(domain.Uuid, testTenant)
+	revision := gotDomain.Revision
+
+	// hogeserver1 is updated, hogeserver2 is unchanged, hogeserver3 is deleted and hogeserver4 is added.
+	desired1, _ := model.NewOriginalHost("hogeserver1", "172.21.1.11", domain.Name)
+	desired2, _ := model.NewOriginalHost("hogeserver2", "172.21.1.2", domain.Name)
+	desired4, _ := model.NewOriginalHost("hogeserver4", "172.21.1.4", domain.Name)
+	gotDomain, changes, err := interactor.Apply([]*model.Host{desired1, desired2, desired4}, domain.Uuid, testTenant)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(changes.Added) != 1 || len(changes.Updated) != 1 || len(changes.Deleted) != 1 || len(changes.Unchanged) != 1 {
+		t.Errorf("changes are missmatched: %+v", changes)
+	}
+	if gotDomain.Revision != revision+1 {
+		t.Errorf("changes are not applied with one write: %d -> %d", revision, gotDomain.Revision)
+	}
+
+	hosts := map[string]*model.Host{}
+	for _, h := range gotDomain.Hosts {
+		hosts[h.Name] = h
+	}
+	if len(hosts) != 3 || hosts["hogeserver3.hogehoge.hoge"] != nil {
+		t.Error("hosts are missmatched after applying")
+		return
+	}
+	updated := hosts["hogeserver1.hogehoge.hoge"]
+	if updated.Uuid != host1.Uuid || updated.Address != "172.21.1.11" {
+		t.Error("updated host doesn't keep its UUID")
+	}
+	if updated.Lease != time.Hour || !updated.ExpiresAt.Equal(host1.ExpiresAt) {
+		t.Error("updated host doesn't keep its lease")
+	}
+	if hosts["hogeserver2.hogehoge.hoge"].Uuid != host2.Uuid {
+		t.Error("unchanged host doesn't keep its UUID")
+	}
+	if hosts["hogeserver4.hogehoge.hoge"].Uuid != desired4.Uuid {
+		t.Error("added host is missmatched")
+	}
+
+	// Nothing is written when nothing is changed.
+	revision = gotDomain.Revision
+	gotDomain, changes, err = interactor.Apply([]*model.Host{desired1, desired2, desired4}, domain.Uuid, testTenant)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(changes.Unchanged) != 3 || gotDomain.Revision != revision {
+		t.Error("domain is written without changes")
+	}
+}
+
+func TestApplyDuplicated(t *testing.T) {
+	fsRepository := newTestRepository(t)
+	interactor := usecase.NewHostInteractor(fsRepository, nil, nil)
+	domain := addTestDomain(t, fsRepository, "hogehoge.hoge", testTenant)
+
+	host1, _ := model.NewOriginalHost("hogeserver1", "172.21.1.1", domain.Name)
+	host2, _ := model.NewOriginalHost("hogeserver1", "172.21.1.2", domain.Name)
+	_, _, err := interactor.Apply([]*model.Host{host1, host2}, domain.Uuid, testTenant)
+	if _, ok := err.(*usecase.HostDuplicatedError); !ok {
+		t.Error("duplicated hostnames are accepted")
+	}
+
+	host3, _ := model.NewOriginalHost("hogeserver3", "172.21.1.1", domain.Name)
+	_, _, err = interactor.Apply([]*model.Host{host1, host3}, domain.Uuid, testTenant)
+	if _, ok := err.(*usecase.HostDuplicatedError); !ok {
+		t.Error("duplicated addresses are accepted")
+	}
+
+	gotDomain, _ := interactor.GetDomain(domain.Uuid, testTenant)
+	if len(gotDomain.Hosts) != 0 {
+		t.Error("duplicated hosts are applied")
+	}
+}
+
+func TestAddFromPool(t *testing.T) {
+	fsRepository := newTestRepository(t)
+	interactor := usecase.NewHostInteractor(fsRepository, nil, nil)
+	domain := addTestDomain(t, fsRepository, "hogehoge.hoge", testTenant)
+	otherDomain := addTestDomain(t, fsRepository, "fugafuga.fuga", testOtherTenant)
+
+	// The pool has 172.21.1.1 and 172.21.1.2, and the first one is used in the domain of another tenant.
+	pool, _ := model.NewOriginalPool("hoge", []string{testTenant}, []string{"172.21.1.0/30"}, nil, nil)
+	otherPool, _ := model.NewOriginalPool("fuga", []string{testOtherTenant}, []string{"172.21.2.0/30"}, nil, nil)
+	poolInteractor := usecase.NewPoolInteractor(fsRepository)
+	for _, p := range []*model.Pool{pool, otherPool} {
+		err := poolInteractor.Add(p)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+	used, _ := model.NewOriginalHost("fugaserver1", "172.21.1.1", otherDomain.Name)
+	_, err := interactor.Add(used, otherDomain.Uuid, testOtherTenant)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	_, host, err := interactor.AddFromPool("hogeserver1", time.Time{}, 0, pool.Uuid, domain.Uuid, testTenant)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if host.Address != "172.21.1.2" {
+		t.Error("address used in another domain is allocated: " + host.Address)
+	}
+
+	_, _, err = interactor.AddFromPool("hogeserver2", time.Time{}, 0, pool.Uuid, domain.Uuid, testTenant)
+	if _, ok := err.(*model.PoolExhaustedError); !ok {
+		t.Errorf("address is allocated from the exhausted pool: %v", err)
+	}
+
+	_, _, err = interactor.AddFromPool("hogeserver2", time.Time{}, 0, otherPool.Uuid, domain.Uuid, testTenant)
+	if _, ok := err.(*model.PoolPermissionError); !ok {
+		t.Errorf("address is allocated from the pool of another tenant: %v", err)
+	}
+
+	gotDomain, _ := interactor.GetDomain(domain.Uuid, testTenant)
+	if len(gotDomain.Hosts) != 1 {
+		t.Error("host is added without the address")
+	}
+}
@@ -0,0 +1,37 @@
+package usecase
+
+import (
+	"log/slog"
+	"time"
+)
+
+// HostReaper removes expired hosts in background.
+type HostReaper struct {
+	interactor *HostInteractor
+}
+
+func NewHostReaper(itr *HostInteractor) *HostReaper {
+	return &HostReaper{itr}
+}
+
+// Run removes expired hosts every interval. It never returns.
+// Hosts expired while the server is stopped are removed at the start.
+func (r *HostReaper) Run(interval time.Duration) {
+	r.reap(time.Now())
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		r.reap(now)
+	}
+}
+
+func (r *HostReaper) reap(now time.Time) {
+	reaped, err := r.interactor.ReapExpired(now)
+	for _, h := range reaped {
+		slog.Info("expired host is deleted", "host", h.Name, "host_uuid", h.Uuid)
+	}
+	if err != nil {
+		slog.Error("failed to delete expired hosts", "error", err)
+	}
+}
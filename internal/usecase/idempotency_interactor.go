@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"time"
+
+	"coredns_api/internal/model"
+)
+
+type IdempotencyInteractor struct {
+	repository IIdempotencyRepository
+}
+
+func NewIdempotencyInteractor(iRepo IIdempotencyRepository) *IdempotencyInteractor {
+	return &IdempotencyInteractor{iRepo}
+}
+
+// Begin keeps the request until the window passes, or returns the response of the former request with the same key.
+// IdempotencyKeyConflictError is returned when the former request is another one, or it is still handled.
+// IdempotencyKeysFullError is returned when maxKeys requests are already kept.
+func (i *IdempotencyInteractor) Begin(request *model.IdempotentRequest, window time.Duration, maxKeys int, now time.Time) (*model.IdempotentResponse, error) {
+	i.repository.Lock()
+	defer i.repository.UnLock()
+
+	i.repository.DeleteExpired(now)
+
+	former := i.repository.Get(request.Tenant, request.Key)
+	if former != nil {
+		if former.RequestHash != request.RequestHash {
+			return nil, model.NewIdempotencyKeyConflictError("idempotency key is already used for another request. key: " + request.Key)
+		}
+		if former.Response == nil {
+			return nil, model.NewIdempotencyKeyConflictError("request with the idempotency key is still handled. key: " + request.Key)
+		}
+		return former.Response, nil
+	}
+
+	if i.repository.Count() >= maxKeys {
+		return nil, model.NewIdempotencyKeysFullError(maxKeys)
+	}
+
+	request.ExpiresAt = now.Add(window)
+	i.repository.Put(request)
+	return nil, nil
+}
+
+// Complete keeps the response to replay it for the retries.
+// Server errors and the responses without a status are not kept, so that the retries are handled again.
+func (i *IdempotencyInteractor) Complete(request *model.IdempotentRequest, response *model.IdempotentResponse) {
+	i.repository.Lock()
+	defer i.repository.UnLock()
+
+	if response.StatusCode < 100 || response.StatusCode >= 500 {
+		i.repository.Delete(request)
+		return
+	}
+	request.Response = response
+	i.repository.Put(request)
+}
+
+// Abort forgets the request which is not completed, so that the retries are handled again.
+func (i *IdempotencyInteractor) Abort(request *model.IdempotentRequest) {
+	i.repository.Lock()
+	defer i.repository.UnLock()
+
+	i.repository.Delete(request)
+}
@@ -0,0 +1,108 @@
+package usecase_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"coredns_api/internal/interface/repository"
+	"coredns_api/internal/model"
+	"coredns_api/internal/usecase"
+)
+
+func newTestIdempotentRequest(t *testing.T, key, body string) *model.IdempotentRequest {
+	request, err := model.NewIdempotentRequest(key, testTenant, "POST /v1/domains", []byte(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return request
+}
+
+func TestIdempotencyInteractor(t *testing.T) {
+	interactor := usecase.NewIdempotencyInteractor(repository.NewIdempotencyRepository())
+	now := time.Now()
+	// The keys are kept by the repository of every test, so they are unique to this one.
+	key := "interactor-" + model.NewRequestId("")
+
+	request := newTestIdempotentRequest(t, key, `{"domain":"hogehoge.hoge"}`)
+	response, err := interactor.Begin(request, time.Hour, 100, now)
+	if err != nil || response != nil {
+		t.Error("first request is not begun")
+		return
+	}
+
+	// The retry is refused while the first one is handled.
+	_, err = interactor.Begin(newTestIdempotentRequest(t, key, `{"domain":"hogehoge.hoge"}`), time.Hour, 100, now)
+	if _, ok := err.(*model.IdempotencyKeyConflictError); !ok {
+		t.Error("retry is begun while the first request is handled")
+	}
+
+	interactor.Complete(request, &model.IdempotentResponse{StatusCode: http.StatusCreated, Body: []byte(`{}`)})
+	response, err = interactor.Begin(newTestIdempotentRequest(t, key, `{"domain":"hogehoge.hoge"}`), time.Hour, 100, now)
+	if err != nil || response == nil || response.StatusCode != http.StatusCreated {
+		t.Error("response is not replayed for the retry")
+	}
+
+	_, err = interactor.Begin(newTestIdempotentRequest(t, key, `{"domain":"fugafuga.fuga"}`), time.Hour, 100, now)
+	if _, ok := err.(*model.IdempotencyKeyConflictError); !ok {
+		t.Error("key is reused for another request")
+	}
+
+	// The key is forgotten after the window.
+	response, err = interactor.Begin(newTestIdempotentRequest(t, key, `{"domain":"fugafuga.fuga"}`), time.Hour, 100, now.Add(time.Hour))
+	if err != nil || response != nil {
+		t.Error("key is kept after the window")
+	}
+}
+
+func TestIdempotencyInteractorServerError(t *testing.T) {
+	interactor := usecase.NewIdempotencyInteractor(repository.NewIdempotencyRepository())
+	now := time.Now()
+
+	// The response without a status is the one of the handler which didn't respond.
+	for _, status := range []int{http.StatusInternalServerError, 0} {
+		key := "server-error-" + model.NewRequestId("")
+		request := newTestIdempotentRequest(t, key, `{}`)
+		_, err := interactor.Begin(request, time.Hour, 100, now)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		interactor.Complete(request, &model.IdempotentResponse{StatusCode: status})
+
+		response, err := interactor.Begin(newTestIdempotentRequest(t, key, `{}`), time.Hour, 100, now)
+		if err != nil || response != nil {
+			t.Errorf("response is replayed: %d", status)
+		}
+	}
+}
+
+func TestIdempotencyInteractorMaxKeys(t *testing.T) {
+	iRepository := repository.NewIdempotencyRepository()
+	interactor := usecase.NewIdempotencyInteractor(iRepository)
+	now := time.Now()
+
+	iRepository.Lock()
+	maxKeys := iRepository.Count() + 1
+	iRepository.UnLock()
+
+	key := "max-keys-" + model.NewRequestId("")
+	request := newTestIdempotentRequest(t, key, `{}`)
+	_, err := interactor.Begin(request, time.Hour, maxKeys, now)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer interactor.Abort(request)
+
+	_, err = interactor.Begin(newTestIdempotentRequest(t, "other-"+key, `{}`), time.Hour, maxKeys, now)
+	if _, ok := err.(*model.IdempotencyKeysFullError); !ok {
+		t.Error("key is kept over the max keys")
+	}
+
+	// The kept key is still replayed when the keys are full.
+	_, err = interactor.Begin(newTestIdempotentRequest(t, key, `{}`), time.Hour, maxKeys, now)
+	if _, ok := err.(*model.IdempotencyKeyConflictError); !ok {
+		t.Error("kept key is not found when the keys are full")
+	}
+}
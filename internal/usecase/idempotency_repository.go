@@ -0,0 +1,17 @@
+package usecase
+
+import (
+	"time"
+
+	"coredns_api/internal/model"
+)
+
+type IIdempotencyRepository interface {
+	Lock()
+	UnLock()
+	Get(tenant, key string) *model.IdempotentRequest
+	Put(request *model.IdempotentRequest)
+	Delete(request *model.IdempotentRequest)
+	DeleteExpired(now time.Time)
+	Count() int
+}
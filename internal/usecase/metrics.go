@@ -0,0 +1,20 @@
+package usecase
+
+import "time"
+
+// IMetrics records the operations of the interactors.
+type IMetrics interface {
+	ObserveOperation(operation string, duration time.Duration, err error)
+}
+
+// observe records the operation started at start with the error it returned.
+// It is deferred with the pointer to the named error result, like:
+//
+//	defer observe(i.metrics, "host_add", time.Now(), &err)
+//
+// Nothing is recorded by the interactors working against a staged repository.
+func observe(metrics IMetrics, operation string, start time.Time, err *error) {
+	if metrics != nil {
+		metrics.ObserveOperation(operation, time.Since(start), *err)
+	}
+}
@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"log/slog"
+
+	"coredns_api/internal/model"
+)
+
+type PoolInteractor struct {
+	fsRepository IFilesystemRepository
+}
+
+func NewPoolInteractor(fRepo IFilesystemRepository) *PoolInteractor {
+	return &PoolInteractor{fRepo}
+}
+
+// WithLogger returns a PoolInteractor which writes the logs with the logger, like the one of a request.
+func (i *PoolInteractor) WithLogger(logger *slog.Logger) *PoolInteractor {
+	return &PoolInteractor{i.fsRepository.WithLogger(logger)}
+}
+
+// Stage returns a PoolInteractor which works against a staged repository,
+// and the staged repository to get the changes from.
+func (i *PoolInteractor) Stage() (*PoolInteractor, IStagedRepository) {
+	staged := i.fsRepository.Stage()
+	return &PoolInteractor{staged}, staged
+}
+
+func (i *PoolInteractor) Add(pool *model.Pool) error {
+	i.fsRepository.Lock()
+	defer i.fsRepository.UnLock()
+
+	return i.fsRepository.WritePoolFile(pool)
+}
+
+func (i *PoolInteractor) Get(poolUuid model.Uuid, requestTenantUuid model.Uuid) (*model.Pool, error) {
+	i.fsRepository.Lock()
+	defer i.fsRepository.UnLock()
+
+	return i.fsRepository.GetPoolByUuid(poolUuid, requestTenantUuid)
+}
+
+func (i *PoolInteractor) GetPoolsList(requestTenantUuid model.Uuid) ([]*model.Pool, error) {
+	i.fsRepository.Lock()
+	defer i.fsRepository.UnLock()
+
+	return i.fsRepository.LoadTenantAllPools(requestTenantUuid)
+}
+
+// Delete deletes the pool. Hosts allocated from the pool keep their addresses.
+func (i *PoolInteractor) Delete(poolUuid model.Uuid, requestTenantUuid model.Uuid) error {
+	i.fsRepository.Lock()
+	defer i.fsRepository.UnLock()
+
+	pool, err := i.fsRepository.GetPoolByUuid(poolUuid, requestTenantUuid)
+	if err != nil {
+		return err
+	}
+
+	return i.fsRepository.DeletePoolFile(pool)
+}
@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"log/slog"
+
+	"coredns_api/internal/model"
+)
+
+type ReverseZoneInteractor struct {
+	fsRepository IFilesystemRepository
+}
+
+func NewReverseZoneInteractor(fRepo IFilesystemRepository) *ReverseZoneInteractor {
+	return &ReverseZoneInteractor{fRepo}
+}
+
+// WithLogger returns a ReverseZoneInteractor which writes the logs with the logger, like the one of a request.
+func (i *ReverseZoneInteractor) WithLogger(logger *slog.Logger) *ReverseZoneInteractor {
+	return &ReverseZoneInteractor{i.fsRepository.WithLogger(logger)}
+}
+
+// GetReverseZones returns the reverse zones, which have only the PTR records and conflicts
+// of the hosts in the domains of the tenant.
+func (i *ReverseZoneInteractor) GetReverseZones(requestTenantUuid model.Uuid) ([]*model.ReverseZone, error) {
+	i.fsRepository.Lock()
+	defer i.fsRepository.UnLock()
+
+	domains, err := i.fsRepository.LoadTenantAllDomains(requestTenantUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	hostnames := map[string]bool{}
+	for _, d := range domains {
+		for _, h := range d.Hosts {
+			hostnames[h.Name] = true
+		}
+	}
+
+	zones, err := i.fsRepository.LoadReverseZones()
+	if err != nil {
+		return nil, err
+	}
+
+	var tenantZones []*model.ReverseZone
+	for _, z := range zones {
+		tenantZones = append(tenantZones, z.FilterNames(hostnames))
+	}
+	return tenantZones, nil
+}
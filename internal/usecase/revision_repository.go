@@ -0,0 +1,35 @@
+package usecase
+
+import (
+	"log/slog"
+
+	"coredns_api/internal/model"
+)
+
+// revisionRepository checks the revision of the domain got by UUID,
+// so that the domain is changed only when nobody changed it since the client got it.
+// The check is done in the lock of the interactor, together with the change.
+type revisionRepository struct {
+	IFilesystemRepository
+	revisions []uint64
+}
+
+func newRevisionRepository(fRepo IFilesystemRepository, revisions []uint64) IFilesystemRepository {
+	return &revisionRepository{IFilesystemRepository: fRepo, revisions: revisions}
+}
+
+func (r *revisionRepository) GetDomainByUuid(domainUuid model.Uuid, requestTenantUuid model.Uuid) (*model.Domain, error) {
+	domain, err := r.IFilesystemRepository.GetDomainByUuid(domainUuid, requestTenantUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if !domain.MatchRevision(r.revisions) {
+		return nil, model.NewDomainRevisionMismatchError(domain.Name, domain.Revision)
+	}
+	return domain, nil
+}
+
+func (r *revisionRepository) WithLogger(logger *slog.Logger) IFilesystemRepository {
+	return newRevisionRepository(r.IFilesystemRepository.WithLogger(logger), r.revisions)
+}
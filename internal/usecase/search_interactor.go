@@ -0,0 +1,29 @@
+package usecase
+
+import (
+	"log/slog"
+
+	"coredns_api/internal/model"
+)
+
+type SearchInteractor struct {
+	fsRepository IFilesystemRepository
+}
+
+func NewSearchInteractor(fRepo IFilesystemRepository) *SearchInteractor {
+	return &SearchInteractor{fRepo}
+}
+
+// WithLogger returns a SearchInteractor which writes the logs with the logger, like the one of a request.
+func (i *SearchInteractor) WithLogger(logger *slog.Logger) *SearchInteractor {
+	return &SearchInteractor{i.fsRepository.WithLogger(logger)}
+}
+
+// SearchHosts returns the hosts matching the search in the domains the tenant can access.
+// Admin tenants search every domain.
+func (i *SearchInteractor) SearchHosts(search *model.HostSearch, requestTenantUuid model.Uuid) ([]*model.SearchHit, error) {
+	i.fsRepository.Lock()
+	defer i.fsRepository.UnLock()
+
+	return i.fsRepository.SearchHosts(search, requestTenantUuid, model.IsAdminTenant(requestTenantUuid))
+}
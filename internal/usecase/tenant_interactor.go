@@ -1,18 +1,32 @@
 package usecase
 
-import "coredns_api/internal/model"
+import (
+	"log/slog"
+
+	"coredns_api/internal/model"
+)
 
 type TenantInteractor struct {
 	fsRepository IFilesystemRepository
 }
 
 func NewTenantInteractor(fRepo IFilesystemRepository) *TenantInteractor {
-	r := &TenantInteractor{fsRepository: fRepo}
-	r.fsRepository.Initialize()
-	return r
+	return &TenantInteractor{fsRepository: fRepo}
+}
+
+// WithLogger returns a TenantInteractor which writes the logs with the logger, like the one of a request.
+func (i *TenantInteractor) WithLogger(logger *slog.Logger) *TenantInteractor {
+	return &TenantInteractor{i.fsRepository.WithLogger(logger)}
+}
+
+func (t *TenantInteractor) GetDomainList(requestTenantUuid model.Uuid) ([]*model.Domain, error) {
+	t.fsRepository.Lock()
+	defer t.fsRepository.UnLock()
+
+	return t.fsRepository.LoadTenantAllDomains(requestTenantUuid)
 }
 
-func (t *TenantInteractor) GetDomainList() ([]*model.Domain, error) {
+func (t *TenantInteractor) GetAllDomainsList() ([]*model.Domain, error) {
 	t.fsRepository.Lock()
 	defer t.fsRepository.UnLock()
 
@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"coredns_api/internal/model"
+)
+
+const (
+	webhookQueueSize = 1024
+	webhookRetryWait = time.Second
+)
+
+// WebhookDispatcher sends the events to a webhook in background.
+type WebhookDispatcher struct {
+	events     IEventBus
+	repository IWebhookRepository
+	retryWait  time.Duration
+}
+
+func NewWebhookDispatcher(events IEventBus, wRepo IWebhookRepository) *WebhookDispatcher {
+	return &WebhookDispatcher{events: events, repository: wRepo, retryWait: webhookRetryWait}
+}
+
+// Run sends the events to the webhook in the published order. It never returns.
+// A failed event is resent up to maxRetries times with exponential backoff,
+// and written as a dead letter after that.
+//
+// The events are moved from the subscription to the queue of the dispatcher as soon as they are published,
+// so the subscription is not closed while an event is retried.
+// The events which overflow the queue are written as dead letters without being sent.
+func (d *WebhookDispatcher) Run(webhook *model.Webhook, maxRetries int) {
+	queue := make(chan *model.Event, webhookQueueSize)
+	go func() {
+		for event := range queue {
+			d.deliver(webhook, event, maxRetries)
+		}
+	}()
+
+	// The dead letters are written by another goroutine, so the subscription is received without waiting for the file.
+	overflowed := &eventBacklog{}
+	overflowed.cond = sync.NewCond(&overflowed.lock)
+	go func() {
+		for {
+			for _, event := range overflowed.takeAll() {
+				d.writeDeadLetter(webhook, event, 0, model.NewServerSideError("webhook queue is full"))
+			}
+		}
+	}()
+
+	for {
+		subscription := d.events.Subscribe(webhookQueueSize)
+		for event := range subscription.Events() {
+			select {
+			case queue <- event:
+			default:
+				overflowed.add(event)
+			}
+		}
+		slog.Warn("events are dropped since the dispatcher can't keep up with them", "url", webhook.Url)
+	}
+}
+
+func (d *WebhookDispatcher) deliver(webhook *model.Webhook, event *model.Event, maxRetries int) {
+	wait := d.retryWait
+	var err error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(wait)
+			wait *= 2
+		}
+
+		err = d.repository.Send(webhook, event)
+		if err == nil {
+			return
+		}
+	}
+
+	d.writeDeadLetter(webhook, event, maxRetries+1, err)
+}
+
+func (d *WebhookDispatcher) writeDeadLetter(webhook *model.Webhook, event *model.Event, attempts int, cause error) {
+	letter := model.NewDeadLetter(webhook, event, attempts, cause)
+	err := d.repository.WriteDeadLetter(letter)
+	if err != nil {
+		slog.Error("failed to write the dead letter", "url", webhook.Url, "event_id", event.Uuid, "domain_uuid", event.DomainUuid, "error", err)
+	}
+}
+
+// eventBacklog keeps the events until they are taken, without the limit of the size.
+type eventBacklog struct {
+	lock   sync.Mutex
+	cond   *sync.Cond
+	events []*model.Event
+}
+
+func (b *eventBacklog) add(event *model.Event) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.events = append(b.events, event)
+	b.cond.Signal()
+}
+
+// takeAll waits for the events, and returns all of them.
+func (b *eventBacklog) takeAll() []*model.Event {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for len(b.events) == 0 {
+		b.cond.Wait()
+	}
+	events := b.events
+	b.events = nil
+	return events
+}
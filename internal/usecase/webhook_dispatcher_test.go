@@ -0,0 +1,244 @@
+package usecase_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"coredns_api/internal/infrastructure"
+	"coredns_api/internal/interface/repository"
+	"coredns_api/internal/model"
+	"coredns_api/internal/usecase"
+)
+
+// fakeWebhookClient records the events of the domain posted to it.
+// The dispatchers are never stopped, so the events of the other domains are accepted and ignored.
+type fakeWebhookClient struct {
+	domainUuid model.Uuid
+	probeUuid  model.Uuid
+	// post is called with the events of the domain. It is nil to accept every event.
+	post func(event *model.Event) error
+
+	lock   sync.Mutex
+	probed bool
+	events []*model.Event
+	times  []time.Time
+}
+
+func newFakeWebhookClient(domainUuid model.Uuid, post func(event *model.Event) error) *fakeWebhookClient {
+	return &fakeWebhookClient{domainUuid: domainUuid, probeUuid: model.Uuid(model.NewRequestId("")), post: post}
+}
+
+func (c *fakeWebhookClient) Post(url string, headers map[string]string, body []byte) error {
+	var event model.Event
+	err := json.Unmarshal(body, &event)
+	if err != nil {
+		return err
+	}
+
+	c.lock.Lock()
+	if event.DomainUuid == c.probeUuid {
+		c.probed = true
+	}
+	if event.DomainUuid != c.domainUuid {
+		c.lock.Unlock()
+		return nil
+	}
+	c.events = append(c.events, &event)
+	c.times = append(c.times, time.Now())
+	c.lock.Unlock()
+
+	if c.post == nil {
+		return nil
+	}
+	return c.post(&event)
+}
+
+func (c *fakeWebhookClient) isProbed() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.probed
+}
+
+func (c *fakeWebhookClient) received() ([]*model.Event, []time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]*model.Event(nil), c.events...), append([]time.Time(nil), c.times...)
+}
+
+// runTestDispatcher runs the dispatcher with the fake client, and the dead letters written to a temporary file.
+// It returns the webhook and the path of the dead letter file after the dispatcher subscribes to the events.
+func runTestDispatcher(t *testing.T, events usecase.IEventBus, client *fakeWebhookClient, maxRetries int) (*model.Webhook, string) {
+	path := filepath.Join(t.TempDir(), "dead_letters.jsonl")
+	t.Setenv("WEBHOOK_DEAD_LETTER_PATH", path)
+
+	dispatcher := usecase.NewWebhookDispatcher(events, repository.NewWebhookRepository(client, infrastructure.NewFilesystem()))
+	dispatcher.SetRetryWait(10 * time.Millisecond)
+	// The dispatchers of the other tests are still running, so the URL tells the dead letters of this one.
+	webhook := &model.Webhook{Url: "http://127.0.0.1/" + string(client.probeUuid), Secret: "secret"}
+	go dispatcher.Run(webhook, maxRetries)
+
+	probe := &model.Domain{Uuid: client.probeUuid, Name: "probe.hoge"}
+	for i := 0; i < 100 && !client.isProbed(); i++ {
+		events.Publish(model.NewDomainEvent(model.EventDomainAdded, nil, probe))
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !client.isProbed() {
+		t.Fatal("dispatcher doesn't subscribe to the events")
+	}
+	return webhook, path
+}
+
+// readDeadLetters returns the dead letters of the webhook in the file.
+func readDeadLetters(t *testing.T, path string, webhook *model.Webhook) []*model.DeadLetter {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var letters []*model.DeadLetter
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var letter model.DeadLetter
+		err = json.Unmarshal([]byte(line), &letter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if letter.Url == webhook.Url {
+			letters = append(letters, &letter)
+		}
+	}
+	return letters
+}
+
+// waitFor polls the condition for a few seconds.
+func waitFor(condition func() bool) bool {
+	for i := 0; i < 500; i++ {
+		if condition() {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return condition()
+}
+
+func TestWebhookQueueOverflow(t *testing.T) {
+	events := repository.NewEventBus()
+	domain, _ := model.NewOriginalDomain("hogehoge.hoge", []string{testTenant})
+
+	// The first event blocks the delivery until the test ends.
+	release := make(chan struct{})
+	defer close(release)
+	blocked := make(chan struct{}, 1)
+	client := newFakeWebhookClient(domain.Uuid, func(event *model.Event) error {
+		select {
+		case blocked <- struct{}{}:
+			<-release
+		default:
+		}
+		return nil
+	})
+	webhook, path := runTestDispatcher(t, events, client, 0)
+
+	events.Publish(model.NewDomainEvent(model.EventDomainAdded, nil, domain))
+	<-blocked
+
+	// 1024 events wait in the queue, and the others are written as dead letters.
+	// The events are published slowly enough for the subscription to be received.
+	for i := 0; i < 1024+3; i++ {
+		events.Publish(model.NewDomainEvent(model.EventTenantsChanged, domain, domain))
+		if i%64 == 0 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	if !waitFor(func() bool { return len(readDeadLetters(t, path, webhook)) == 3 }) {
+		t.Error("overflowed events are not written as dead letters")
+		return
+	}
+	for _, letter := range readDeadLetters(t, path, webhook) {
+		if letter.Attempts != 0 || letter.Event.Type != model.EventTenantsChanged {
+			t.Error("dead letter of the overflowed event is missmatched")
+		}
+	}
+}
+
+func TestWebhookRetry(t *testing.T) {
+	events := repository.NewEventBus()
+	domain, _ := model.NewOriginalDomain("hogehoge.hoge", []string{testTenant})
+
+	// The event fails twice, and is sent at the third attempt.
+	failures := 2
+	client := newFakeWebhookClient(domain.Uuid, func(event *model.Event) error {
+		if failures > 0 {
+			failures--
+			return errors.New("webhook is unavailable")
+		}
+		return nil
+	})
+	webhook, path := runTestDispatcher(t, events, client, 3)
+
+	added := model.NewDomainEvent(model.EventDomainAdded, nil, domain)
+	events.Publish(added)
+	deleted := model.NewDomainEvent(model.EventDomainDeleted, domain, nil)
+	events.Publish(deleted)
+
+	if !waitFor(func() bool { received, _ := client.received(); return len(received) == 4 }) {
+		t.Error("event is not retried")
+		return
+	}
+
+	received, times := client.received()
+	for i, event := range received[:3] {
+		if event.Uuid != added.Uuid {
+			t.Error("retried event is missmatched", i)
+		}
+	}
+	if received[3].Uuid != deleted.Uuid {
+		t.Error("next event is not sent after the retried one")
+	}
+
+	// The wait is doubled on every retry from 10ms.
+	if times[1].Sub(times[0]) < 10*time.Millisecond || times[2].Sub(times[1]) < 20*time.Millisecond {
+		t.Error("retries are not backed off")
+	}
+
+	if len(readDeadLetters(t, path, webhook)) != 0 {
+		t.Error("dead letter is written for the sent event")
+	}
+}
+
+func TestWebhookDeadLetter(t *testing.T) {
+	events := repository.NewEventBus()
+	domain, _ := model.NewOriginalDomain("hogehoge.hoge", []string{testTenant})
+
+	client := newFakeWebhookClient(domain.Uuid, func(event *model.Event) error {
+		return errors.New("webhook is unavailable")
+	})
+	webhook, path := runTestDispatcher(t, events, client, 2)
+
+	event := model.NewDomainEvent(model.EventDomainAdded, nil, domain)
+	events.Publish(event)
+
+	if !waitFor(func() bool { return len(readDeadLetters(t, path, webhook)) == 1 }) {
+		t.Error("dead letter is not written")
+		return
+	}
+
+	received, _ := client.received()
+	if len(received) != 3 {
+		t.Error("event is not sent for the max retries")
+	}
+
+	letter := readDeadLetters(t, path, webhook)[0]
+	if letter.Attempts != 3 || letter.Error != "webhook is unavailable" || letter.Event.Uuid != event.Uuid {
+		t.Error("dead letter is missmatched")
+	}
+}
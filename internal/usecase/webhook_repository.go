@@ -0,0 +1,8 @@
+package usecase
+
+import "coredns_api/internal/model"
+
+type IWebhookRepository interface {
+	Send(webhook *model.Webhook, event *model.Event) error
+	WriteDeadLetter(letter *model.DeadLetter) error
+}
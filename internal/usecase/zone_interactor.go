@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"log/slog"
+	"time"
+
+	"coredns_api/internal/model"
+)
+
+type ZoneInteractor struct {
+	fsRepository   IFilesystemRepository
+	zoneRepository IZoneRepository
+	events         IEventBus
+	metrics        IMetrics
+}
+
+func NewZoneInteractor(fRepo IFilesystemRepository, zRepo IZoneRepository, events IEventBus, metrics IMetrics) *ZoneInteractor {
+	return &ZoneInteractor{fsRepository: fRepo, zoneRepository: zRepo, events: events, metrics: metrics}
+}
+
+// WithLogger returns a ZoneInteractor which writes the logs with the logger, like the one of a request.
+func (i *ZoneInteractor) WithLogger(logger *slog.Logger) *ZoneInteractor {
+	return &ZoneInteractor{fsRepository: i.fsRepository.WithLogger(logger), zoneRepository: i.zoneRepository.WithLogger(logger), events: i.events, metrics: i.metrics}
+}
+
+// Stage returns a ZoneInteractor which works against a staged repository,
+// and the staged repository to get the changes from. It publishes no events and records no metrics.
+func (i *ZoneInteractor) Stage() (*ZoneInteractor, IStagedRepository) {
+	staged := i.fsRepository.Stage()
+	return &ZoneInteractor{fsRepository: staged, zoneRepository: i.zoneRepository}, staged
+}
+
+// ImportZoneFile creates a new domain from RFC 1035 master file.
+func (i *ZoneInteractor) ImportZoneFile(name string, tenantList []string, zoneFile string) (_ *model.Domain, _ []*model.SkippedZoneRecord, err error) {
+	defer observe(i.metrics, "zone_import_file", time.Now(), &err)
+
+	domainName, err := model.NewDomainName(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	records, err := i.zoneRepository.LoadZoneFile(domainName, zoneFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return i.importRecords(name, tenantList, records)
+}
+
+// ImportTransfer creates a new domain from the zone transferred with AXFR.
+// Only the servers in ZONE_TRANSFER_SERVERS are connected to.
+func (i *ZoneInteractor) ImportTransfer(name string, tenantList []string, server string, tsigKey *model.TsigKey) (_ *model.Domain, _ []*model.SkippedZoneRecord, err error) {
+	defer observe(i.metrics, "zone_import_transfer", time.Now(), &err)
+
+	domainName, err := model.NewDomainName(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	server, err = model.AllowZoneTransfer(server)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	records, err := i.zoneRepository.TransferZone(domainName, server, tsigKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return i.importRecords(name, tenantList, records)
+}
+
+func (i *ZoneInteractor) importRecords(name string, tenantList []string, records []*model.ZoneRecord) (*model.Domain, []*model.SkippedZoneRecord, error) {
+	domain, skipped, err := model.NewDomainFromZoneRecords(name, tenantList, records)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	i.fsRepository.Lock()
+	defer i.fsRepository.UnLock()
+
+	domains, err := i.fsRepository.LoadAllDomains()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, d := range domains {
+		if d.Name == domain.Name {
+			return nil, nil, NewDomainDuplicatedError(domain.Name.String())
+		}
+	}
+
+	err = i.fsRepository.WriteDomainFile(domain)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = i.fsRepository.WriteConfCache()
+	if err != nil {
+		_ = i.fsRepository.DeleteDomainFile(domain)
+		return nil, nil, err
+	}
+
+	publish(i.events, model.NewDomainEvent(model.EventDomainAdded, nil, domain))
+	for _, h := range domain.Hosts {
+		publish(i.events, model.NewHostEvent(model.EventHostAdded, domain, nil, h))
+	}
+	return domain, skipped, nil
+}
+
+// GetZone returns a copy of the domain with the name, which can be read without the lock.
+func (i *ZoneInteractor) GetZone(domainName model.DomainName) (*model.Domain, error) {
+	i.fsRepository.Lock()
+	defer i.fsRepository.UnLock()
+
+	domains, err := i.fsRepository.LoadAllDomains()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range domains {
+		if d.Name == domainName {
+			return d.Clone(), nil
+		}
+	}
+	return nil, model.NewDomainNotFoundError()
+}
+
+// GetZoneList returns copies of all domains, which can be read without the lock.
+func (i *ZoneInteractor) GetZoneList() ([]*model.Domain, error) {
+	i.fsRepository.Lock()
+	defer i.fsRepository.UnLock()
+
+	domains, err := i.fsRepository.LoadAllDomains()
+	if err != nil {
+		return nil, err
+	}
+
+	var zones []*model.Domain
+	for _, d := range domains {
+		zones = append(zones, d.Clone())
+	}
+	return zones, nil
+}
+
+// Watch returns a watcher which is told the names of the domains changed after this call.
+func (i *ZoneInteractor) Watch() IDomainWatcher {
+	return i.fsRepository.Watch()
+}
@@ -0,0 +1,13 @@
+package usecase
+
+import (
+	"log/slog"
+
+	"coredns_api/internal/model"
+)
+
+type IZoneRepository interface {
+	LoadZoneFile(domainName model.DomainName, zoneFile string) ([]*model.ZoneRecord, error)
+	TransferZone(domainName model.DomainName, server string, tsigKey *model.TsigKey) ([]*model.ZoneRecord, error)
+	WithLogger(logger *slog.Logger) IZoneRepository
+}
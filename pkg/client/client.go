@@ -0,0 +1,249 @@
+// Package client is the Go client of the REST API for standalone coredns.
+//
+//	c := client.NewClient("http://127.0.0.1:8080", client.WithTenant("df397e50-8006-450e-b18b-5c5bd940baff"))
+//	domains, err := c.ListDomains(ctx)
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultMaxRetries = 3
+	defaultRetryWait  = 100 * time.Millisecond
+)
+
+// Client calls the API server.
+// Its methods are safe for concurrent use.
+type Client struct {
+	baseURL    string
+	tenant     string
+	httpClient *http.Client
+	maxRetries int
+	retryWait  time.Duration
+}
+
+type Option func(*Client)
+
+// WithTenant sets the tenant UUID which is sent in the Tenant header for access control.
+func WithTenant(tenant string) Option {
+	return func(c *Client) {
+		c.tenant = tenant
+	}
+}
+
+// WithHTTPClient sets the HTTP client to send the requests with.
+// It can be used to set the timeout, TLS or proxy settings.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRetry sets how many times the request is retried on 5xx responses and connection errors,
+// and the wait before the first retry. The wait is doubled on every retry.
+func WithRetry(maxRetries int, wait time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.retryWait = wait
+	}
+}
+
+func NewClient(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		maxRetries: defaultMaxRetries,
+		retryWait:  defaultRetryWait,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Tenant returns a copy of the client which sends the other tenant UUID.
+func (c *Client) Tenant(tenant string) *Client {
+	copied := *c
+	copied.tenant = tenant
+	return &copied
+}
+
+// ListOptions are the filters and the page of list requests.
+// Tenant is only for admin tenants to list the domains of the tenant.
+type ListOptions struct {
+	Name    string
+	Address string
+	Tenant  string
+	Limit   int
+	Cursor  string
+}
+
+func (o ListOptions) query() url.Values {
+	query := url.Values{}
+	if o.Name != "" {
+		query.Set("name", o.Name)
+	}
+	if o.Address != "" {
+		query.Set("address", o.Address)
+	}
+	if o.Tenant != "" {
+		query.Set("tenant", o.Tenant)
+	}
+	if o.Limit > 0 {
+		query.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Cursor != "" {
+		query.Set("cursor", o.Cursor)
+	}
+	return query
+}
+
+// NextCursor returns the cursor in the next link of the page, or empty on the last page.
+func NextCursor(next string) string {
+	u, err := url.Parse(next)
+	if err != nil {
+		return ""
+	}
+	return u.Query().Get("cursor")
+}
+
+// request is an API request.
+// body is encoded to JSON unless it is a string, which is sent as it is with contentType.
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	body        interface{}
+	contentType string
+	accept      string
+}
+
+// do sends the request, and decodes the response body into result if it is not nil.
+// Requests except POST are retried on 5xx responses and connection errors,
+// since POST adds domains and hosts, and retrying it might add them twice.
+func (c *Client) do(ctx context.Context, req request, result interface{}) error {
+	body, contentType, err := encodeBody(req)
+	if err != nil {
+		return err
+	}
+
+	u := c.baseURL + req.path
+	if len(req.query) > 0 {
+		u += "?" + req.query.Encode()
+	}
+
+	retries := c.maxRetries
+	if req.method == http.MethodPost {
+		retries = 0
+	}
+
+	wait := c.retryWait
+	for attempt := 0; ; attempt++ {
+		respBody, err := c.send(ctx, req, u, body, contentType)
+		if err == nil {
+			return decodeBody(respBody, result)
+		}
+
+		if attempt >= retries || !isRetryable(ctx, err) {
+			return err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		wait *= 2
+	}
+}
+
+func (c *Client) send(ctx context.Context, req request, u string, body []byte, contentType string) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	httpReq, err := http.NewRequest(req.method, u, reader)
+	if err != nil {
+		return nil, err
+	}
+	httpReq = httpReq.WithContext(ctx)
+
+	if c.tenant != "" {
+		httpReq.Header.Set("Tenant", c.tenant)
+	}
+	if contentType != "" {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+	if req.accept != "" {
+		httpReq.Header.Set("Accept", req.accept)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 300 {
+		return nil, newResponseError(resp.StatusCode, respBody)
+	}
+	return respBody, nil
+}
+
+func encodeBody(req request) ([]byte, string, error) {
+	switch b := req.body.(type) {
+	case nil:
+		return nil, "", nil
+	case string:
+		return []byte(b), req.contentType, nil
+	default:
+		body, err := json.Marshal(b)
+		if err != nil {
+			return nil, "", err
+		}
+		return body, "application/json", nil
+	}
+}
+
+func decodeBody(body []byte, result interface{}) error {
+	if result == nil || len(body) == 0 {
+		return nil
+	}
+
+	if s, ok := result.(*string); ok {
+		*s = string(body)
+		return nil
+	}
+	return json.Unmarshal(body, result)
+}
+
+func isRetryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	apiErr, ok := err.(interface{ statusCode() int })
+	if !ok {
+		// Connection errors
+		return true
+	}
+	return apiErr.statusCode() >= 500
+}
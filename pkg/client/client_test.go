@@ -0,0 +1,346 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"coredns_api/cmd/web/infrastructure"
+)
+
+const (
+	testTenant      = "df397e50-8006-450e-b18b-5c5bd940baff"
+	testOtherTenant = "02c03bd4-fe2e-45f2-85b6-b535af15215d"
+)
+
+var testServerURL string
+
+// TestMain runs the API server in process against the hosts files in a temp dir.
+func TestMain(m *testing.M) {
+	dir, err := ioutil.TempDir("", "coredns_api_client")
+	if err != nil {
+		panic(err)
+	}
+
+	hostsDir := filepath.Join(dir, "hosts")
+	confPath := filepath.Join(dir, "coredns.conf")
+	err = os.Mkdir(hostsDir, 0755)
+	if err != nil {
+		panic(err)
+	}
+	err = ioutil.WriteFile(confPath, []byte(". {\n    forward . 8.8.8.8\n}\n"), 0644)
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("HOSTS_DIR", hostsDir)
+	os.Setenv("CONF_PATH", confPath)
+
+	gin.SetMode(gin.TestMode)
+	infrastructure.InitializeFilesystemRepository().Initialize()
+	server := httptest.NewServer(infrastructure.NewRouter())
+	testServerURL = server.URL
+
+	code := m.Run()
+
+	server.Close()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestDomain(t *testing.T) {
+	ctx := context.Background()
+	c := NewClient(testServerURL, WithTenant(testTenant))
+
+	domain, err := c.AddDomain(ctx, "domain.client.test", []string{testTenant})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	domains, err := c.ListDomains(ctx)
+	if err != nil {
+		t.Error(err)
+	}
+	found := false
+	for _, d := range domains {
+		if d.Uuid == domain.Uuid {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("added domain is not listed")
+	}
+
+	domain, err = c.UpdateDomainTenants(ctx, domain.Uuid, []string{testTenant, testOtherTenant})
+	if err != nil {
+		t.Error(err)
+	}
+	if len(domain.Tenants) != 2 {
+		t.Error("tenants are not updated")
+	}
+
+	got, err := c.GetDomain(ctx, domain.Uuid)
+	if err != nil {
+		t.Error(err)
+	} else if got.Domain != "domain.client.test" || len(got.Tenants) != 2 {
+		t.Error("domain is missmatched: " + got.Domain)
+	}
+
+	err = c.DeleteDomain(ctx, domain.Uuid)
+	if err != nil {
+		t.Error(err)
+	}
+
+	_, err = c.GetDomain(ctx, domain.Uuid)
+	var notFound *NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Errorf("deleted domain is found: %v", err)
+	}
+}
+
+func TestHost(t *testing.T) {
+	ctx := context.Background()
+	c := NewClient(testServerURL, WithTenant(testTenant))
+
+	domain, err := c.AddDomain(ctx, "host.client.test", []string{testTenant})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer c.DeleteDomain(ctx, domain.Uuid)
+
+	domain, err = c.AddHost(ctx, domain.Uuid, HostRequest{Name: "server1.host.client.test", Address: "172.21.1.1"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(domain.Hosts) != 1 {
+		t.Error("host is not added")
+		return
+	}
+	hostUuid := domain.Hosts[0].Uuid
+
+	_, err = c.AddHost(ctx, domain.Uuid, HostRequest{Name: "server1.host.client.test", Address: "172.21.1.2"})
+	var duplicated *DuplicatedError
+	if !errors.As(err, &duplicated) {
+		t.Errorf("duplicated host is added: %v", err)
+	}
+
+	err = c.UpdateHost(ctx, domain.Uuid, hostUuid, HostRequest{Address: "172.21.1.3"})
+	if err != nil {
+		t.Error(err)
+	}
+
+	host, err := c.GetHost(ctx, domain.Uuid, hostUuid)
+	if err != nil {
+		t.Error(err)
+	} else if host.Address != "172.21.1.3" {
+		t.Error("host is not updated: " + host.Address)
+	}
+
+	imported, err := c.ImportHosts(ctx, domain.Uuid, "hosts", "", "172.21.1.4  server2.host.client.test\n")
+	if err != nil {
+		t.Error(err)
+	} else if len(imported.Imported) != 1 {
+		t.Error("host is not imported")
+	}
+
+	exported, err := c.ExportHosts(ctx, domain.Uuid, "csv")
+	if err != nil {
+		t.Error(err)
+	}
+	if !strings.Contains(exported, "server2.host.client.test") {
+		t.Error("imported host is not exported: " + exported)
+	}
+
+	applied, err := c.ApplyHosts(ctx, domain.Uuid, []HostRequest{{Name: "server1.host.client.test", Address: "172.21.1.3"}})
+	if err != nil {
+		t.Error(err)
+	} else if len(applied.Changes.Deleted) != 1 {
+		t.Error("host is not deleted by apply")
+	}
+
+	err = c.DeleteHost(ctx, domain.Uuid, hostUuid)
+	if err != nil {
+		t.Error(err)
+	}
+
+	hosts, err := c.ListHosts(ctx, domain.Uuid)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(hosts) != 0 {
+		t.Error("host is not deleted")
+	}
+}
+
+func TestHostPage(t *testing.T) {
+	ctx := context.Background()
+	c := NewClient(testServerURL, WithTenant(testTenant))
+
+	domain, err := c.AddDomain(ctx, "page.client.test", []string{testTenant})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer c.DeleteDomain(ctx, domain.Uuid)
+
+	_, err = c.ImportHosts(ctx, domain.Uuid, "hosts", "",
+		"172.21.1.3  server3.page.client.test\n172.21.1.1  server1.page.client.test\n172.21.2.2  server2.page.client.test\n")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	var names []string
+	opts := ListOptions{Address: "172.21.1.0/24", Limit: 1}
+	for {
+		page, err := c.ListHostsPage(ctx, domain.Uuid, opts)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if page.Total != 2 {
+			t.Errorf("total is missmatched: %d", page.Total)
+		}
+		for _, h := range page.Hosts {
+			names = append(names, h.Name)
+		}
+
+		opts.Cursor = NextCursor(page.Next)
+		if opts.Cursor == "" {
+			break
+		}
+	}
+
+	if strings.Join(names, ",") != "server1.page.client.test,server3.page.client.test" {
+		t.Error("hosts are missmatched: " + strings.Join(names, ","))
+	}
+}
+
+func TestPermission(t *testing.T) {
+	ctx := context.Background()
+	c := NewClient(testServerURL, WithTenant(testTenant))
+
+	domain, err := c.AddDomain(ctx, "permission.client.test", []string{testTenant})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer c.DeleteDomain(ctx, domain.Uuid)
+
+	_, err = c.Tenant(testOtherTenant).GetDomain(ctx, domain.Uuid)
+	var permission *PermissionError
+	if !errors.As(err, &permission) {
+		t.Errorf("domain is accessible from other tenant: %v", err)
+	}
+
+	tenants, err := c.ListTenants(ctx)
+	if err != nil {
+		t.Error(err)
+	}
+	found := false
+	for _, tenant := range tenants {
+		if tenant.Uuid == testTenant {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("tenant is not listed")
+	}
+}
+
+func TestRetry(t *testing.T) {
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&count, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"code":503,"message":"unavailable"}`))
+			return
+		}
+		w.Write([]byte(`{"domains":[]}`))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	c := NewClient(server.URL, WithTenant(testTenant), WithRetry(3, time.Millisecond))
+
+	_, err := c.ListDomains(ctx)
+	if err != nil {
+		t.Error(err)
+	}
+	if count != 3 {
+		t.Errorf("request is not retried: %d", count)
+	}
+
+	atomic.StoreInt32(&count, 0)
+	_, err = c.AddDomain(ctx, "retry.client.test", []string{testTenant})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("POST is retried: %d", count)
+	}
+}
+
+func TestContextCancel(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer server.Close()
+	defer close(done)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	c := NewClient(server.URL, WithTenant(testTenant))
+	_, err := c.ListDomains(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("request is not canceled: %v", err)
+	}
+}
+
+func TestSearchHosts(t *testing.T) {
+	ctx := context.Background()
+	c := NewClient(testServerURL, WithTenant(testTenant))
+
+	domain, err := c.AddDomain(ctx, "search.client.test", []string{testTenant})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer c.DeleteDomain(ctx, domain.Uuid)
+
+	_, err = c.ImportHosts(ctx, domain.Uuid, "hosts", "",
+		"172.21.3.1  server1.search.client.test\n172.21.3.2  server2.search.client.test\n")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	hosts, err := c.SearchHosts(ctx, "server*", "172.21.3.0/24")
+	if err != nil {
+		t.Error(err)
+	} else if len(hosts) != 2 || hosts[0].Domain != "search.client.test" {
+		t.Errorf("search result is missmatched: %v", hosts)
+	}
+
+	hosts, err = c.Tenant(testOtherTenant).SearchHosts(ctx, "", "172.21.3.1")
+	if err != nil {
+		t.Error(err)
+	} else if len(hosts) != 0 {
+		t.Error("host is found from other tenant")
+	}
+}
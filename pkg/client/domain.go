@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"coredns_api/pkg/interface/controllers"
+)
+
+func domainPath(domainUuid string) string {
+	return "/v1/domains/" + url.PathEscape(domainUuid)
+}
+
+// ListDomains returns every domain accessible from the tenant, sorted by the name.
+func (c *Client) ListDomains(ctx context.Context) ([]DomainSummary, error) {
+	page, err := c.ListDomainsPage(ctx, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return page.Domains, nil
+}
+
+// ListDomainsPage returns a page of the domains matching the options.
+// The next page is got with the cursor from NextCursor(page.Next).
+func (c *Client) ListDomainsPage(ctx context.Context, opts ListOptions) (*DomainPage, error) {
+	var result DomainPage
+	err := c.do(ctx, request{method: http.MethodGet, path: "/v1/domains", query: opts.query()}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) GetDomain(ctx context.Context, domainUuid string) (*Domain, error) {
+	var result Domain
+	err := c.do(ctx, request{method: http.MethodGet, path: domainPath(domainUuid)}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// AddDomain adds the domain which is accessible from the tenants.
+func (c *Client) AddDomain(ctx context.Context, name string, tenants []string) (*Domain, error) {
+	body := controllers.DomainRequest{Name: name, Tenants: tenants}
+
+	var result Domain
+	err := c.do(ctx, request{method: http.MethodPost, path: "/v1/domains", body: body}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ImportDomain adds the domain from the zone file or the zone transfer.
+func (c *Client) ImportDomain(ctx context.Context, zone ZoneImport) (*ZoneImported, error) {
+	var result ZoneImported
+	err := c.do(ctx, request{method: http.MethodPost, path: "/v1/domains:import", body: zone}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpdateDomainTenants replaces the tenants which can access the domain.
+func (c *Client) UpdateDomainTenants(ctx context.Context, domainUuid string, tenants []string) (*Domain, error) {
+	body := controllers.DomainUpdateRequest{Tenants: tenants}
+
+	var result Domain
+	err := c.do(ctx, request{method: http.MethodPatch, path: domainPath(domainUuid), body: body}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) DeleteDomain(ctx context.Context, domainUuid string) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: domainPath(domainUuid)}, nil)
+}
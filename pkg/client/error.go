@@ -0,0 +1,77 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"coredns_api/pkg/interface/controllers"
+)
+
+// APIError is the error response from the API server.
+// It is returned as one of the typed errors below when the kind of the error is known.
+type APIError struct {
+	StatusCode int
+	Type       string
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return http.StatusText(e.StatusCode) + ": " + e.Message
+}
+
+func (e *APIError) statusCode() int {
+	return e.StatusCode
+}
+
+// NotFoundError is returned when the domain, host or pool is not found.
+type NotFoundError struct {
+	APIError
+}
+
+// PermissionError is returned when the tenant does not have permission to the domain or pool.
+type PermissionError struct {
+	APIError
+}
+
+// DuplicatedError is returned when the domain or host is already registered.
+type DuplicatedError struct {
+	APIError
+}
+
+// DegradedError is returned when the domain is degraded and read-only.
+type DegradedError struct {
+	APIError
+}
+
+// InvalidParameterError is returned when the request has an invalid parameter.
+type InvalidParameterError struct {
+	APIError
+}
+
+func newResponseError(status int, body []byte) error {
+	var httpError controllers.HTTPError
+	err := json.Unmarshal(body, &httpError)
+	if err != nil || httpError.Message == "" {
+		httpError.Message = strings.TrimSpace(string(body))
+	}
+
+	apiErr := APIError{StatusCode: status, Type: httpError.Type, Message: httpError.Message}
+	switch httpError.Type {
+	case controllers.ErrorTypeNotFound:
+		return &NotFoundError{apiErr}
+	case controllers.ErrorTypePermission:
+		return &PermissionError{apiErr}
+	case controllers.ErrorTypeDuplicated:
+		return &DuplicatedError{apiErr}
+	case controllers.ErrorTypeDegraded:
+		return &DegradedError{apiErr}
+	case controllers.ErrorTypeInvalidParameter:
+		return &InvalidParameterError{apiErr}
+	}
+
+	if status == http.StatusNotFound {
+		return &NotFoundError{apiErr}
+	}
+	return &apiErr
+}
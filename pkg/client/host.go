@@ -0,0 +1,135 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"coredns_api/pkg/interface/controllers"
+)
+
+func hostPath(domainUuid, hostUuid string) string {
+	return domainPath(domainUuid) + "/hosts/" + url.PathEscape(hostUuid)
+}
+
+// ListHosts returns every host of the domain, sorted by the hostname.
+func (c *Client) ListHosts(ctx context.Context, domainUuid string) ([]Host, error) {
+	page, err := c.ListHostsPage(ctx, domainUuid, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return page.Hosts, nil
+}
+
+// ListHostsPage returns a page of the hosts matching the options.
+// The next page is got with the cursor from NextCursor(page.Next).
+func (c *Client) ListHostsPage(ctx context.Context, domainUuid string, opts ListOptions) (*HostPage, error) {
+	var result HostPage
+	err := c.do(ctx, request{method: http.MethodGet, path: domainPath(domainUuid) + "/hosts", query: opts.query()}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) GetHost(ctx context.Context, domainUuid, hostUuid string) (*Host, error) {
+	var result Domain
+	err := c.do(ctx, request{method: http.MethodGet, path: hostPath(domainUuid, hostUuid)}, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, h := range result.Hosts {
+		if h.Uuid == hostUuid {
+			return &h, nil
+		}
+	}
+	return nil, &NotFoundError{APIError{StatusCode: http.StatusNotFound, Type: controllers.ErrorTypeNotFound,
+		Message: "target host is not found in the response"}}
+}
+
+// AddHost adds the host to the domain, and returns the domain with its hosts.
+// The address is allocated from the pool when host.Pool is set instead of host.Address.
+func (c *Client) AddHost(ctx context.Context, domainUuid string, host HostRequest) (*Domain, error) {
+	var result Domain
+	err := c.do(ctx, request{method: http.MethodPost, path: domainPath(domainUuid) + "/hosts", body: host}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpdateHost changes the given fields of the host.
+func (c *Client) UpdateHost(ctx context.Context, domainUuid, hostUuid string, host HostRequest) error {
+	return c.do(ctx, request{method: http.MethodPatch, path: hostPath(domainUuid, hostUuid), body: host}, nil)
+}
+
+func (c *Client) DeleteHost(ctx context.Context, domainUuid, hostUuid string) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: hostPath(domainUuid, hostUuid)}, nil)
+}
+
+// RenewHost extends the lease of the host. The current lease is used when lease is empty.
+func (c *Client) RenewHost(ctx context.Context, domainUuid, hostUuid, lease string) (*Host, error) {
+	req := request{method: http.MethodPost, path: hostPath(domainUuid, hostUuid) + ":renew"}
+	if lease != "" {
+		req.body = controllers.HostRenewRequest{TtlLease: lease}
+	}
+
+	var result Host
+	err := c.do(ctx, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ApplyHosts makes the hosts of the domain the same as the given ones.
+func (c *Client) ApplyHosts(ctx context.Context, domainUuid string, hosts []HostRequest) (*HostApplyResult, error) {
+	body := controllers.HostsApplyRequest{Hosts: hosts}
+
+	var result HostApplyResult
+	err := c.do(ctx, request{method: http.MethodPut, path: domainPath(domainUuid) + "/hosts", body: body}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ImportHosts adds the hosts in the host list, whose format is csv, json or hosts.
+// The hosts format is used when it is empty.
+// mode is atomic or skip_invalid, and the server default is used when it is empty.
+func (c *Client) ImportHosts(ctx context.Context, domainUuid, format, mode, hostList string) (*HostImport, error) {
+	query := url.Values{}
+	if format != "" {
+		query.Set("format", format)
+	}
+	if mode != "" {
+		query.Set("mode", mode)
+	}
+
+	req := request{method: http.MethodPost, path: domainPath(domainUuid) + "/hosts:import", query: query,
+		body: hostList, contentType: "text/plain"}
+
+	var result HostImport
+	err := c.do(ctx, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ExportHosts returns the host list of the domain with the format, which is csv, json or hosts.
+// The json format is used when it is empty.
+func (c *Client) ExportHosts(ctx context.Context, domainUuid, format string) (string, error) {
+	query := url.Values{}
+	if format != "" {
+		query.Set("format", format)
+	}
+
+	var result string
+	err := c.do(ctx, request{method: http.MethodGet, path: domainPath(domainUuid) + "/hosts:export", query: query}, &result)
+	if err != nil {
+		return "", err
+	}
+	return result, nil
+}
@@ -0,0 +1,29 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"coredns_api/pkg/interface/controllers"
+)
+
+// SearchHosts returns the hosts matching the name and the address across the accessible domains.
+// The name matches the hostnames which start with it when it ends with "*",
+// and the address can be a CIDR.
+func (c *Client) SearchHosts(ctx context.Context, name, address string) ([]SearchHost, error) {
+	query := url.Values{}
+	if name != "" {
+		query.Set("name", name)
+	}
+	if address != "" {
+		query.Set("address", address)
+	}
+
+	var result controllers.SearchResult
+	err := c.do(ctx, request{method: http.MethodGet, path: "/v1/search", query: query}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result.Hosts, nil
+}
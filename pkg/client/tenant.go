@@ -0,0 +1,19 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"coredns_api/pkg/interface/controllers"
+)
+
+// ListTenants returns the tenant of the client and its accessible domains.
+// Admin tenants get every tenant.
+func (c *Client) ListTenants(ctx context.Context) ([]Tenant, error) {
+	var result controllers.TenantInfoResult
+	err := c.do(ctx, request{method: http.MethodGet, path: "/v1/tenants"}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result.Tenants, nil
+}
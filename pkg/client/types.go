@@ -0,0 +1,21 @@
+package client
+
+import "coredns_api/pkg/interface/controllers"
+
+// The request and result types are the ones of the API server,
+// so that they are always in sync with it.
+type (
+	Domain          = controllers.DomainInfoResult
+	DomainSummary   = controllers.DomainResult
+	DomainPage      = controllers.DomainListResult
+	HostPage        = controllers.HostListResult
+	Host            = controllers.HostResult
+	HostRequest     = controllers.HostRequest
+	HostApplyResult = controllers.HostApplyResult
+	HostImport      = controllers.HostImportResult
+	ZoneImport      = controllers.ZoneImportRequest
+	ZoneTransfer    = controllers.ZoneTransferRequest
+	ZoneImported    = controllers.ZoneImportResult
+	Tenant          = controllers.TenantResult
+	SearchHost      = controllers.SearchHostResult
+)
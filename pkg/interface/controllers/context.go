@@ -1,11 +1,18 @@
 package controllers
 
+import "io"
+
 type Context interface {
 	GetHeader(key string) string
+	Header(key, value string)
 	ShouldBindJSON(obj interface{}) error
 	Param(string) string
+	Query(string) string
 	Bind(interface{}) error
 	Status(int)
 	JSON(int, interface{})
+	GetRawData() ([]byte, error)
+	Data(int, string, []byte)
+	Stream(step func(w io.Writer) bool) bool
 }
 
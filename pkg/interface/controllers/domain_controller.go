@@ -2,8 +2,9 @@ package controllers
 
 import (
 	"errors"
-	"log"
 	"net/http"
+	"sort"
+	"time"
 
 	"coredns_api/internal/model"
 	"coredns_api/internal/usecase"
@@ -29,16 +30,51 @@ type DomainResult struct {
 	Domain  string   `json:"domain"`
 	Uuid    string   `json:"uuid"`
 	Tenants []string `json:"tenants"`
+	Status  string   `json:"status,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// newDegradedDomainResult returns the result of the domain whose hosts file can't be loaded.
+// It has no hosts, and its status is "degraded" with the load error.
+func newDegradedDomainResult(dom *model.DegradedDomain) DomainInfoResult {
+	tenants := make([]string, 0)
+	for _, t := range dom.Tenants {
+		tenants = append(tenants, t.String())
+	}
+
+	var result DomainInfoResult
+	result.Domain = dom.Name.String()
+	result.Uuid = dom.Uuid.String()
+	result.Tenants = tenants
+	result.Status = "degraded"
+	result.Error = dom.Err
+	result.Hosts = make([]HostResult, 0)
+	return result
 }
 
 type HostResult struct {
-	Name    string `json:"hostname"`
-	Address string `json:"address"`
-	Uuid    string `json:"uuid"`
+	Name      string `json:"hostname"`
+	Address   string `json:"address"`
+	Uuid      string `json:"uuid"`
+	ExpiresAt string `json:"expires_at,omitempty"`
+	TtlLease  string `json:"ttl_lease,omitempty"`
+}
+
+func newHostResult(h *model.Host) HostResult {
+	result := HostResult{Name: h.Name, Address: h.Address, Uuid: h.Uuid.String()}
+	if !h.ExpiresAt.IsZero() {
+		result.ExpiresAt = h.ExpiresAt.Format(time.RFC3339)
+	}
+	if h.Lease != 0 {
+		result.TtlLease = h.Lease.String()
+	}
+	return result
 }
 
 type DomainListResult struct {
 	Domains []DomainResult `json:"domains"`
+	Total   int            `json:"total"`
+	Next    string         `json:"next,omitempty"`
 }
 
 // error to return with HTTP 500
@@ -70,18 +106,30 @@ func NewDomainController(itr *usecase.DomainInteractor) *DomainController {
 // @Accept json
 // @Produce json
 // @Param domain body DomainRequest true "Request body parameter with json format"
+// @Param dry_run query bool false "Return the file changes without writing them"
 // @Success 201 {object} DomainInfoResult
+// @Success 200 {object} DryRunResult
 // @Failure 400 {object} HTTPError
+// @Failure 409 {object} HTTPError
 // @Failure 500 {object} HTTPError
 // @Router /v1/domains [post]
 func (d *DomainController) Add(c Context) {
+	logger := NewRequestLogger(c)
+	interactor := d.interactor.WithLogger(logger)
+
+	dryRun, err := getDryRun(c)
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		return
+	}
+
 	var request DomainRequest
-	err := c.ShouldBindJSON(&request)
+	err = c.ShouldBindJSON(&request)
 	if err != nil {
 		NewError(c,
 			http.StatusInternalServerError,
 			NewUnAvailableHandlingError())
-		log.Print(err)
+		logger.Error("request failed", "error", err)
 		return
 	}
 
@@ -103,18 +151,36 @@ func (d *DomainController) Add(c Context) {
 			NewError(c,
 				http.StatusInternalServerError,
 				NewUnAvailableHandlingError())
-			log.Print(e)
+			logger.Error("unexpected error", "error", e)
 		}
-		log.Print(err)
+		logger.Warn("request failed", "error", err)
 		return
 	}
 
-	err = d.interactor.Add(newDomain)
+	var staged usecase.IStagedRepository
+	if dryRun {
+		interactor, staged = interactor.Stage()
+	}
+
+	err = interactor.Add(newDomain)
 	if err != nil {
-		NewError(c,
-			http.StatusInternalServerError,
-			NewUnAvailableHandlingError())
-		log.Print(err)
+		switch e := err.(type) {
+		case *model.InvalidCorefileError, *usecase.DomainDuplicatedError:
+			NewError(c, http.StatusBadRequest, err)
+		case *model.DomainDegradedError:
+			NewError(c, http.StatusConflict, err)
+		default:
+			NewError(c,
+				http.StatusInternalServerError,
+				NewUnAvailableHandlingError())
+			logger.Error("unexpected error", "error", e)
+		}
+		logger.Warn("request failed", "error", err)
+		return
+	}
+
+	if staged != nil {
+		returnDryRunResult(c, staged)
 		return
 	}
 
@@ -129,19 +195,30 @@ func (d *DomainController) Add(c Context) {
 	result.Uuid = newDomain.Uuid.String()
 	result.Hosts = hosts
 	result.Tenants = tenants
+	setETag(c, newDomain)
 	c.JSON(http.StatusCreated, result)
 }
 
 // List handler doc
 // @Tags Domain
 // @Summary List domains
-// @Description List domains from coredns
+// @Description List domains from coredns, sorted by the name. Domains whose hosts file can't be loaded are listed with "degraded" status and the error.
+// @Description Tenants in ADMIN_TENANTS list the domains of every tenant, and can filter them by tenant.
 // @Produce json
 // @Param Tenant header string true "Tenant UUID to set access control"
+// @Param name query string false "Domain name prefix, or glob pattern with any of *?["
+// @Param address query string false "Address or CIDR which the domain has any host in"
+// @Param tenant query string false "Tenant UUID which can access the domain. Only for admin tenants"
+// @Param limit query int false "Max number of the domains in the page"
+// @Param cursor query string false "Cursor of the page, from next of the previous page"
 // @Success 200 {object} DomainListResult
+// @Failure 400 {object} HTTPError
 // @Failure 500 {object} HTTPError
 // @Router /v1/domains [get]
 func (d *DomainController) List(c Context) {
+	logger := NewRequestLogger(c)
+	interactor := d.interactor.WithLogger(logger)
+
 	requestTenant := c.GetHeader("Tenant")
 	if requestTenant == "" {
 		NewError(c,
@@ -156,17 +233,41 @@ func (d *DomainController) List(c Context) {
 		return
 	}
 
-	domainList, err := d.interactor.GetDomainsList(requestTenantUuid)
+	query, err := getListQuery(c)
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var domainList []*model.Domain
+	var degradedList []*model.DegradedDomain
+	if model.IsAdminTenant(requestTenantUuid) {
+		domainList, degradedList, err = interactor.GetAllDomainsList()
+	} else {
+		if query.Tenant != "" && query.Tenant != requestTenantUuid {
+			NewError(c, http.StatusBadRequest, model.NewDomainPermissionError())
+			return
+		}
+
+		domainList, err = interactor.GetDomainsList(requestTenantUuid)
+		if err == nil {
+			degradedList, err = interactor.GetDegradedDomainsList(requestTenantUuid)
+		}
+	}
 	if err != nil {
 		NewError(c,
 			http.StatusInternalServerError,
 			NewUnAvailableHandlingError())
-		log.Print(err)
+		logger.Error("request failed", "error", err)
 		return
 	}
 
 	domList := make([]DomainResult, 0)
 	for _, dom := range domainList {
+		if !query.MatchDomain(dom) {
+			continue
+		}
+
 		var tenants []string
 		for _, t := range dom.Tenants {
 			tenants = append(tenants, t.String())
@@ -175,8 +276,24 @@ func (d *DomainController) List(c Context) {
 		domRes := DomainResult{Domain: dom.Name.String(), Uuid: dom.Uuid.String(), Tenants: tenants}
 		domList = append(domList, domRes)
 	}
+	for _, dom := range degradedList {
+		if query.MatchDegradedDomain(dom) {
+			domList = append(domList, newDegradedDomainResult(dom).DomainResult)
+		}
+	}
+
+	// Domain names are unique, so they are used as the cursor.
+	sort.Slice(domList, func(i, j int) bool { return domList[i].Domain < domList[j].Domain })
+	var keys []string
+	for _, dom := range domList {
+		keys = append(keys, dom.Domain)
+	}
+	start, end, next := query.Page(keys)
 
-	result := DomainListResult{Domains: domList}
+	result := DomainListResult{
+		Domains: domList[start:end],
+		Total:   len(domList),
+		Next:    query.NextLink("/v1/domains", next)}
 	c.JSON(http.StatusOK, result)
 }
 
@@ -188,12 +305,21 @@ func (d *DomainController) List(c Context) {
 // @Param Tenant header string true "Tenant UUID to set access control"
 // @Param domain_uuid path string true "Target domain's UUID"
 // @Param domain body DomainUpdateRequest true "Request body parameter with json format"
+// @Param If-Match header string false "ETag of the domain. The domain is updated only when it is not changed since then"
+// @Param dry_run query bool false "Return the file changes without writing them"
 // @Success 200 {object} DomainInfoResult
+// @Success 200 {object} DryRunResult
+// @Header 200 {string} ETag "Revision of the domain"
 // @Failure 400 {object} HTTPError
 // @Failure 404 {object} HTTPError
+// @Failure 409 {object} HTTPError
+// @Failure 412 {object} HTTPError
 // @Failure 500 {object} HTTPError
 // @Router /v1/domains/{domain_uuid} [patch]
 func (d *DomainController) Update(c Context) {
+	logger := NewRequestLogger(c)
+	interactor := d.interactor.WithLogger(logger)
+
 	requestTenant := c.GetHeader("Tenant")
 	if requestTenant == "" {
 		NewError(c,
@@ -211,7 +337,13 @@ func (d *DomainController) Update(c Context) {
 	targetDomainUuid, err := model.NewUuid(domainUuid)
 	if err != nil {
 		NewError(c, http.StatusBadRequest, err)
-		log.Print(err)
+		logger.Warn("request failed", "error", err)
+		return
+	}
+
+	dryRun, err := getDryRun(c)
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -221,7 +353,7 @@ func (d *DomainController) Update(c Context) {
 		NewError(c,
 			http.StatusInternalServerError,
 			NewUnAvailableHandlingError())
-		log.Print(err)
+		logger.Error("request failed", "error", err)
 		return
 	}
 	tenantList := request.Tenants
@@ -240,26 +372,44 @@ func (d *DomainController) Update(c Context) {
 		tenantUuidList = append(tenantUuidList, tUuid)
 	}
 
-	domain, err := d.interactor.Update(targetDomainUuid, requestTenantUuid, tenantUuidList)
+	var staged usecase.IStagedRepository
+	if dryRun {
+		interactor, staged = interactor.Stage()
+	}
+
+	if revisions, ok := getIfMatch(c); ok {
+		interactor = interactor.IfMatch(revisions)
+	}
+
+	domain, err := interactor.Update(targetDomainUuid, requestTenantUuid, tenantUuidList)
 	if err != nil {
 		switch e := err.(type) {
-		case *model.InvalidParameterGiven, *model.DomainPermissionError:
+		case *model.InvalidParameterGiven, *model.DomainPermissionError, *model.InvalidCorefileError:
 			NewError(c, http.StatusBadRequest, err)
+		case *model.DomainDegradedError:
+			NewError(c, http.StatusConflict, err)
+		case *model.DomainRevisionMismatchError:
+			NewError(c, http.StatusPreconditionFailed, err)
 		case *model.DomainNotFoundError:
 			NewError(c, http.StatusNotFound, err)
 		default:
 			NewError(c,
 				http.StatusInternalServerError,
 				NewUnAvailableHandlingError())
-			log.Print(e)
+			logger.Error("unexpected error", "error", e)
 		}
-		log.Print(err)
+		logger.Warn("request failed", "error", err)
+		return
+	}
+
+	if staged != nil {
+		returnDryRunResult(c, staged)
 		return
 	}
 
 	hosts := make([]HostResult, 0)
 	for _, h := range domain.Hosts {
-		host := HostResult{Name: h.Name, Address: h.Address, Uuid: h.Uuid.String()}
+		host := newHostResult(h)
 		hosts = append(hosts, host)
 	}
 
@@ -273,22 +423,29 @@ func (d *DomainController) Update(c Context) {
 	result.Uuid = domain.Uuid.String()
 	result.Tenants = tenants
 	result.Hosts = hosts
+	setETag(c, domain)
 	c.JSON(http.StatusOK, result)
 }
 
 // Get handler doc
 // @Tags Domain
 // @Summary Get domain
-// @Description Get domain from coredns
+// @Description Get domain from coredns. A domain whose hosts file can't be loaded is read-only, and it is returned with "degraded" status and the error
 // @Produce json
 // @Param Tenant header string true "Tenant UUID to set access control"
 // @Param domain_uuid path string true "Target domain's UUID"
+// @Param If-None-Match header string false "ETag of the domain. 304 is returned when the domain is not changed since then"
 // @Success 200 {object} DomainInfoResult
+// @Success 304
+// @Header 200 {string} ETag "Revision of the domain"
 // @Failure 400 {object} HTTPError
 // @Failure 404 {object} HTTPError
 // @Failure 500 {object} HTTPError
 // @Router /v1/domains/{domain_uuid} [get]
 func (d *DomainController) Get(c Context) {
+	logger := NewRequestLogger(c)
+	interactor := d.interactor.WithLogger(logger)
+
 	requestTenant := c.GetHeader("Tenant")
 	if requestTenant == "" {
 		NewError(c,
@@ -312,11 +469,24 @@ func (d *DomainController) Get(c Context) {
 	targetDomainUuid, err := model.NewUuid(domainUuid)
 	if err != nil {
 		NewError(c, http.StatusBadRequest, err)
-		log.Print(err)
+		logger.Warn("request failed", "error", err)
 		return
 	}
 
-	gotDomain, err := d.interactor.Get(targetDomainUuid, requestTenantUuid)
+	gotDomain, err := interactor.Get(targetDomainUuid, requestTenantUuid)
+	if _, ok := err.(*model.DomainDegradedError); ok {
+		// Degraded domains are still visible, but without their hosts.
+		degraded, err := interactor.GetDegraded(targetDomainUuid, requestTenantUuid)
+		if err != nil {
+			NewError(c,
+				http.StatusInternalServerError,
+				NewUnAvailableHandlingError())
+			logger.Error("request failed", "error", err)
+			return
+		}
+		c.JSON(http.StatusOK, newDegradedDomainResult(degraded))
+		return
+	}
 	if err != nil {
 		switch e := err.(type) {
 		case *model.InvalidParameterGiven, *model.DomainPermissionError:
@@ -327,15 +497,20 @@ func (d *DomainController) Get(c Context) {
 			NewError(c,
 				http.StatusInternalServerError,
 				NewUnAvailableHandlingError())
-			log.Print(e)
+			logger.Error("unexpected error", "error", e)
 		}
-		log.Print(err)
+		logger.Warn("request failed", "error", err)
+		return
+	}
+
+	setETag(c, gotDomain)
+	if notModified(c, gotDomain) {
 		return
 	}
 
 	hosts := make([]HostResult, 0)
 	for _, h := range gotDomain.Hosts {
-		host := HostResult{Name: h.Name, Address: h.Address, Uuid: h.Uuid.String()}
+		host := newHostResult(h)
 		hosts = append(hosts, host)
 	}
 
@@ -358,12 +533,20 @@ func (d *DomainController) Get(c Context) {
 // @Description Delete new domain to coredns
 // @Param Tenant header string true "Tenant UUID to set access control"
 // @Param domain_uuid path string true "Target domain's UUID"
+// @Param If-Match header string false "ETag of the domain. The domain is deleted only when it is not changed since then"
+// @Param dry_run query bool false "Return the file changes without writing them"
 // @Success 204
+// @Success 200 {object} DryRunResult
 // @Failure 400 {object} HTTPError
 // @Failure 404 {object} HTTPError
+// @Failure 409 {object} HTTPError
+// @Failure 412 {object} HTTPError
 // @Failure 500 {object} HTTPError
 // @Router /v1/domains/{domain_uuid} [delete]
 func (d *DomainController) Delete(c Context) {
+	logger := NewRequestLogger(c)
+	interactor := d.interactor.WithLogger(logger)
+
 	requestTenant := c.GetHeader("Tenant")
 	if requestTenant == "" {
 		NewError(c,
@@ -381,24 +564,48 @@ func (d *DomainController) Delete(c Context) {
 	targetDomainUuid, err := model.NewUuid(domainUuid)
 	if err != nil {
 		NewError(c, http.StatusBadRequest, err)
-		log.Print(err)
+		logger.Warn("request failed", "error", err)
+		return
+	}
+
+	dryRun, err := getDryRun(c)
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
 		return
 	}
 
-	err = d.interactor.Delete(targetDomainUuid, requestTenantUuid)
+	var staged usecase.IStagedRepository
+	if dryRun {
+		interactor, staged = interactor.Stage()
+	}
+
+	if revisions, ok := getIfMatch(c); ok {
+		interactor = interactor.IfMatch(revisions)
+	}
+
+	err = interactor.Delete(targetDomainUuid, requestTenantUuid)
 	if err != nil {
 		switch e := err.(type) {
-		case *model.InvalidParameterGiven, *model.DomainPermissionError:
+		case *model.InvalidParameterGiven, *model.DomainPermissionError, *model.InvalidCorefileError:
 			NewError(c, http.StatusBadRequest, err)
+		case *model.DomainDegradedError:
+			NewError(c, http.StatusConflict, err)
+		case *model.DomainRevisionMismatchError:
+			NewError(c, http.StatusPreconditionFailed, err)
 		case *model.DomainNotFoundError:
 			NewError(c, http.StatusNotFound, err)
 		default:
 			NewError(c,
 				http.StatusInternalServerError,
 				NewUnAvailableHandlingError())
-			log.Print(e)
+			logger.Error("unexpected error", "error", e)
 		}
-		log.Print(err)
+		logger.Warn("request failed", "error", err)
+		return
+	}
+
+	if staged != nil {
+		returnDryRunResult(c, staged)
 		return
 	}
 
@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"coredns_api/internal/usecase"
+)
+
+// Result
+type DryRunResult struct {
+	DryRun  bool               `json:"dry_run"`
+	Changes []FileChangeResult `json:"changes"`
+}
+
+type FileChangeResult struct {
+	Path string `json:"path"`
+	Diff string `json:"diff"`
+}
+
+// getDryRun returns whether `?dry_run=true` is given to the request.
+func getDryRun(c Context) (bool, error) {
+	dryRun := c.Query("dry_run")
+	if dryRun == "" {
+		return false, nil
+	}
+
+	result, err := strconv.ParseBool(dryRun)
+	if err != nil {
+		return false, errors.New("invalid dry_run query parameter is specified. dry_run: " + dryRun)
+	}
+	return result, nil
+}
+
+// returnDryRunResult responds the changes which the staged repository would write.
+func returnDryRunResult(c Context, staged usecase.IStagedRepository) {
+	logger := NewRequestLogger(c)
+
+	changes, err := staged.GetChanges()
+	if err != nil {
+		NewError(c,
+			http.StatusInternalServerError,
+			NewUnAvailableHandlingError())
+		logger.Error("request failed", "error", err)
+		return
+	}
+
+	changeList := make([]FileChangeResult, 0)
+	for _, change := range changes {
+		changeList = append(changeList, FileChangeResult{Path: change.Path, Diff: change.Diff})
+	}
+
+	result := DryRunResult{DryRun: true, Changes: changeList}
+	c.JSON(http.StatusOK, result)
+}
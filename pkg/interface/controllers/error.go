@@ -1,5 +1,10 @@
 package controllers
 
+import (
+	"coredns_api/internal/model"
+	"coredns_api/internal/usecase"
+)
+
 // type Error struct {
 // 	Message string
 // }
@@ -13,6 +18,7 @@ package controllers
 func NewError(ctx Context, status int, err error) {
 	er := HTTPError{
 		Code:    status,
+		Type:    getErrorType(err),
 		Message: err.Error(),
 	}
 	ctx.JSON(status, er)
@@ -21,5 +27,39 @@ func NewError(ctx Context, status int, err error) {
 // HTTPError example
 type HTTPError struct {
 	Code    int    `json:"code" example:"400"`
+	Type    string `json:"type,omitempty" example:"not_found"`
 	Message string `json:"message" example:"status bad request"`
 }
+
+// Error types tell the kind of the error to clients,
+// since the same HTTP status is used for several kinds of errors.
+const (
+	ErrorTypeNotFound            = "not_found"
+	ErrorTypePermission          = "permission"
+	ErrorTypeDuplicated          = "duplicated"
+	ErrorTypeDegraded            = "degraded"
+	ErrorTypeInvalidParameter    = "invalid_parameter"
+	ErrorTypeRevisionMismatch    = "revision_mismatch"
+	ErrorTypeIdempotencyConflict = "idempotency_conflict"
+)
+
+func getErrorType(err error) string {
+	switch err.(type) {
+	case *model.DomainNotFoundError, *model.HostNotFoundError, *model.PoolNotFoundError:
+		return ErrorTypeNotFound
+	case *model.DomainPermissionError, *model.PoolPermissionError:
+		return ErrorTypePermission
+	case *usecase.HostDuplicatedError, *usecase.DomainDuplicatedError:
+		return ErrorTypeDuplicated
+	case *model.DomainDegradedError:
+		return ErrorTypeDegraded
+	case *model.InvalidParameterGiven:
+		return ErrorTypeInvalidParameter
+	case *model.DomainRevisionMismatchError:
+		return ErrorTypeRevisionMismatch
+	case *model.IdempotencyKeyConflictError:
+		return ErrorTypeIdempotencyConflict
+	default:
+		return ""
+	}
+}
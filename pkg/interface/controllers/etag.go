@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"coredns_api/internal/model"
+)
+
+// ETags of domains and hosts are the revision of the domain,
+// since every change of the domain and its hosts is written as a new revision.
+func domainETag(domain *model.Domain) string {
+	return `"` + strconv.FormatUint(domain.Revision, 10) + `"`
+}
+
+func setETag(c Context, domain *model.Domain) {
+	c.Header("ETag", domainETag(domain))
+}
+
+// getIfMatch returns the revisions in If-Match header, and whether the request has to be checked with them.
+// "*" matches any revision of the existing domain, so it is not checked.
+// Weak ETags and the ones which are not given by the API never match.
+func getIfMatch(c Context) ([]uint64, bool) {
+	ifMatch := strings.TrimSpace(c.GetHeader("If-Match"))
+	if ifMatch == "" || ifMatch == "*" {
+		return nil, false
+	}
+
+	var revisions []uint64
+	for _, tag := range strings.Split(ifMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) || len(tag) < 2 {
+			continue
+		}
+		revision, err := strconv.ParseUint(tag[1:len(tag)-1], 10, 64)
+		if err == nil {
+			revisions = append(revisions, revision)
+		}
+	}
+	return revisions, true
+}
+
+// notModified responds 304 when If-None-Match has the ETag of the domain.
+// ETags are compared weakly as RFC 7232 requires for If-None-Match.
+func notModified(c Context, domain *model.Domain) bool {
+	ifNoneMatch := strings.TrimSpace(c.GetHeader("If-None-Match"))
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	etag := domainETag(domain)
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"coredns_api/internal/model"
+	"coredns_api/internal/usecase"
+)
+
+func newTestETagControllers(t *testing.T) (*DomainController, *HostController, usecase.IFilesystemRepository, *model.Domain, *model.Host) {
+	fsRepository := newTestRepository(t)
+	domain := addTestDomain(t, fsRepository, "hogehoge.hoge", testTenant)
+	hostInteractor := usecase.NewHostInteractor(fsRepository, nil, nil)
+	host, _ := model.NewOriginalHost("hogeserver1", "172.21.1.1", domain.Name)
+	_, err := hostInteractor.Add(host, domain.Uuid, testTenant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewDomainController(usecase.NewDomainInteractor(fsRepository, nil, nil)), NewHostController(hostInteractor), fsRepository, domain, host
+}
+
+func TestIfMatch(t *testing.T) {
+	domainController, hostController, fsRepository, domain, host := newTestETagControllers(t)
+	revision, _, _ := getTestDomain(t, fsRepository, domain.Uuid)
+	etag := `"` + strconv.FormatUint(revision, 10) + `"`
+	staleETag := `"` + strconv.FormatUint(revision-1, 10) + `"`
+
+	domainParams := map[string]string{"domain_uuid": domain.Uuid.String()}
+	hostParams := map[string]string{"domain_uuid": domain.Uuid.String(), "host_uuid": host.Uuid.String()}
+	requests := []struct {
+		name    string
+		handler func(c Context)
+		params  map[string]string
+		body    interface{}
+	}{
+		{"PATCH domain", domainController.Update, domainParams, DomainUpdateRequest{Tenants: []string{testTenant, testOtherTenant}}},
+		{"DELETE domain", domainController.Delete, domainParams, nil},
+		{"PATCH host", hostController.Update, hostParams, HostRequest{Name: "hogeserver1", Address: "172.21.1.2"}},
+		{"DELETE host", hostController.Delete, hostParams, nil},
+		{"PUT hosts", hostController.Apply, domainParams, HostsApplyRequest{Hosts: []HostRequest{{Name: "hogeserver2", Address: "172.21.1.2"}}}},
+	}
+
+	// Weak ETags and the ones which are not given by the API never match.
+	for _, ifMatch := range []string{staleETag, "W/" + etag, strconv.FormatUint(revision, 10), `"hoge"`, `"`, staleETag + `, "hoge"`} {
+		for _, r := range requests {
+			c := NewRecordingContext(map[string]string{"Tenant": testTenant, "If-Match": ifMatch}, r.params, nil, r.body)
+			r.handler(c)
+			if c.StatusCode() != http.StatusPreconditionFailed {
+				t.Errorf("%s is not refused with If-Match %s: %d", r.name, ifMatch, c.StatusCode())
+			}
+		}
+	}
+
+	newRevision, addresses, _ := getTestDomain(t, fsRepository, domain.Uuid)
+	if newRevision != revision || len(addresses) != 1 || addresses[host.Name] != host.Address {
+		t.Error("domain is changed with the stale If-Match")
+	}
+
+	// The list matches when any of them is the current ETag, and "*" matches any revision.
+	for _, ifMatch := range []string{staleETag + ", " + etag, "*"} {
+		c := NewRecordingContext(map[string]string{"Tenant": testTenant, "If-Match": ifMatch}, domainParams, nil, requests[4].body)
+		hostController.Apply(c)
+		if c.StatusCode() != http.StatusOK {
+			t.Errorf("hosts are not replaced with If-Match %s: %d %v", ifMatch, c.StatusCode(), c.Result())
+		}
+	}
+}
+
+func TestIfNoneMatch(t *testing.T) {
+	domainController, hostController, fsRepository, domain, host := newTestETagControllers(t)
+	revision, _, _ := getTestDomain(t, fsRepository, domain.Uuid)
+	etag := `"` + strconv.FormatUint(revision, 10) + `"`
+	staleETag := `"` + strconv.FormatUint(revision-1, 10) + `"`
+
+	domainParams := map[string]string{"domain_uuid": domain.Uuid.String()}
+	hostParams := map[string]string{"domain_uuid": domain.Uuid.String(), "host_uuid": host.Uuid.String()}
+	requests := []struct {
+		name    string
+		handler func(c Context)
+		params  map[string]string
+	}{
+		{"GET domain", domainController.Get, domainParams},
+		{"GET hosts", hostController.List, domainParams},
+		{"GET host", hostController.Get, hostParams},
+	}
+
+	// ETags are compared weakly for If-None-Match.
+	for _, ifNoneMatch := range []string{etag, "W/" + etag, "*", staleETag + ", " + etag} {
+		for _, r := range requests {
+			c := NewRecordingContext(map[string]string{"Tenant": testTenant, "If-None-Match": ifNoneMatch}, r.params, nil, nil)
+			r.handler(c)
+			if c.StatusCode() != http.StatusNotModified {
+				t.Errorf("%s is modified with If-None-Match %s: %d", r.name, ifNoneMatch, c.StatusCode())
+			}
+			if c.ResponseHeaders()["ETag"] != etag {
+				t.Errorf("ETag of %s is missmatched: %s", r.name, c.ResponseHeaders()["ETag"])
+			}
+		}
+	}
+
+	for _, ifNoneMatch := range []string{staleETag, strconv.FormatUint(revision, 10), `"hoge"`, `"`} {
+		for _, r := range requests {
+			c := NewRecordingContext(map[string]string{"Tenant": testTenant, "If-None-Match": ifNoneMatch}, r.params, nil, nil)
+			r.handler(c)
+			if c.StatusCode() != http.StatusOK {
+				t.Errorf("%s is not modified with If-None-Match %s: %d", r.name, ifNoneMatch, c.StatusCode())
+			}
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"coredns_api/internal/model"
+	"coredns_api/internal/usecase"
+)
+
+const eventKeepAliveInterval = 15 * time.Second
+
+// Controller
+type EventController struct {
+	interactor *usecase.EventInteractor
+}
+
+func NewEventController(itr *usecase.EventInteractor) *EventController {
+	return &EventController{itr}
+}
+
+// Stream handler doc
+// @Tags Event
+// @Summary Stream events
+// @Description Stream the changes of the domains and hosts the tenant can access as Server-Sent Events.
+// @Description Each event is sent with its type as the event name and model.Event as the data.
+// @Description The stream is closed when the client can't keep up with the events.
+// @Produce text/event-stream
+// @Param Tenant header string true "Tenant UUID to set access control"
+// @Param domain_uuid query string false "Domain UUID to receive only its events"
+// @Success 200 {object} model.Event
+// @Failure 400 {object} HTTPError
+// @Router /v1/events [get]
+func (e *EventController) Stream(c Context) {
+	requestTenant := c.GetHeader("Tenant")
+	if requestTenant == "" {
+		NewError(c,
+			http.StatusBadRequest,
+			errors.New("tenant uuid header is not specified"))
+		return
+	}
+
+	requestTenantUuid, err := model.NewUuid(requestTenant)
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var domainUuid model.Uuid
+	if c.Query("domain_uuid") != "" {
+		domainUuid, err = model.NewUuid(c.Query("domain_uuid"))
+		if err != nil {
+			NewError(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	logger := NewRequestLogger(c)
+	subscription := e.interactor.Subscribe()
+	defer subscription.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Status(http.StatusOK)
+
+	keepAlive := time.NewTicker(eventKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	connected := false
+	c.Stream(func(w io.Writer) bool {
+		if !connected {
+			connected = true
+			_, err := io.WriteString(w, ": connected\n\n")
+			return err == nil
+		}
+
+		select {
+		case event, ok := <-subscription.Events():
+			if !ok {
+				logger.Warn("event stream is closed since the client can't keep up with the events")
+				return false
+			}
+			if domainUuid != "" && event.DomainUuid != domainUuid {
+				return true
+			}
+			if !e.interactor.CanReceive(event, requestTenantUuid) {
+				return true
+			}
+			err := writeEvent(w, event)
+			if err != nil {
+				logger.Debug("failed to send the event", "event_id", event.Uuid, "error", err)
+			}
+			return err == nil
+		case <-keepAlive.C:
+			_, err := io.WriteString(w, ": keepalive\n\n")
+			return err == nil
+		}
+	})
+}
+
+func writeEvent(w io.Writer, event *model.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.Uuid, event.Type, data)
+	return err
+}
@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"coredns_api/internal/interface/repository"
+	"coredns_api/internal/model"
+	"coredns_api/internal/usecase"
+)
+
+const (
+	testTenant      = "df397e50-8006-450e-b18b-5c5bd940baff"
+	testOtherTenant = "02c03bd4-fe2e-45f2-85b6-b535af15215d"
+)
+
+// readEventIds connects to the event stream, and returns the ids of the events until the last id is received.
+func readEventIds(t *testing.T, url, tenant, lastId string, publish func()) []string {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	req.Header.Set("Tenant", tenant)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	var ids []string
+	scanner := bufio.NewScanner(res.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == ": connected" {
+			publish()
+		}
+		if !strings.HasPrefix(line, "id: ") {
+			continue
+		}
+		id := strings.TrimPrefix(line, "id: ")
+		ids = append(ids, id)
+		if id == lastId {
+			return ids
+		}
+	}
+	t.Error("stream is closed before the last event")
+	return ids
+}
+
+func TestStreamEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("ADMIN_TENANTS", "")
+	events := repository.NewEventBus()
+	controller := NewEventController(usecase.NewEventInteractor(events))
+
+	router := gin.New()
+	router.GET("/v1/events", func(c *gin.Context) { controller.Stream(c) })
+	server := httptest.NewServer(router)
+	defer func() {
+		// The streams notice the closed connections only after they send an event,
+		// so the events are published until the server is closed.
+		closed := make(chan struct{})
+		go func() {
+			server.Close()
+			close(closed)
+		}()
+		wakeUp, _ := model.NewOriginalDomain("wakeup.hoge", []string{testTenant, testOtherTenant})
+		for {
+			events.Publish(model.NewDomainEvent(model.EventDomainAdded, nil, wakeUp))
+			select {
+			case <-closed:
+				return
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	}()
+
+	shared, _ := model.NewOriginalDomain("hogehoge.hoge", []string{testTenant, testOtherTenant})
+	private, _ := model.NewOriginalDomain("fugafuga.fuga", []string{testTenant})
+	host, _ := model.NewOriginalHost("hogeserver1", "172.21.1.1", shared.Name)
+
+	sharedHost := model.NewHostEvent(model.EventHostAdded, shared, nil, host)
+	privateHost := model.NewHostEvent(model.EventHostAdded, private, nil, host)
+	removed := shared.Clone()
+	removed.Tenants = []model.Uuid{testTenant}
+	tenantsChanged := model.NewDomainEvent(model.EventTenantsChanged, shared, removed)
+	afterRemoved := model.NewHostEvent(model.EventHostDeleted, removed, host, nil)
+	// The last event is visible to both tenants to end the reading.
+	last := model.NewDomainEvent(model.EventDomainAdded, nil, shared)
+
+	publish := func() {
+		for _, e := range []*model.Event{sharedHost, privateHost, tenantsChanged, afterRemoved, last} {
+			events.Publish(e)
+		}
+	}
+
+	ids := readEventIds(t, server.URL+"/v1/events", testOtherTenant, string(last.Uuid), publish)
+	expected := []string{string(sharedHost.Uuid), string(tenantsChanged.Uuid), string(last.Uuid)}
+	if strings.Join(ids, ",") != strings.Join(expected, ",") {
+		t.Error("events of the tenant are missmatched: " + strings.Join(ids, ","))
+	}
+
+	ids = readEventIds(t, server.URL+"/v1/events", testTenant, string(last.Uuid), publish)
+	if len(ids) != 5 {
+		t.Error("events of the domains of the tenant are not received")
+	}
+
+	ids = readEventIds(t, server.URL+"/v1/events?domain_uuid="+string(private.Uuid), testTenant, string(privateHost.Uuid), publish)
+	if len(ids) != 1 {
+		t.Error("events of the other domains are received")
+	}
+}
+
+func TestStreamEventsWithoutTenant(t *testing.T) {
+	c := NewRecordingContext(nil, nil, nil, nil)
+	NewEventController(usecase.NewEventInteractor(repository.NewEventBus())).Stream(c)
+	if c.StatusCode() != http.StatusBadRequest {
+		t.Error("events are streamed without the tenant")
+	}
+}
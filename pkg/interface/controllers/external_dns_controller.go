@@ -0,0 +1,304 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"coredns_api/internal/model"
+	"coredns_api/internal/usecase"
+)
+
+// externalDNSMediaType is the media type of the external-dns webhook provider protocol.
+const externalDNSMediaType = "application/external.dns.webhook+json;version=1"
+
+// Request and Result
+type ExternalDNSEndpoint struct {
+	DNSName          string                        `json:"dnsName"`
+	Targets          []string                      `json:"targets"`
+	RecordType       string                        `json:"recordType"`
+	SetIdentifier    string                        `json:"setIdentifier,omitempty"`
+	RecordTTL        int64                         `json:"recordTTL,omitempty"`
+	Labels           map[string]string             `json:"labels,omitempty"`
+	ProviderSpecific []ExternalDNSProviderSpecific `json:"providerSpecific,omitempty"`
+}
+
+type ExternalDNSProviderSpecific struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type ExternalDNSChanges struct {
+	Create    []ExternalDNSEndpoint `json:"Create"`
+	UpdateOld []ExternalDNSEndpoint `json:"UpdateOld"`
+	UpdateNew []ExternalDNSEndpoint `json:"UpdateNew"`
+	Delete    []ExternalDNSEndpoint `json:"Delete"`
+}
+
+type ExternalDNSDomainFilter struct {
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+func returnExternalDNSResult(c Context, status int, result interface{}) {
+	logger := NewRequestLogger(c)
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		NewError(c,
+			http.StatusInternalServerError,
+			NewUnAvailableHandlingError())
+		logger.Error("request failed", "error", err)
+		return
+	}
+	c.Data(status, externalDNSMediaType, data)
+}
+
+// findDomain returns the domain whose name is the longest suffix of the name.
+func findDomain(domains []*model.Domain, name string) *model.Domain {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	var found *model.Domain
+	for _, d := range domains {
+		zone := strings.ToLower(d.Name.String())
+		if name != zone && !strings.HasSuffix(name, "."+zone) {
+			continue
+		}
+		if found == nil || len(zone) > len(found.Name.String()) {
+			found = d
+		}
+	}
+	return found
+}
+
+// Controller
+type ExternalDNSController struct {
+	domainInteractor *usecase.DomainInteractor
+	hostInteractor   *usecase.HostInteractor
+}
+
+func NewExternalDNSController(dItr *usecase.DomainInteractor, hItr *usecase.HostInteractor) *ExternalDNSController {
+	return &ExternalDNSController{domainInteractor: dItr, hostInteractor: hItr}
+}
+
+// getDomains returns the domains of EXTERNAL_DNS_TENANT.
+func (e *ExternalDNSController) getDomains(c Context) (model.Uuid, []*model.Domain, bool) {
+	logger := NewRequestLogger(c)
+	domainInteractor := e.domainInteractor.WithLogger(logger)
+
+	tenant, err := model.GetExternalDNSTenant()
+	if err != nil {
+		NewError(c,
+			http.StatusInternalServerError,
+			NewUnAvailableHandlingError())
+		logger.Error("request failed", "error", err)
+		return "", nil, false
+	}
+
+	domains, err := domainInteractor.GetDomainsList(tenant)
+	if err != nil {
+		NewError(c,
+			http.StatusInternalServerError,
+			NewUnAvailableHandlingError())
+		logger.Error("request failed", "error", err)
+		return "", nil, false
+	}
+	return tenant, domains, true
+}
+
+// Negotiate handler doc
+// @Tags ExternalDNS
+// @Summary Negotiate
+// @Description external-dns webhook provider. The domains of EXTERNAL_DNS_TENANT are returned as the domain filter.
+// @Produce json
+// @Success 200 {object} ExternalDNSDomainFilter
+// @Failure 500 {object} HTTPError
+// @Router / [get]
+func (e *ExternalDNSController) Negotiate(c Context) {
+	_, domains, ok := e.getDomains(c)
+	if !ok {
+		return
+	}
+
+	filter := ExternalDNSDomainFilter{Include: make([]string, 0)}
+	for _, d := range domains {
+		filter.Include = append(filter.Include, d.Name.String())
+	}
+	sort.Strings(filter.Include)
+
+	returnExternalDNSResult(c, http.StatusOK, filter)
+}
+
+// Records handler doc
+// @Tags ExternalDNS
+// @Summary List records
+// @Description external-dns webhook provider. Hosts are returned as A and AAAA records with the TXT records of the domains.
+// @Produce json
+// @Success 200 {array} ExternalDNSEndpoint
+// @Failure 500 {object} HTTPError
+// @Router /records [get]
+func (e *ExternalDNSController) Records(c Context) {
+	_, domains, ok := e.getDomains(c)
+	if !ok {
+		return
+	}
+
+	endpoints := make([]ExternalDNSEndpoint, 0)
+	for _, d := range domains {
+		for _, h := range d.Hosts {
+			endpoint := ExternalDNSEndpoint{DNSName: h.Name, Targets: []string{h.Address}, RecordType: model.GetRecordType(h.Address)}
+			endpoints = append(endpoints, endpoint)
+		}
+
+		txtTargets := map[string][]string{}
+		var txtNames []string
+		for _, r := range d.TxtRecords {
+			if _, ok := txtTargets[r.Name]; !ok {
+				txtNames = append(txtNames, r.Name)
+			}
+			txtTargets[r.Name] = append(txtTargets[r.Name], r.Text)
+		}
+		for _, name := range txtNames {
+			endpoints = append(endpoints, ExternalDNSEndpoint{DNSName: name, Targets: txtTargets[name], RecordType: "TXT"})
+		}
+	}
+
+	returnExternalDNSResult(c, http.StatusOK, endpoints)
+}
+
+// ApplyChanges handler doc
+// @Tags ExternalDNS
+// @Summary Apply changes
+// @Description external-dns webhook provider. The changes of each domain are applied with one write,
+// @Description and nothing is written if any change is invalid or any old record of the updates is not the current one.
+// @Description Only A, AAAA and TXT records are available, and A and AAAA records have one target.
+// @Accept json
+// @Param changes body ExternalDNSChanges true "Request body parameter with json format"
+// @Success 204
+// @Failure 400 {object} HTTPError
+// @Failure 409 {object} HTTPError
+// @Failure 500 {object} HTTPError
+// @Router /records [post]
+func (e *ExternalDNSController) ApplyChanges(c Context) {
+	logger := NewRequestLogger(c)
+	hostInteractor := e.hostInteractor.WithLogger(logger)
+
+	var request ExternalDNSChanges
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	tenant, domains, ok := e.getDomains(c)
+	if !ok {
+		return
+	}
+
+	// Deletes are applied at first, so that a record can be deleted and created again in the same changes.
+	// Old records of the updates have to be the current ones, and they are replaced by the new ones.
+	domainChanges := map[*model.Domain]*usecase.RRsetChanges{}
+	var rrsetChanges []*usecase.RRsetChanges
+	getChanges := func(endpoint ExternalDNSEndpoint) *usecase.RRsetChanges {
+		domain := findDomain(domains, endpoint.DNSName)
+		if domain == nil {
+			return nil
+		}
+		if _, ok := domainChanges[domain]; !ok {
+			domainChanges[domain] = &usecase.RRsetChanges{DomainUuid: domain.Uuid}
+			rrsetChanges = append(rrsetChanges, domainChanges[domain])
+		}
+		return domainChanges[domain]
+	}
+
+	for _, endpoint := range request.UpdateOld {
+		changes := getChanges(endpoint)
+		if changes == nil {
+			NewError(c, http.StatusBadRequest, model.NewInvalidParameterGiven("domain of the record is not found. name: "+endpoint.DNSName))
+			return
+		}
+		changes.Expected = append(changes.Expected, &model.RRsetChange{Name: endpoint.DNSName, Type: endpoint.RecordType, Values: endpoint.Targets})
+	}
+
+	var endpoints []ExternalDNSEndpoint
+	for _, endpoint := range request.Delete {
+		endpoint.Targets = nil
+		endpoints = append(endpoints, endpoint)
+	}
+	endpoints = append(endpoints, request.Create...)
+	endpoints = append(endpoints, request.UpdateNew...)
+
+	for _, endpoint := range endpoints {
+		changes := getChanges(endpoint)
+		if changes == nil {
+			NewError(c, http.StatusBadRequest, model.NewInvalidParameterGiven("domain of the record is not found. name: "+endpoint.DNSName))
+			return
+		}
+
+		change := &model.RRsetChange{Name: endpoint.DNSName, Type: endpoint.RecordType, Values: endpoint.Targets}
+		if endpoint.RecordType == "TXT" {
+			changes.TxtRecords = append(changes.TxtRecords, change)
+		} else {
+			changes.Hosts = append(changes.Hosts, change)
+		}
+	}
+
+	err = hostInteractor.ApplyRRsetChanges(rrsetChanges, tenant)
+	if err != nil {
+		switch e := err.(type) {
+		case *model.InvalidParameterGiven, *usecase.HostDuplicatedError:
+			NewError(c, http.StatusBadRequest, err)
+		case *model.DomainDegradedError, *model.DomainNotFoundError, *model.RRsetMismatchError:
+			NewError(c, http.StatusConflict, err)
+		default:
+			NewError(c,
+				http.StatusInternalServerError,
+				NewUnAvailableHandlingError())
+			logger.Error("unexpected error", "error", e)
+		}
+		logger.Warn("request failed", "error", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AdjustEndpoints handler doc
+// @Tags ExternalDNS
+// @Summary Adjust endpoints
+// @Description external-dns webhook provider. Records other than A, AAAA and TXT are dropped,
+// @Description A and AAAA records keep only the first target, and TTL is dropped since hosts file doesn't have it.
+// @Accept json
+// @Produce json
+// @Param endpoints body []ExternalDNSEndpoint true "Request body parameter with json format"
+// @Success 200 {array} ExternalDNSEndpoint
+// @Failure 400 {object} HTTPError
+// @Router /adjustendpoints [post]
+func (e *ExternalDNSController) AdjustEndpoints(c Context) {
+	var request []ExternalDNSEndpoint
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	endpoints := make([]ExternalDNSEndpoint, 0)
+	for _, endpoint := range request {
+		switch endpoint.RecordType {
+		case "A", "AAAA":
+			if len(endpoint.Targets) > 1 {
+				targets := append([]string{}, endpoint.Targets...)
+				sort.Strings(targets)
+				endpoint.Targets = targets[:1]
+			}
+		case "TXT":
+		default:
+			continue
+		}
+
+		endpoint.RecordTTL = 0
+		endpoints = append(endpoints, endpoint)
+	}
+
+	returnExternalDNSResult(c, http.StatusOK, endpoints)
+}
@@ -0,0 +1,183 @@
+package controllers
+
+import (
+	"net/http"
+	"testing"
+
+	"coredns_api/internal/model"
+	"coredns_api/internal/usecase"
+)
+
+func newTestExternalDNSController(t *testing.T) (*ExternalDNSController, usecase.IFilesystemRepository, *model.Domain) {
+	t.Setenv("EXTERNAL_DNS_TENANT", testTenant)
+	fsRepository := newTestRepository(t)
+	domain := addTestDomain(t, fsRepository, "hogehoge.hoge", testTenant)
+	controller := NewExternalDNSController(
+		usecase.NewDomainInteractor(fsRepository, nil, nil),
+		usecase.NewHostInteractor(fsRepository, nil, nil))
+	return controller, fsRepository, domain
+}
+
+// getTestDomain returns the revision, the host addresses and the TXT records of the domain.
+func getTestDomain(t *testing.T, fsRepository usecase.IFilesystemRepository, domainUuid model.Uuid) (uint64, map[string]string, map[string]string) {
+	domain, err := usecase.NewHostInteractor(fsRepository, nil, nil).GetDomain(domainUuid, testTenant)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addresses := map[string]string{}
+	for _, h := range domain.Hosts {
+		addresses[h.Name] = h.Address
+	}
+	texts := map[string]string{}
+	for _, r := range domain.TxtRecords {
+		texts[r.Name] = r.Text
+	}
+	return domain.Revision, addresses, texts
+}
+
+func TestExternalDNSApplyChanges(t *testing.T) {
+	controller, fsRepository, domain := newTestExternalDNSController(t)
+	hostInteractor := usecase.NewHostInteractor(fsRepository, nil, nil)
+	for _, h := range [][]string{{"hogeserver1", "172.21.1.1"}, {"hogeserver2", "172.21.1.2"}} {
+		host, _ := model.NewOriginalHost(h[0], h[1], domain.Name)
+		_, err := hostInteractor.Add(host, domain.Uuid, testTenant)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+	revision, _, _ := getTestDomain(t, fsRepository, domain.Uuid)
+
+	// Deletes are applied before creates, so hogeserver1 is created again,
+	// and the address of hogeserver2 can be used by hogeserver3.
+	changes := ExternalDNSChanges{
+		Create: []ExternalDNSEndpoint{
+			{DNSName: "hogeserver1.hogehoge.hoge", Targets: []string{"172.21.1.3"}, RecordType: "A"},
+			{DNSName: "hogeserver3.hogehoge.hoge", Targets: []string{"172.21.1.2"}, RecordType: "A"},
+			{DNSName: "hogeserver3.hogehoge.hoge", Targets: []string{"\"heritage=external-dns\""}, RecordType: "TXT"},
+		},
+		Delete: []ExternalDNSEndpoint{
+			{DNSName: "hogeserver1.hogehoge.hoge", Targets: []string{"172.21.1.1"}, RecordType: "A"},
+			{DNSName: "hogeserver2.hogehoge.hoge", Targets: []string{"172.21.1.2"}, RecordType: "A"},
+		},
+	}
+	c := NewRecordingContext(nil, nil, nil, changes)
+	controller.ApplyChanges(c)
+	if c.StatusCode() != http.StatusNoContent {
+		t.Errorf("changes are not applied: %d %v", c.StatusCode(), c.Result())
+		return
+	}
+
+	// The A and TXT records are written with one write.
+	newRevision, addresses, texts := getTestDomain(t, fsRepository, domain.Uuid)
+	if newRevision != revision+1 {
+		t.Errorf("changes are not applied with one write: %d -> %d", revision, newRevision)
+	}
+	expects := map[string]string{"hogeserver1.hogehoge.hoge": "172.21.1.3", "hogeserver3.hogehoge.hoge": "172.21.1.2"}
+	if len(addresses) != len(expects) {
+		t.Errorf("hosts are missmatched: %v", addresses)
+	}
+	for name, address := range expects {
+		if addresses[name] != address {
+			t.Errorf("address of %s is missmatched: %s", name, addresses[name])
+		}
+	}
+	if len(texts) != 1 || texts["hogeserver3.hogehoge.hoge"] != "\"heritage=external-dns\"" {
+		t.Errorf("TXT records are missmatched: %v", texts)
+	}
+}
+
+func TestExternalDNSApplyChangesConflicted(t *testing.T) {
+	controller, fsRepository, domain := newTestExternalDNSController(t)
+	host, _ := model.NewOriginalHost("hogeserver1", "172.21.1.1", domain.Name)
+	_, err := usecase.NewHostInteractor(fsRepository, nil, nil).Add(host, domain.Uuid, testTenant)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	revision, _, _ := getTestDomain(t, fsRepository, domain.Uuid)
+
+	// The old record of the update is not the current one, since it is changed by another request.
+	changes := ExternalDNSChanges{
+		UpdateOld: []ExternalDNSEndpoint{
+			{DNSName: "hogeserver1.hogehoge.hoge", Targets: []string{"172.21.1.2"}, RecordType: "A"},
+		},
+		UpdateNew: []ExternalDNSEndpoint{
+			{DNSName: "hogeserver1.hogehoge.hoge", Targets: []string{"172.21.1.3"}, RecordType: "A"},
+		},
+	}
+	c := NewRecordingContext(nil, nil, nil, changes)
+	controller.ApplyChanges(c)
+	if c.StatusCode() != http.StatusConflict {
+		t.Errorf("update of the changed record is not conflicted: %d", c.StatusCode())
+	}
+
+	newRevision, addresses, _ := getTestDomain(t, fsRepository, domain.Uuid)
+	if newRevision != revision || addresses["hogeserver1.hogehoge.hoge"] != "172.21.1.1" {
+		t.Error("update of the changed record is applied")
+	}
+
+	changes.UpdateOld[0].Targets = []string{"172.21.1.1"}
+	c = NewRecordingContext(nil, nil, nil, changes)
+	controller.ApplyChanges(c)
+	if c.StatusCode() != http.StatusNoContent {
+		t.Errorf("update of the current record is not applied: %d %v", c.StatusCode(), c.Result())
+	}
+	_, addresses, _ = getTestDomain(t, fsRepository, domain.Uuid)
+	if addresses["hogeserver1.hogehoge.hoge"] != "172.21.1.3" {
+		t.Error("address of the updated record is missmatched")
+	}
+}
+
+func TestExternalDNSApplyChangesInvalid(t *testing.T) {
+	controller, fsRepository, domain := newTestExternalDNSController(t)
+	otherDomain := addTestDomain(t, fsRepository, "fugafuga.fuga", testTenant)
+	revision, _, _ := getTestDomain(t, fsRepository, domain.Uuid)
+	otherRevision, _, _ := getTestDomain(t, fsRepository, otherDomain.Uuid)
+
+	// The change to the other domain is invalid, so nothing is written to any domain.
+	changes := ExternalDNSChanges{Create: []ExternalDNSEndpoint{
+		{DNSName: "hogeserver1.hogehoge.hoge", Targets: []string{"172.21.1.1"}, RecordType: "A"},
+		{DNSName: "fugaserver1.fugafuga.fuga", Targets: []string{"fd00::1"}, RecordType: "A"},
+	}}
+	c := NewRecordingContext(nil, nil, nil, changes)
+	controller.ApplyChanges(c)
+	if c.StatusCode() != http.StatusBadRequest {
+		t.Errorf("invalid change is accepted: %d", c.StatusCode())
+	}
+
+	newRevision, addresses, _ := getTestDomain(t, fsRepository, domain.Uuid)
+	if newRevision != revision || len(addresses) != 0 {
+		t.Error("changes are applied to the domain with the invalid change to another domain")
+	}
+	newOtherRevision, _, _ := getTestDomain(t, fsRepository, otherDomain.Uuid)
+	if newOtherRevision != otherRevision {
+		t.Error("invalid change is applied")
+	}
+}
+
+func TestExternalDNSApplyChangesUnknownDomain(t *testing.T) {
+	controller, fsRepository, domain := newTestExternalDNSController(t)
+	addTestDomain(t, fsRepository, "fugafuga.fuga", testOtherTenant)
+	revision, _, _ := getTestDomain(t, fsRepository, domain.Uuid)
+
+	// The domain of another tenant is unknown as well as the one which doesn't exist.
+	for _, name := range []string{"hogeserver1.piyopiyo.piyo", "hogeserver1.fugafuga.fuga"} {
+		changes := ExternalDNSChanges{Create: []ExternalDNSEndpoint{
+			{DNSName: "hogeserver1.hogehoge.hoge", Targets: []string{"172.21.1.1"}, RecordType: "A"},
+			{DNSName: name, Targets: []string{"172.21.1.2"}, RecordType: "A"},
+		}}
+		c := NewRecordingContext(nil, nil, nil, changes)
+		controller.ApplyChanges(c)
+		if c.StatusCode() != http.StatusBadRequest {
+			t.Error("record of the unknown domain is accepted: " + name)
+		}
+	}
+
+	// Nothing is written, even for the known domain.
+	newRevision, addresses, _ := getTestDomain(t, fsRepository, domain.Uuid)
+	if newRevision != revision || len(addresses) != 0 {
+		t.Error("changes are applied with the record of the unknown domain")
+	}
+}
@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"net/http"
+
+	"coredns_api/internal/model"
+	"coredns_api/internal/usecase"
+)
+
+// Result
+type HealthResult struct {
+	Status string `json:"status"`
+	// Checks are the names of the failed checks. Their errors are only logged,
+	// since they have the names of the domains and the paths.
+	Checks []string `json:"checks,omitempty"`
+}
+
+// Controller
+type HealthController struct {
+	interactor *usecase.HealthInteractor
+}
+
+func NewHealthController(itr *usecase.HealthInteractor) *HealthController {
+	return &HealthController{itr}
+}
+
+// Healthz handler doc
+// @Tags Health
+// @Summary Liveness
+// @Description Responds while the API server is running. The dependencies are not checked.
+// @Produce json
+// @Success 200 {object} HealthResult
+// @Router /healthz [get]
+func (h *HealthController) Healthz(c Context) {
+	c.JSON(http.StatusOK, HealthResult{Status: "ok"})
+}
+
+// Readyz handler doc
+// @Tags Health
+// @Summary Readiness
+// @Description Checks HOSTS_DIR and CONF_PATH are writable, the domain cache is loaded without degraded domains,
+// @Description and CoreDNS answers the canary host when READINESS_PROBE_SERVER is specified
+// @Produce json
+// @Success 200 {object} HealthResult
+// @Failure 503 {object} HealthResult
+// @Failure 500 {object} HTTPError
+// @Router /readyz [get]
+func (h *HealthController) Readyz(c Context) {
+	logger := NewRequestLogger(c)
+	interactor := h.interactor.WithLogger(logger)
+
+	probe, err := model.GetReadinessProbe()
+	if err != nil {
+		NewError(c,
+			http.StatusInternalServerError,
+			NewUnAvailableHandlingError())
+		logger.Error("request failed", "error", err)
+		return
+	}
+
+	readiness := interactor.CheckReadiness(probe)
+	if !readiness.IsReady() {
+		var failed []string
+		for _, check := range readiness.Checks {
+			if check.Error != "" {
+				failed = append(failed, check.Name)
+				logger.Warn("dependency is not ready", "check", check.Name, "error", check.Error)
+			}
+		}
+		c.JSON(http.StatusServiceUnavailable, HealthResult{Status: "not ready", Checks: failed})
+		return
+	}
+
+	c.JSON(http.StatusOK, HealthResult{Status: "ready"})
+}
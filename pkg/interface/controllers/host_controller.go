@@ -1,17 +1,49 @@
 package controllers
 
 import (
+	"encoding/json"
 	"errors"
-	"log"
 	"net/http"
+	"sort"
+	"time"
 
 	"coredns_api/internal/model"
 	"coredns_api/internal/usecase"
 )
 
 type HostRequest struct {
-	Name    string `json:"hostname"`
-	Address string `json:"address"`
+	Name      string `json:"hostname"`
+	Address   string `json:"address"`
+	Pool      string `json:"pool"`
+	ExpiresAt string `json:"expires_at"`
+	TtlLease  string `json:"ttl_lease"`
+}
+
+type HostRenewRequest struct {
+	TtlLease string `json:"ttl_lease"`
+}
+
+type HostsApplyRequest struct {
+	Hosts []HostRequest `json:"hosts"`
+}
+
+// Result
+type HostApplyResult struct {
+	DomainInfoResult
+	Changes HostChangesResult `json:"changes"`
+}
+
+type HostListResult struct {
+	DomainInfoResult
+	Total int    `json:"total"`
+	Next  string `json:"next,omitempty"`
+}
+
+type HostChangesResult struct {
+	Added     []HostResult `json:"added"`
+	Updated   []HostResult `json:"updated"`
+	Deleted   []HostResult `json:"deleted"`
+	Unchanged []HostResult `json:"unchanged"`
 }
 
 type HostController struct {
@@ -25,18 +57,26 @@ func NewHostController(itr *usecase.HostInteractor) *HostController {
 // Add handler doc
 // @Tags Host
 // @Summary Add new host
-// @Description Add new host to domain
+// @Description Add new host to domain.
+// @Description When pool is specified instead of address, the lowest free address of the pool is allocated.
+// @Description When expires_at or ttl_lease is specified, the host is removed after it expires.
 // @Accept json
 // @Produce json
 // @Param Tenant header string true "Tenant UUID to set access control"
 // @Param domain_uuid path string true "Target domain's UUID"
 // @Param host body HostRequest true "Request body parameter with json format"
+// @Param dry_run query bool false "Return the file changes without writing them"
 // @Success 201 {object} DomainInfoResult
+// @Success 200 {object} DryRunResult
 // @Failure 400 {object} HTTPError
 // @Failure 404 {object} HTTPError
+// @Failure 409 {object} HTTPError
 // @Failure 500 {object} HTTPError
 // @Router /v1/domains/{domain_uuid}/hosts [post]
 func (d *HostController) Add(c Context) {
+	logger := NewRequestLogger(c)
+	interactor := d.interactor.WithLogger(logger)
+
 	requestTenant := c.GetHeader("Tenant")
 	if requestTenant == "" {
 		NewError(c,
@@ -54,19 +94,28 @@ func (d *HostController) Add(c Context) {
 	targetDomainUuid, err := model.NewUuid(domainUuid)
 	if err != nil {
 		NewError(c, http.StatusBadRequest, err)
-		log.Print(err)
+		logger.Warn("request failed", "error", err)
+		return
+	}
+
+	dryRun, err := getDryRun(c)
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
 		return
 	}
-	targetDomain, err := d.interactor.GetDomain(targetDomainUuid, requestTenantUuid)
+
+	targetDomain, err := interactor.GetDomain(targetDomainUuid, requestTenantUuid)
 	if err != nil {
 		switch e := err.(type) {
 		case *model.DomainPermissionError:
 			NewError(c, http.StatusBadRequest, err)
+		case *model.DomainDegradedError:
+			NewError(c, http.StatusConflict, err)
 		default:
 			NewError(c, http.StatusNotFound, err)
-			log.Print(e)
+			logger.Error("unexpected error", "error", e)
 		}
-		log.Print(err)
+		logger.Warn("request failed", "error", err)
 		return
 	}
 
@@ -76,7 +125,12 @@ func (d *HostController) Add(c Context) {
 		NewError(c,
 			http.StatusInternalServerError,
 			NewUnAvailableHandlingError())
-		log.Print(err)
+		logger.Error("request failed", "error", err)
+		return
+	}
+
+	if requestedHost.Pool != "" {
+		d.addFromPool(c, requestedHost, targetDomainUuid, requestTenantUuid, dryRun)
 		return
 	}
 
@@ -91,57 +145,161 @@ func (d *HostController) Add(c Context) {
 			NewError(c,
 				http.StatusInternalServerError,
 				NewUnAvailableHandlingError())
-			log.Print(e)
+			logger.Error("unexpected error", "error", e)
 		}
-		log.Print(err)
+		logger.Warn("request failed", "error", err)
 		return
 	}
 
-	gotDomain, err := d.interactor.Add(newHost, targetDomainUuid, requestTenantUuid)
+	expiresAt, lease, err := getHostLease(requestedHost.ExpiresAt, requestedHost.TtlLease)
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		return
+	}
+	if !expiresAt.IsZero() || lease != 0 {
+		err = newHost.SetLease(expiresAt, lease, time.Now())
+		if err != nil {
+			NewError(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	var staged usecase.IStagedRepository
+	if dryRun {
+		interactor, staged = interactor.Stage()
+	}
+
+	gotDomain, err := interactor.Add(newHost, targetDomainUuid, requestTenantUuid)
 	if err != nil {
 		switch e := err.(type) {
 		case *usecase.HostDuplicatedError:
 			NewError(c, http.StatusBadRequest, err)
+		case *model.DomainDegradedError:
+			NewError(c, http.StatusConflict, err)
 		case *model.DomainNotFoundError:
 			NewError(c, http.StatusNotFound, err)
 		default:
 			NewError(c,
 				http.StatusInternalServerError,
 				NewUnAvailableHandlingError())
-			log.Print(e)
+			logger.Error("unexpected error", "error", e)
 		}
-		log.Print(err)
+		logger.Warn("request failed", "error", err)
+		return
+	}
+
+	if staged != nil {
+		returnDryRunResult(c, staged)
 		return
 	}
 
 	hosts := make([]HostResult, 0)
 	for _, h := range gotDomain.Hosts {
-		hr := HostResult{Name: h.Name, Address: h.Address, Uuid: h.Uuid.String()}
+		hr := newHostResult(h)
+		hosts = append(hosts, hr)
+	}
+
+	var result DomainInfoResult
+	result.Domain = gotDomain.Name.String()
+	result.Uuid = gotDomain.Uuid.String()
+	result.Hosts = hosts
+	setETag(c, gotDomain)
+	c.JSON(http.StatusCreated, result)
+}
+
+// addFromPool adds the host with the address allocated from the pool.
+func (d *HostController) addFromPool(c Context, requestedHost HostRequest, targetDomainUuid, requestTenantUuid model.Uuid, dryRun bool) {
+	logger := NewRequestLogger(c)
+	interactor := d.interactor.WithLogger(logger)
+
+	if requestedHost.Address != "" {
+		NewError(c,
+			http.StatusBadRequest,
+			errors.New("address and pool can not be specified at the same time"))
+		return
+	}
+
+	poolUuid, err := model.NewUuid(requestedHost.Pool)
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	expiresAt, lease, err := getHostLease(requestedHost.ExpiresAt, requestedHost.TtlLease)
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var staged usecase.IStagedRepository
+	if dryRun {
+		interactor, staged = interactor.Stage()
+	}
+
+	gotDomain, _, err := interactor.AddFromPool(requestedHost.Name, expiresAt, lease, poolUuid, targetDomainUuid, requestTenantUuid)
+	if err != nil {
+		switch e := err.(type) {
+		case *usecase.HostDuplicatedError, *model.InvalidParameterGiven, *model.PoolPermissionError:
+			NewError(c, http.StatusBadRequest, err)
+		case *model.DomainDegradedError:
+			NewError(c, http.StatusConflict, err)
+		case *model.DomainNotFoundError, *model.PoolNotFoundError:
+			NewError(c, http.StatusNotFound, err)
+		case *model.PoolExhaustedError:
+			NewError(c, http.StatusConflict, err)
+		default:
+			NewError(c,
+				http.StatusInternalServerError,
+				NewUnAvailableHandlingError())
+			logger.Error("unexpected error", "error", e)
+		}
+		logger.Warn("request failed", "error", err)
+		return
+	}
+
+	if staged != nil {
+		returnDryRunResult(c, staged)
+		return
+	}
+
+	hosts := make([]HostResult, 0)
+	for _, h := range gotDomain.Hosts {
+		hr := newHostResult(h)
 		hosts = append(hosts, hr)
 	}
-	hostRes := HostResult{Name: newHost.Name, Address: newHost.Address, Uuid: newHost.Uuid.String()}
-	hosts = append(hosts, hostRes)
 
 	var result DomainInfoResult
 	result.Domain = gotDomain.Name.String()
 	result.Uuid = gotDomain.Uuid.String()
 	result.Hosts = hosts
+	setETag(c, gotDomain)
 	c.JSON(http.StatusCreated, result)
 }
 
 // List handler doc
 // @Tags Host
 // @Summary List hosts
-// @Description List hosts from domain
+// @Description List hosts from domain, sorted by the hostname
 // @Produce json
 // @Param Tenant header string true "Tenant UUID to set access control"
 // @Param domain_uuid path string true "Target domain's UUID"
-// @Success 200 {object} DomainInfoResult
+// @Param name query string false "Hostname prefix, or glob pattern with any of *?["
+// @Param address query string false "Address or CIDR of the hosts"
+// @Param limit query int false "Max number of the hosts in the page"
+// @Param cursor query string false "Cursor of the page, from next of the previous page"
+// @Param If-None-Match header string false "ETag of the domain. 304 is returned when the domain is not changed since then"
+// @Success 200 {object} HostListResult
+// @Success 304
+// @Header 200 {string} ETag "Revision of the domain"
 // @Failure 400 {object} HTTPError
 // @Failure 404 {object} HTTPError
+// @Failure 409 {object} HTTPError
 // @Failure 500 {object} HTTPError
 // @Router /v1/domains/{domain_uuid}/hosts [get]
 func (d *HostController) List(c Context) {
+	logger := NewRequestLogger(c)
+	interactor := d.interactor.WithLogger(logger)
+
 	requestTenant := c.GetHeader("Tenant")
 	if requestTenant == "" {
 		NewError(c,
@@ -159,40 +317,88 @@ func (d *HostController) List(c Context) {
 	targetDomainUuid, err := model.NewUuid(domainUuid)
 	if err != nil {
 		NewError(c, http.StatusBadRequest, err)
-		log.Print(err)
+		logger.Warn("request failed", "error", err)
+		return
+	}
+
+	query, err := getListQuery(c)
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		return
+	}
+	if query.Tenant != "" {
+		NewError(c,
+			http.StatusBadRequest,
+			errors.New("tenant query parameter is only for domain list"))
 		return
 	}
 
-	gotDomain, err := d.interactor.GetDomain(targetDomainUuid, requestTenantUuid)
+	gotDomain, err := interactor.GetDomain(targetDomainUuid, requestTenantUuid)
 	if err != nil {
 		switch e := err.(type) {
 		case *model.InvalidParameterGiven, *model.DomainPermissionError:
 			NewError(c, http.StatusBadRequest, err)
+		case *model.DomainDegradedError:
+			NewError(c, http.StatusConflict, err)
 		case *model.DomainNotFoundError:
 			NewError(c, http.StatusNotFound, err)
 		default:
 			NewError(c,
 				http.StatusInternalServerError,
 				NewUnAvailableHandlingError())
-			log.Print(e)
+			logger.Error("unexpected error", "error", e)
 		}
-		log.Print(err)
+		logger.Warn("request failed", "error", err)
 		return
 	}
 
-	hosts := make([]HostResult, 0)
+	setETag(c, gotDomain)
+	if notModified(c, gotDomain) {
+		return
+	}
+
+	var matched []*model.Host
 	for _, h := range gotDomain.Hosts {
-		host := HostResult{Name: h.Name, Address: h.Address, Uuid: h.Uuid.String()}
+		if query.MatchHost(h) {
+			matched = append(matched, h)
+		}
+	}
+
+	var keys []string
+	for _, h := range matched {
+		keys = append(keys, model.HostSortKey(h))
+	}
+	sort.Sort(hostsByKey{hosts: matched, keys: keys})
+	start, end, next := query.Page(keys)
+
+	hosts := make([]HostResult, 0)
+	for _, h := range matched[start:end] {
+		host := newHostResult(h)
 		hosts = append(hosts, host)
 	}
 
-	var result DomainInfoResult
+	var result HostListResult
 	result.Domain = gotDomain.Name.String()
 	result.Uuid = gotDomain.Uuid.String()
 	result.Hosts = hosts
+	result.Total = len(matched)
+	result.Next = query.NextLink("/v1/domains/"+gotDomain.Uuid.String()+"/hosts", next)
 	c.JSON(http.StatusOK, result)
 }
 
+// hostsByKey sorts the hosts with their sort keys.
+type hostsByKey struct {
+	hosts []*model.Host
+	keys  []string
+}
+
+func (h hostsByKey) Len() int           { return len(h.hosts) }
+func (h hostsByKey) Less(i, j int) bool { return h.keys[i] < h.keys[j] }
+func (h hostsByKey) Swap(i, j int) {
+	h.hosts[i], h.hosts[j] = h.hosts[j], h.hosts[i]
+	h.keys[i], h.keys[j] = h.keys[j], h.keys[i]
+}
+
 // Update handler doc
 // @Tags Host
 // @Summary Update host
@@ -202,12 +408,21 @@ func (d *HostController) List(c Context) {
 // @Param Tenant header string true "Tenant UUID to set access control"
 // @Param domain_uuid path string true "Target domain's UUID"
 // @Param host body HostRequest true "Request body parameter with json format"
+// @Param If-Match header string false "ETag of the domain or the host. The host is updated only when the domain is not changed since then"
+// @Param dry_run query bool false "Return the file changes without writing them"
 // @Success 204 {object} DomainInfoResult
+// @Success 200 {object} DryRunResult
+// @Header 204 {string} ETag "Revision of the domain"
 // @Failure 400 {object} HTTPError
 // @Failure 404 {object} HTTPError
+// @Failure 409 {object} HTTPError
+// @Failure 412 {object} HTTPError
 // @Failure 500 {object} HTTPError
 // @Router /v1/domains/{domain_uuid}/hosts [patch]
 func (d *HostController) Update(c Context) {
+	logger := NewRequestLogger(c)
+	interactor := d.interactor.WithLogger(logger)
+
 	requestTenant := c.GetHeader("Tenant")
 	if requestTenant == "" {
 		NewError(c,
@@ -225,19 +440,28 @@ func (d *HostController) Update(c Context) {
 	targetDomainUuid, err := model.NewUuid(domainUuid)
 	if err != nil {
 		NewError(c, http.StatusBadRequest, err)
-		log.Print(err)
+		logger.Warn("request failed", "error", err)
 		return
 	}
-	targetDomain, err := d.interactor.GetDomain(targetDomainUuid, requestTenantUuid)
+
+	dryRun, err := getDryRun(c)
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	targetDomain, err := interactor.GetDomain(targetDomainUuid, requestTenantUuid)
 	if err != nil {
 		switch e := err.(type) {
 		case *model.InvalidParameterGiven, *model.DomainPermissionError:
 			NewError(c, http.StatusBadRequest, err)
+		case *model.DomainDegradedError:
+			NewError(c, http.StatusConflict, err)
 		default:
 			NewError(c, http.StatusBadRequest, err)
-			log.Print(e)
+			logger.Error("unexpected error", "error", e)
 		}
-		log.Print(err)
+		logger.Warn("request failed", "error", err)
 		return
 	}
 
@@ -245,24 +469,26 @@ func (d *HostController) Update(c Context) {
 	targetHostUuid, err := model.NewUuid(hostUuid)
 	if err != nil {
 		NewError(c, http.StatusBadRequest, err)
-		log.Print(err)
+		logger.Warn("request failed", "error", err)
 		return
 	}
 
-	host, err := d.interactor.Get(targetHostUuid, targetDomainUuid, requestTenantUuid)
+	host, err := interactor.Get(targetHostUuid, targetDomainUuid, requestTenantUuid)
 	if err != nil {
 		switch e := err.(type) {
 		case *model.HostNotFoundError:
 			NewError(c, http.StatusNotFound, err)
+		case *model.DomainDegradedError:
+			NewError(c, http.StatusConflict, err)
 		case *model.DomainNotFoundError:
 			NewError(c, http.StatusBadRequest, err)
 		default:
 			NewError(c,
 				http.StatusInternalServerError,
 				NewUnAvailableHandlingError())
-			log.Print(e)
+			logger.Error("unexpected error", "error", e)
 		}
-		log.Print(err)
+		logger.Warn("request failed", "error", err)
 		return
 	}
 
@@ -272,7 +498,7 @@ func (d *HostController) Update(c Context) {
 		NewError(c,
 			http.StatusInternalServerError,
 			NewUnAvailableHandlingError())
-		log.Print(err)
+		logger.Error("request failed", "error", err)
 		return
 	}
 
@@ -294,13 +520,39 @@ func (d *HostController) Update(c Context) {
 	updatedHost, err := model.NewHost(targetHostUuid, hostFqdn, address)
 	if err != nil {
 		NewError(c, http.StatusBadRequest, err)
-		log.Print(err)
+		logger.Warn("request failed", "error", err)
 		return
 	}
 
-	err = d.interactor.Update(updatedHost, targetDomainUuid, requestTenantUuid)
+	expiresAt, lease, err := getHostLease(requestedHostInfo.ExpiresAt, requestedHostInfo.TtlLease)
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		return
+	}
+	if !expiresAt.IsZero() || lease != 0 {
+		err = updatedHost.SetLease(expiresAt, lease, time.Now())
+		if err != nil {
+			NewError(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	var staged usecase.IStagedRepository
+	if dryRun {
+		interactor, staged = interactor.Stage()
+	}
+
+	if revisions, ok := getIfMatch(c); ok {
+		interactor = interactor.IfMatch(revisions)
+	}
+
+	err = interactor.Update(updatedHost, targetDomainUuid, requestTenantUuid)
 	if err != nil {
 		switch e := err.(type) {
+		case *model.DomainDegradedError:
+			NewError(c, http.StatusConflict, err)
+		case *model.DomainRevisionMismatchError:
+			NewError(c, http.StatusPreconditionFailed, err)
 		case *model.HostNotFoundError, *model.DomainNotFoundError:
 			NewError(c, http.StatusNotFound, err)
 		case *usecase.HostDuplicatedError, *model.DomainPermissionError:
@@ -309,15 +561,22 @@ func (d *HostController) Update(c Context) {
 			NewError(c,
 				http.StatusInternalServerError,
 				NewUnAvailableHandlingError())
-			log.Print(e)
+			logger.Error("unexpected error", "error", e)
 		}
-		log.Print(err)
+		logger.Warn("request failed", "error", err)
 		return
 	}
 
-	domain, err := d.interactor.GetDomain(targetDomainUuid, requestTenantUuid)
+	if staged != nil {
+		returnDryRunResult(c, staged)
+		return
+	}
+
+	domain, err := interactor.GetDomain(targetDomainUuid, requestTenantUuid)
 	if err != nil {
 		switch e := err.(type) {
+		case *model.DomainDegradedError:
+			NewError(c, http.StatusConflict, err)
 		case *model.DomainNotFoundError:
 			NewError(c, http.StatusNotFound, err)
 		case *model.DomainPermissionError:
@@ -326,24 +585,25 @@ func (d *HostController) Update(c Context) {
 			NewError(c,
 				http.StatusInternalServerError,
 				NewUnAvailableHandlingError())
-			log.Print(e)
+			logger.Error("unexpected error", "error", e)
 		}
-		log.Print(err)
+		logger.Warn("request failed", "error", err)
 		return
 	}
 
 	hosts := make([]HostResult, 0)
 	for _, h := range domain.Hosts {
-		hr := HostResult{Name: h.Name, Address: h.Address, Uuid: h.Uuid.String()}
+		hr := newHostResult(h)
 		hosts = append(hosts, hr)
 	}
-	hostRes := HostResult{Name: updatedHost.Name, Address: updatedHost.Address, Uuid: updatedHost.Uuid.String()}
+	hostRes := newHostResult(updatedHost)
 	hosts = append(hosts, hostRes)
 
 	var result DomainInfoResult
 	result.Domain = domain.Name.String()
 	result.Uuid = domain.Uuid.String()
 	result.Hosts = hosts
+	setETag(c, domain)
 	c.JSON(http.StatusNoContent, result)
 }
 
@@ -355,11 +615,17 @@ func (d *HostController) Update(c Context) {
 // @Param Tenant header string true "Tenant UUID to set access control"
 // @Param domain_uuid path string true "Target domain's UUID"
 // @Param host_uuid path string true "Target host's UUID"
+// @Param If-None-Match header string false "ETag of the domain or the host. 304 is returned when the domain is not changed since then"
 // @Success 200 {object} DomainInfoResult
+// @Success 304
+// @Header 200 {string} ETag "Revision of the domain"
 // @Failure 400 {object} HTTPError
 // @Failure 404 {object} HTTPError
 // @Router /v1/domains/{domain_uuid}/hosts/{host_uuid} [get]
 func (d *HostController) Get(c Context) {
+	logger := NewRequestLogger(c)
+	interactor := d.interactor.WithLogger(logger)
+
 	requestTenant := c.GetHeader("Tenant")
 	if requestTenant == "" {
 		NewError(c,
@@ -377,7 +643,7 @@ func (d *HostController) Get(c Context) {
 	targetDomainUuid, err := model.NewUuid(domainUuid)
 	if err != nil {
 		NewError(c, http.StatusBadRequest, err)
-		log.Print(err)
+		logger.Warn("request failed", "error", err)
 		return
 	}
 
@@ -385,14 +651,17 @@ func (d *HostController) Get(c Context) {
 	targetHostUuid, err := model.NewUuid(hostUuid)
 	if err != nil {
 		NewError(c, http.StatusBadRequest, err)
-		log.Print(err)
+		logger.Warn("request failed", "error", err)
 		return
 	}
 
-	host, err := d.interactor.Get(targetHostUuid, targetDomainUuid, requestTenantUuid)
+	// The domain is got before the host, so that ETag is never newer than the host.
+	domain, err := interactor.GetDomain(targetDomainUuid, requestTenantUuid)
 	if err != nil {
 		switch e := err.(type) {
-		case *model.HostNotFoundError, *model.DomainNotFoundError:
+		case *model.DomainDegradedError:
+			NewError(c, http.StatusConflict, err)
+		case *model.DomainNotFoundError:
 			NewError(c, http.StatusNotFound, err)
 		case *model.DomainPermissionError:
 			NewError(c, http.StatusBadRequest, err)
@@ -400,29 +669,38 @@ func (d *HostController) Get(c Context) {
 			NewError(c,
 				http.StatusInternalServerError,
 				NewUnAvailableHandlingError())
-			log.Print(e)
+			logger.Error("unexpected error", "error", e)
 		}
-		log.Print(err)
+		logger.Warn("request failed", "error", err)
 		return
 	}
 
-	domain, err := d.interactor.GetDomain(targetDomainUuid, requestTenantUuid)
+	host, err := interactor.Get(targetHostUuid, targetDomainUuid, requestTenantUuid)
 	if err != nil {
 		switch e := err.(type) {
-		case *model.DomainNotFoundError:
+		case *model.DomainDegradedError:
+			NewError(c, http.StatusConflict, err)
+		case *model.HostNotFoundError, *model.DomainNotFoundError:
 			NewError(c, http.StatusNotFound, err)
+		case *model.DomainPermissionError:
+			NewError(c, http.StatusBadRequest, err)
 		default:
 			NewError(c,
 				http.StatusInternalServerError,
 				NewUnAvailableHandlingError())
-			log.Print(e)
+			logger.Error("unexpected error", "error", e)
 		}
-		log.Print(err)
+		logger.Warn("request failed", "error", err)
+		return
+	}
+
+	setETag(c, domain)
+	if notModified(c, domain) {
 		return
 	}
 
 	hosts := make([]HostResult, 0)
-	hostRes := HostResult{Name: host.Name, Address: host.Address, Uuid: host.Uuid.String()}
+	hostRes := newHostResult(host)
 	hosts = append(hosts, hostRes)
 
 	var result DomainInfoResult
@@ -432,6 +710,124 @@ func (d *HostController) Get(c Context) {
 	c.JSON(http.StatusOK, result)
 }
 
+// Renew handler doc
+// @Tags Host
+// @Summary Renew host
+// @Description Extend the expiry of the leased host by its lease from now.
+// @Description When ttl_lease is specified, the lease of the host is replaced with it.
+// @Accept json
+// @Produce json
+// @Param Tenant header string true "Tenant UUID to set access control"
+// @Param domain_uuid path string true "Target domain's UUID"
+// @Param host_uuid path string true "Target host's UUID"
+// @Param lease body HostRenewRequest false "Request body parameter with json format"
+// @Success 200 {object} HostResult
+// @Failure 400 {object} HTTPError
+// @Failure 404 {object} HTTPError
+// @Failure 409 {object} HTTPError
+// @Failure 500 {object} HTTPError
+// @Router /v1/domains/{domain_uuid}/hosts/{host_uuid}:renew [post]
+func (d *HostController) Renew(c Context) {
+	logger := NewRequestLogger(c)
+	interactor := d.interactor.WithLogger(logger)
+
+	requestTenant := c.GetHeader("Tenant")
+	if requestTenant == "" {
+		NewError(c,
+			http.StatusBadRequest,
+			errors.New("tenant uuid header is not specified"))
+		return
+	}
+
+	requestTenantUuid, err := model.NewUuid(requestTenant)
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		return
+	}
+	targetDomainUuid, err := model.NewUuid(c.Param("domain_uuid"))
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		logger.Warn("request failed", "error", err)
+		return
+	}
+	targetHostUuid, err := model.NewUuid(c.Param("host_uuid"))
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		logger.Warn("request failed", "error", err)
+		return
+	}
+
+	// The body is optional, since the lease of the host is used by default.
+	body, err := c.GetRawData()
+	if err != nil {
+		NewError(c,
+			http.StatusInternalServerError,
+			NewUnAvailableHandlingError())
+		logger.Error("request failed", "error", err)
+		return
+	}
+
+	var request HostRenewRequest
+	if len(body) > 0 {
+		err = json.Unmarshal(body, &request)
+		if err != nil {
+			NewError(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	_, lease, err := getHostLease("", request.TtlLease)
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	host, err := interactor.Renew(targetHostUuid, lease, targetDomainUuid, requestTenantUuid)
+	if err != nil {
+		switch e := err.(type) {
+		case *model.InvalidParameterGiven, *model.DomainPermissionError:
+			NewError(c, http.StatusBadRequest, err)
+		case *model.DomainDegradedError:
+			NewError(c, http.StatusConflict, err)
+		case *model.HostNotFoundError, *model.DomainNotFoundError:
+			NewError(c, http.StatusNotFound, err)
+		default:
+			NewError(c,
+				http.StatusInternalServerError,
+				NewUnAvailableHandlingError())
+			logger.Error("unexpected error", "error", e)
+		}
+		logger.Warn("request failed", "error", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newHostResult(host))
+}
+
+// getHostLease parses expires_at with RFC 3339 and ttl_lease with Go duration like "1h30m".
+// Both of them are zero when they are not specified.
+func getHostLease(expiresAtParam, leaseParam string) (time.Time, time.Duration, error) {
+	var expiresAt time.Time
+	var lease time.Duration
+	var err error
+
+	if expiresAtParam != "" {
+		expiresAt, err = time.Parse(time.RFC3339, expiresAtParam)
+		if err != nil {
+			return time.Time{}, 0, errors.New("invalid expires_at is specified. it has to be RFC 3339 format")
+		}
+	}
+
+	if leaseParam != "" {
+		lease, err = time.ParseDuration(leaseParam)
+		if err != nil || lease <= 0 {
+			return time.Time{}, 0, errors.New("invalid ttl_lease is specified. it has to be positive duration like 1h30m")
+		}
+	}
+
+	return expiresAt, lease, nil
+}
+
 // Delete handler doc
 // @Tags Host
 // @Summary Delete host
@@ -439,11 +835,18 @@ func (d *HostController) Get(c Context) {
 // @Param Tenant header string true "Tenant UUID to set access control"
 // @Param domain_uuid path string true "Target domain's UUID"
 // @Param host_uuid path string true "Target host's UUID"
+// @Param If-Match header string false "ETag of the domain or the host. The host is deleted only when the domain is not changed since then"
+// @Param dry_run query bool false "Return the file changes without writing them"
 // @Success 204 {object} DomainInfoResult
+// @Success 200 {object} DryRunResult
 // @Failure 400 {object} HTTPError
 // @Failure 404 {object} HTTPError
+// @Failure 412 {object} HTTPError
 // @Router /v1/domains/{domain_uuid}/hosts/{host_uuid} [delete]
 func (d *HostController) Delete(c Context) {
+	logger := NewRequestLogger(c)
+	interactor := d.interactor.WithLogger(logger)
+
 	requestTenant := c.GetHeader("Tenant")
 	if requestTenant == "" {
 		NewError(c,
@@ -461,7 +864,7 @@ func (d *HostController) Delete(c Context) {
 	targetDomainUuid, err := model.NewUuid(domainUuid)
 	if err != nil {
 		NewError(c, http.StatusBadRequest, err)
-		log.Print(err)
+		logger.Warn("request failed", "error", err)
 		return
 	}
 
@@ -469,11 +872,17 @@ func (d *HostController) Delete(c Context) {
 	targetHostUuid, err := model.NewUuid(hostUuid)
 	if err != nil {
 		NewError(c, http.StatusBadRequest, err)
-		log.Print(err)
+		logger.Warn("request failed", "error", err)
+		return
+	}
+
+	dryRun, err := getDryRun(c)
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
 		return
 	}
 
-	host, err := d.interactor.Get(targetHostUuid, targetDomainUuid, requestTenantUuid)
+	host, err := interactor.Get(targetHostUuid, targetDomainUuid, requestTenantUuid)
 	if err != nil {
 		switch e := err.(type) {
 		case *model.HostNotFoundError:
@@ -484,15 +893,28 @@ func (d *HostController) Delete(c Context) {
 			NewError(c,
 				http.StatusInternalServerError,
 				NewUnAvailableHandlingError())
-			log.Print(e)
+			logger.Error("unexpected error", "error", e)
 		}
-		log.Print(err)
+		logger.Warn("request failed", "error", err)
 		return
 	}
 
-	err = d.interactor.Delete(host, targetDomainUuid, requestTenantUuid)
+	var staged usecase.IStagedRepository
+	if dryRun {
+		interactor, staged = interactor.Stage()
+	}
+
+	if revisions, ok := getIfMatch(c); ok {
+		interactor = interactor.IfMatch(revisions)
+	}
+
+	err = interactor.Delete(host, targetDomainUuid, requestTenantUuid)
 	if err != nil {
 		switch e := err.(type) {
+		case *model.DomainDegradedError:
+			NewError(c, http.StatusConflict, err)
+		case *model.DomainRevisionMismatchError:
+			NewError(c, http.StatusPreconditionFailed, err)
 		case *model.HostNotFoundError, *model.DomainNotFoundError:
 			NewError(c, http.StatusNotFound, err)
 		case *model.DomainPermissionError:
@@ -501,11 +923,167 @@ func (d *HostController) Delete(c Context) {
 			NewError(c,
 				http.StatusInternalServerError,
 				NewUnAvailableHandlingError())
-			log.Print(e)
+			logger.Error("unexpected error", "error", e)
 		}
-		log.Print(err)
+		logger.Warn("request failed", "error", err)
+		return
+	}
+
+	if staged != nil {
+		returnDryRunResult(c, staged)
 		return
 	}
 
 	c.Status(http.StatusNoContent)
 }
+
+// Apply handler doc
+// @Tags Host
+// @Summary Apply hosts
+// @Description Replace hosts in domain with the desired host set in one write
+// @Accept json
+// @Produce json
+// @Param Tenant header string true "Tenant UUID to set access control"
+// @Param domain_uuid path string true "Target domain's UUID"
+// @Param If-Match header string false "ETag of the domain. The hosts are replaced only when the domain is not changed since then"
+// @Param hosts body HostsApplyRequest true "Request body parameter with json format"
+// @Param dry_run query bool false "Return the file changes without writing them"
+// @Success 200 {object} HostApplyResult
+// @Failure 400 {object} HTTPError
+// @Failure 404 {object} HTTPError
+// @Failure 409 {object} HTTPError
+// @Failure 412 {object} HTTPError
+// @Failure 500 {object} HTTPError
+// @Router /v1/domains/{domain_uuid}/hosts [put]
+func (d *HostController) Apply(c Context) {
+	logger := NewRequestLogger(c)
+	interactor := d.interactor.WithLogger(logger)
+
+	requestTenant := c.GetHeader("Tenant")
+	if requestTenant == "" {
+		NewError(c,
+			http.StatusBadRequest,
+			errors.New("tenant uuid header is not specified"))
+		return
+	}
+
+	requestTenantUuid, err := model.NewUuid(requestTenant)
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		return
+	}
+	domainUuid := c.Param("domain_uuid")
+	targetDomainUuid, err := model.NewUuid(domainUuid)
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		logger.Warn("request failed", "error", err)
+		return
+	}
+
+	dryRun, err := getDryRun(c)
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	targetDomain, err := interactor.GetDomain(targetDomainUuid, requestTenantUuid)
+	if err != nil {
+		switch e := err.(type) {
+		case *model.InvalidParameterGiven, *model.DomainPermissionError:
+			NewError(c, http.StatusBadRequest, err)
+		case *model.DomainDegradedError:
+			NewError(c, http.StatusConflict, err)
+		case *model.DomainNotFoundError:
+			NewError(c, http.StatusNotFound, err)
+		default:
+			NewError(c,
+				http.StatusInternalServerError,
+				NewUnAvailableHandlingError())
+			logger.Error("unexpected error", "error", e)
+		}
+		logger.Warn("request failed", "error", err)
+		return
+	}
+
+	var request HostsApplyRequest
+	err = c.ShouldBindJSON(&request)
+	if err != nil {
+		NewError(c,
+			http.StatusInternalServerError,
+			NewUnAvailableHandlingError())
+		logger.Error("request failed", "error", err)
+		return
+	}
+
+	var desiredHosts []*model.Host
+	for _, h := range request.Hosts {
+		newHost, err := model.NewOriginalHost(h.Name, h.Address, targetDomain.Name)
+		if err != nil {
+			NewError(c, http.StatusBadRequest, err)
+			logger.Warn("request failed", "error", err)
+			return
+		}
+		desiredHosts = append(desiredHosts, newHost)
+	}
+
+	var staged usecase.IStagedRepository
+	if dryRun {
+		interactor, staged = interactor.Stage()
+	}
+
+	if revisions, ok := getIfMatch(c); ok {
+		interactor = interactor.IfMatch(revisions)
+	}
+
+	domain, changes, err := interactor.Apply(desiredHosts, targetDomainUuid, requestTenantUuid)
+	if err != nil {
+		switch e := err.(type) {
+		case *model.InvalidParameterGiven, *model.DomainPermissionError, *usecase.HostDuplicatedError:
+			NewError(c, http.StatusBadRequest, err)
+		case *model.DomainDegradedError:
+			NewError(c, http.StatusConflict, err)
+		case *model.DomainRevisionMismatchError:
+			NewError(c, http.StatusPreconditionFailed, err)
+		case *model.DomainNotFoundError:
+			NewError(c, http.StatusNotFound, err)
+		default:
+			NewError(c,
+				http.StatusInternalServerError,
+				NewUnAvailableHandlingError())
+			logger.Error("unexpected error", "error", e)
+		}
+		logger.Warn("request failed", "error", err)
+		return
+	}
+
+	if staged != nil {
+		returnDryRunResult(c, staged)
+		return
+	}
+
+	tenants := make([]string, 0)
+	for _, t := range domain.Tenants {
+		tenants = append(tenants, t.String())
+	}
+
+	var result HostApplyResult
+	result.Domain = domain.Name.String()
+	result.Uuid = domain.Uuid.String()
+	result.Tenants = tenants
+	result.Hosts = newHostResultList(domain.Hosts)
+	result.Changes.Added = newHostResultList(changes.Added)
+	result.Changes.Updated = newHostResultList(changes.Updated)
+	result.Changes.Deleted = newHostResultList(changes.Deleted)
+	result.Changes.Unchanged = newHostResultList(changes.Unchanged)
+	setETag(c, domain)
+	c.JSON(http.StatusOK, result)
+}
+
+func newHostResultList(hostList []*model.Host) []HostResult {
+	hosts := make([]HostResult, 0)
+	for _, h := range hostList {
+		hr := newHostResult(h)
+		hosts = append(hosts, hr)
+	}
+	return hosts
+}
@@ -0,0 +1,302 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"coredns_api/internal/model"
+	"coredns_api/internal/usecase"
+)
+
+// Import modes
+const (
+	hostImportModeAtomic      = "atomic"
+	hostImportModeSkipInvalid = "skip_invalid"
+)
+
+// Result
+type HostImportResult struct {
+	Domain   string                `json:"domain"`
+	Uuid     string                `json:"uuid"`
+	Imported []HostResult          `json:"imported"`
+	Errors   []HostLineErrorResult `json:"errors"`
+}
+
+type HostImportErrorResult struct {
+	HTTPError
+	Errors []HostLineErrorResult `json:"errors"`
+}
+
+type HostLineErrorResult struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+var hostListContentTypes = map[string]string{
+	model.HostListFormatCSV:   "text/csv",
+	model.HostListFormatJSON:  "application/json",
+	model.HostListFormatHosts: "text/plain",
+}
+
+// getHostListFormat returns the format given with `?format=`, or the one matched with the header.
+// The media types of the header are tried in the order of their q-values, and of the header for the same q-value.
+func getHostListFormat(c Context, header, defaultFormat string) (string, error) {
+	format := c.Query("format")
+	if format != "" {
+		if _, ok := hostListContentTypes[format]; !ok {
+			return "", errors.New("invalid format query parameter is specified. format: " + format)
+		}
+		return format, nil
+	}
+
+	found := ""
+	foundQuality := 0.0
+	for _, mediaRange := range strings.Split(c.GetHeader(header), ",") {
+		params := strings.Split(mediaRange, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+
+		quality := 1.0
+		for _, param := range params[1:] {
+			key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if ok && strings.TrimSpace(key) == "q" {
+				q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+				if err != nil {
+					q = 0
+				}
+				quality = q
+			}
+		}
+		if quality <= foundQuality {
+			continue
+		}
+
+		for f, contentType := range hostListContentTypes {
+			if mediaType == contentType {
+				found = f
+				foundQuality = quality
+			}
+		}
+	}
+
+	if found == "" {
+		return defaultFormat, nil
+	}
+	return found, nil
+}
+
+func newHostLineErrorResultList(lineErrors []*usecase.HostLineError) []HostLineErrorResult {
+	errorList := make([]HostLineErrorResult, 0)
+	for _, e := range lineErrors {
+		errorList = append(errorList, HostLineErrorResult{Line: e.Line, Message: e.Err.Error()})
+	}
+	return errorList
+}
+
+// Import handler doc
+// @Tags Host
+// @Summary Import hosts
+// @Description Import host list in CSV, JSON array or hosts file format to domain with one write
+// @Accept plain
+// @Produce json
+// @Param Tenant header string true "Tenant UUID to set access control"
+// @Param domain_uuid path string true "Target domain's UUID"
+// @Param format query string false "Host list format. csv, json or hosts. Content-Type is used if it is not given"
+// @Param mode query string false "atomic (default) to import nothing with any invalid line, or skip_invalid"
+// @Param dry_run query bool false "Return the file changes without writing them"
+// @Success 200 {object} HostImportResult
+// @Failure 400 {object} HostImportErrorResult
+// @Failure 404 {object} HTTPError
+// @Failure 409 {object} HTTPError
+// @Failure 500 {object} HTTPError
+// @Router /v1/domains/{domain_uuid}/hosts:import [post]
+func (d *HostController) Import(c Context) {
+	logger := NewRequestLogger(c)
+	interactor := d.interactor.WithLogger(logger)
+
+	requestTenant := c.GetHeader("Tenant")
+	if requestTenant == "" {
+		NewError(c,
+			http.StatusBadRequest,
+			errors.New("tenant uuid header is not specified"))
+		return
+	}
+
+	requestTenantUuid, err := model.NewUuid(requestTenant)
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		return
+	}
+	domainUuid := c.Param("domain_uuid")
+	targetDomainUuid, err := model.NewUuid(domainUuid)
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		logger.Warn("request failed", "error", err)
+		return
+	}
+
+	dryRun, err := getDryRun(c)
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	format, err := getHostListFormat(c, "Content-Type", "")
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		return
+	}
+	if format == "" {
+		NewError(c,
+			http.StatusBadRequest,
+			errors.New("host list format is not specified with format query parameter or Content-Type"))
+		return
+	}
+
+	mode := c.Query("mode")
+	if mode == "" {
+		mode = hostImportModeAtomic
+	}
+	if mode != hostImportModeAtomic && mode != hostImportModeSkipInvalid {
+		NewError(c,
+			http.StatusBadRequest,
+			errors.New("invalid mode query parameter is specified. mode: "+mode))
+		return
+	}
+
+	data, err := c.GetRawData()
+	if err != nil {
+		NewError(c,
+			http.StatusInternalServerError,
+			NewUnAvailableHandlingError())
+		logger.Error("request failed", "error", err)
+		return
+	}
+
+	lines, err := model.ParseHostList(format, string(data))
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		logger.Warn("request failed", "error", err)
+		return
+	}
+
+	var staged usecase.IStagedRepository
+	if dryRun {
+		interactor, staged = interactor.Stage()
+	}
+
+	domain, imported, err := interactor.Import(lines, mode == hostImportModeSkipInvalid, targetDomainUuid, requestTenantUuid)
+	if err != nil {
+		switch e := err.(type) {
+		case *usecase.HostImportError:
+			var result HostImportErrorResult
+			result.Code = http.StatusBadRequest
+			result.Message = err.Error()
+			result.Errors = newHostLineErrorResultList(imported.Errors)
+			c.JSON(http.StatusBadRequest, result)
+		case *model.InvalidParameterGiven, *model.DomainPermissionError:
+			NewError(c, http.StatusBadRequest, err)
+		case *model.DomainDegradedError:
+			NewError(c, http.StatusConflict, err)
+		case *model.DomainNotFoundError:
+			NewError(c, http.StatusNotFound, err)
+		default:
+			NewError(c,
+				http.StatusInternalServerError,
+				NewUnAvailableHandlingError())
+			logger.Error("unexpected error", "error", e)
+		}
+		logger.Warn("request failed", "error", err)
+		return
+	}
+
+	if staged != nil {
+		returnDryRunResult(c, staged)
+		return
+	}
+
+	var result HostImportResult
+	result.Domain = domain.Name.String()
+	result.Uuid = domain.Uuid.String()
+	result.Imported = newHostResultList(imported.Imported)
+	result.Errors = newHostLineErrorResultList(imported.Errors)
+	setETag(c, domain)
+	c.JSON(http.StatusOK, result)
+}
+
+// Export handler doc
+// @Tags Host
+// @Summary Export hosts
+// @Description Export hosts in domain as CSV, JSON array or hosts file format
+// @Produce plain
+// @Param Tenant header string true "Tenant UUID to set access control"
+// @Param domain_uuid path string true "Target domain's UUID"
+// @Param format query string false "Host list format. csv, json or hosts. Accept header is used if it is not given"
+// @Success 200 {string} string
+// @Failure 400 {object} HTTPError
+// @Failure 404 {object} HTTPError
+// @Failure 409 {object} HTTPError
+// @Failure 500 {object} HTTPError
+// @Router /v1/domains/{domain_uuid}/hosts:export [get]
+func (d *HostController) Export(c Context) {
+	logger := NewRequestLogger(c)
+	interactor := d.interactor.WithLogger(logger)
+
+	requestTenant := c.GetHeader("Tenant")
+	if requestTenant == "" {
+		NewError(c,
+			http.StatusBadRequest,
+			errors.New("tenant uuid header is not specified"))
+		return
+	}
+
+	requestTenantUuid, err := model.NewUuid(requestTenant)
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		return
+	}
+	domainUuid := c.Param("domain_uuid")
+	targetDomainUuid, err := model.NewUuid(domainUuid)
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		logger.Warn("request failed", "error", err)
+		return
+	}
+
+	format, err := getHostListFormat(c, "Accept", model.HostListFormatJSON)
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	gotDomain, err := interactor.GetDomain(targetDomainUuid, requestTenantUuid)
+	if err != nil {
+		switch e := err.(type) {
+		case *model.InvalidParameterGiven, *model.DomainPermissionError:
+			NewError(c, http.StatusBadRequest, err)
+		case *model.DomainDegradedError:
+			NewError(c, http.StatusConflict, err)
+		case *model.DomainNotFoundError:
+			NewError(c, http.StatusNotFound, err)
+		default:
+			NewError(c,
+				http.StatusInternalServerError,
+				NewUnAvailableHandlingError())
+			logger.Error("unexpected error", "error", e)
+		}
+		logger.Warn("request failed", "error", err)
+		return
+	}
+
+	hostList, err := model.FormatHostList(format, gotDomain.Hosts)
+	if err != nil {
+		NewError(c,
+			http.StatusInternalServerError,
+			NewUnAvailableHandlingError())
+		logger.Error("request failed", "error", err)
+		return
+	}
+
+	c.Data(http.StatusOK, hostListContentTypes[format]+"; charset=utf-8", []byte(hostList))
+}
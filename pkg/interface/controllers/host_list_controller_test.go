@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"testing"
+
+	"coredns_api/internal/model"
+)
+
+func TestGetHostListFormat(t *testing.T) {
+	expects := map[string]string{
+		"":                                    model.HostListFormatJSON,
+		"*/*":                                 model.HostListFormatJSON,
+		"text/csv":                            model.HostListFormatCSV,
+		"text/plain; charset=utf-8":           model.HostListFormatHosts,
+		"text/csv, text/plain":                model.HostListFormatCSV,
+		"text/plain, text/csv":                model.HostListFormatHosts,
+		"text/csv;q=0.5, text/plain":          model.HostListFormatHosts,
+		"text/csv;q=0.9, text/plain;q=0.9":    model.HostListFormatCSV,
+		"text/html, text/csv;q=0.1":           model.HostListFormatCSV,
+		"text/csv;q=0, text/plain;q=0.1":      model.HostListFormatHosts,
+		"text/csv;q=hoge, application/json":   model.HostListFormatJSON,
+		"Text/CSV":                            model.HostListFormatCSV,
+		"application/json-patch+json":         model.HostListFormatJSON,
+		"text/plain, text/csv, text/html;q=1": model.HostListFormatHosts,
+	}
+
+	// The header is parsed many times, since the formats used to be tried in the random order.
+	for header, expect := range expects {
+		for i := 0; i < 20; i++ {
+			c := NewRecordingContext(map[string]string{"Accept": header}, nil, nil, nil)
+			format, err := getHostListFormat(c, "Accept", model.HostListFormatJSON)
+			if err != nil || format != expect {
+				t.Errorf("format is missmatched: %q %s", header, format)
+				break
+			}
+		}
+	}
+
+	c := NewRecordingContext(map[string]string{"Accept": "text/csv"}, nil, map[string]string{"format": "hosts"}, nil)
+	format, err := getHostListFormat(c, "Accept", model.HostListFormatJSON)
+	if err != nil || format != model.HostListFormatHosts {
+		t.Error("format query is not preferred to the header")
+	}
+	c = NewRecordingContext(nil, nil, map[string]string{"format": "yaml"}, nil)
+	_, err = getHostListFormat(c, "Accept", model.HostListFormatJSON)
+	if err == nil {
+		t.Error("invalid format query is accepted")
+	}
+}
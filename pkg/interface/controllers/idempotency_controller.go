@@ -0,0 +1,168 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"coredns_api/internal/model"
+	"coredns_api/internal/usecase"
+)
+
+// Controller
+type IdempotencyController struct {
+	interactor *usecase.IdempotencyInteractor
+}
+
+func NewIdempotencyController(itr *usecase.IdempotencyInteractor) *IdempotencyController {
+	return &IdempotencyController{itr}
+}
+
+// Handle calls the handler only once for the requests with the same Idempotency-Key in the window,
+// and replays its response for the retries. request is the method and the path of the request,
+// which are compared with the body to tell the retries from another request with the same key.
+func (i *IdempotencyController) Handle(c Context, request string, handler func(c Context)) {
+	logger := NewRequestLogger(c)
+
+	key := c.GetHeader("Idempotency-Key")
+	window, err := model.GetIdempotencyWindow()
+	if key == "" || err != nil || window == 0 {
+		handler(c)
+		return
+	}
+
+	maxKeys, err := model.GetIdempotencyMaxKeys()
+	if err != nil {
+		NewError(c,
+			http.StatusInternalServerError,
+			NewUnAvailableHandlingError())
+		logger.Error("request failed", "error", err)
+		return
+	}
+
+	// The keys are scoped by the tenant, so only valid tenants can keep them.
+	requestTenant := c.GetHeader("Tenant")
+	if requestTenant == "" {
+		NewError(c,
+			http.StatusBadRequest,
+			errors.New("tenant uuid header is not specified"))
+		return
+	}
+	requestTenantUuid, err := model.NewUuid(requestTenant)
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	body, err := c.GetRawData()
+	if err != nil {
+		NewError(c,
+			http.StatusInternalServerError,
+			NewUnAvailableHandlingError())
+		logger.Error("request failed", "error", err)
+		return
+	}
+
+	idempotentRequest, err := model.NewIdempotentRequest(key, requestTenantUuid.String(), request, body)
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	response, err := i.interactor.Begin(idempotentRequest, window, maxKeys, time.Now())
+	if err != nil {
+		switch e := err.(type) {
+		case *model.IdempotencyKeyConflictError:
+			NewError(c, http.StatusConflict, err)
+		case *model.IdempotencyKeysFullError:
+			NewError(c, http.StatusServiceUnavailable, err)
+			logger.Warn("request failed", "error", err)
+		default:
+			NewError(c,
+				http.StatusInternalServerError,
+				NewUnAvailableHandlingError())
+			logger.Error("unexpected error", "error", e)
+		}
+		return
+	}
+	if response != nil {
+		replayResponse(c, response)
+		return
+	}
+
+	ic := &idempotentContext{Context: c, recorder: NewRecordingContext(nil, nil, nil, body)}
+	completed := false
+	defer func() {
+		// The handler panicked.
+		if !completed {
+			i.interactor.Abort(idempotentRequest)
+		}
+	}()
+
+	handler(ic)
+	i.interactor.Complete(idempotentRequest, ic.response())
+	completed = true
+}
+
+func replayResponse(c Context, response *model.IdempotentResponse) {
+	for key, value := range response.Headers {
+		c.Header(key, value)
+	}
+	c.Header("Idempotent-Replayed", "true")
+
+	if response.Body == nil {
+		c.Status(response.StatusCode)
+		return
+	}
+	c.Data(response.StatusCode, response.ContentType, response.Body)
+}
+
+// idempotentContext passes the response to the context, and records it to replay.
+// The request body is given from the one read to hash it.
+type idempotentContext struct {
+	Context
+	recorder *RecordingContext
+}
+
+func (c *idempotentContext) GetRawData() ([]byte, error) {
+	return c.recorder.GetRawData()
+}
+func (c *idempotentContext) ShouldBindJSON(obj interface{}) error {
+	return c.recorder.ShouldBindJSON(obj)
+}
+func (c *idempotentContext) Bind(obj interface{}) error {
+	return c.recorder.Bind(obj)
+}
+func (c *idempotentContext) Header(key, value string) {
+	c.recorder.Header(key, value)
+	c.Context.Header(key, value)
+}
+func (c *idempotentContext) Status(code int) {
+	c.recorder.Status(code)
+	c.Context.Status(code)
+}
+func (c *idempotentContext) JSON(code int, obj interface{}) {
+	c.recorder.JSON(code, obj)
+	c.Context.JSON(code, obj)
+}
+func (c *idempotentContext) Data(code int, contentType string, data []byte) {
+	c.recorder.Data(code, contentType, data)
+	c.Context.Data(code, contentType, data)
+}
+
+// response returns the recorded response to replay.
+func (c *idempotentContext) response() *model.IdempotentResponse {
+	response := &model.IdempotentResponse{
+		StatusCode:  c.recorder.StatusCode(),
+		Headers:     c.recorder.ResponseHeaders(),
+		ContentType: c.recorder.ContentType(),
+	}
+
+	body, err := c.recorder.ResponseBody()
+	if err != nil {
+		response.StatusCode = http.StatusInternalServerError
+		return response
+	}
+	response.Body = body
+	return response
+}
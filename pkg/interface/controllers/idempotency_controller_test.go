@@ -0,0 +1,175 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"coredns_api/internal/interface/repository"
+	"coredns_api/internal/model"
+	"coredns_api/internal/usecase"
+)
+
+func newTestIdempotentContext(key, tenant, body string) *RecordingContext {
+	return NewRecordingContext(map[string]string{"Idempotency-Key": key, "Tenant": tenant}, nil, nil, []byte(body))
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	t.Setenv("IDEMPOTENCY_WINDOW", "1h")
+	controller := NewIdempotencyController(usecase.NewIdempotencyInteractor(repository.NewIdempotencyRepository()))
+	// The keys are kept by the repository of every test, so they are unique to this one.
+	key := "replay-" + model.NewRequestId("")
+
+	calls := 0
+	handler := func(c Context) {
+		calls++
+		var request DomainRequest
+		err := c.ShouldBindJSON(&request)
+		if err != nil {
+			t.Error(err)
+		}
+		c.Header("Location", "/v1/domains/1")
+		c.JSON(http.StatusCreated, DomainRequest{Name: request.Name})
+	}
+
+	first := newTestIdempotentContext(key, testTenant, `{"domain":"hogehoge.hoge"}`)
+	controller.Handle(first, "POST /v1/domains", handler)
+	retried := newTestIdempotentContext(key, testTenant, `{"domain":"hogehoge.hoge"}`)
+	controller.Handle(retried, "POST /v1/domains", handler)
+
+	if calls != 1 {
+		t.Error("handler is called for the retry")
+	}
+	firstBody, _ := first.ResponseBody()
+	retriedBody, _ := retried.ResponseBody()
+	if retried.StatusCode() != http.StatusCreated || string(retriedBody) != string(firstBody) {
+		t.Error("response is not replayed: " + string(retriedBody))
+	}
+	if retried.ResponseHeaders()["Idempotent-Replayed"] != "true" || retried.ResponseHeaders()["Location"] != "/v1/domains/1" {
+		t.Error("headers are not replayed")
+	}
+
+	// The key of another tenant is another key.
+	other := newTestIdempotentContext(key, testOtherTenant, `{"domain":"hogehoge.hoge"}`)
+	controller.Handle(other, "POST /v1/domains", handler)
+	if calls != 2 || other.ResponseHeaders()["Idempotent-Replayed"] != "" {
+		t.Error("response is replayed for another tenant")
+	}
+
+	conflicted := newTestIdempotentContext(key, testTenant, `{"domain":"fugafuga.fuga"}`)
+	controller.Handle(conflicted, "POST /v1/domains", handler)
+	if conflicted.StatusCode() != http.StatusConflict || calls != 2 {
+		t.Error("key is reused for another body")
+	}
+}
+
+func TestIdempotencyInFlight(t *testing.T) {
+	t.Setenv("IDEMPOTENCY_WINDOW", "1h")
+	controller := NewIdempotencyController(usecase.NewIdempotencyInteractor(repository.NewIdempotencyRepository()))
+	key := "in-flight-" + model.NewRequestId("")
+
+	// The retry comes while the first request is handled.
+	retried := newTestIdempotentContext(key, testTenant, `{}`)
+	controller.Handle(newTestIdempotentContext(key, testTenant, `{}`), "POST /v1/domains", func(c Context) {
+		controller.Handle(retried, "POST /v1/domains", func(c Context) {
+			t.Error("handler is called for the retry in flight")
+		})
+		c.Status(http.StatusNoContent)
+	})
+
+	if retried.StatusCode() != http.StatusConflict {
+		t.Error("retry in flight is not conflicted")
+	}
+}
+
+func TestIdempotencyServerError(t *testing.T) {
+	t.Setenv("IDEMPOTENCY_WINDOW", "1h")
+	controller := NewIdempotencyController(usecase.NewIdempotencyInteractor(repository.NewIdempotencyRepository()))
+	key := "server-error-" + model.NewRequestId("")
+
+	calls := 0
+	handler := func(c Context) {
+		calls++
+		if calls == 1 {
+			NewError(c, http.StatusInternalServerError, NewUnAvailableHandlingError())
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+
+	controller.Handle(newTestIdempotentContext(key, testTenant, `{}`), "POST /v1/domains", handler)
+	retried := newTestIdempotentContext(key, testTenant, `{}`)
+	controller.Handle(retried, "POST /v1/domains", handler)
+
+	if calls != 2 || retried.StatusCode() != http.StatusNoContent {
+		t.Error("server error is replayed")
+	}
+}
+
+func TestIdempotencyNoStatus(t *testing.T) {
+	t.Setenv("IDEMPOTENCY_WINDOW", "1h")
+	controller := NewIdempotencyController(usecase.NewIdempotencyInteractor(repository.NewIdempotencyRepository()))
+	key := "no-status-" + model.NewRequestId("")
+
+	calls := 0
+	handler := func(c Context) {
+		calls++
+		if calls == 1 {
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+
+	controller.Handle(newTestIdempotentContext(key, testTenant, `{}`), "POST /v1/domains", handler)
+	retried := newTestIdempotentContext(key, testTenant, `{}`)
+	controller.Handle(retried, "POST /v1/domains", handler)
+
+	if calls != 2 || retried.StatusCode() != http.StatusNoContent || retried.ResponseHeaders()["Idempotent-Replayed"] != "" {
+		t.Error("response without a status is replayed")
+	}
+}
+
+func TestIdempotencyInvalidTenant(t *testing.T) {
+	t.Setenv("IDEMPOTENCY_WINDOW", "1h")
+	controller := NewIdempotencyController(usecase.NewIdempotencyInteractor(repository.NewIdempotencyRepository()))
+	handler := func(c Context) {
+		t.Error("handler is called without the valid tenant")
+	}
+
+	for _, tenant := range []string{"", "hogehoge-hogehoge-hogehoge-hogehoge-hogehoge"} {
+		c := newTestIdempotentContext("invalid-tenant", tenant, `{}`)
+		controller.Handle(c, "POST /v1/domains", handler)
+		if c.StatusCode() != http.StatusBadRequest {
+			t.Error("key is kept for the invalid tenant: " + tenant)
+		}
+	}
+}
+
+func TestIdempotencyMaxKeys(t *testing.T) {
+	t.Setenv("IDEMPOTENCY_WINDOW", "1h")
+	iRepository := repository.NewIdempotencyRepository()
+	controller := NewIdempotencyController(usecase.NewIdempotencyInteractor(iRepository))
+
+	iRepository.Lock()
+	t.Setenv("IDEMPOTENCY_MAX_KEYS", strconv.Itoa(iRepository.Count()+1))
+	iRepository.UnLock()
+
+	key := "max-keys-" + model.NewRequestId("")
+	handler := func(c Context) {
+		c.Status(http.StatusNoContent)
+	}
+
+	controller.Handle(newTestIdempotentContext(key, testTenant, `{}`), "POST /v1/domains", handler)
+	full := newTestIdempotentContext("other-"+key, testTenant, `{}`)
+	controller.Handle(full, "POST /v1/domains", handler)
+	if full.StatusCode() != http.StatusServiceUnavailable {
+		t.Error("key is kept over the max keys")
+	}
+
+	// The requests without the key are handled as usual.
+	c := NewRecordingContext(map[string]string{"Tenant": testTenant}, nil, nil, []byte(`{}`))
+	controller.Handle(c, "POST /v1/domains", handler)
+	if c.StatusCode() != http.StatusNoContent {
+		t.Error("request without the key is refused")
+	}
+}
@@ -0,0 +1,10 @@
+package controllers
+
+import (
+	"coredns_api/internal/model"
+)
+
+// getListQuery returns the filters and the page given with the query parameters.
+func getListQuery(c Context) (*model.ListQuery, error) {
+	return model.NewListQuery(c.Query("name"), c.Query("address"), c.Query("tenant"), c.Query("limit"), c.Query("cursor"))
+}
@@ -0,0 +1,18 @@
+package controllers
+
+import (
+	"log/slog"
+)
+
+// RequestIdHeader is the header of the request ID. The router sets a new one when the client doesn't give it.
+const RequestIdHeader = "X-Request-Id"
+
+// NewRequestLogger returns the logger which writes the request ID, the tenant and the domain UUID of the request
+// in every line. The interactors are given it with WithLogger, so that the repositories write them too.
+func NewRequestLogger(c Context) *slog.Logger {
+	return slog.With(
+		"request_id", c.GetHeader(RequestIdHeader),
+		"tenant", c.GetHeader("Tenant"),
+		"domain_uuid", c.Param("domain_uuid"),
+	)
+}
@@ -0,0 +1,307 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"coredns_api/internal/model"
+	"coredns_api/internal/usecase"
+)
+
+// Request
+type PoolRequest struct {
+	Name     string   `json:"name"`
+	Tenants  []string `json:"tenants"`
+	Cidrs    []string `json:"cidrs"`
+	Reserved []string `json:"reserved"`
+	Gateways []string `json:"gateways"`
+}
+
+// Result
+type PoolResult struct {
+	Name     string   `json:"name"`
+	Uuid     string   `json:"uuid"`
+	Tenants  []string `json:"tenants"`
+	Cidrs    []string `json:"cidrs"`
+	Reserved []string `json:"reserved"`
+	Gateways []string `json:"gateways"`
+}
+
+type PoolListResult struct {
+	Pools []PoolResult `json:"pools"`
+}
+
+func newPoolResult(pool *model.Pool) PoolResult {
+	tenants := make([]string, 0)
+	for _, t := range pool.Tenants {
+		tenants = append(tenants, t.String())
+	}
+
+	return PoolResult{
+		Name:     pool.Name,
+		Uuid:     pool.Uuid.String(),
+		Tenants:  tenants,
+		Cidrs:    pool.GetCidrList(),
+		Reserved: pool.GetReservedList(),
+		Gateways: pool.GetGatewayList()}
+}
+
+// Controller
+type PoolController struct {
+	interactor *usecase.PoolInteractor
+}
+
+func NewPoolController(itr *usecase.PoolInteractor) *PoolController {
+	return &PoolController{itr}
+}
+
+// Add handler doc
+// @Tags Pool
+// @Summary Add new pool
+// @Description Add new address pool to allocate host addresses from
+// @Accept json
+// @Produce json
+// @Param pool body PoolRequest true "Request body parameter with json format"
+// @Param dry_run query bool false "Return the file changes without writing them"
+// @Success 201 {object} PoolResult
+// @Success 200 {object} DryRunResult
+// @Failure 400 {object} HTTPError
+// @Failure 500 {object} HTTPError
+// @Router /v1/pools [post]
+func (p *PoolController) Add(c Context) {
+	logger := NewRequestLogger(c)
+	interactor := p.interactor.WithLogger(logger)
+
+	dryRun, err := getDryRun(c)
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var request PoolRequest
+	err = c.ShouldBindJSON(&request)
+	if err != nil {
+		NewError(c,
+			http.StatusInternalServerError,
+			NewUnAvailableHandlingError())
+		logger.Error("request failed", "error", err)
+		return
+	}
+
+	if len(request.Tenants) == 0 {
+		NewError(c,
+			http.StatusBadRequest,
+			errors.New("accessible tenant uuid is not specified"))
+		return
+	}
+
+	newPool, err := model.NewOriginalPool(request.Name, request.Tenants, request.Cidrs, request.Reserved, request.Gateways)
+	if err != nil {
+		switch e := err.(type) {
+		case *model.InvalidParameterGiven:
+			NewError(c, http.StatusBadRequest, err)
+		default:
+			NewError(c,
+				http.StatusInternalServerError,
+				NewUnAvailableHandlingError())
+			logger.Error("unexpected error", "error", e)
+		}
+		logger.Warn("request failed", "error", err)
+		return
+	}
+
+	var staged usecase.IStagedRepository
+	if dryRun {
+		interactor, staged = interactor.Stage()
+	}
+
+	err = interactor.Add(newPool)
+	if err != nil {
+		NewError(c,
+			http.StatusInternalServerError,
+			NewUnAvailableHandlingError())
+		logger.Error("request failed", "error", err)
+		return
+	}
+
+	if staged != nil {
+		returnDryRunResult(c, staged)
+		return
+	}
+
+	c.JSON(http.StatusCreated, newPoolResult(newPool))
+}
+
+// List handler doc
+// @Tags Pool
+// @Summary List pools
+// @Description List address pools which the tenant can use
+// @Produce json
+// @Param Tenant header string true "Tenant UUID to set access control"
+// @Success 200 {object} PoolListResult
+// @Failure 400 {object} HTTPError
+// @Failure 500 {object} HTTPError
+// @Router /v1/pools [get]
+func (p *PoolController) List(c Context) {
+	logger := NewRequestLogger(c)
+	interactor := p.interactor.WithLogger(logger)
+
+	requestTenant := c.GetHeader("Tenant")
+	if requestTenant == "" {
+		NewError(c,
+			http.StatusBadRequest,
+			errors.New("tenant uuid header is not specified"))
+		return
+	}
+
+	requestTenantUuid, err := model.NewUuid(requestTenant)
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	poolList, err := interactor.GetPoolsList(requestTenantUuid)
+	if err != nil {
+		NewError(c,
+			http.StatusInternalServerError,
+			NewUnAvailableHandlingError())
+		logger.Error("request failed", "error", err)
+		return
+	}
+
+	pools := make([]PoolResult, 0)
+	for _, pool := range poolList {
+		pools = append(pools, newPoolResult(pool))
+	}
+
+	c.JSON(http.StatusOK, PoolListResult{Pools: pools})
+}
+
+// Get handler doc
+// @Tags Pool
+// @Summary Get pool
+// @Description Get address pool
+// @Produce json
+// @Param Tenant header string true "Tenant UUID to set access control"
+// @Param pool_uuid path string true "Target pool's UUID"
+// @Success 200 {object} PoolResult
+// @Failure 400 {object} HTTPError
+// @Failure 404 {object} HTTPError
+// @Failure 500 {object} HTTPError
+// @Router /v1/pools/{pool_uuid} [get]
+func (p *PoolController) Get(c Context) {
+	logger := NewRequestLogger(c)
+	interactor := p.interactor.WithLogger(logger)
+
+	requestTenant := c.GetHeader("Tenant")
+	if requestTenant == "" {
+		NewError(c,
+			http.StatusBadRequest,
+			errors.New("tenant uuid header is not specified"))
+		return
+	}
+
+	requestTenantUuid, err := model.NewUuid(requestTenant)
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		return
+	}
+	targetPoolUuid, err := model.NewUuid(c.Param("pool_uuid"))
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		logger.Warn("request failed", "error", err)
+		return
+	}
+
+	pool, err := interactor.Get(targetPoolUuid, requestTenantUuid)
+	if err != nil {
+		switch e := err.(type) {
+		case *model.PoolPermissionError:
+			NewError(c, http.StatusBadRequest, err)
+		case *model.PoolNotFoundError:
+			NewError(c, http.StatusNotFound, err)
+		default:
+			NewError(c,
+				http.StatusInternalServerError,
+				NewUnAvailableHandlingError())
+			logger.Error("unexpected error", "error", e)
+		}
+		logger.Warn("request failed", "error", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newPoolResult(pool))
+}
+
+// Delete handler doc
+// @Tags Pool
+// @Summary Delete pool
+// @Description Delete address pool. Hosts allocated from the pool keep their addresses.
+// @Param Tenant header string true "Tenant UUID to set access control"
+// @Param pool_uuid path string true "Target pool's UUID"
+// @Param dry_run query bool false "Return the file changes without writing them"
+// @Success 204
+// @Success 200 {object} DryRunResult
+// @Failure 400 {object} HTTPError
+// @Failure 404 {object} HTTPError
+// @Failure 500 {object} HTTPError
+// @Router /v1/pools/{pool_uuid} [delete]
+func (p *PoolController) Delete(c Context) {
+	logger := NewRequestLogger(c)
+	interactor := p.interactor.WithLogger(logger)
+
+	requestTenant := c.GetHeader("Tenant")
+	if requestTenant == "" {
+		NewError(c,
+			http.StatusBadRequest,
+			errors.New("tenant uuid header is not specified"))
+		return
+	}
+
+	requestTenantUuid, err := model.NewUuid(requestTenant)
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		return
+	}
+	targetPoolUuid, err := model.NewUuid(c.Param("pool_uuid"))
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		logger.Warn("request failed", "error", err)
+		return
+	}
+
+	dryRun, err := getDryRun(c)
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var staged usecase.IStagedRepository
+	if dryRun {
+		interactor, staged = interactor.Stage()
+	}
+
+	err = interactor.Delete(targetPoolUuid, requestTenantUuid)
+	if err != nil {
+		switch e := err.(type) {
+		case *model.PoolPermissionError:
+			NewError(c, http.StatusBadRequest, err)
+		case *model.PoolNotFoundError:
+			NewError(c, http.StatusNotFound, err)
+		default:
+			NewError(c,
+				http.StatusInternalServerError,
+				NewUnAvailableHandlingError())
+			logger.Error("unexpected error", "error", e)
+		}
+		logger.Warn("request failed", "error", err)
+		return
+	}
+
+	if staged != nil {
+		returnDryRunResult(c, staged)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
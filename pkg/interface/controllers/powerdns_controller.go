@@ -0,0 +1,484 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+
+	"coredns_api/internal/model"
+	"coredns_api/internal/usecase"
+)
+
+// powerDNSServerId is the only server of the PowerDNS compatible API.
+const powerDNSServerId = "localhost"
+
+// powerDNSRecordTtl is the TTL of the records served by the DNS listener.
+const powerDNSRecordTtl = 3600
+
+// Request
+type PowerDNSZoneRequest struct {
+	Name        string          `json:"name"`
+	Kind        string          `json:"kind"`
+	Nameservers []string        `json:"nameservers"`
+	RRsets      []PowerDNSRRset `json:"rrsets"`
+}
+
+type PowerDNSPatchRequest struct {
+	RRsets []PowerDNSRRset `json:"rrsets"`
+}
+
+// Result
+type PowerDNSServer struct {
+	Type       string `json:"type"`
+	Id         string `json:"id"`
+	DaemonType string `json:"daemon_type"`
+	Version    string `json:"version"`
+	Url        string `json:"url"`
+	ConfigUrl  string `json:"config_url"`
+	ZonesUrl   string `json:"zones_url"`
+}
+
+type PowerDNSZone struct {
+	Id      string   `json:"id"`
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	Url     string   `json:"url"`
+	Kind    string   `json:"kind"`
+	Serial  uint64   `json:"serial"`
+	Dnssec  bool     `json:"dnssec"`
+	Account string   `json:"account"`
+	Masters []string `json:"masters"`
+}
+
+type PowerDNSZoneInfo struct {
+	PowerDNSZone
+	RRsets []PowerDNSRRset `json:"rrsets"`
+}
+
+type PowerDNSRRset struct {
+	Name       string           `json:"name"`
+	Type       string           `json:"type"`
+	Ttl        int              `json:"ttl"`
+	ChangeType string           `json:"changetype,omitempty"`
+	Records    []PowerDNSRecord `json:"records"`
+	Comments   []interface{}    `json:"comments"`
+}
+
+type PowerDNSRecord struct {
+	Content  string `json:"content"`
+	Disabled bool   `json:"disabled"`
+}
+
+type PowerDNSError struct {
+	Error string `json:"error"`
+}
+
+func newPowerDNSError(c Context, status int, err error) {
+	c.JSON(status, PowerDNSError{Error: err.Error()})
+}
+
+func newPowerDNSServer() PowerDNSServer {
+	url := "/api/v1/servers/" + powerDNSServerId
+	return PowerDNSServer{
+		Type:       "Server",
+		Id:         powerDNSServerId,
+		DaemonType: "authoritative",
+		Version:    "coredns_api",
+		Url:        url,
+		ConfigUrl:  url + "/config{/config_setting}",
+		ZonesUrl:   url + "/zones{/zone}",
+	}
+}
+
+func newPowerDNSZone(domain *model.Domain) PowerDNSZone {
+	id := domain.Name.String() + "."
+	return PowerDNSZone{
+		Id:      id,
+		Name:    id,
+		Type:    "Zone",
+		Url:     "/api/v1/servers/" + powerDNSServerId + "/zones/" + id,
+		Kind:    "Native",
+		Serial:  domain.Revision,
+		Masters: make([]string, 0),
+	}
+}
+
+func newPowerDNSZoneInfo(domain *model.Domain) PowerDNSZoneInfo {
+	rrsets := make([]PowerDNSRRset, 0)
+	for _, h := range domain.Hosts {
+		rrset := PowerDNSRRset{
+			Name:     h.Name + ".",
+			Type:     model.GetRecordType(h.Address),
+			Ttl:      powerDNSRecordTtl,
+			Records:  []PowerDNSRecord{{Content: h.Address}},
+			Comments: make([]interface{}, 0)}
+		rrsets = append(rrsets, rrset)
+	}
+	sort.Slice(rrsets, func(i, j int) bool { return rrsets[i].Name < rrsets[j].Name })
+
+	return PowerDNSZoneInfo{PowerDNSZone: newPowerDNSZone(domain), RRsets: rrsets}
+}
+
+// newRRsetChanges returns the changes of PATCH request. Disabled records are regarded as deleted.
+func newRRsetChanges(rrsets []PowerDNSRRset) ([]*model.RRsetChange, error) {
+	var changes []*model.RRsetChange
+	for _, rrset := range rrsets {
+		change := &model.RRsetChange{Name: rrset.Name, Type: strings.ToUpper(rrset.Type)}
+		switch strings.ToUpper(rrset.ChangeType) {
+		case "REPLACE":
+			for _, r := range rrset.Records {
+				if !r.Disabled {
+					change.Values = append(change.Values, r.Content)
+				}
+			}
+		case "DELETE":
+		default:
+			return nil, model.NewInvalidParameterGiven("changetype has to be REPLACE or DELETE. changetype: " + rrset.ChangeType)
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// Controller
+type PowerDNSController struct {
+	domainInteractor *usecase.DomainInteractor
+	hostInteractor   *usecase.HostInteractor
+}
+
+func NewPowerDNSController(dItr *usecase.DomainInteractor, hItr *usecase.HostInteractor) *PowerDNSController {
+	return &PowerDNSController{domainInteractor: dItr, hostInteractor: hItr}
+}
+
+// getTenant returns the tenant of X-API-Key header in PDNS_API_KEYS.
+func (p *PowerDNSController) getTenant(c Context) (model.Uuid, bool) {
+	logger := NewRequestLogger(c)
+
+	keys, err := model.GetPowerDNSApiKeys()
+	if err != nil {
+		newPowerDNSError(c, http.StatusInternalServerError, NewUnAvailableHandlingError())
+		logger.Error("request failed", "error", err)
+		return "", false
+	}
+
+	apiKey := c.GetHeader("X-API-Key")
+	if apiKey == "" {
+		newPowerDNSError(c, http.StatusUnauthorized, errors.New("unauthorized"))
+		return "", false
+	}
+
+	// Every key is compared in constant time, so that the key can't be guessed from the response time.
+	var tenant model.Uuid
+	for key, t := range keys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(apiKey)) == 1 {
+			tenant = t
+		}
+	}
+	if tenant == "" {
+		newPowerDNSError(c, http.StatusUnauthorized, errors.New("unauthorized"))
+		return "", false
+	}
+
+	if c.Param("server_id") != "" && c.Param("server_id") != powerDNSServerId {
+		newPowerDNSError(c, http.StatusNotFound, errors.New("server is not found. server: "+c.Param("server_id")))
+		return "", false
+	}
+	return tenant, true
+}
+
+// getDomain returns the domain of zone_id path parameter, which is the zone name with or without the trailing dot.
+func (p *PowerDNSController) getDomain(c Context, requestTenantUuid model.Uuid) (*model.Domain, bool) {
+	logger := NewRequestLogger(c)
+	domainInteractor := p.domainInteractor.WithLogger(logger)
+
+	domains, err := domainInteractor.GetDomainsList(requestTenantUuid)
+	if err != nil {
+		newPowerDNSError(c, http.StatusInternalServerError, NewUnAvailableHandlingError())
+		logger.Error("request failed", "error", err)
+		return nil, false
+	}
+
+	name := strings.ToLower(strings.TrimSuffix(c.Param("zone_id"), "."))
+	for _, d := range domains {
+		if strings.ToLower(d.Name.String()) == name {
+			return d, true
+		}
+	}
+
+	newPowerDNSError(c, http.StatusNotFound, model.NewDomainNotFoundError())
+	return nil, false
+}
+
+func (p *PowerDNSController) returnError(c Context, err error) {
+	logger := NewRequestLogger(c)
+
+	switch e := err.(type) {
+	case *model.InvalidParameterGiven, *usecase.HostDuplicatedError, *model.InvalidCorefileError:
+		newPowerDNSError(c, http.StatusUnprocessableEntity, err)
+	case *usecase.DomainDuplicatedError, *model.DomainDegradedError, *model.DomainRevisionMismatchError, *model.RRsetMismatchError:
+		newPowerDNSError(c, http.StatusConflict, err)
+	case *model.DomainNotFoundError, *model.DomainPermissionError:
+		newPowerDNSError(c, http.StatusNotFound, err)
+	default:
+		newPowerDNSError(c, http.StatusInternalServerError, NewUnAvailableHandlingError())
+		logger.Error("unexpected error", "error", e)
+	}
+	logger.Warn("request failed", "error", err)
+}
+
+// ListServers handler doc
+// @Tags PowerDNS
+// @Summary List servers
+// @Description PowerDNS compatible API. Only "localhost" server is available.
+// @Produce json
+// @Param X-API-Key header string true "API key in PDNS_API_KEYS"
+// @Success 200 {array} PowerDNSServer
+// @Failure 401 {object} PowerDNSError
+// @Router /api/v1/servers [get]
+func (p *PowerDNSController) ListServers(c Context) {
+	_, ok := p.getTenant(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, []PowerDNSServer{newPowerDNSServer()})
+}
+
+// GetServer handler doc
+// @Tags PowerDNS
+// @Summary Get server
+// @Description PowerDNS compatible API.
+// @Produce json
+// @Param X-API-Key header string true "API key in PDNS_API_KEYS"
+// @Param server_id path string true "localhost"
+// @Success 200 {object} PowerDNSServer
+// @Failure 401 {object} PowerDNSError
+// @Failure 404 {object} PowerDNSError
+// @Router /api/v1/servers/{server_id} [get]
+func (p *PowerDNSController) GetServer(c Context) {
+	_, ok := p.getTenant(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, newPowerDNSServer())
+}
+
+// ListZones handler doc
+// @Tags PowerDNS
+// @Summary List zones
+// @Description PowerDNS compatible API. Domains of the tenant of the API key are listed as zones.
+// @Produce json
+// @Param X-API-Key header string true "API key in PDNS_API_KEYS"
+// @Param server_id path string true "localhost"
+// @Param zone query string false "Zone name to list only the zone"
+// @Success 200 {array} PowerDNSZone
+// @Failure 401 {object} PowerDNSError
+// @Failure 404 {object} PowerDNSError
+// @Failure 500 {object} PowerDNSError
+// @Router /api/v1/servers/{server_id}/zones [get]
+func (p *PowerDNSController) ListZones(c Context) {
+	logger := NewRequestLogger(c)
+	domainInteractor := p.domainInteractor.WithLogger(logger)
+
+	requestTenantUuid, ok := p.getTenant(c)
+	if !ok {
+		return
+	}
+
+	domains, err := domainInteractor.GetDomainsList(requestTenantUuid)
+	if err != nil {
+		p.returnError(c, err)
+		return
+	}
+
+	name := strings.ToLower(strings.TrimSuffix(c.Query("zone"), "."))
+	zones := make([]PowerDNSZone, 0)
+	for _, d := range domains {
+		if name == "" || strings.ToLower(d.Name.String()) == name {
+			zones = append(zones, newPowerDNSZone(d))
+		}
+	}
+	sort.Slice(zones, func(i, j int) bool { return zones[i].Name < zones[j].Name })
+
+	c.JSON(http.StatusOK, zones)
+}
+
+// AddZone handler doc
+// @Tags PowerDNS
+// @Summary Add zone
+// @Description PowerDNS compatible API. A new domain is added with the tenant of the API key.
+// @Description Only A and AAAA rrsets with one record are stored. Nameservers are ignored.
+// @Accept json
+// @Produce json
+// @Param X-API-Key header string true "API key in PDNS_API_KEYS"
+// @Param server_id path string true "localhost"
+// @Param zone body PowerDNSZoneRequest true "Request body parameter with json format"
+// @Success 201 {object} PowerDNSZoneInfo
+// @Failure 401 {object} PowerDNSError
+// @Failure 404 {object} PowerDNSError
+// @Failure 409 {object} PowerDNSError
+// @Failure 422 {object} PowerDNSError
+// @Failure 500 {object} PowerDNSError
+// @Router /api/v1/servers/{server_id}/zones [post]
+func (p *PowerDNSController) AddZone(c Context) {
+	logger := NewRequestLogger(c)
+	domainInteractor := p.domainInteractor.WithLogger(logger)
+
+	requestTenantUuid, ok := p.getTenant(c)
+	if !ok {
+		return
+	}
+
+	var request PowerDNSZoneRequest
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		newPowerDNSError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	for i := range request.RRsets {
+		request.RRsets[i].ChangeType = "REPLACE"
+	}
+	changes, err := newRRsetChanges(request.RRsets)
+	if err != nil {
+		p.returnError(c, err)
+		return
+	}
+
+	newDomain, err := model.NewOriginalDomain(strings.TrimSuffix(request.Name, "."), []string{requestTenantUuid.String()})
+	if err != nil {
+		p.returnError(c, err)
+		return
+	}
+
+	newDomain.Hosts, err = model.PlanRRsetChanges(newDomain, changes)
+	if err != nil {
+		p.returnError(c, err)
+		return
+	}
+
+	err = domainInteractor.Add(newDomain)
+	if err != nil {
+		p.returnError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, newPowerDNSZoneInfo(newDomain))
+}
+
+// GetZone handler doc
+// @Tags PowerDNS
+// @Summary Get zone
+// @Description PowerDNS compatible API. Hosts are returned as A and AAAA rrsets.
+// @Produce json
+// @Param X-API-Key header string true "API key in PDNS_API_KEYS"
+// @Param server_id path string true "localhost"
+// @Param zone_id path string true "Zone name"
+// @Success 200 {object} PowerDNSZoneInfo
+// @Failure 401 {object} PowerDNSError
+// @Failure 404 {object} PowerDNSError
+// @Failure 500 {object} PowerDNSError
+// @Router /api/v1/servers/{server_id}/zones/{zone_id} [get]
+func (p *PowerDNSController) GetZone(c Context) {
+	requestTenantUuid, ok := p.getTenant(c)
+	if !ok {
+		return
+	}
+
+	domain, ok := p.getDomain(c, requestTenantUuid)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, newPowerDNSZoneInfo(domain))
+}
+
+// PatchZone handler doc
+// @Tags PowerDNS
+// @Summary Change rrsets
+// @Description PowerDNS compatible API. All of the rrsets are changed with one write.
+// @Description Only A and AAAA rrsets with at most one record can be replaced, since a host has one address.
+// @Accept json
+// @Param X-API-Key header string true "API key in PDNS_API_KEYS"
+// @Param server_id path string true "localhost"
+// @Param zone_id path string true "Zone name"
+// @Param rrsets body PowerDNSPatchRequest true "Request body parameter with json format"
+// @Success 204
+// @Failure 401 {object} PowerDNSError
+// @Failure 404 {object} PowerDNSError
+// @Failure 422 {object} PowerDNSError
+// @Failure 500 {object} PowerDNSError
+// @Router /api/v1/servers/{server_id}/zones/{zone_id} [patch]
+func (p *PowerDNSController) PatchZone(c Context) {
+	logger := NewRequestLogger(c)
+	hostInteractor := p.hostInteractor.WithLogger(logger)
+
+	requestTenantUuid, ok := p.getTenant(c)
+	if !ok {
+		return
+	}
+
+	var request PowerDNSPatchRequest
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		newPowerDNSError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	changes, err := newRRsetChanges(request.RRsets)
+	if err != nil {
+		p.returnError(c, err)
+		return
+	}
+
+	domain, ok := p.getDomain(c, requestTenantUuid)
+	if !ok {
+		return
+	}
+
+	err = hostInteractor.ApplyRRsetChanges([]*usecase.RRsetChanges{{DomainUuid: domain.Uuid, Hosts: changes}}, requestTenantUuid)
+	if err != nil {
+		p.returnError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// DeleteZone handler doc
+// @Tags PowerDNS
+// @Summary Delete zone
+// @Description PowerDNS compatible API.
+// @Param X-API-Key header string true "API key in PDNS_API_KEYS"
+// @Param server_id path string true "localhost"
+// @Param zone_id path string true "Zone name"
+// @Success 204
+// @Failure 401 {object} PowerDNSError
+// @Failure 404 {object} PowerDNSError
+// @Failure 500 {object} PowerDNSError
+// @Router /api/v1/servers/{server_id}/zones/{zone_id} [delete]
+func (p *PowerDNSController) DeleteZone(c Context) {
+	logger := NewRequestLogger(c)
+	domainInteractor := p.domainInteractor.WithLogger(logger)
+
+	requestTenantUuid, ok := p.getTenant(c)
+	if !ok {
+		return
+	}
+
+	domain, ok := p.getDomain(c, requestTenantUuid)
+	if !ok {
+		return
+	}
+
+	err := domainInteractor.Delete(domain.Uuid, requestTenantUuid)
+	if err != nil {
+		p.returnError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
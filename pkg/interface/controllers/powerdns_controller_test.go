@@ -0,0 +1,183 @@
+package controllers
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"coredns_api/internal/infrastructure"
+	"coredns_api/internal/interface/repository"
+	"coredns_api/internal/model"
+	"coredns_api/internal/usecase"
+)
+
+// newTestRepository returns the repository initialized on the empty HOSTS_DIR and CONF_PATH in a temporary directory.
+func newTestRepository(t *testing.T) usecase.IFilesystemRepository {
+	dir := t.TempDir()
+	hostsDir := filepath.Join(dir, "hosts")
+	err := os.Mkdir(hostsDir, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOSTS_DIR", hostsDir)
+	t.Setenv("CONF_PATH", filepath.Join(dir, "coredns.conf"))
+
+	fsRepository := repository.NewFileRepository(infrastructure.NewFilesystem())
+	fsRepository.Initialize()
+	return fsRepository
+}
+
+// addTestDomain adds the domain of the tenants with the domain interactor.
+func addTestDomain(t *testing.T, fsRepository usecase.IFilesystemRepository, name string, tenants ...string) *model.Domain {
+	domain, err := model.NewOriginalDomain(name, tenants)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = usecase.NewDomainInteractor(fsRepository, nil, nil).Add(domain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return domain
+}
+
+func newTestPowerDNSController(t *testing.T) (*PowerDNSController, usecase.IFilesystemRepository) {
+	t.Setenv("PDNS_API_KEYS", "hogekey:"+testTenant+",fugakey:"+testOtherTenant)
+	fsRepository := newTestRepository(t)
+	controller := NewPowerDNSController(
+		usecase.NewDomainInteractor(fsRepository, nil, nil),
+		usecase.NewHostInteractor(fsRepository, nil, nil))
+	return controller, fsRepository
+}
+
+func TestPowerDNSApiKey(t *testing.T) {
+	controller, fsRepository := newTestPowerDNSController(t)
+	addTestDomain(t, fsRepository, "hogehoge.hoge", testTenant)
+
+	params := map[string]string{"server_id": "localhost", "zone_id": "hogehoge.hoge."}
+	for _, key := range []string{"", "unknownkey", testTenant} {
+		c := NewRecordingContext(map[string]string{"X-API-Key": key}, params, nil, nil)
+		controller.GetZone(c)
+		if c.StatusCode() != http.StatusUnauthorized {
+			t.Error("zone is got with the unknown API key: " + key)
+		}
+	}
+
+	// The zone of another tenant is not found, as if it doesn't exist.
+	c := NewRecordingContext(map[string]string{"X-API-Key": "fugakey"}, params, nil, nil)
+	controller.GetZone(c)
+	if c.StatusCode() != http.StatusNotFound {
+		t.Error("zone of another tenant is got")
+	}
+
+	c = NewRecordingContext(map[string]string{"X-API-Key": "hogekey"}, map[string]string{"server_id": "remote", "zone_id": "hogehoge.hoge."}, nil, nil)
+	controller.GetZone(c)
+	if c.StatusCode() != http.StatusNotFound {
+		t.Error("zone is got from the unknown server")
+	}
+
+	c = NewRecordingContext(map[string]string{"X-API-Key": "hogekey"}, params, nil, nil)
+	controller.GetZone(c)
+	zone, ok := c.Result().(PowerDNSZoneInfo)
+	if c.StatusCode() != http.StatusOK || !ok || zone.Name != "hogehoge.hoge." {
+		t.Error("zone of the tenant is not got")
+	}
+}
+
+func TestPowerDNSListZones(t *testing.T) {
+	controller, fsRepository := newTestPowerDNSController(t)
+	addTestDomain(t, fsRepository, "hogehoge.hoge", testTenant)
+	addTestDomain(t, fsRepository, "fugafuga.fuga", testTenant, testOtherTenant)
+	addTestDomain(t, fsRepository, "piyopiyo.piyo", testOtherTenant)
+
+	c := NewRecordingContext(map[string]string{"X-API-Key": "hogekey"}, map[string]string{"server_id": "localhost"}, nil, nil)
+	controller.ListZones(c)
+	zones, ok := c.Result().([]PowerDNSZone)
+	if c.StatusCode() != http.StatusOK || !ok {
+		t.Error("zones are not listed")
+		return
+	}
+	if len(zones) != 2 || zones[0].Name != "fugafuga.fuga." || zones[1].Name != "hogehoge.hoge." {
+		t.Errorf("zones of the tenant are missmatched: %v", zones)
+	}
+
+	c = NewRecordingContext(map[string]string{"X-API-Key": "fugakey"}, map[string]string{"server_id": "localhost"}, map[string]string{"zone": "hogehoge.hoge."}, nil)
+	controller.ListZones(c)
+	zones, _ = c.Result().([]PowerDNSZone)
+	if len(zones) != 0 {
+		t.Error("zone of another tenant is listed")
+	}
+}
+
+func TestPowerDNSPatchZone(t *testing.T) {
+	controller, fsRepository := newTestPowerDNSController(t)
+	domain := addTestDomain(t, fsRepository, "hogehoge.hoge", testTenant)
+	hostInteractor := usecase.NewHostInteractor(fsRepository, nil, nil)
+	for _, h := range [][]string{{"hogeserver1", "172.21.1.1"}, {"hogeserver2", "172.21.1.2"}} {
+		host, _ := model.NewOriginalHost(h[0], h[1], domain.Name)
+		_, err := hostInteractor.Add(host, domain.Uuid, testTenant)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+	before, err := hostInteractor.GetDomain(domain.Uuid, testTenant)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	// The domain is the cached one, so its revision is kept before it is changed.
+	revision := before.Revision
+
+	// The address of hogeserver1 is moved to hogeserver3 in the same request,
+	// which fails if the changes are written one by one.
+	request := PowerDNSPatchRequest{RRsets: []PowerDNSRRset{
+		{Name: "hogeserver1.hogehoge.hoge.", Type: "A", ChangeType: "DELETE"},
+		{Name: "hogeserver3.hogehoge.hoge.", Type: "A", ChangeType: "REPLACE", Records: []PowerDNSRecord{{Content: "172.21.1.1"}}},
+		{Name: "hogeserver2.hogehoge.hoge.", Type: "A", ChangeType: "REPLACE", Records: []PowerDNSRecord{{Content: "172.21.1.4"}}},
+	}}
+	c := NewRecordingContext(map[string]string{"X-API-Key": "hogekey"}, map[string]string{"server_id": "localhost", "zone_id": "hogehoge.hoge"}, nil, request)
+	controller.PatchZone(c)
+	if c.StatusCode() != http.StatusNoContent {
+		t.Errorf("rrsets are not changed: %d %v", c.StatusCode(), c.Result())
+		return
+	}
+
+	after, err := hostInteractor.GetDomain(domain.Uuid, testTenant)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if after.Revision != revision+1 {
+		t.Errorf("rrsets are not changed with one write: %d -> %d", revision, after.Revision)
+	}
+	addresses := map[string]string{}
+	for _, h := range after.Hosts {
+		addresses[h.Name] = h.Address
+	}
+	expects := map[string]string{"hogeserver2.hogehoge.hoge": "172.21.1.4", "hogeserver3.hogehoge.hoge": "172.21.1.1"}
+	if len(addresses) != len(expects) {
+		t.Errorf("hosts are missmatched: %v", addresses)
+	}
+	for name, address := range expects {
+		if addresses[name] != address {
+			t.Errorf("address of %s is missmatched: %s", name, addresses[name])
+		}
+	}
+
+	// Nothing is written when one of the changes is invalid.
+	revision = after.Revision
+	request = PowerDNSPatchRequest{RRsets: []PowerDNSRRset{
+		{Name: "hogeserver2.hogehoge.hoge.", Type: "A", ChangeType: "DELETE"},
+		{Name: "hogeserver4.hogehoge.hoge.", Type: "TXT", ChangeType: "REPLACE", Records: []PowerDNSRecord{{Content: "\"hoge\""}}},
+	}}
+	c = NewRecordingContext(map[string]string{"X-API-Key": "hogekey"}, map[string]string{"server_id": "localhost", "zone_id": "hogehoge.hoge"}, nil, request)
+	controller.PatchZone(c)
+	if c.StatusCode() != http.StatusUnprocessableEntity {
+		t.Error("invalid rrset is accepted")
+	}
+	unchanged, _ := hostInteractor.GetDomain(domain.Uuid, testTenant)
+	if unchanged == nil || unchanged.Revision != revision {
+		t.Error("domain is written by the invalid rrsets")
+	}
+}
@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+)
+
+// RecordingContext is the Context to call the controllers without the HTTP server,
+// like from the command line client and the gRPC API. The response is recorded to be read after the call.
+//
+// The request body is either the raw body as []byte, or the value of the request type of the controller.
+// The value is given to the controller as it is, so it isn't encoded to JSON and decoded again.
+type RecordingContext struct {
+	headers map[string]string
+	params  map[string]string
+	query   map[string]string
+	body    interface{}
+
+	code            int
+	responseHeaders map[string]string
+	contentType     string
+	// result is the value given to JSON, and data is the one given to Data.
+	result interface{}
+	data   []byte
+}
+
+func NewRecordingContext(headers, params, query map[string]string, body interface{}) *RecordingContext {
+	if headers == nil {
+		headers = map[string]string{}
+	}
+	return &RecordingContext{
+		headers: headers,
+		params:  params,
+		query:   query,
+		body:    body,
+
+		responseHeaders: map[string]string{},
+	}
+}
+
+func (c *RecordingContext) GetHeader(key string) string {
+	return c.headers[key]
+}
+func (c *RecordingContext) Header(key, value string) {
+	c.responseHeaders[key] = value
+}
+func (c *RecordingContext) ShouldBindJSON(obj interface{}) error {
+	if c.body == nil {
+		return errors.New("request body is empty")
+	}
+
+	if data, ok := c.body.([]byte); ok {
+		if len(data) == 0 {
+			return errors.New("request body is empty")
+		}
+		return json.Unmarshal(data, obj)
+	}
+
+	target := reflect.ValueOf(obj)
+	body := reflect.ValueOf(c.body)
+	if body.Kind() == reflect.Ptr {
+		body = body.Elem()
+	}
+	if target.Kind() != reflect.Ptr || target.Elem().Type() != body.Type() {
+		return errors.New("request body is not " + target.Type().String())
+	}
+	target.Elem().Set(body)
+	return nil
+}
+func (c *RecordingContext) Param(key string) string {
+	return c.params[key]
+}
+func (c *RecordingContext) Query(key string) string {
+	return c.query[key]
+}
+func (c *RecordingContext) Bind(obj interface{}) error {
+	return c.ShouldBindJSON(obj)
+}
+func (c *RecordingContext) Status(code int) {
+	c.code = code
+}
+func (c *RecordingContext) JSON(code int, obj interface{}) {
+	c.code = code
+	c.contentType = "application/json; charset=utf-8"
+	c.result = obj
+	c.data = nil
+}
+
+// GetRawData returns the raw body, or the request value encoded to JSON.
+func (c *RecordingContext) GetRawData() ([]byte, error) {
+	if c.body == nil {
+		return nil, nil
+	}
+	if data, ok := c.body.([]byte); ok {
+		return data, nil
+	}
+	return json.Marshal(c.body)
+}
+func (c *RecordingContext) Data(code int, contentType string, data []byte) {
+	c.code = code
+	c.contentType = contentType
+	c.result = nil
+	c.data = data
+}
+
+// Stream is not supported, since only one response is recorded.
+func (c *RecordingContext) Stream(step func(w io.Writer) bool) bool {
+	return false
+}
+
+// StatusCode returns the status written by the controller. It is 0 when the controller didn't respond.
+func (c *RecordingContext) StatusCode() int {
+	return c.code
+}
+
+func (c *RecordingContext) ResponseHeaders() map[string]string {
+	return c.responseHeaders
+}
+
+func (c *RecordingContext) ContentType() string {
+	return c.contentType
+}
+
+// Result returns the value given to JSON, like DomainInfoResult or HTTPError.
+// It is nil when the response is written with Data or Status.
+func (c *RecordingContext) Result() interface{} {
+	return c.result
+}
+
+// ResponseBody returns the body as it is sent by the HTTP server.
+func (c *RecordingContext) ResponseBody() ([]byte, error) {
+	if c.result == nil {
+		return c.data, nil
+	}
+	return json.Marshal(c.result)
+}
@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"coredns_api/internal/model"
+	"coredns_api/internal/usecase"
+)
+
+// Result
+type ReverseZoneListResult struct {
+	ReverseZones []ReverseZoneResult `json:"reverse_zones"`
+}
+
+type ReverseZoneResult struct {
+	Zone      string                  `json:"zone"`
+	Network   string                  `json:"network"`
+	Records   []PtrRecordResult       `json:"records"`
+	Conflicts []ReverseConflictResult `json:"conflicts"`
+}
+
+type PtrRecordResult struct {
+	Address string `json:"address"`
+	Name    string `json:"hostname"`
+}
+
+type ReverseConflictResult struct {
+	Address string   `json:"address"`
+	Names   []string `json:"hostnames"`
+	Winner  string   `json:"ptr,omitempty"`
+}
+
+// Controller
+type ReverseZoneController struct {
+	interactor *usecase.ReverseZoneInteractor
+}
+
+func NewReverseZoneController(itr *usecase.ReverseZoneInteractor) *ReverseZoneController {
+	return &ReverseZoneController{itr}
+}
+
+// List handler doc
+// @Tags ReverseZone
+// @Summary List reverse zones
+// @Description List reverse zones configured with REVERSE_ZONES, with the PTR records of the tenant's hosts.
+// @Description Conflicts show the addresses claimed by multiple hostnames, and the hostname used as the PTR record.
+// @Description Only the tenant's hostnames are shown. The PTR record is omitted when it is a hostname of another tenant.
+// @Produce json
+// @Param Tenant header string true "Tenant UUID to set access control"
+// @Success 200 {object} ReverseZoneListResult
+// @Failure 400 {object} HTTPError
+// @Failure 500 {object} HTTPError
+// @Router /v1/reverse_zones [get]
+func (r *ReverseZoneController) List(c Context) {
+	logger := NewRequestLogger(c)
+	interactor := r.interactor.WithLogger(logger)
+
+	requestTenant := c.GetHeader("Tenant")
+	if requestTenant == "" {
+		NewError(c,
+			http.StatusBadRequest,
+			errors.New("tenant uuid header is not specified"))
+		return
+	}
+
+	requestTenantUuid, err := model.NewUuid(requestTenant)
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	zones, err := interactor.GetReverseZones(requestTenantUuid)
+	if err != nil {
+		NewError(c,
+			http.StatusInternalServerError,
+			NewUnAvailableHandlingError())
+		logger.Error("request failed", "error", err)
+		return
+	}
+
+	zoneList := make([]ReverseZoneResult, 0)
+	for _, z := range zones {
+		records := make([]PtrRecordResult, 0)
+		for _, rec := range z.Records {
+			records = append(records, PtrRecordResult{Address: rec.Address, Name: rec.Name})
+		}
+
+		conflicts := make([]ReverseConflictResult, 0)
+		for _, con := range z.Conflicts {
+			conflicts = append(conflicts, ReverseConflictResult{Address: con.Address, Names: con.Names, Winner: con.Winner})
+		}
+
+		zoneList = append(zoneList, ReverseZoneResult{
+			Zone:      z.Name.String(),
+			Network:   z.Network.String(),
+			Records:   records,
+			Conflicts: conflicts})
+	}
+
+	c.JSON(http.StatusOK, ReverseZoneListResult{ReverseZones: zoneList})
+}
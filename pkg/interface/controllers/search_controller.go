@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"coredns_api/internal/model"
+	"coredns_api/internal/usecase"
+)
+
+// Result
+type SearchResult struct {
+	Hosts []SearchHostResult `json:"hosts"`
+	Total int                `json:"total"`
+}
+
+type SearchHostResult struct {
+	HostResult
+	Domain     string `json:"domain"`
+	DomainUuid string `json:"domain_uuid"`
+}
+
+// Controller
+type SearchController struct {
+	interactor *usecase.SearchInteractor
+}
+
+func NewSearchController(itr *usecase.SearchInteractor) *SearchController {
+	return &SearchController{itr}
+}
+
+// Search handler doc
+// @Tags Search
+// @Summary Search hosts
+// @Description Search hosts by hostname and address across the domains the tenant can access
+// @Produce json
+// @Param Tenant header string true "Tenant UUID to set access control"
+// @Param name query string false "Hostname. The hostnames which start with it are matched when it ends with *"
+// @Param address query string false "Address, or CIDR to match the addresses in it"
+// @Success 200 {object} SearchResult
+// @Failure 400 {object} HTTPError
+// @Failure 500 {object} HTTPError
+// @Router /v1/search [get]
+func (s *SearchController) Search(c Context) {
+	logger := NewRequestLogger(c)
+	interactor := s.interactor.WithLogger(logger)
+
+	requestTenant := c.GetHeader("Tenant")
+	if requestTenant == "" {
+		NewError(c,
+			http.StatusBadRequest,
+			errors.New("tenant uuid header is not specified"))
+		return
+	}
+
+	requestTenantUuid, err := model.NewUuid(requestTenant)
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	search, err := model.NewHostSearch(c.Query("name"), c.Query("address"))
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	hits, err := interactor.SearchHosts(search, requestTenantUuid)
+	if err != nil {
+		NewError(c,
+			http.StatusInternalServerError,
+			NewUnAvailableHandlingError())
+		logger.Error("request failed", "error", err)
+		return
+	}
+
+	hosts := make([]SearchHostResult, 0)
+	for _, hit := range hits {
+		host := SearchHostResult{
+			HostResult: newHostResult(hit.Host),
+			Domain:     hit.Domain.Name.String(),
+			DomainUuid: hit.Domain.Uuid.String()}
+		hosts = append(hosts, host)
+	}
+
+	result := SearchResult{Hosts: hosts, Total: len(hosts)}
+	c.JSON(http.StatusOK, result)
+}
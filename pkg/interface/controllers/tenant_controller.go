@@ -1,10 +1,11 @@
 package controllers
 
 import (
+	"errors"
+	"net/http"
+
 	"coredns_api/internal/model"
 	"coredns_api/internal/usecase"
-	"log"
-	"net/http"
 )
 
 // Result
@@ -26,37 +27,65 @@ func NewTenantController(itr *usecase.TenantInteractor) *TenantController {
 	return &TenantController{itr}
 }
 
+// List handler doc
+// @Tags Tenant
+// @Summary List tenants
+// @Description List the request tenant and its accessible domains.
+// @Description Tenants in ADMIN_TENANTS list every tenant.
+// @Produce json
+// @Param Tenant header string true "Request tenant uuid"
+// @Success 200 {object} TenantInfoResult
+// @Failure 400 {object} HTTPError
+// @Failure 500 {object} HTTPError
+// @Router /v1/tenants [get]
 func (t *TenantController) List(c Context) {
-	domains, err := t.interactor.GetDomainList()
-	if err != nil {
+	logger := NewRequestLogger(c)
+	interactor := t.interactor.WithLogger(logger)
+
+	requestTenant := c.GetHeader("Tenant")
+	if requestTenant == "" {
 		NewError(c,
-			http.StatusInternalServerError,
-			NewUnAvailableHandlingError())
-		log.Print(err)
+			http.StatusBadRequest,
+			errors.New("tenant uuid header is not specified"))
+		return
+	}
+	requestTenantUuid, err := model.NewUuid(requestTenant)
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		logger.Warn("request failed", "error", err)
 		return
 	}
 
-	var tenants []model.Uuid
-	for _, d := range domains {
-		for _, t := range d.Tenants {
-			tenants = append(tenants, t)
+	var domains []*model.Domain
+	tenants := []model.Uuid{requestTenantUuid}
+	if model.IsAdminTenant(requestTenantUuid) {
+		domains, err = interactor.GetAllDomainsList()
+		tenants = nil
+		for _, d := range domains {
+			for _, t := range d.Tenants {
+				if !containsUuid(tenants, t) {
+					tenants = append(tenants, t)
+				}
+			}
 		}
+	} else {
+		domains, err = interactor.GetDomainList(requestTenantUuid)
+	}
+	if err != nil {
+		NewError(c,
+			http.StatusInternalServerError,
+			NewUnAvailableHandlingError())
+		logger.Error("request failed", "error", err)
+		return
 	}
 
 	var result TenantInfoResult
 	tenantList := make([]TenantResult, 0)
 	for _, t := range tenants {
-		var tenant TenantResult
-		tenant.Uuid = t.String()
-
+		tenant := TenantResult{Uuid: t.String(), Domains: []string{}}
 		for _, d := range domains {
-			for _, tin := range d.Tenants {
-				if t == tin {
-					tdomains := tenant.Domains
-					tdomains = append(tdomains, d.Uuid.String())
-					tenant.Domains = tdomains
-					continue
-				}
+			if d.HasTenant(t) {
+				tenant.Domains = append(tenant.Domains, d.Uuid.String())
 			}
 		}
 		tenantList = append(tenantList, tenant)
@@ -64,3 +93,12 @@ func (t *TenantController) List(c Context) {
 	result.Tenants = tenantList
 	c.JSON(http.StatusOK, result)
 }
+
+func containsUuid(list []model.Uuid, target model.Uuid) bool {
+	for _, u := range list {
+		if u == target {
+			return true
+		}
+	}
+	return false
+}
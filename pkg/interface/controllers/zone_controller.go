@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"coredns_api/internal/model"
+	"coredns_api/internal/usecase"
+)
+
+// Request
+type ZoneImportRequest struct {
+	Name     string               `json:"domain"`
+	Tenants  []string             `json:"tenants"`
+	ZoneFile string               `json:"zone_file"`
+	Transfer *ZoneTransferRequest `json:"transfer"`
+}
+
+type ZoneTransferRequest struct {
+	Server        string `json:"server"`
+	TsigName      string `json:"tsig_name"`
+	TsigAlgorithm string `json:"tsig_algorithm"`
+	TsigSecret    string `json:"tsig_secret"`
+}
+
+// Result
+type ZoneImportResult struct {
+	DomainInfoResult
+	Skipped []SkippedRecordResult `json:"skipped"`
+}
+
+type SkippedRecordResult struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Value  string `json:"value"`
+	Reason string `json:"reason"`
+}
+
+// Controller
+type ZoneController struct {
+	interactor *usecase.ZoneInteractor
+}
+
+func NewZoneController(itr *usecase.ZoneInteractor) *ZoneController {
+	return &ZoneController{itr}
+}
+
+// Import handler doc
+// @Tags Domain
+// @Summary Import domain from zone
+// @Description Add new domain from BIND zone file, or from zone transfer (AXFR) of an existing DNS server.
+// @Description A and AAAA records are imported as hosts, and the other records are reported as skipped.
+// @Description Zone transfer is allowed only from the servers in ZONE_TRANSFER_SERVERS.
+// @Accept json
+// @Produce json
+// @Param zone body ZoneImportRequest true "Request body parameter with json format. zone_file or transfer is required"
+// @Param dry_run query bool false "Return the file changes without writing them"
+// @Success 201 {object} ZoneImportResult
+// @Success 200 {object} DryRunResult
+// @Failure 400 {object} HTTPError
+// @Failure 409 {object} HTTPError
+// @Failure 500 {object} HTTPError
+// @Failure 502 {object} HTTPError
+// @Router /v1/domains:import [post]
+func (z *ZoneController) Import(c Context) {
+	logger := NewRequestLogger(c)
+	interactor := z.interactor.WithLogger(logger)
+
+	dryRun, err := getDryRun(c)
+	if err != nil {
+		NewError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var request ZoneImportRequest
+	err = c.ShouldBindJSON(&request)
+	if err != nil {
+		NewError(c,
+			http.StatusInternalServerError,
+			NewUnAvailableHandlingError())
+		logger.Error("request failed", "error", err)
+		return
+	}
+
+	if len(request.Tenants) == 0 {
+		NewError(c,
+			http.StatusBadRequest,
+			errors.New("accessible tenant uuid is not specified"))
+		return
+	}
+
+	if (request.ZoneFile == "") == (request.Transfer == nil) {
+		NewError(c,
+			http.StatusBadRequest,
+			errors.New("either of zone_file or transfer has to be specified"))
+		return
+	}
+
+	var staged usecase.IStagedRepository
+	if dryRun {
+		interactor, staged = interactor.Stage()
+	}
+
+	var domain *model.Domain
+	var skipped []*model.SkippedZoneRecord
+	if request.Transfer != nil {
+		var tsigKey *model.TsigKey
+		if request.Transfer.TsigName != "" {
+			tsigKey = &model.TsigKey{
+				Name:      request.Transfer.TsigName,
+				Algorithm: request.Transfer.TsigAlgorithm,
+				Secret:    request.Transfer.TsigSecret}
+			if tsigKey.Algorithm == "" {
+				tsigKey.Algorithm = "hmac-sha256."
+			}
+		}
+
+		domain, skipped, err = interactor.ImportTransfer(request.Name, request.Tenants, request.Transfer.Server, tsigKey)
+	} else {
+		domain, skipped, err = interactor.ImportZoneFile(request.Name, request.Tenants, request.ZoneFile)
+	}
+
+	if err != nil {
+		switch e := err.(type) {
+		case *model.InvalidParameterGiven, *usecase.DomainDuplicatedError, *model.InvalidCorefileError:
+			NewError(c, http.StatusBadRequest, err)
+		case *model.ZoneTransferError:
+			NewError(c, http.StatusBadGateway, err)
+		case *model.DomainDegradedError:
+			NewError(c, http.StatusConflict, err)
+		default:
+			NewError(c,
+				http.StatusInternalServerError,
+				NewUnAvailableHandlingError())
+			logger.Error("unexpected error", "error", e)
+		}
+		logger.Warn("request failed", "error", err)
+		return
+	}
+
+	if staged != nil {
+		returnDryRunResult(c, staged)
+		return
+	}
+
+	tenants := make([]string, 0)
+	for _, t := range domain.Tenants {
+		tenants = append(tenants, t.String())
+	}
+
+	skippedList := make([]SkippedRecordResult, 0)
+	for _, r := range skipped {
+		skippedList = append(skippedList, SkippedRecordResult{Name: r.Name, Type: r.Type, Value: r.Value, Reason: r.Reason})
+	}
+
+	var result ZoneImportResult
+	result.Domain = domain.Name.String()
+	result.Uuid = domain.Uuid.String()
+	result.Tenants = tenants
+	result.Hosts = newHostResultList(domain.Hosts)
+	result.Skipped = skippedList
+	setETag(c, domain)
+	c.JSON(http.StatusCreated, result)
+}
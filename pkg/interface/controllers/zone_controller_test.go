@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"coredns_api/internal/model"
+	"coredns_api/internal/usecase"
+)
+
+// fakeZoneRepository records the servers which the zones are transferred from.
+type fakeZoneRepository struct {
+	servers []string
+}
+
+func (f *fakeZoneRepository) LoadZoneFile(domainName model.DomainName, zoneFile string) ([]*model.ZoneRecord, error) {
+	return nil, nil
+}
+
+func (f *fakeZoneRepository) TransferZone(domainName model.DomainName, server string, tsigKey *model.TsigKey) ([]*model.ZoneRecord, error) {
+	f.servers = append(f.servers, server)
+	return nil, nil
+}
+
+func (f *fakeZoneRepository) WithLogger(logger *slog.Logger) usecase.IZoneRepository {
+	return f
+}
+
+func TestImportTransferServers(t *testing.T) {
+	t.Setenv("ZONE_TRANSFER_SERVERS", "192.0.2.53")
+	fsRepository := newTestRepository(t)
+	zRepository := &fakeZoneRepository{}
+	controller := NewZoneController(usecase.NewZoneInteractor(fsRepository, zRepository, nil, nil))
+
+	for _, server := range []string{"127.0.0.1:8080", "192.0.2.53:5353", "metadata.internal"} {
+		request := ZoneImportRequest{Name: "hogehoge.hoge", Tenants: []string{testTenant}, Transfer: &ZoneTransferRequest{Server: server}}
+		c := NewRecordingContext(nil, nil, nil, request)
+		controller.Import(c)
+		if c.StatusCode() != http.StatusBadRequest {
+			t.Errorf("zone is transferred from the server which is not allowed: %s %d", server, c.StatusCode())
+		}
+	}
+	if len(zRepository.servers) != 0 {
+		t.Errorf("server which is not allowed is connected: %v", zRepository.servers)
+	}
+
+	request := ZoneImportRequest{Name: "hogehoge.hoge", Tenants: []string{testTenant}, Transfer: &ZoneTransferRequest{Server: "192.0.2.53"}}
+	c := NewRecordingContext(nil, nil, nil, request)
+	controller.Import(c)
+	if c.StatusCode() != http.StatusCreated {
+		t.Errorf("zone is not transferred from the allowed server: %d %v", c.StatusCode(), c.Result())
+	}
+	if len(zRepository.servers) != 1 || zRepository.servers[0] != "192.0.2.53:53" {
+		t.Errorf("allowed server is missmatched: %v", zRepository.servers)
+	}
+}
@@ -0,0 +1,117 @@
+package dnsserver
+
+import (
+	"net"
+	"os"
+	"strings"
+
+	"github.com/miekg/dns"
+
+	"coredns_api/internal/model"
+)
+
+// Config is the setting of the embedded DNS listener.
+type Config struct {
+	// Listen is the address to listen on with both UDP and TCP, like ":5353".
+	Listen string
+	// TsigKeys are the keys accepted for zone transfers.
+	TsigKeys []*model.TsigKey
+	// UpdateTenants maps the TSIG key names to the tenants which the key can update as.
+	// Dynamic updates are accepted only with these keys.
+	UpdateTenants map[string]model.Uuid
+	// TransferACL is the source networks allowed to transfer zones without TSIG.
+	TransferACL []*net.IPNet
+	// Notify is the secondaries' addresses to send NOTIFY to, like "192.0.2.1:53".
+	Notify []string
+	// PrimaryNs is the name server written in SOA and NS records.
+	// "ns.<domain>" is used if it is empty.
+	PrimaryNs string
+}
+
+// NewConfigFromEnv reads the config from the OS environment variables.
+//
+//	DNS_LISTEN:       listen address. the listener is disabled if it is empty.
+//	DNS_TSIG_KEYS:    comma separated "name:algorithm:secret[:tenant]" list.
+//	                  keys with tenant can send dynamic updates as the tenant.
+//	DNS_TRANSFER_ACL: comma separated CIDR list.
+//	DNS_NOTIFY:       comma separated secondary addresses. port 53 is used if it is omitted.
+//	DNS_PRIMARY_NS:   name server name of SOA and NS records.
+func NewConfigFromEnv() (*Config, error) {
+	tsigKeys, updateTenants, err := parseTsigKeys(os.Getenv("DNS_TSIG_KEYS"))
+	if err != nil {
+		return nil, err
+	}
+
+	var acl []*net.IPNet
+	for _, c := range splitList(os.Getenv("DNS_TRANSFER_ACL")) {
+		_, network, err := net.ParseCIDR(c)
+		if err != nil {
+			return nil, model.NewInvalidParameterGiven("invalid CIDR is given to DNS_TRANSFER_ACL. CIDR: " + c)
+		}
+		acl = append(acl, network)
+	}
+
+	var notify []string
+	for _, n := range splitList(os.Getenv("DNS_NOTIFY")) {
+		if _, _, err := net.SplitHostPort(n); err != nil {
+			n = net.JoinHostPort(n, "53")
+		}
+		notify = append(notify, n)
+	}
+
+	primaryNs := strings.TrimSuffix(os.Getenv("DNS_PRIMARY_NS"), ".")
+	if primaryNs != "" {
+		if _, ok := dns.IsDomainName(primaryNs); !ok {
+			return nil, model.NewInvalidParameterGiven("invalid name is given to DNS_PRIMARY_NS. name: " + primaryNs)
+		}
+	}
+
+	return &Config{
+		Listen:        os.Getenv("DNS_LISTEN"),
+		TsigKeys:      tsigKeys,
+		UpdateTenants: updateTenants,
+		TransferACL:   acl,
+		Notify:        notify,
+		PrimaryNs:     primaryNs}, nil
+}
+
+func parseTsigKeys(keys string) ([]*model.TsigKey, map[string]model.Uuid, error) {
+	var tsigKeys []*model.TsigKey
+	updateTenants := map[string]model.Uuid{}
+	for _, k := range splitList(keys) {
+		fields := strings.Split(k, ":")
+		if len(fields) < 3 || len(fields) > 4 || fields[0] == "" || fields[2] == "" {
+			return nil, nil, model.NewInvalidParameterGiven("TSIG key has to be name:algorithm:secret[:tenant] format.")
+		}
+
+		algorithm := dns.Fqdn(strings.ToLower(fields[1]))
+		switch algorithm {
+		case dns.HmacSHA1, dns.HmacSHA224, dns.HmacSHA256, dns.HmacSHA384, dns.HmacSHA512:
+		default:
+			return nil, nil, model.NewInvalidParameterGiven("unsupported TSIG algorithm is given. algorithm: " + fields[1])
+		}
+
+		key := &model.TsigKey{Name: dns.Fqdn(strings.ToLower(fields[0])), Algorithm: algorithm, Secret: fields[2]}
+		tsigKeys = append(tsigKeys, key)
+
+		if len(fields) == 4 {
+			tenant, err := model.NewUuid(fields[3])
+			if err != nil {
+				return nil, nil, err
+			}
+			updateTenants[key.Name] = tenant
+		}
+	}
+	return tsigKeys, updateTenants, nil
+}
+
+func splitList(list string) []string {
+	var items []string
+	for _, i := range strings.Split(list, ",") {
+		i = strings.TrimSpace(i)
+		if i != "" {
+			items = append(items, i)
+		}
+	}
+	return items
+}
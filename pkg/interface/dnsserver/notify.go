@@ -0,0 +1,43 @@
+package dnsserver
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+const (
+	notifyRetry    = 3
+	notifyInterval = 5 * time.Second
+	notifyTimeout  = 2 * time.Second
+)
+
+// sendNotify tells the secondaries that the zone of the SOA is changed, as RFC 1996.
+func (s *Server) sendNotify(soa *dns.SOA) {
+	for _, secondary := range s.config.Notify {
+		go notify(secondary, soa)
+	}
+}
+
+func notify(secondary string, soa *dns.SOA) {
+	m := &dns.Msg{}
+	m.SetNotify(soa.Hdr.Name)
+	m.Answer = []dns.RR{soa}
+
+	c := &dns.Client{Timeout: notifyTimeout}
+	for i := 0; i < notifyRetry; i++ {
+		resp, _, err := c.Exchange(m, secondary)
+		if err == nil && resp.Rcode == dns.RcodeSuccess {
+			return
+		}
+
+		if err == nil {
+			slog.Warn("NOTIFY is rejected", "zone", soa.Hdr.Name, "secondary", secondary, "rcode", dns.RcodeToString[resp.Rcode])
+			return
+		}
+
+		slog.Warn("failed to send NOTIFY", "zone", soa.Hdr.Name, "secondary", secondary, "error", err)
+		time.Sleep(notifyInterval)
+	}
+}
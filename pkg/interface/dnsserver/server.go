@@ -0,0 +1,317 @@
+package dnsserver
+
+import (
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+
+	"coredns_api/internal/model"
+	"coredns_api/internal/usecase"
+)
+
+// envelopeSize is the number of records sent in one message of zone transfer.
+const envelopeSize = 100
+
+// Server is the embedded DNS listener serving the domains in coreDNSConfCache.
+// It answers zone transfers for secondaries, SOA queries to check the serial,
+// and dynamic updates of the hosts.
+type Server struct {
+	interactor     *usecase.ZoneInteractor
+	hostInteractor *usecase.HostInteractor
+	config         *Config
+
+	lock  sync.RWMutex
+	zones map[string]*zoneState
+}
+
+func NewServer(zItr *usecase.ZoneInteractor, hItr *usecase.HostInteractor) *Server {
+	return &Server{interactor: zItr, hostInteractor: hItr, zones: map[string]*zoneState{}}
+}
+
+// ListenAndServe starts to listen on config.Listen with both UDP and TCP.
+func (s *Server) ListenAndServe(config *Config) error {
+	packetConn, err := net.ListenPacket("udp", config.Listen)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", config.Listen)
+	if err != nil {
+		_ = packetConn.Close()
+		return err
+	}
+
+	return s.ActivateAndServe(config, packetConn, listener)
+}
+
+// ActivateAndServe serves DNS on the given connections until either of them fails.
+func (s *Server) ActivateAndServe(config *Config, packetConn net.PacketConn, listener net.Listener) error {
+	s.config = config
+
+	// The watcher is created before loading zones not to miss any change in between.
+	watcher := s.interactor.Watch()
+	err := s.loadZones()
+	if err != nil {
+		return err
+	}
+	go s.watchZones(watcher)
+
+	tsigSecret := map[string]string{}
+	for _, k := range config.TsigKeys {
+		tsigSecret[k.Name] = k.Secret
+	}
+
+	errCh := make(chan error, 2)
+	udpServer := &dns.Server{PacketConn: packetConn, Handler: s, TsigSecret: tsigSecret, MsgAcceptFunc: acceptMsg}
+	tcpServer := &dns.Server{Listener: listener, Handler: s, TsigSecret: tsigSecret, MsgAcceptFunc: acceptMsg}
+	go func() { errCh <- udpServer.ActivateAndServe() }()
+	go func() { errCh <- tcpServer.ActivateAndServe() }()
+
+	err = <-errCh
+	_ = udpServer.Shutdown()
+	_ = tcpServer.Shutdown()
+	return err
+}
+
+func (s *Server) loadZones() error {
+	domains, err := s.interactor.GetZoneList()
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, d := range domains {
+		zone := newZoneState(d)
+		s.zones[zone.origin] = zone
+	}
+	return nil
+}
+
+func (s *Server) watchZones(watcher usecase.IDomainWatcher) {
+	for range watcher.Changed() {
+		for _, name := range watcher.TakeChanged() {
+			s.refreshZone(name)
+		}
+	}
+}
+
+// refreshZone reloads the domain, and sends NOTIFY to secondaries if its records are changed.
+func (s *Server) refreshZone(domainName model.DomainName) {
+	origin := strings.ToLower(dns.Fqdn(domainName.String()))
+
+	domain, err := s.interactor.GetZone(domainName)
+	if err != nil {
+		if _, ok := err.(*model.DomainNotFoundError); ok {
+			s.lock.Lock()
+			delete(s.zones, origin)
+			s.lock.Unlock()
+			return
+		}
+		slog.Error("failed to reload the zone", "zone", origin, "error", err)
+		return
+	}
+
+	s.lock.Lock()
+	changed := true
+	zone, ok := s.zones[origin]
+	if ok {
+		changed = zone.update(domain)
+	} else {
+		zone = newZoneState(domain)
+		s.zones[origin] = zone
+	}
+	soa := zone.soa(s.config.PrimaryNs, zone.serial)
+	s.lock.Unlock()
+
+	if changed {
+		s.sendNotify(soa)
+	}
+}
+
+// findZone returns the zone which the name belongs to.
+func (s *Server) findZone(name string) *zoneState {
+	name = strings.ToLower(dns.Fqdn(name))
+	for {
+		if zone, ok := s.zones[name]; ok {
+			return zone
+		}
+
+		i := strings.Index(name, ".")
+		if i < 0 || i == len(name)-1 {
+			return nil
+		}
+		name = name[i+1:]
+	}
+}
+
+func (s *Server) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
+	if r.Opcode == dns.OpcodeUpdate {
+		s.serveUpdate(w, r)
+		return
+	}
+	if r.Opcode != dns.OpcodeQuery {
+		writeRcode(w, r, dns.RcodeNotImplemented)
+		return
+	}
+	if len(r.Question) != 1 {
+		writeRcode(w, r, dns.RcodeFormatError)
+		return
+	}
+
+	switch r.Question[0].Qtype {
+	case dns.TypeAXFR, dns.TypeIXFR:
+		s.serveTransfer(w, r)
+	default:
+		s.serveQuery(w, r)
+	}
+}
+
+func (s *Server) serveQuery(w dns.ResponseWriter, r *dns.Msg) {
+	q := r.Question[0]
+
+	s.lock.RLock()
+	zone := s.findZone(q.Name)
+	if zone == nil {
+		s.lock.RUnlock()
+		writeRcode(w, r, dns.RcodeRefused)
+		return
+	}
+	answer, exists := zone.lookup(s.config.PrimaryNs, q.Name, q.Qtype)
+	soa := zone.soa(s.config.PrimaryNs, zone.serial)
+	s.lock.RUnlock()
+
+	m := &dns.Msg{}
+	m.SetReply(r)
+	m.Authoritative = true
+	m.Answer = answer
+	if len(answer) == 0 {
+		m.Ns = []dns.RR{soa}
+		if !exists {
+			m.Rcode = dns.RcodeNameError
+		}
+	}
+	_ = w.WriteMsg(m)
+}
+
+func (s *Server) serveTransfer(w dns.ResponseWriter, r *dns.Msg) {
+	q := r.Question[0]
+	logger := slog.With("request_id", model.NewRequestId(""), "zone", q.Name, "client", w.RemoteAddr().String())
+
+	if !s.isTransferAllowed(w, r) {
+		logger.Warn("zone transfer is refused")
+		rcode := dns.RcodeRefused
+		if r.IsTsig() != nil {
+			rcode = dns.RcodeNotAuth
+		}
+		writeRcode(w, r, rcode)
+		return
+	}
+
+	_, isUdp := w.RemoteAddr().(*net.UDPAddr)
+	if isUdp && q.Qtype == dns.TypeAXFR {
+		writeRcode(w, r, dns.RcodeRefused)
+		return
+	}
+
+	var clientSerial uint32
+	if q.Qtype == dns.TypeIXFR {
+		if len(r.Ns) == 0 {
+			writeRcode(w, r, dns.RcodeFormatError)
+			return
+		}
+		soa, ok := r.Ns[0].(*dns.SOA)
+		if !ok {
+			writeRcode(w, r, dns.RcodeFormatError)
+			return
+		}
+		clientSerial = soa.Serial
+	}
+
+	s.lock.RLock()
+	zone, ok := s.zones[strings.ToLower(q.Name)]
+	if !ok {
+		s.lock.RUnlock()
+		writeRcode(w, r, dns.RcodeNotAuth)
+		return
+	}
+
+	var rrs []dns.RR
+	switch {
+	case q.Qtype == dns.TypeAXFR:
+		rrs = zone.axfr(s.config.PrimaryNs)
+	case isUdp:
+		// IXFR over UDP is answered with the current SOA only,
+		// then the client retries it with TCP if it is outdated.
+		rrs = []dns.RR{zone.soa(s.config.PrimaryNs, zone.serial)}
+	default:
+		rrs = zone.ixfr(s.config.PrimaryNs, clientSerial)
+	}
+	s.lock.RUnlock()
+
+	logger.Info("zone transfer", "type", dns.TypeToString[q.Qtype])
+
+	ch := make(chan *dns.Envelope, len(rrs)/envelopeSize+1)
+	for len(rrs) > 0 {
+		n := envelopeSize
+		if len(rrs) < n {
+			n = len(rrs)
+		}
+		ch <- &dns.Envelope{RR: rrs[:n]}
+		rrs = rrs[n:]
+	}
+	close(ch)
+
+	tr := &dns.Transfer{}
+	err := tr.Out(w, r, ch)
+	if err != nil {
+		logger.Warn("zone transfer failed", "error", err)
+	}
+}
+
+// isTransferAllowed accepts the request signed with a valid TSIG key, or sent from the ACL.
+func (s *Server) isTransferAllowed(w dns.ResponseWriter, r *dns.Msg) bool {
+	if r.IsTsig() != nil {
+		return w.TsigStatus() == nil
+	}
+
+	host, _, err := net.SplitHostPort(w.RemoteAddr().String())
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	for _, network := range s.config.TransferACL {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptMsg accepts dynamic updates in addition to the messages accepted by default,
+// since their prerequisite and update sections can have any number of records.
+func acceptMsg(dh dns.Header) dns.MsgAcceptAction {
+	opcode := int(dh.Bits>>11) & 0xF
+	isResponse := dh.Bits&(1<<15) != 0
+	if opcode == dns.OpcodeUpdate && !isResponse {
+		if dh.Qdcount != 1 {
+			return dns.MsgReject
+		}
+		return dns.MsgAccept
+	}
+	return dns.DefaultMsgAcceptFunc(dh)
+}
+
+// writeRcode answers with the rcode, signed with the TSIG key of the request if it is valid.
+func writeRcode(w dns.ResponseWriter, r *dns.Msg, rcode int) {
+	m := &dns.Msg{}
+	m.SetRcode(r, rcode)
+	if tsig := r.IsTsig(); tsig != nil && w.TsigStatus() == nil {
+		m.SetTsig(tsig.Hdr.Name, tsig.Algorithm, tsig.Fudge, time.Now().Unix())
+	}
+	_ = w.WriteMsg(m)
+}
@@ -0,0 +1,301 @@
+package dnsserver
+
+import (
+	"log/slog"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/miekg/dns"
+
+	"coredns_api/internal/model"
+	"coredns_api/internal/usecase"
+)
+
+// hostUpdatePlan is the host changes requested by a dynamic update message.
+type hostUpdatePlan struct {
+	// hosts are all the hosts of the domain after the update.
+	hosts []*model.Host
+	// revision is the revision of the domain the plan is made from.
+	revision uint64
+
+	deleted []*model.Host
+	updated []*model.Host
+	added   []*model.Host
+}
+
+func (p *hostUpdatePlan) isEmpty() bool {
+	return len(p.deleted) == 0 && len(p.updated) == 0 && len(p.added) == 0
+}
+
+// apply writes the hosts of the plan with one Apply, only if the domain is still at the revision of the plan.
+// So the update is applied entirely or not at all, and the hostnames and addresses can be swapped.
+func (p *hostUpdatePlan) apply(itr *usecase.HostInteractor, domainUuid, tenantUuid model.Uuid) error {
+	_, _, err := itr.IfMatch([]uint64{p.revision}).Apply(p.hosts, domainUuid, tenantUuid)
+	return err
+}
+
+// updateAttempts is how many times the update is tried when the domain is changed by another request in the middle.
+const updateAttempts = 3
+
+// serveUpdate handles RFC 2136 dynamic update signed with a TSIG key mapped to a tenant.
+func (s *Server) serveUpdate(w dns.ResponseWriter, r *dns.Msg) {
+	if len(r.Question) != 1 || r.Question[0].Qtype != dns.TypeSOA {
+		writeRcode(w, r, dns.RcodeFormatError)
+		return
+	}
+	zoneName := strings.ToLower(r.Question[0].Name)
+	logger := slog.With("request_id", model.NewRequestId(""), "zone", zoneName, "client", w.RemoteAddr().String())
+
+	tsig := r.IsTsig()
+	if tsig == nil {
+		logger.Warn("dynamic update without TSIG is refused")
+		writeRcode(w, r, dns.RcodeRefused)
+		return
+	}
+	if w.TsigStatus() != nil {
+		logger.Warn("dynamic update with invalid TSIG is refused")
+		writeRcode(w, r, dns.RcodeNotAuth)
+		return
+	}
+
+	tenantUuid, ok := s.config.UpdateTenants[strings.ToLower(tsig.Hdr.Name)]
+	if !ok {
+		logger.Warn("TSIG key is not allowed to update", "key", tsig.Hdr.Name)
+		writeRcode(w, r, dns.RcodeRefused)
+		return
+	}
+	logger = logger.With("tenant", tenantUuid)
+
+	domainName, err := model.NewDomainName(strings.TrimSuffix(zoneName, "."))
+	if err != nil {
+		writeRcode(w, r, dns.RcodeNotAuth)
+		return
+	}
+
+	var rcode int
+	for attempt := 0; attempt < updateAttempts; attempt++ {
+		rcode, err = s.update(domainName, tenantUuid, r, logger)
+		if _, ok := err.(*model.DomainRevisionMismatchError); !ok {
+			break
+		}
+	}
+	if err != nil {
+		logger.Warn("dynamic update failed", "error", err)
+		writeRcode(w, r, updateErrorRcode(err))
+		return
+	}
+	if rcode != dns.RcodeSuccess {
+		writeRcode(w, r, rcode)
+		return
+	}
+
+	logger.Info("dynamic update", "key", tsig.Hdr.Name)
+	writeRcode(w, r, dns.RcodeSuccess)
+}
+
+// update checks the prerequisites on the current domain, and applies the update to it.
+// The error is DomainRevisionMismatchError when the domain is changed after it is got.
+func (s *Server) update(domainName model.DomainName, tenantUuid model.Uuid, r *dns.Msg, logger *slog.Logger) (int, error) {
+	domain, err := s.interactor.WithLogger(logger).GetZone(domainName)
+	if err != nil {
+		return 0, err
+	}
+	logger = logger.With("domain_uuid", domain.Uuid)
+	hostInteractor := s.hostInteractor.WithLogger(logger)
+
+	// Check the tenant before the prerequisites not to tell the zone contents to other tenants.
+	_, err = hostInteractor.GetDomain(domain.Uuid, tenantUuid)
+	if err != nil {
+		return 0, err
+	}
+
+	rcode := checkPrerequisites(newZoneState(domain), s.config.PrimaryNs, r.Answer)
+	if rcode != dns.RcodeSuccess {
+		return rcode, nil
+	}
+
+	plan, rcode := planUpdate(domain, r.Ns, logger)
+	if rcode != dns.RcodeSuccess || plan.isEmpty() {
+		return rcode, nil
+	}
+
+	return dns.RcodeSuccess, plan.apply(hostInteractor, domain.Uuid, tenantUuid)
+}
+
+// checkPrerequisites checks the prerequisite section as RFC 2136 3.2.
+func checkPrerequisites(zone *zoneState, primaryNs string, prereqs []dns.RR) int {
+	valueDependent := map[string][]dns.RR{}
+	var valueDependentKeys []string
+	for _, rr := range prereqs {
+		hdr := rr.Header()
+		if !dns.IsSubDomain(zone.origin, strings.ToLower(hdr.Name)) {
+			return dns.RcodeNotZone
+		}
+
+		switch hdr.Class {
+		case dns.ClassANY:
+			answer, exists := zone.lookup(primaryNs, hdr.Name, hdr.Rrtype)
+			if hdr.Rrtype == dns.TypeANY && !exists {
+				return dns.RcodeNameError
+			}
+			if hdr.Rrtype != dns.TypeANY && len(answer) == 0 {
+				return dns.RcodeNXRrset
+			}
+		case dns.ClassNONE:
+			answer, exists := zone.lookup(primaryNs, hdr.Name, hdr.Rrtype)
+			if hdr.Rrtype == dns.TypeANY && exists {
+				return dns.RcodeYXDomain
+			}
+			if hdr.Rrtype != dns.TypeANY && len(answer) > 0 {
+				return dns.RcodeYXRrset
+			}
+		case dns.ClassINET:
+			key := strings.ToLower(hdr.Name) + " " + dns.TypeToString[hdr.Rrtype]
+			if _, ok := valueDependent[key]; !ok {
+				valueDependentKeys = append(valueDependentKeys, key)
+			}
+			valueDependent[key] = append(valueDependent[key], rr)
+		default:
+			return dns.RcodeFormatError
+		}
+	}
+
+	for _, key := range valueDependentKeys {
+		rrs := valueDependent[key]
+		answer, _ := zone.lookup(primaryNs, rrs[0].Header().Name, rrs[0].Header().Rrtype)
+		if len(subtractRecords(answer, rrs)) > 0 || len(subtractRecords(rrs, answer)) > 0 {
+			return dns.RcodeNXRrset
+		}
+	}
+	return dns.RcodeSuccess
+}
+
+// planUpdate converts the update section to the host changes, as RFC 2136 3.4.
+// A hosts file has one address for a hostname, so adding a second address to a hostname is refused.
+// SOA and NS records of the zone apex are managed by this server, and their updates are ignored.
+func planUpdate(domain *model.Domain, updates []dns.RR, logger *slog.Logger) (*hostUpdatePlan, int) {
+	origin := strings.ToLower(dns.Fqdn(domain.Name.String()))
+
+	desired := map[string]string{}
+	for _, h := range domain.Hosts {
+		desired[strings.ToLower(h.Name)] = h.Address
+	}
+
+	for _, rr := range updates {
+		hdr := rr.Header()
+		name := strings.ToLower(hdr.Name)
+		if !dns.IsSubDomain(origin, name) {
+			return nil, dns.RcodeNotZone
+		}
+		hostname := strings.TrimSuffix(name, ".")
+
+		if name == origin && (hdr.Rrtype == dns.TypeSOA || hdr.Rrtype == dns.TypeNS) {
+			continue
+		}
+
+		switch hdr.Class {
+		case dns.ClassINET:
+			address, ok := recordAddress(rr)
+			if !ok {
+				logger.Warn("dynamic update of the record is not supported in hosts file", "type", dns.TypeToString[hdr.Rrtype])
+				return nil, dns.RcodeRefused
+			}
+
+			current, exists := desired[hostname]
+			if exists && current != address {
+				logger.Warn("hostname already has another address in hosts file", "host", hostname)
+				return nil, dns.RcodeRefused
+			}
+			desired[hostname] = address
+		case dns.ClassANY:
+			current, exists := desired[hostname]
+			if exists && (hdr.Rrtype == dns.TypeANY || hdr.Rrtype == addressType(current)) {
+				delete(desired, hostname)
+			}
+		case dns.ClassNONE:
+			address, ok := recordAddress(rr)
+			if ok && desired[hostname] == address {
+				delete(desired, hostname)
+			}
+		default:
+			return nil, dns.RcodeFormatError
+		}
+	}
+
+	plan := &hostUpdatePlan{revision: domain.Revision}
+	current := map[string]bool{}
+	for _, h := range domain.Hosts {
+		name := strings.ToLower(h.Name)
+		current[name] = true
+
+		address, ok := desired[name]
+		if !ok {
+			plan.deleted = append(plan.deleted, h)
+			continue
+		}
+		if address == h.Address {
+			plan.hosts = append(plan.hosts, h)
+			continue
+		}
+
+		host, err := model.NewHost(h.Uuid, h.Name, address)
+		if err != nil {
+			logger.Warn("dynamic update is refused", "error", err)
+			return nil, dns.RcodeRefused
+		}
+		plan.updated = append(plan.updated, host)
+		plan.hosts = append(plan.hosts, host)
+	}
+
+	var addedNames []string
+	for name := range desired {
+		if !current[name] {
+			addedNames = append(addedNames, name)
+		}
+	}
+	sort.Strings(addedNames)
+
+	for _, name := range addedNames {
+		host, err := model.NewOriginalHost(name, desired[name], domain.Name)
+		if err != nil {
+			logger.Warn("dynamic update is refused", "error", err)
+			return nil, dns.RcodeRefused
+		}
+		plan.added = append(plan.added, host)
+		plan.hosts = append(plan.hosts, host)
+	}
+	return plan, dns.RcodeSuccess
+}
+
+func recordAddress(rr dns.RR) (string, bool) {
+	switch v := rr.(type) {
+	case *dns.A:
+		return v.A.String(), v.A != nil
+	case *dns.AAAA:
+		return v.AAAA.String(), v.AAAA != nil
+	}
+	return "", false
+}
+
+func addressType(address string) uint16 {
+	if net.ParseIP(address).To4() != nil {
+		return dns.TypeA
+	}
+	return dns.TypeAAAA
+}
+
+func updateErrorRcode(err error) int {
+	switch err.(type) {
+	case *model.DomainNotFoundError:
+		return dns.RcodeNotAuth
+	case *model.DomainPermissionError, *model.InvalidParameterGiven:
+		return dns.RcodeRefused
+	case *usecase.HostDuplicatedError:
+		return dns.RcodeYXRrset
+	case *model.HostNotFoundError:
+		return dns.RcodeNXRrset
+	default:
+		return dns.RcodeServerFailure
+	}
+}
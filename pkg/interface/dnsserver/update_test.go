@@ -0,0 +1,173 @@
+package dnsserver
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/miekg/dns"
+
+	"coredns_api/internal/infrastructure"
+	"coredns_api/internal/interface/repository"
+	"coredns_api/internal/model"
+	"coredns_api/internal/usecase"
+)
+
+func newTestRR(t *testing.T, s string) dns.RR {
+	rr, err := dns.NewRR(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rr
+}
+
+// newTestEmptyRR creates the record without data, which is used to match RRsets in dynamic update.
+func newTestEmptyRR(name string, class, rrtype uint16) dns.RR {
+	rr := dns.TypeToRR[rrtype]()
+	*rr.Header() = dns.RR_Header{Name: name, Rrtype: rrtype, Class: class}
+	return rr
+}
+
+func TestCheckPrerequisites(t *testing.T) {
+	zone := newZoneState(newTestDomain(t, map[string]string{"hogeserver1.hogehoge.hoge": "172.21.1.2"}))
+
+	cases := []struct {
+		prereq dns.RR
+		rcode  int
+	}{
+		{newTestEmptyRR("hogeserver1.hogehoge.hoge.", dns.ClassANY, dns.TypeANY), dns.RcodeSuccess},
+		{newTestEmptyRR("hogeserver2.hogehoge.hoge.", dns.ClassANY, dns.TypeANY), dns.RcodeNameError},
+		{newTestEmptyRR("hogeserver2.hogehoge.hoge.", dns.ClassNONE, dns.TypeANY), dns.RcodeSuccess},
+		{newTestEmptyRR("hogeserver1.hogehoge.hoge.", dns.ClassNONE, dns.TypeANY), dns.RcodeYXDomain},
+		{newTestEmptyRR("hogeserver1.hogehoge.hoge.", dns.ClassANY, dns.TypeA), dns.RcodeSuccess},
+		{newTestEmptyRR("hogeserver1.hogehoge.hoge.", dns.ClassANY, dns.TypeAAAA), dns.RcodeNXRrset},
+		{newTestEmptyRR("hogeserver1.hogehoge.hoge.", dns.ClassNONE, dns.TypeA), dns.RcodeYXRrset},
+		{newTestRR(t, "hogeserver1.hogehoge.hoge. 0 IN A 172.21.1.2"), dns.RcodeSuccess},
+		{newTestRR(t, "hogeserver1.hogehoge.hoge. 0 IN A 172.21.1.3"), dns.RcodeNXRrset},
+		{newTestEmptyRR("fugaserver.fugafuga.fuga.", dns.ClassANY, dns.TypeANY), dns.RcodeNotZone},
+	}
+
+	for _, c := range cases {
+		rcode := checkPrerequisites(zone, "", []dns.RR{c.prereq})
+		if rcode != c.rcode {
+			t.Error(c.prereq.Header().String() + " returns " + dns.RcodeToString[rcode])
+		}
+	}
+}
+
+func TestPlanUpdate(t *testing.T) {
+	domain := newTestDomain(t, map[string]string{
+		"hogeserver1.hogehoge.hoge": "172.21.1.2",
+		"hogeserver2.hogehoge.hoge": "172.21.1.3"})
+
+	plan, rcode := planUpdate(domain, []dns.RR{
+		newTestEmptyRR("hogeserver1.hogehoge.hoge.", dns.ClassANY, dns.TypeA),
+		newTestRR(t, "hogeserver1.hogehoge.hoge. 300 IN A 172.21.1.4"),
+		newTestRR(t, "hogeserver2.hogehoge.hoge. 0 NONE A 172.21.1.3"),
+		newTestRR(t, "hogeserver3.hogehoge.hoge. 300 IN AAAA fd00::3"),
+		newTestRR(t, "hogehoge.hoge. 300 IN NS ns1.hogehoge.hoge."),
+	}, slog.Default())
+	if rcode != dns.RcodeSuccess {
+		t.Error(dns.RcodeToString[rcode])
+		return
+	}
+
+	if len(plan.updated) != 1 || plan.updated[0].Address != "172.21.1.4" {
+		t.Error("address change is not planned as update")
+	}
+	if len(plan.deleted) != 1 || plan.deleted[0].Name != "hogeserver2.hogehoge.hoge" {
+		t.Error("delete is not planned")
+	}
+	if len(plan.added) != 1 || plan.added[0].Address != "fd00::3" {
+		t.Error("add is not planned")
+	}
+
+	_, rcode = planUpdate(domain, []dns.RR{newTestRR(t, "hogeserver1.hogehoge.hoge. 300 IN A 172.21.1.4")}, slog.Default())
+	if rcode != dns.RcodeRefused {
+		t.Error("second address of hostname is accepted")
+	}
+
+	_, rcode = planUpdate(domain, []dns.RR{newTestRR(t, "_acme-challenge.hogehoge.hoge. 300 IN TXT token")}, slog.Default())
+	if rcode != dns.RcodeRefused {
+		t.Error("unsupported record is accepted")
+	}
+
+	_, rcode = planUpdate(domain, []dns.RR{newTestRR(t, "fugaserver.fugafuga.fuga. 300 IN A 172.21.1.5")}, slog.Default())
+	if rcode != dns.RcodeNotZone {
+		t.Error("out of zone record is accepted")
+	}
+}
+
+func TestApplyUpdatePlan(t *testing.T) {
+	dir := t.TempDir()
+	hostsDir := filepath.Join(dir, "hosts")
+	err := os.Mkdir(hostsDir, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOSTS_DIR", hostsDir)
+	t.Setenv("CONF_PATH", filepath.Join(dir, "coredns.conf"))
+
+	fsRepository := repository.NewFileRepository(infrastructure.NewFilesystem())
+	fsRepository.Initialize()
+	zoneInteractor := usecase.NewZoneInteractor(fsRepository, nil, nil, nil)
+	hostInteractor := usecase.NewHostInteractor(fsRepository, nil, nil)
+
+	domain := newTestDomain(t, map[string]string{
+		"hogeserver1.hogehoge.hoge": "172.21.1.2",
+		"hogeserver2.hogehoge.hoge": "172.21.1.3"})
+	hosts := domain.Hosts
+	domain.Hosts = nil
+	err = usecase.NewDomainInteractor(fsRepository, nil, nil).Add(domain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = hostInteractor.Apply(hosts, domain.Uuid, domain.Tenants[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	current, err := zoneInteractor.GetZone(domain.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The addresses are swapped between the hostnames in one update.
+	plan, rcode := planUpdate(current, []dns.RR{
+		newTestEmptyRR("hogeserver1.hogehoge.hoge.", dns.ClassANY, dns.TypeA),
+		newTestEmptyRR("hogeserver2.hogehoge.hoge.", dns.ClassANY, dns.TypeA),
+		newTestRR(t, "hogeserver1.hogehoge.hoge. 300 IN A 172.21.1.3"),
+		newTestRR(t, "hogeserver2.hogehoge.hoge. 300 IN A 172.21.1.2"),
+	}, slog.Default())
+	if rcode != dns.RcodeSuccess {
+		t.Error(dns.RcodeToString[rcode])
+		return
+	}
+	err = plan.apply(hostInteractor, domain.Uuid, domain.Tenants[0])
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	swapped, err := zoneInteractor.GetZone(domain.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, h := range swapped.Hosts {
+		if (h.Name == "hogeserver1.hogehoge.hoge" && h.Address != "172.21.1.3") || (h.Name == "hogeserver2.hogehoge.hoge" && h.Address != "172.21.1.2") {
+			t.Error("addresses are not swapped: " + h.Name + " " + h.Address)
+		}
+	}
+
+	// The plan made from the old revision is not applied.
+	plan, _ = planUpdate(current, []dns.RR{newTestRR(t, "hogeserver3.hogehoge.hoge. 300 IN A 172.21.1.4")}, slog.Default())
+	err = plan.apply(hostInteractor, domain.Uuid, domain.Tenants[0])
+	if _, ok := err.(*model.DomainRevisionMismatchError); !ok {
+		t.Error("plan of the old revision is applied")
+	}
+	after, _ := zoneInteractor.GetZone(domain.Name)
+	if len(after.Hosts) != 2 {
+		t.Error("hosts are changed by the plan of the old revision")
+	}
+}
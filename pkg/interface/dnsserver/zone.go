@@ -0,0 +1,213 @@
+package dnsserver
+
+import (
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+
+	"coredns_api/internal/model"
+)
+
+const (
+	recordTtl = 3600
+
+	// journalSize is the number of the changes kept for IXFR.
+	// Older serials are answered with the whole zone.
+	journalSize = 32
+)
+
+// zoneDiff is the records changed from one serial to the next one.
+type zoneDiff struct {
+	from    uint32
+	to      uint32
+	deleted []dns.RR
+	added   []dns.RR
+}
+
+// zoneState is the served version of a domain, with the journal to reach it.
+// Serials are not persisted. They start from the unix time when the zone is loaded,
+// so a restarted server always has a newer serial than secondaries have.
+type zoneState struct {
+	origin  string
+	serial  uint32
+	records []dns.RR
+	journal []*zoneDiff
+}
+
+func newZoneState(domain *model.Domain) *zoneState {
+	return &zoneState{
+		origin:  dns.Fqdn(domain.Name.String()),
+		serial:  uint32(time.Now().Unix()),
+		records: newHostRecords(domain)}
+}
+
+// update sets the records of the domain as a new serial.
+// It returns false if nothing is changed.
+func (z *zoneState) update(domain *model.Domain) bool {
+	records := newHostRecords(domain)
+	deleted := subtractRecords(z.records, records)
+	added := subtractRecords(records, z.records)
+	if len(deleted) == 0 && len(added) == 0 {
+		return false
+	}
+
+	serial := z.serial + 1
+	if now := uint32(time.Now().Unix()); isNewerSerial(now, serial) {
+		serial = now
+	}
+
+	z.journal = append(z.journal, &zoneDiff{from: z.serial, to: serial, deleted: deleted, added: added})
+	if len(z.journal) > journalSize {
+		z.journal = z.journal[len(z.journal)-journalSize:]
+	}
+	z.serial = serial
+	z.records = records
+	return true
+}
+
+// diffsFrom returns the journal from the serial to the current one,
+// or false if the serial is not in the journal.
+func (z *zoneState) diffsFrom(serial uint32) ([]*zoneDiff, bool) {
+	for i, d := range z.journal {
+		if d.from == serial {
+			return z.journal[i:], true
+		}
+	}
+	return nil, false
+}
+
+func (z *zoneState) soa(primaryNs string, serial uint32) *dns.SOA {
+	return &dns.SOA{
+		Hdr:     dns.RR_Header{Name: z.origin, Rrtype: dns.TypeSOA, Class: dns.ClassINET, Ttl: recordTtl},
+		Ns:      z.primaryNs(primaryNs),
+		Mbox:    "hostmaster." + z.origin,
+		Serial:  serial,
+		Refresh: 3600,
+		Retry:   600,
+		Expire:  86400,
+		Minttl:  recordTtl}
+}
+
+func (z *zoneState) ns(primaryNs string) *dns.NS {
+	return &dns.NS{
+		Hdr: dns.RR_Header{Name: z.origin, Rrtype: dns.TypeNS, Class: dns.ClassINET, Ttl: recordTtl},
+		Ns:  z.primaryNs(primaryNs)}
+}
+
+func (z *zoneState) primaryNs(primaryNs string) string {
+	if primaryNs == "" {
+		return "ns." + z.origin
+	}
+	return dns.Fqdn(primaryNs)
+}
+
+// axfr returns the whole zone records surrounded by the SOA record.
+func (z *zoneState) axfr(primaryNs string) []dns.RR {
+	soa := z.soa(primaryNs, z.serial)
+	rrs := []dns.RR{soa, z.ns(primaryNs)}
+	rrs = append(rrs, z.records...)
+	return append(rrs, soa)
+}
+
+// ixfr returns the incremental transfer records from the serial, as RFC 1995.
+// The whole zone is returned when the serial is too old.
+func (z *zoneState) ixfr(primaryNs string, serial uint32) []dns.RR {
+	current := z.soa(primaryNs, z.serial)
+	if serial == z.serial || isNewerSerial(serial, z.serial) {
+		return []dns.RR{current}
+	}
+
+	diffs, ok := z.diffsFrom(serial)
+	if !ok {
+		return z.axfr(primaryNs)
+	}
+
+	rrs := []dns.RR{current}
+	for _, d := range diffs {
+		rrs = append(rrs, z.soa(primaryNs, d.from))
+		rrs = append(rrs, d.deleted...)
+		rrs = append(rrs, z.soa(primaryNs, d.to))
+		rrs = append(rrs, d.added...)
+	}
+	return append(rrs, current)
+}
+
+// lookup returns the records of the name and type,
+// and whether the name exists in the zone or not.
+func (z *zoneState) lookup(primaryNs, name string, qtype uint16) ([]dns.RR, bool) {
+	name = strings.ToLower(dns.Fqdn(name))
+	if name == z.origin {
+		switch qtype {
+		case dns.TypeSOA:
+			return []dns.RR{z.soa(primaryNs, z.serial)}, true
+		case dns.TypeNS:
+			return []dns.RR{z.ns(primaryNs)}, true
+		}
+	}
+
+	exists := name == z.origin
+	var answer []dns.RR
+	for _, rr := range z.records {
+		if strings.ToLower(rr.Header().Name) != name {
+			continue
+		}
+		exists = true
+		if qtype == dns.TypeANY || rr.Header().Rrtype == qtype {
+			answer = append(answer, rr)
+		}
+	}
+	return answer, exists
+}
+
+func newHostRecords(domain *model.Domain) []dns.RR {
+	var records []dns.RR
+	for _, h := range domain.Hosts {
+		ip := net.ParseIP(h.Address)
+		if ip == nil {
+			continue
+		}
+
+		hdr := dns.RR_Header{Name: dns.Fqdn(h.Name), Class: dns.ClassINET, Ttl: recordTtl}
+		if ip4 := ip.To4(); ip4 != nil {
+			hdr.Rrtype = dns.TypeA
+			records = append(records, &dns.A{Hdr: hdr, A: ip4})
+		} else {
+			hdr.Rrtype = dns.TypeAAAA
+			records = append(records, &dns.AAAA{Hdr: hdr, AAAA: ip})
+		}
+	}
+
+	sort.Slice(records, func(i, j int) bool { return records[i].String() < records[j].String() })
+	return records
+}
+
+// subtractRecords returns the records in a which are not in b.
+// Records are compared by name, type and data, ignoring TTL and class.
+func subtractRecords(a, b []dns.RR) []dns.RR {
+	inB := map[string]bool{}
+	for _, rb := range b {
+		inB[recordKey(rb)] = true
+	}
+
+	var diff []dns.RR
+	for _, ra := range a {
+		if !inB[recordKey(ra)] {
+			diff = append(diff, ra)
+		}
+	}
+	return diff
+}
+
+func recordKey(rr dns.RR) string {
+	hdr := rr.Header()
+	data := strings.TrimPrefix(rr.String(), hdr.String())
+	return strings.ToLower(hdr.Name) + " " + dns.TypeToString[hdr.Rrtype] + " " + data
+}
+
+// isNewerSerial compares serials with RFC 1982 serial number arithmetic.
+func isNewerSerial(a, b uint32) bool {
+	return a != b && int32(a-b) > 0
+}
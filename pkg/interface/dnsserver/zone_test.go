@@ -0,0 +1,107 @@
+package dnsserver
+
+import (
+	"testing"
+
+	"github.com/miekg/dns"
+
+	"coredns_api/internal/model"
+)
+
+func newTestDomain(t *testing.T, hosts map[string]string) *model.Domain {
+	domain, err := model.NewOriginalDomain("hogehoge.hoge", []string{"df397e50-8006-450e-b18b-5c5bd940baff"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, address := range hosts {
+		host, err := model.NewOriginalHost(name, address, domain.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		domain.Hosts = append(domain.Hosts, host)
+	}
+	return domain
+}
+
+func TestZoneAxfr(t *testing.T) {
+	zone := newZoneState(newTestDomain(t, map[string]string{
+		"hogeserver1.hogehoge.hoge": "172.21.1.2",
+		"hogeserver2.hogehoge.hoge": "fd00::2"}))
+
+	rrs := zone.axfr("")
+	if len(rrs) != 5 {
+		t.Error("zone is not transferred")
+		return
+	}
+	if rrs[0].Header().Rrtype != dns.TypeSOA || rrs[4].Header().Rrtype != dns.TypeSOA {
+		t.Error("zone is not surrounded by SOA")
+	}
+	if rrs[0].(*dns.SOA).Ns != "ns.hogehoge.hoge." {
+		t.Error(rrs[0])
+	}
+	if rrs[3].Header().Rrtype != dns.TypeAAAA {
+		t.Error(rrs[3])
+	}
+}
+
+func TestZoneIxfr(t *testing.T) {
+	zone := newZoneState(newTestDomain(t, map[string]string{"hogeserver1.hogehoge.hoge": "172.21.1.2"}))
+	first := zone.serial
+
+	if zone.update(newTestDomain(t, map[string]string{"hogeserver1.hogehoge.hoge": "172.21.1.2"})) {
+		t.Error("serial is changed without any change")
+	}
+
+	if !zone.update(newTestDomain(t, map[string]string{"hogeserver1.hogehoge.hoge": "172.21.1.3"})) {
+		t.Error("change is not detected")
+	}
+	if !isNewerSerial(zone.serial, first) {
+		t.Error("serial is not increased")
+	}
+
+	rrs := zone.ixfr("ns1.hogehoge.hoge", first)
+	if len(rrs) != 6 {
+		t.Error("incremental transfer is not returned")
+		return
+	}
+	if rrs[1].(*dns.SOA).Serial != first || rrs[2].(*dns.A).A.String() != "172.21.1.2" {
+		t.Error("deleted record is missmatched")
+	}
+	if rrs[3].(*dns.SOA).Serial != zone.serial || rrs[4].(*dns.A).A.String() != "172.21.1.3" {
+		t.Error("added record is missmatched")
+	}
+
+	if len(zone.ixfr("", zone.serial)) != 1 {
+		t.Error("up to date client is not answered with SOA only")
+	}
+	if len(zone.ixfr("", first-100)) != 4 {
+		t.Error("unknown serial is not answered with whole zone")
+	}
+}
+
+func TestParseTsigKeys(t *testing.T) {
+	keys, tenants, err := parseTsigKeys("transfer-key:hmac-sha256:c2VjcmV0, update-key:HMAC-SHA512.:c2VjcmV0:df397e50-8006-450e-b18b-5c5bd940baff")
+	if err != nil {
+		t.Error(err)
+	}
+	if len(keys) != 2 || keys[0].Name != "transfer-key." || keys[1].Algorithm != dns.HmacSHA512 {
+		t.Error("TSIG keys are not parsed")
+	}
+	if len(tenants) != 1 || tenants["update-key."].String() != "df397e50-8006-450e-b18b-5c5bd940baff" {
+		t.Error("tenant of TSIG key is not parsed")
+	}
+
+	_, _, err = parseTsigKeys("transfer-key:md5:c2VjcmV0")
+	if err == nil {
+		t.Error("unsupported algorithm is accepted")
+	}
+	_, _, err = parseTsigKeys("update-key:hmac-sha256:c2VjcmV0:")
+	if err == nil {
+		t.Error("invalid tenant is accepted")
+	}
+	_, _, err = parseTsigKeys("transfer-key:c2VjcmV0")
+	if err == nil {
+		t.Error("invalid format is accepted")
+	}
+}
@@ -0,0 +1,112 @@
+package grpcserver
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"coredns_api/internal/model"
+	"coredns_api/pkg/interface/controllers"
+)
+
+// grpcContext is the controllers.Context to call the controllers from gRPC calls,
+// so that they behave the same as the REST API.
+// Tenant, If-Match and X-Request-Id headers are taken from the metadata of the call.
+type grpcContext struct {
+	*controllers.RecordingContext
+}
+
+// newGrpcContext makes the context with the request value of the controller as the body.
+func newGrpcContext(ctx context.Context, params, query map[string]string, body interface{}) *grpcContext {
+	headers := map[string]string{}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for key, header := range map[string]string{"tenant": "Tenant", "if-match": "If-Match", "x-request-id": controllers.RequestIdHeader} {
+		if values := md.Get(key); len(values) > 0 {
+			headers[header] = values[0]
+		}
+	}
+
+	// The request ID is returned in the header metadata, same as the REST API.
+	headers[controllers.RequestIdHeader] = model.NewRequestId(headers[controllers.RequestIdHeader])
+	c := &grpcContext{controllers.NewRecordingContext(headers, params, query, body)}
+	c.Header(controllers.RequestIdHeader, headers[controllers.RequestIdHeader])
+	return c
+}
+
+// sendHeader sends the response headers as the header metadata.
+func (c *grpcContext) sendHeader(ctx context.Context) {
+	if len(c.ResponseHeaders()) == 0 {
+		return
+	}
+
+	md := metadata.MD{}
+	for key, value := range c.ResponseHeaders() {
+		md.Set(strings.ToLower(key), value)
+	}
+	_ = grpc.SetHeader(ctx, md)
+}
+
+// result returns the result of the controller as T,
+// or the error response as gRPC status.
+func result[T any](ctx context.Context, c *grpcContext) (T, error) {
+	var zero T
+	if c.StatusCode() >= http.StatusBadRequest {
+		httpError, ok := c.Result().(controllers.HTTPError)
+		if !ok {
+			return zero, status.Error(codes.Internal, http.StatusText(c.StatusCode()))
+		}
+		return zero, status.Error(getCode(c.StatusCode(), httpError.Type), httpError.Message)
+	}
+
+	c.sendHeader(ctx)
+	r, ok := c.Result().(T)
+	if !ok {
+		return zero, status.Errorf(codes.Internal, "unexpected result %T", c.Result())
+	}
+	return r, nil
+}
+
+// noResult checks the controller responded without a result, like 204 No Content.
+func noResult(ctx context.Context, c *grpcContext) error {
+	if c.StatusCode() >= http.StatusBadRequest {
+		_, err := result[controllers.HTTPError](ctx, c)
+		return err
+	}
+
+	c.sendHeader(ctx)
+	return nil
+}
+
+// getCode returns the gRPC code for the HTTP status and the error type of the REST API.
+func getCode(statusCode int, errorType string) codes.Code {
+	switch errorType {
+	case controllers.ErrorTypeNotFound:
+		return codes.NotFound
+	case controllers.ErrorTypePermission:
+		return codes.PermissionDenied
+	case controllers.ErrorTypeDuplicated:
+		return codes.AlreadyExists
+	case controllers.ErrorTypeDegraded, controllers.ErrorTypeRevisionMismatch:
+		return codes.FailedPrecondition
+	case controllers.ErrorTypeInvalidParameter:
+		return codes.InvalidArgument
+	}
+
+	switch statusCode {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	default:
+		return codes.Internal
+	}
+}
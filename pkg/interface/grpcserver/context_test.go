@@ -0,0 +1,52 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"coredns_api/internal/model"
+	"coredns_api/pkg/interface/controllers"
+)
+
+func TestGrpcContextHeaders(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("tenant", "df397e50-8006-450e-b18b-5c5bd940baff", "if-match", `"3"`))
+	c := newGrpcContext(ctx, nil, nil, nil)
+	if c.GetHeader("Tenant") != "df397e50-8006-450e-b18b-5c5bd940baff" || c.GetHeader("If-Match") != `"3"` {
+		t.Error("headers are not taken from the metadata")
+	}
+}
+
+func TestGrpcContextResponse(t *testing.T) {
+	c := newGrpcContext(context.Background(), nil, nil, nil)
+	c.JSON(http.StatusOK, controllers.HostResult{Name: "hogeserver1.hogehoge.hoge", Address: "172.21.1.1", Uuid: "5b9ea8eb-5ce5-422a-9d70-37d25fa896ae", TtlLease: "1h0m0s"})
+
+	r, err := result[controllers.HostResult](context.Background(), c)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	host := newHost(r)
+	if host.Hostname != "hogeserver1.hogehoge.hoge" || host.Address != "172.21.1.1" || host.TtlLease != "1h0m0s" {
+		t.Error("host is missmatched")
+	}
+
+	c = newGrpcContext(context.Background(), nil, nil, nil)
+	controllers.NewError(c, http.StatusBadRequest, model.NewDomainPermissionError())
+	_, err = result[controllers.HostResult](context.Background(), c)
+	if status.Code(err) != codes.PermissionDenied {
+		t.Error("permission error is returned as " + status.Code(err).String())
+	}
+
+	c = newGrpcContext(context.Background(), nil, nil, nil)
+	controllers.NewError(c, http.StatusBadRequest, errors.New("tenant uuid header is not specified"))
+	_, err = result[controllers.HostResult](context.Background(), c)
+	if status.Code(err) != codes.InvalidArgument || status.Convert(err).Message() != "tenant uuid header is not specified" {
+		t.Error("bad request is returned as " + status.Code(err).String())
+	}
+}
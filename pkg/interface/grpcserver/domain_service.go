@@ -0,0 +1,149 @@
+package grpcserver
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"coredns_api/internal/model"
+	"coredns_api/pkg/interface/controllers"
+	"coredns_api/pkg/interface/grpcserver/pb"
+)
+
+type domainService struct {
+	pb.UnimplementedDomainServiceServer
+	server *Server
+}
+
+func (s *domainService) AddDomain(ctx context.Context, req *pb.AddDomainRequest) (*pb.DomainInfo, error) {
+	c := newGrpcContext(ctx, nil, nil, &controllers.DomainRequest{Name: req.Domain, Tenants: req.Tenants})
+
+	s.server.domains.Add(c)
+	r, err := result[controllers.DomainInfoResult](ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	return newDomainInfo(r), nil
+}
+
+func (s *domainService) ListDomains(ctx context.Context, req *pb.ListDomainsRequest) (*pb.ListDomainsResponse, error) {
+	query := map[string]string{"name": req.Name, "address": req.Address, "tenant": req.Tenant, "cursor": req.Cursor}
+	if req.Limit != 0 {
+		query["limit"] = strconv.Itoa(int(req.Limit))
+	}
+	c := newGrpcContext(ctx, nil, query, nil)
+
+	s.server.domains.List(c)
+	r, err := result[controllers.DomainListResult](ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	return newListDomainsResponse(r), nil
+}
+
+func (s *domainService) GetDomain(ctx context.Context, req *pb.GetDomainRequest) (*pb.DomainInfo, error) {
+	c := newGrpcContext(ctx, map[string]string{"domain_uuid": req.DomainUuid}, nil, nil)
+
+	s.server.domains.Get(c)
+	r, err := result[controllers.DomainInfoResult](ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	return newDomainInfo(r), nil
+}
+
+func (s *domainService) UpdateDomain(ctx context.Context, req *pb.UpdateDomainRequest) (*pb.DomainInfo, error) {
+	c := newGrpcContext(ctx, map[string]string{"domain_uuid": req.DomainUuid}, nil, &controllers.DomainUpdateRequest{Tenants: req.Tenants})
+
+	s.server.domains.Update(c)
+	r, err := result[controllers.DomainInfoResult](ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	return newDomainInfo(r), nil
+}
+
+func (s *domainService) DeleteDomain(ctx context.Context, req *pb.DeleteDomainRequest) (*pb.DeleteDomainResponse, error) {
+	c := newGrpcContext(ctx, map[string]string{"domain_uuid": req.DomainUuid}, nil, nil)
+
+	s.server.domains.Delete(c)
+	return &pb.DeleteDomainResponse{}, noResult(ctx, c)
+}
+
+func (s *domainService) WatchDomain(req *pb.WatchDomainRequest, stream pb.DomainService_WatchDomainServer) error {
+	ctx := stream.Context()
+
+	// The subscription is made before getting the domain not to miss any change in between.
+	subscription := s.server.events.Subscribe()
+	defer subscription.Close()
+
+	c := newGrpcContext(ctx, map[string]string{"domain_uuid": req.DomainUuid}, nil, nil)
+
+	s.server.domains.Get(c)
+	r, err := result[controllers.DomainInfoResult](ctx, c)
+	if err != nil {
+		return err
+	}
+
+	domain := newDomainInfo(r)
+	err = stream.Send(&pb.WatchDomainResponse{Message: &pb.WatchDomainResponse_Domain{Domain: domain}})
+	if err != nil {
+		return err
+	}
+
+	// The tenant and the domain UUID are valid, since the domain is got with them.
+	requestTenantUuid := model.Uuid(c.GetHeader("Tenant"))
+	domainUuid := model.Uuid(domain.Uuid)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "events are dropped since the client can't keep up with them")
+			}
+			if event.DomainUuid != domainUuid || !s.server.events.CanReceive(event, requestTenantUuid) {
+				continue
+			}
+
+			err = stream.Send(&pb.WatchDomainResponse{Message: &pb.WatchDomainResponse_Event{Event: newEvent(event)}})
+			if err != nil {
+				return err
+			}
+			if s.server.events.IsLastFor(event, requestTenantUuid) {
+				return nil
+			}
+		}
+	}
+}
+
+func newEvent(event *model.Event) *pb.Event {
+	return &pb.Event{
+		Id:         string(event.Uuid),
+		Type:       string(event.Type),
+		Time:       event.Time.Format(time.RFC3339Nano),
+		DomainUuid: string(event.DomainUuid),
+		Domain:     event.Domain.String(),
+		Before:     newEventState(event.Before),
+		After:      newEventState(event.After),
+	}
+}
+
+func newEventState(state interface{}) *pb.EventState {
+	switch s := state.(type) {
+	case *model.EventDomain:
+		domain := &pb.Domain{Domain: s.Name.String(), Uuid: string(s.Uuid)}
+		for _, t := range s.Tenants {
+			domain.Tenants = append(domain.Tenants, string(t))
+		}
+		return &pb.EventState{State: &pb.EventState_Domain{Domain: domain}}
+	case *model.EventHost:
+		host := &pb.Host{Hostname: s.Name, Address: s.Address, Uuid: string(s.Uuid), ExpiresAt: s.ExpiresAt, TtlLease: s.TtlLease}
+		return &pb.EventState{State: &pb.EventState_Host{Host: host}}
+	default:
+		return nil
+	}
+}
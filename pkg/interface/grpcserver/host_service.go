@@ -0,0 +1,82 @@
+package grpcserver
+
+import (
+	"context"
+	"strconv"
+
+	"coredns_api/pkg/interface/controllers"
+	"coredns_api/pkg/interface/grpcserver/pb"
+)
+
+type hostService struct {
+	pb.UnimplementedHostServiceServer
+	server *Server
+}
+
+func (s *hostService) AddHost(ctx context.Context, req *pb.AddHostRequest) (*pb.DomainInfo, error) {
+	body := &controllers.HostRequest{Name: req.Hostname, Address: req.Address, Pool: req.Pool, ExpiresAt: req.ExpiresAt, TtlLease: req.TtlLease}
+	c := newGrpcContext(ctx, map[string]string{"domain_uuid": req.DomainUuid}, nil, body)
+
+	s.server.hosts.Add(c)
+	r, err := result[controllers.DomainInfoResult](ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	return newDomainInfo(r), nil
+}
+
+func (s *hostService) ListHosts(ctx context.Context, req *pb.ListHostsRequest) (*pb.ListHostsResponse, error) {
+	query := map[string]string{"name": req.Name, "address": req.Address, "cursor": req.Cursor}
+	if req.Limit != 0 {
+		query["limit"] = strconv.Itoa(int(req.Limit))
+	}
+	c := newGrpcContext(ctx, map[string]string{"domain_uuid": req.DomainUuid}, query, nil)
+
+	s.server.hosts.List(c)
+	r, err := result[controllers.HostListResult](ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	return newListHostsResponse(r), nil
+}
+
+func (s *hostService) GetHost(ctx context.Context, req *pb.GetHostRequest) (*pb.Host, error) {
+	c := newGrpcContext(ctx, map[string]string{"domain_uuid": req.DomainUuid, "host_uuid": req.HostUuid}, nil, nil)
+
+	s.server.hosts.Get(c)
+	r, err := result[controllers.HostResult](ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	return newHost(r), nil
+}
+
+func (s *hostService) UpdateHost(ctx context.Context, req *pb.UpdateHostRequest) (*pb.DomainInfo, error) {
+	body := &controllers.HostRequest{Name: req.Hostname, Address: req.Address, ExpiresAt: req.ExpiresAt, TtlLease: req.TtlLease}
+	c := newGrpcContext(ctx, map[string]string{"domain_uuid": req.DomainUuid, "host_uuid": req.HostUuid}, nil, body)
+
+	s.server.hosts.Update(c)
+	r, err := result[controllers.DomainInfoResult](ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	return newDomainInfo(r), nil
+}
+
+func (s *hostService) DeleteHost(ctx context.Context, req *pb.DeleteHostRequest) (*pb.DeleteHostResponse, error) {
+	c := newGrpcContext(ctx, map[string]string{"domain_uuid": req.DomainUuid, "host_uuid": req.HostUuid}, nil, nil)
+
+	s.server.hosts.Delete(c)
+	return &pb.DeleteHostResponse{}, noResult(ctx, c)
+}
+
+func (s *hostService) RenewHost(ctx context.Context, req *pb.RenewHostRequest) (*pb.Host, error) {
+	c := newGrpcContext(ctx, map[string]string{"domain_uuid": req.DomainUuid, "host_uuid": req.HostUuid}, nil, &controllers.HostRenewRequest{TtlLease: req.TtlLease})
+
+	s.server.hosts.Renew(c)
+	r, err := result[controllers.HostResult](ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	return newHost(r), nil
+}
@@ -0,0 +1,60 @@
+package grpcserver
+
+import (
+	"coredns_api/pkg/interface/controllers"
+	"coredns_api/pkg/interface/grpcserver/pb"
+)
+
+func newDomainInfo(r controllers.DomainInfoResult) *pb.DomainInfo {
+	return &pb.DomainInfo{
+		Domain:  r.Domain,
+		Uuid:    r.Uuid,
+		Tenants: r.Tenants,
+		Status:  r.Status,
+		Error:   r.Error,
+		Hosts:   newHostList(r.Hosts),
+	}
+}
+
+func newDomain(r controllers.DomainResult) *pb.Domain {
+	return &pb.Domain{Domain: r.Domain, Uuid: r.Uuid, Tenants: r.Tenants, Status: r.Status, Error: r.Error}
+}
+
+func newListDomainsResponse(r controllers.DomainListResult) *pb.ListDomainsResponse {
+	resp := &pb.ListDomainsResponse{Total: int32(r.Total), Next: r.Next}
+	for _, d := range r.Domains {
+		resp.Domains = append(resp.Domains, newDomain(d))
+	}
+	return resp
+}
+
+func newHost(r controllers.HostResult) *pb.Host {
+	return &pb.Host{Hostname: r.Name, Address: r.Address, Uuid: r.Uuid, ExpiresAt: r.ExpiresAt, TtlLease: r.TtlLease}
+}
+
+func newHostList(results []controllers.HostResult) []*pb.Host {
+	var hosts []*pb.Host
+	for _, h := range results {
+		hosts = append(hosts, newHost(h))
+	}
+	return hosts
+}
+
+func newListHostsResponse(r controllers.HostListResult) *pb.ListHostsResponse {
+	return &pb.ListHostsResponse{
+		Domain:  r.Domain,
+		Uuid:    r.Uuid,
+		Tenants: r.Tenants,
+		Hosts:   newHostList(r.Hosts),
+		Total:   int32(r.Total),
+		Next:    r.Next,
+	}
+}
+
+func newListTenantsResponse(r controllers.TenantInfoResult) *pb.ListTenantsResponse {
+	resp := &pb.ListTenantsResponse{}
+	for _, t := range r.Tenants {
+		resp.Tenants = append(resp.Tenants, &pb.Tenant{Uuid: t.Uuid, Domains: t.Domains})
+	}
+	return resp
+}
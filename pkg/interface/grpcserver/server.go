@@ -0,0 +1,44 @@
+package grpcserver
+
+import (
+	"net"
+
+	"google.golang.org/grpc"
+
+	"coredns_api/internal/usecase"
+	"coredns_api/pkg/interface/controllers"
+	"coredns_api/pkg/interface/grpcserver/pb"
+)
+
+// Server is the gRPC listener of the API.
+// Unary calls are handled by the same controllers as the REST API,
+// and WatchDomain streams the events from the event bus.
+type Server struct {
+	domains *controllers.DomainController
+	hosts   *controllers.HostController
+	tenants *controllers.TenantController
+	events  *usecase.EventInteractor
+}
+
+func NewServer(dcntr *controllers.DomainController, hcntr *controllers.HostController, tcntr *controllers.TenantController, eItr *usecase.EventInteractor) *Server {
+	return &Server{domains: dcntr, hosts: hcntr, tenants: tcntr, events: eItr}
+}
+
+// ListenAndServe starts to listen on listen with TCP.
+func (s *Server) ListenAndServe(listen string) error {
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		return err
+	}
+
+	return s.Serve(listener)
+}
+
+// Serve serves gRPC on the listener until it fails.
+func (s *Server) Serve(listener net.Listener) error {
+	server := grpc.NewServer()
+	pb.RegisterDomainServiceServer(server, &domainService{server: s})
+	pb.RegisterHostServiceServer(server, &hostService{server: s})
+	pb.RegisterTenantServiceServer(server, &tenantService{server: s})
+	return server.Serve(listener)
+}
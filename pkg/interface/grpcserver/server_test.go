@@ -0,0 +1,238 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"coredns_api/internal/infrastructure"
+	"coredns_api/internal/interface/repository"
+	"coredns_api/internal/usecase"
+	"coredns_api/pkg/interface/controllers"
+	"coredns_api/pkg/interface/grpcserver/pb"
+)
+
+const (
+	testTenant      = "df397e50-8006-450e-b18b-5c5bd940baff"
+	testOtherTenant = "02c03bd4-fe2e-45f2-85b6-b535af15215d"
+)
+
+// newTestConn serves the gRPC API with the controllers on the empty HOSTS_DIR and CONF_PATH in a temporary directory.
+func newTestConn(t *testing.T) *grpc.ClientConn {
+	dir := t.TempDir()
+	hostsDir := filepath.Join(dir, "hosts")
+	err := os.Mkdir(hostsDir, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOSTS_DIR", hostsDir)
+	t.Setenv("CONF_PATH", filepath.Join(dir, "coredns.conf"))
+
+	fsRepository := repository.NewFileRepository(infrastructure.NewFilesystem())
+	fsRepository.Initialize()
+	events := repository.NewEventBus()
+	server := NewServer(
+		controllers.NewDomainController(usecase.NewDomainInteractor(fsRepository, events, nil)),
+		controllers.NewHostController(usecase.NewHostInteractor(fsRepository, events, nil)),
+		controllers.NewTenantController(usecase.NewTenantInteractor(fsRepository)),
+		usecase.NewEventInteractor(events))
+
+	listener := bufconn.Listen(1024 * 1024)
+	go server.Serve(listener)
+	t.Cleanup(func() { listener.Close() })
+
+	dialer := func(context.Context, string) (net.Conn, error) { return listener.Dial() }
+	conn, err := grpc.DialContext(context.Background(), "bufnet", grpc.WithContextDialer(dialer), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func withTenant(tenant string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "tenant", tenant)
+}
+
+func TestTenantMetadata(t *testing.T) {
+	domains := pb.NewDomainServiceClient(newTestConn(t))
+
+	domain, err := domains.AddDomain(withTenant(testTenant), &pb.AddDomainRequest{Domain: "hogehoge.hoge", Tenants: []string{testTenant}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if domain.Domain != "hogehoge.hoge" || len(domain.Tenants) != 1 || domain.Tenants[0] != testTenant {
+		t.Error("domain is missmatched")
+	}
+
+	_, err = domains.GetDomain(withTenant(testTenant), &pb.GetDomainRequest{DomainUuid: domain.Uuid})
+	if err != nil {
+		t.Error(err)
+	}
+
+	_, err = domains.GetDomain(withTenant(testOtherTenant), &pb.GetDomainRequest{DomainUuid: domain.Uuid})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Error("domain of another tenant is got: " + status.Code(err).String())
+	}
+
+	_, err = domains.GetDomain(context.Background(), &pb.GetDomainRequest{DomainUuid: domain.Uuid})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Error("domain is got without the tenant: " + status.Code(err).String())
+	}
+
+	list, err := domains.ListDomains(withTenant(testOtherTenant), &pb.ListDomainsRequest{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(list.Domains) != 0 {
+		t.Error("domains of another tenant are listed")
+	}
+}
+
+func TestWatchDomain(t *testing.T) {
+	conn := newTestConn(t)
+	domains := pb.NewDomainServiceClient(conn)
+	hosts := pb.NewHostServiceClient(conn)
+
+	ctx := withTenant(testTenant)
+	watched, err := domains.AddDomain(ctx, &pb.AddDomainRequest{Domain: "hogehoge.hoge", Tenants: []string{testTenant, testOtherTenant}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	other, err := domains.AddDomain(ctx, &pb.AddDomainRequest{Domain: "fugafuga.hoge", Tenants: []string{testTenant}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// The error of a stream is returned on the first receive.
+	denied, err := domains.WatchDomain(withTenant(testOtherTenant), &pb.WatchDomainRequest{DomainUuid: other.Uuid})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err = denied.Recv()
+	if status.Code(err) != codes.PermissionDenied {
+		t.Error("domain of another tenant is watched: " + status.Code(err).String())
+	}
+
+	watchCtx, cancel := context.WithTimeout(withTenant(testOtherTenant), 5*time.Second)
+	defer cancel()
+	stream, err := domains.WatchDomain(watchCtx, &pb.WatchDomainRequest{DomainUuid: watched.Uuid})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	first, err := stream.Recv()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if first.GetDomain().GetUuid() != watched.Uuid {
+		t.Error("watched domain is not sent at first")
+	}
+
+	// The change of the other domain is not sent.
+	_, err = hosts.AddHost(ctx, &pb.AddHostRequest{DomainUuid: other.Uuid, Hostname: "hogeserver1", Address: "172.21.1.1"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err = hosts.AddHost(ctx, &pb.AddHostRequest{DomainUuid: watched.Uuid, Hostname: "hogeserver2", Address: "172.21.1.2"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	added, err := stream.Recv()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if added.GetEvent().GetType() != "HostAdded" || added.GetEvent().GetAfter().GetHost().GetHostname() != "hogeserver2.hogehoge.hoge" {
+		t.Error("host added to the watched domain is not sent first")
+	}
+
+	// The stream ends when the tenant loses the domain, and nothing after it is sent.
+	_, err = domains.UpdateDomain(ctx, &pb.UpdateDomainRequest{DomainUuid: watched.Uuid, Tenants: []string{testTenant}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	changed, err := stream.Recv()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if changed.GetEvent().GetType() != "TenantsChanged" {
+		t.Error("tenants change is not sent")
+	}
+
+	_, err = hosts.AddHost(ctx, &pb.AddHostRequest{DomainUuid: watched.Uuid, Hostname: "hogeserver3", Address: "172.21.1.3"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err = stream.Recv()
+	if err == nil {
+		t.Error("event is sent after the tenant lost the domain")
+	}
+}
+
+func TestListTenants(t *testing.T) {
+	t.Setenv("ADMIN_TENANTS", "")
+	conn := newTestConn(t)
+	domains := pb.NewDomainServiceClient(conn)
+	tenants := pb.NewTenantServiceClient(conn)
+
+	shared, err := domains.AddDomain(withTenant(testTenant), &pb.AddDomainRequest{Domain: "hogehoge.hoge", Tenants: []string{testTenant, testOtherTenant}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err = domains.AddDomain(withTenant(testTenant), &pb.AddDomainRequest{Domain: "fugafuga.hoge", Tenants: []string{testTenant}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	_, err = tenants.ListTenants(context.Background(), &pb.ListTenantsRequest{})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Error("tenants are listed without the tenant: " + status.Code(err).String())
+	}
+
+	list, err := tenants.ListTenants(withTenant(testOtherTenant), &pb.ListTenantsRequest{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(list.Tenants) != 1 || list.Tenants[0].Uuid != testOtherTenant {
+		t.Error("other tenants are listed")
+		return
+	}
+	if len(list.Tenants[0].Domains) != 1 || list.Tenants[0].Domains[0] != shared.Uuid {
+		t.Error("domains of the tenant are missmatched")
+	}
+
+	t.Setenv("ADMIN_TENANTS", testOtherTenant)
+	list, err = tenants.ListTenants(withTenant(testOtherTenant), &pb.ListTenantsRequest{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(list.Tenants) != 2 {
+		t.Error("every tenant is not listed for the admin tenant")
+	}
+}
@@ -0,0 +1,24 @@
+package grpcserver
+
+import (
+	"context"
+
+	"coredns_api/pkg/interface/controllers"
+	"coredns_api/pkg/interface/grpcserver/pb"
+)
+
+type tenantService struct {
+	pb.UnimplementedTenantServiceServer
+	server *Server
+}
+
+func (s *tenantService) ListTenants(ctx context.Context, req *pb.ListTenantsRequest) (*pb.ListTenantsResponse, error) {
+	c := newGrpcContext(ctx, nil, nil, nil)
+
+	s.server.tenants.List(c)
+	r, err := result[controllers.TenantInfoResult](ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	return newListTenantsResponse(r), nil
+}
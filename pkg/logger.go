@@ -1,20 +1,58 @@
 package pkg
 
 import (
-	"log"
+	"io"
+	"log/slog"
 	"os"
+
+	"coredns_api/internal/model"
 )
 
-var logfile = "/tmp/test.log"
+// SetLogger sets the default logger from LOG_LEVEL, LOG_FORMAT and LOG_OUTPUT.
+// The standard log package writes to it too.
+// The log file is rotated with LOG_MAX_SIZE_MB and LOG_MAX_BACKUPS.
+func SetLogger() error {
+	level, err := model.GetLogLevel()
+	if err != nil {
+		return err
+	}
 
-func SetLogger(processUUID string) *log.Logger {
-	f, err := os.OpenFile(logfile, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	format, err := model.GetLogFormat()
 	if err != nil {
-		log.Printf("error opening file: %v", err)
+		return err
 	}
-	defer f.Close()
 
-	log.SetOutput(f)
-	logger := log.New(f, "["+processUUID+"]", log.LstdFlags|log.LUTC)
-	return logger
+	var w io.Writer
+	switch output := model.GetLogOutput(); output {
+	case "stdout":
+		w = os.Stdout
+	case "stderr":
+		w = os.Stderr
+	default:
+		maxSize, err := model.GetLogMaxSize()
+		if err != nil {
+			return err
+		}
+		maxBackups, err := model.GetLogMaxBackups()
+		if err != nil {
+			return err
+		}
+
+		w, err = NewRotatingFile(output, maxSize, maxBackups)
+		if err != nil {
+			return err
+		}
+	}
+
+	slog.SetDefault(NewLogger(w, format, level))
+	return nil
+}
+
+// NewLogger returns the logger writing with the format, text or json.
+func NewLogger(w io.Writer, format string, level slog.Level) *slog.Logger {
+	options := &slog.HandlerOptions{Level: level}
+	if format == "json" {
+		return slog.New(slog.NewJSONHandler(w, options))
+	}
+	return slog.New(slog.NewTextHandler(w, options))
 }
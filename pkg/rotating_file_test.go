@@ -0,0 +1,43 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRotatingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "api.log")
+
+	f, err := NewRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer f.Close()
+
+	for _, line := range []string{"line1\n", "line2\n", "line3\n", "line4\n"} {
+		_, err = f.Write([]byte(line))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	for file, expected := range map[string]string{path: "line4\n", path + ".1": "line3\n", path + ".2": "line2\n"} {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if string(data) != expected {
+			t.Error("log file is missmatched. file: " + file + ", data: " + string(data))
+		}
+	}
+
+	_, err = os.Stat(path + ".3")
+	if !os.IsNotExist(err) {
+		t.Error("more backups than maxBackups are kept")
+	}
+}
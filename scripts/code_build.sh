@@ -1,6 +1,10 @@
 #!/bin/sh
 
+protoc -I pkg/interface/grpcserver/pb \
+  --go_out=pkg/interface/grpcserver/pb --go_opt=paths=source_relative \
+  --go-grpc_out=pkg/interface/grpcserver/pb --go-grpc_opt=paths=source_relative \
+  coredns_api.proto
 wire cmd/web/infrastructure/wire.go
 go build -o build/coredns-api cmd/web/main.go
-go build -o build/tenant-list-command cmd/command/main.go
+go build -o build/corednsctl cmd/command/main.go
 swag init -g cmd/web/main.go
@@ -6,4 +6,4 @@ export PORT=8080
 export CONF_PATH=${PWD}/coredns_conf/coredns.conf
 export HOSTS_DIR=${PWD}/coredns_conf/hosts/
 
-./build/tenant-list-command
\ No newline at end of file
+./build/corednsctl tenants list
\ No newline at end of file